@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"shin-monta-no-mori/internal/app"
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/internal/domains/service"
+	"shin-monta-no-mori/pkg/lib/apperror"
+	"shin-monta-no-mori/pkg/lib/binder"
+)
+
+const (
+	// 期間が指定されなかった場合に取得する日数
+	defaultAuditLogDays = 30
+	// 1ページあたりの監査ログの件数
+	auditLogFetchLimit = 50
+)
+
+type (
+	listAuditLogsRequest struct {
+		Page       int64  `form:"p"`
+		Operator   string `form:"operator"`
+		Action     string `form:"action" binding:"omitempty,oneof=create update delete restore reorder rollback disable enable"`
+		EntityType string `form:"entity_type" binding:"omitempty,oneof=illustration daily_illustration character parent_category child_category synonym operator"`
+		EntityID   string `form:"entity_id"`
+		From       string `form:"from"`
+		To         string `form:"to"`
+	}
+
+	listAuditLogsResponse struct {
+		AuditLogs  []db.AuditLog `json:"audit_logs"`
+		TotalPages int64         `json:"total_pages"`
+		TotalCount int64         `json:"total_count"`
+	}
+)
+
+// ListAuditLogs godoc
+// @Summary List audit logs
+// @Description Retrieves the history of admin operations, newest first. Each log has the operator, action, entity type and ID, the changed fields with their values before and after the operation, client IP and user agent. Only owners can list them. Defaults to the last 30 days (JST dates, both inclusive).
+// @Accept  json
+// @Produce  json
+// @Param   p            query  int     false  "Page number for pagination"
+// @Param   operator     query  string  false  "Name of the operator"
+// @Param   action       query  string  false  "Action (create, update, delete, restore, reorder, rollback, disable, enable)"
+// @Param   entity_type  query  string  false  "Entity type (illustration, daily_illustration, character, parent_category, child_category, synonym, operator)"
+// @Param   entity_id    query  string  false  "ID of the entity (date for daily illustrations)"
+// @Param   from         query  string  false  "Start date (YYYY-MM-DD)"
+// @Param   to           query  string  false  "End date (YYYY-MM-DD)"
+// @Success 200 {object} listAuditLogsResponse "A list of audit logs"
+// @Failure 400 {object} apperror.Response "Bad Request: The filters or the period are malformed"
+// @Failure 403 {object} apperror.Response "Forbidden: The operator is not an owner"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to list the audit logs"
+// @Router /api/v1/admin/audit-logs [get]
+func ListAuditLogs(ctx *app.AppContext) {
+	var req listAuditLogsRequest
+	if err := binder.BindQuery(ctx.Context, &req); err != nil {
+		return
+	}
+	from, to, err := parsePeriod(req.From, req.To, defaultAuditLogDays)
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+
+	logs, totalCount, err := ctx.Server.AuditLogService.List(ctx, service.ListAuditLogsParams{
+		OperatorName: req.Operator,
+		Action:       req.Action,
+		EntityType:   req.EntityType,
+		EntityID:     req.EntityID,
+		From:         from,
+		To:           to,
+		Limit:        auditLogFetchLimit,
+		Offset:       int32(req.Page * auditLogFetchLimit),
+	})
+	if err != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListAuditLogs : %w", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, listAuditLogsResponse{
+		AuditLogs:  logs,
+		TotalPages: (totalCount + auditLogFetchLimit - 1) / auditLogFetchLimit,
+		TotalCount: totalCount,
+	})
+}
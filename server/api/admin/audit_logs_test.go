@@ -0,0 +1,178 @@
+package admin_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"shin-monta-no-mori/internal/app"
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/pkg/util"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type auditLogsTest struct{}
+
+func TestListAuditLogs(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	a := auditLogsTest{}
+	ctx := a.setUp(t, config)
+	defer a.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	// 同義語の編集・削除を監査ログに記録する
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("word", "ねこ"))
+	require.NoError(t, writer.WriteField("synonym", "キャット"))
+	require.NoError(t, writer.Close())
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/api/v1/admin/synonyms/81001", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("User-Agent", "audit-test")
+	ctx.Server.Router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodDelete, "/api/v1/admin/synonyms/81001", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("User-Agent", "audit-test")
+	ctx.Server.Router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	tests := []struct {
+		name         string
+		query        string
+		wantActions  []string
+		expectedCode int
+	}{
+		{
+			name:         "正常系",
+			query:        "entity_type=synonym&entity_id=81001",
+			wantActions:  []string{"delete", "update"},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（操作で絞り込む場合）",
+			query:        "entity_type=synonym&entity_id=81001&action=update",
+			wantActions:  []string{"update"},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（該当する監査ログがない場合）",
+			query:        "operator=unknown",
+			wantActions:  []string{},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（存在しない操作の場合）",
+			query:        "action=unknown",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系（期間の形式が不正な場合）",
+			query:        "from=2024/01/01",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/audit-logs?"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.wantActions != nil {
+				var got struct {
+					AuditLogs  []db.AuditLog `json:"audit_logs"`
+					TotalCount int64         `json:"total_count"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				require.Equal(t, int64(len(tt.wantActions)), got.TotalCount)
+
+				actions := make([]string, 0, len(got.AuditLogs))
+				for _, l := range got.AuditLogs {
+					actions = append(actions, l.Action)
+					require.Equal(t, "testuser", l.OperatorName)
+					require.Equal(t, "audit-test", l.UserAgent)
+				}
+				require.Equal(t, tt.wantActions, actions)
+			}
+		})
+	}
+
+	// 変更された項目のみが変更前後の値とともに記録される
+	logs, err := ctx.Server.Store.ListAuditLogs(context.Background(), db.ListAuditLogsParams{
+		Action:     "update",
+		EntityType: "synonym",
+		EntityID:   "81001",
+		FromTime:   time.Now().Add(-time.Hour),
+		ToTime:     time.Now().Add(time.Hour),
+		Limit:      1,
+	})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	var diff map[string]struct {
+		Before any `json:"before"`
+		After  any `json:"after"`
+	}
+	require.NoError(t, json.Unmarshal(logs[0].Diff, &diff))
+	require.Equal(t, "猫", diff["synonym"].Before)
+	require.Equal(t, "キャット", diff["synonym"].After)
+	require.NotContains(t, diff, "word")
+}
+
+func (a auditLogsTest) setUp(t *testing.T, config util.Config) *app.AppContext {
+	store := createConn(config)
+
+	queries := []string{
+		fmt.Sprintln(`
+		INSERT INTO synonyms (id, word, synonym)
+		VALUES
+		(81001, 'ねこ', '猫');
+		`),
+	}
+
+	for _, query := range queries {
+		if _, err := store.ExecQuery(context.Background(), query); err != nil {
+			t.Fatalf("Failed to exec query: %v", err)
+		}
+	}
+
+	s, err := newTestServer(store, config)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	return s
+}
+
+func (a auditLogsTest) tearDown(t *testing.T, config util.Config) {
+	store := createConn(config)
+
+	queries := []string{
+		"TRUNCATE TABLE audit_logs RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE synonyms RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE operators RESTART IDENTITY CASCADE;",
+	}
+	for _, query := range queries {
+		if _, err := store.ExecQuery(context.Background(), query); err != nil {
+			t.Fatalf("Failed to truncate table: %v", err)
+		}
+	}
+}
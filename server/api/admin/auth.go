@@ -2,11 +2,18 @@ package admin
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"shin-monta-no-mori/internal/app"
-	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/internal/domains/service"
+	"shin-monta-no-mori/pkg/lib/apperror"
 	"shin-monta-no-mori/pkg/lib/password"
+	"shin-monta-no-mori/pkg/token"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -18,104 +25,220 @@ type (
 		Email    string `json:"email" binding:"required,email"`
 	}
 
-	loginResponse struct {
-		AccessToken string `json:"access_token"`
+	tokenResponse struct {
+		AccessToken           string    `json:"access_token"`
+		AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+		RefreshToken          string    `json:"refresh_token"`
+		RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
 	}
 )
 
+func newTokenResponse(tokens service.AuthTokens) tokenResponse {
+	return tokenResponse{
+		AccessToken:           tokens.AccessToken,
+		AccessTokenExpiresAt:  tokens.AccessTokenExpiresAt,
+		RefreshToken:          tokens.RefreshToken,
+		RefreshTokenExpiresAt: tokens.RefreshTokenExpiresAt,
+	}
+}
+
+// Login godoc
+// @Summary Log in
+// @Description Issues an access token and a refresh token for the operator. Attempts are limited per client IP and per email, and the email is locked for a while after repeated failures, doubling the period for each lock.
+// @Accept  json
+// @Produce  json
+// @Param   body body loginRequest true "Email and password of the operator"
+// @Success 200 {object} tokenResponse "Access token and refresh token"
+// @Failure 400 {object} apperror.Response "Bad Request: The email or password is malformed"
+// @Failure 401 {object} apperror.Response "Unauthorized: The email or password is incorrect. The same response is returned whether the email is registered or not"
+// @Failure 429 {object} apperror.Response "Too Many Requests: Too many attempts or the email is locked. Retry-After header has the seconds to wait"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to log in"
+// @Router /api/v1/auth/login [post]
 func Login(ctx *app.AppContext) {
 	var req loginRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(err))
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+
+	// IP・メールアドレスごとの試行回数と、連続した失敗によるロックを確認
+	if err := ctx.Server.LoginThrottleService.Allow(ctx, ctx.ClientIP(), req.Email); err != nil {
+		failLoginThrottled(ctx, err)
 		return
 	}
 
+	// 登録されているメールアドレスかを推測されないよう、
+	// メールアドレスが存在しない場合もパスワードの確認と同じ時間をかけ、同じレスポンスを返す
 	operator, err := ctx.Server.Store.GetOperatorByEmail(ctx, req.Email)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, app.ErrorResponse(err))
+			password.CheckDummyPassword(req.Password)
+			failLogin(ctx, service.LoginAttemptParams{
+				Email:         req.Email,
+				FailureReason: service.LoginFailureUnknownEmail,
+			}, err)
 			return
 		}
-		ctx.Server.Logger.Error("failed to GetOperatorByEmail",
-			zap.String("email", req.Email),
-			zap.Error(err),
-		)
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(err))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to GetOperatorByEmail : %w", err))
 		return
 	}
 
 	if err = password.CheckPassword(req.Password, operator.HashedPassword); err != nil {
-		ctx.Server.Logger.Info("failed to CheckPassword",
-			zap.Error(err),
-		)
-		ctx.JSON(http.StatusUnauthorized, app.ErrorResponse(err))
+		failLogin(ctx, service.LoginAttemptParams{
+			Email:         req.Email,
+			OperatorName:  operator.Name,
+			FailureReason: service.LoginFailureWrongPassword,
+		}, err)
 		return
 	}
 	if err = password.CheckEmail(req.Email, operator.Email); err != nil {
-		ctx.Server.Logger.Info("failed to CheckEmail",
-			zap.String("email", req.Email),
-			zap.Error(err),
-		)
-		ctx.JSON(http.StatusUnauthorized, app.ErrorResponse(err))
+		failLogin(ctx, service.LoginAttemptParams{
+			Email:         req.Email,
+			FailureReason: service.LoginFailureUnknownEmail,
+		}, err)
+		return
+	}
+
+	// 無効化されたオペレーターも、パスワードが誤っている場合と同じレスポンスを返す
+	if operator.DisabledAt.Valid {
+		failLogin(ctx, service.LoginAttemptParams{
+			Email:         req.Email,
+			OperatorName:  operator.Name,
+			FailureReason: service.LoginFailureDisabled,
+		}, service.ErrOperatorDisabled)
 		return
 	}
 
 	// アクセストークンとリフレッシュトークンを作成し、セッションに保存
-	accessToken, _, err := ctx.Server.TokenMaker.CreateToken(
-		operator.Name,
-		ctx.Server.Config.AccessTokenDuration,
-	)
+	tokens, err := ctx.Server.AuthService.StartSession(ctx, service.StartSessionParams{
+		OperatorName: operator.Name,
+		Role:         token.Role(operator.Role),
+		Email:        req.Email,
+		ClientIP:     ctx.ClientIP(),
+		UserAgent:    ctx.Request.UserAgent(),
+	})
 	if err != nil {
-		ctx.Server.Logger.Info("failed to CreateToken",
-			zap.String("email", req.Email),
-			zap.Error(err),
-		)
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(err))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to StartSession : %w", err))
 		return
 	}
 
-	refreshToken, refreshPayload, err := ctx.Server.TokenMaker.CreateToken(
-		operator.Name,
-		ctx.Server.Config.RefreshTokenDuration,
+	recordLoginAttempt(ctx, service.LoginAttemptParams{
+		Email:        req.Email,
+		OperatorName: operator.Name,
+		Succeeded:    true,
+	})
+	ctx.Server.LoginThrottleService.Reset(ctx, req.Email)
+
+	rsp := newTokenResponse(tokens)
+
+	ctx.Server.Logger.Info("login success",
+		zap.Int64("operator_id", operator.ID),
+		zap.String("operator_name", operator.Name),
+		zap.String("email", req.Email),
 	)
-	if err != nil {
-		ctx.Server.Logger.Info("failed to CreateToken",
-			zap.String("email", req.Email),
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// recordLoginAttempt はリクエストしたクライアントの情報とともにログインの成否を記録する
+// 記録に失敗した場合もログインの処理は続ける
+func recordLoginAttempt(ctx *app.AppContext, arg service.LoginAttemptParams) {
+	arg.ClientIP = ctx.ClientIP()
+	arg.UserAgent = ctx.Request.UserAgent()
+	if err := ctx.Server.AuthService.RecordLoginAttempt(ctx, arg); err != nil {
+		ctx.Server.Logger.Warn("failed to RecordLoginAttempt",
+			zap.String("email", arg.Email),
 			zap.Error(err),
 		)
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(err))
+	}
+}
+
+// failLogin はログインの失敗を記録し、メールアドレスの有無や失敗した理由によらず同じレスポンスを返す
+// 連続して失敗した場合はメールアドレスをロックする
+func failLogin(ctx *app.AppContext, arg service.LoginAttemptParams, err error) {
+	recordLoginAttempt(ctx, arg)
+	ctx.Server.LoginThrottleService.RecordFailure(ctx, ctx.ClientIP(), arg.Email)
+	ctx.Fail(apperror.InvalidCredentials, fmt.Errorf("%s : %w", arg.FailureReason, err))
+}
+
+// failLoginThrottled はログインが制限されている場合に、再度試行できるまでの秒数とともに429を返す
+func failLoginThrottled(ctx *app.AppContext, err error) {
+	var throttledErr *service.LoginThrottledError
+	if errors.As(err, &throttledErr) {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttledErr.RetryAfter.Seconds()))))
+	}
+	ctx.Fail(apperror.TooManyRequests, err)
+}
+
+// RefreshToken godoc
+// @Summary Refresh the access token
+// @Description Issues a new access token and refresh token from a refresh token stored in the sessions. The used refresh token cannot be used again, and reusing it blocks all sessions of the same login.
+// @Accept  json
+// @Produce  json
+// @Param   body body refreshTokenRequest true "Refresh token issued at login or the previous refresh"
+// @Success 200 {object} tokenResponse "New access token and refresh token"
+// @Failure 400 {object} apperror.Response "Bad Request: The refresh token is missing"
+// @Failure 401 {object} apperror.Response "Unauthorized: The refresh token is invalid, expired, blocked or already used"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to refresh the tokens"
+// @Router /api/v1/auth/refresh [post]
+func RefreshToken(ctx *app.AppContext) {
+	var req refreshTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Fail(apperror.BadRequest, err)
 		return
 	}
 
-	_, err = ctx.Server.Store.CreateSession(ctx, db.CreateSessionParams{
-		ID:           refreshPayload.ID,
-		Name:         operator.Name,
-		Email:        sql.NullString{String: req.Email, Valid: true},
-		RefreshToken: refreshToken,
-		ExpiresAt:    refreshPayload.ExpiredAt,
+	tokens, err := ctx.Server.AuthService.Refresh(ctx, service.RefreshParams{
+		RefreshToken: req.RefreshToken,
+		ClientIP:     ctx.ClientIP(),
+		UserAgent:    ctx.Request.UserAgent(),
 	})
 	if err != nil {
-		ctx.Server.Logger.Info("failed to CreateSessionParams",
-			zap.String("operator_name", operator.Name),
-			zap.String("email", req.Email),
-			zap.Error(err),
-		)
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(err))
+		switch {
+		case errors.Is(err, service.ErrRefreshTokenReused):
+			ctx.Server.Logger.Warn("refresh token reused, blocked the sessions of the login", zap.Error(err))
+			deleteSessionBlockedCache(ctx)
+			ctx.Fail(apperror.Unauthorized, err)
+		case errors.Is(err, service.ErrInvalidRefreshToken),
+			errors.Is(err, service.ErrSessionBlocked),
+			errors.Is(err, service.ErrOperatorDisabled),
+			errors.Is(err, service.ErrSessionExpired):
+			ctx.Fail(apperror.Unauthorized, err)
+		default:
+			ctx.Fail(apperror.Internal, fmt.Errorf("failed to Refresh : %w", err))
+		}
 		return
 	}
 
-	rsp := loginResponse{
-		AccessToken: accessToken,
+	ctx.JSON(http.StatusOK, newTokenResponse(tokens))
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Blocks the session of the access token with all sessions of the same login. The access token and refresh token can no longer be used.
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} gin/H "Returns a success message indicating the operator has logged out"
+// @Failure 401 {object} apperror.Response "Unauthorized: The access token is invalid"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to log out"
+// @Router /api/v1/auth/logout [post]
+func Logout(ctx *app.AppContext) {
+	payload := ctx.AuthPayload()
+
+	if err := ctx.Server.AuthService.Logout(ctx, payload.ID); err != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to Logout : %w", err))
+		return
 	}
 
-	ctx.Server.Logger.Info("login success",
-		zap.Int64("operator_id", operator.ID),
-		zap.String("operator_name", operator.Name),
-		zap.String("email", req.Email),
-		zap.Error(err),
-	)
+	deleteSessionBlockedCache(ctx)
 
-	ctx.JSON(http.StatusOK, rsp)
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "logoutに成功しました",
+	})
 }
 
 type verifyRequest struct {
@@ -125,16 +248,27 @@ type verifyRequest struct {
 func VerifyAccessToken(ctx *app.AppContext) {
 	var req verifyRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		ctx.Server.Logger.Info("failed to bind accessToken", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(err))
+		ctx.Fail(apperror.BadRequest, err)
 		return
 	}
-	_, err := ctx.Server.TokenMaker.VerifyToken(req.AccessToken)
+	payload, err := ctx.Server.TokenMaker.VerifyToken(req.AccessToken)
 	if err != nil {
-		ctx.Server.Logger.Info("failed to VerifyToken", zap.Error(err))
-		ctx.JSON(http.StatusUnauthorized, app.ErrorResponse(err))
+		ctx.Fail(apperror.Unauthorized, err)
+		return
+	}
+	if err := payload.Expect(token.TokenTypeAccess); err != nil {
+		ctx.Fail(apperror.Unauthorized, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"result": true})
 }
+
+// operatorName はリクエストしたオペレーターの名前をアクセストークンから取得する
+func operatorName(ctx *app.AppContext) string {
+	payload := ctx.AuthPayload()
+	if payload == nil {
+		return ""
+	}
+	return payload.Username
+}
@@ -0,0 +1,334 @@
+package admin_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/internal/cache"
+	"shin-monta-no-mori/pkg/lib/apperror"
+	"shin-monta-no-mori/pkg/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type authTest struct{}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func TestRefreshToken(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	a := authTest{}
+	ctx := a.setUp(t, config)
+	defer a.tearDown(t, config)
+
+	_, err = newTestUserCreation(ctx, "testuser", "testtest", "test@test.com")
+	require.NoError(t, err)
+	loggedIn := login(t, ctx, "test@test.com", "testtest")
+
+	// 各ケースで発行されたトークンを後続のケースで使用する
+	var refreshed tokenResponse
+	tests := []struct {
+		name         string
+		refreshToken func() string
+		expectedCode int
+	}{
+		{
+			name:         "正常系",
+			refreshToken: func() string { return loggedIn.RefreshToken },
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（更新済みのリフレッシュトークンを再使用した場合）",
+			refreshToken: func() string { return loggedIn.RefreshToken },
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "異常系（再使用により同じログインのセッションが無効化された場合）",
+			refreshToken: func() string { return refreshed.RefreshToken },
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "異常系（アクセストークンをリフレッシュトークンとして使用した場合）",
+			refreshToken: func() string { return refreshed.AccessToken },
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "異常系（リフレッシュトークンが不正な場合）",
+			refreshToken: func() string { return "invalid_refresh_token" },
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "異常系（リフレッシュトークンが空の場合）",
+			refreshToken: func() string { return "" },
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(map[string]string{"refresh_token": tt.refreshToken()})
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/refresh", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode != http.StatusOK {
+				require.NotEmpty(t, w.Body.String())
+				return
+			}
+
+			err = json.Unmarshal(w.Body.Bytes(), &refreshed)
+			require.NoError(t, err)
+			require.NotEmpty(t, refreshed.AccessToken)
+			require.NotEqual(t, loggedIn.RefreshToken, refreshed.RefreshToken)
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	a := authTest{}
+	ctx := a.setUp(t, config)
+	defer a.tearDown(t, config)
+
+	_, err = newTestUserCreation(ctx, "testuser", "testtest", "test@test.com")
+	require.NoError(t, err)
+	loggedIn := login(t, ctx, "test@test.com", "testtest")
+	other := login(t, ctx, "test@test.com", "testtest")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+loggedIn.AccessToken)
+	ctx.Server.Router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	tests := []struct {
+		name         string
+		accessToken  string
+		expectedCode int
+	}{
+		{
+			name:         "正常系（他のログインのアクセストークンは使用できる）",
+			accessToken:  other.AccessToken,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（ログアウトしたアクセストークンの場合）",
+			accessToken:  loggedIn.AccessToken,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "異常系（ログアウトしたリフレッシュトークンをアクセストークンとして使用した場合）",
+			accessToken:  loggedIn.RefreshToken,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "異常系（リフレッシュトークンをアクセストークンとして使用した場合）",
+			accessToken:  other.RefreshToken,
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/sessions", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.accessToken)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+
+	// ログアウトしたリフレッシュトークンは使用できない
+	body, err := json.Marshal(map[string]string{"refresh_token": loggedIn.RefreshToken})
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/auth/refresh", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	ctx.Server.Router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestLoginThrottle(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	a := authTest{}
+	ctx := a.setUp(t, config)
+	defer a.tearDown(t, config)
+
+	_, err = newTestUserCreation(ctx, "testuser", "testtest", "test@test.com")
+	require.NoError(t, err)
+
+	type testCase struct {
+		name         string
+		email        string
+		password     string
+		expectedCode int
+		wantCode     apperror.Code
+	}
+	wrongPassword := func(name string) testCase {
+		return testCase{
+			name:         name,
+			email:        "test@test.com",
+			password:     "wrongpassword",
+			expectedCode: http.StatusUnauthorized,
+			wantCode:     apperror.InvalidCredentials,
+		}
+	}
+
+	// ケースは順に実行し、失敗の回数を積み重ねる
+	tests := []testCase{
+		{
+			name:         "異常系（登録されていないメールアドレスの場合）",
+			email:        "unknown@test.com",
+			password:     "testtest",
+			expectedCode: http.StatusUnauthorized,
+			wantCode:     apperror.InvalidCredentials,
+		},
+		wrongPassword("異常系（パスワードが誤っている場合は登録されていない場合と同じレスポンス）"),
+		{
+			name:         "正常系（上限に達する前は成功する）",
+			email:        "test@test.com",
+			password:     "testtest",
+			expectedCode: http.StatusOK,
+		},
+	}
+	// 成功で失敗の回数は消去されるため、上限まで連続して失敗させる
+	for i := 1; i <= 5; i++ {
+		tests = append(tests, wrongPassword(fmt.Sprintf("異常系（%d回連続して失敗した場合）", i)))
+	}
+	tests = append(tests, testCase{
+		name:         "異常系（ロックされている場合は正しいパスワードでも失敗する）",
+		email:        "TEST@test.com",
+		password:     "testtest",
+		expectedCode: http.StatusTooManyRequests,
+		wantCode:     apperror.TooManyRequests,
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(map[string]string{"email": tt.email, "password": tt.password})
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusOK {
+				return
+			}
+
+			var got apperror.Response
+			err = json.Unmarshal(w.Body.Bytes(), &got)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCode, got.Error.Code)
+			if tt.expectedCode == http.StatusTooManyRequests {
+				require.NotEmpty(t, w.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func TestLoginThrottleWithWildcardEmail(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	a := authTest{}
+	ctx := a.setUp(t, config)
+	defer a.tearDown(t, config)
+
+	_, err = newTestUserCreation(ctx, "testuser", "testtest", "test@test.com")
+	require.NoError(t, err)
+	_, err = newTestUserCreation(ctx, "wildcard", "testtest", "t*t@test.com")
+	require.NoError(t, err)
+
+	post := func(email, password string) int {
+		body, err := json.Marshal(map[string]string{"email": email, "password": password})
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		ctx.Server.Router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for i := 1; i <= 4; i++ {
+		require.Equal(t, http.StatusUnauthorized, post("test@test.com", "wrongpassword"))
+	}
+	// ワイルドカードを含むメールアドレスのログインで、他のメールアドレスの失敗の回数は消去されない
+	require.Equal(t, http.StatusOK, post("t*t@test.com", "testtest"))
+	require.Equal(t, http.StatusUnauthorized, post("test@test.com", "wrongpassword"))
+	require.Equal(t, http.StatusTooManyRequests, post("test@test.com", "testtest"))
+}
+
+// login はログインして発行されたトークンを返す
+func login(t *testing.T, c *app.AppContext, email, pw string) tokenResponse {
+	body, err := json.Marshal(map[string]string{"email": email, "password": pw})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	c.Server.Router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var got tokenResponse
+	err = json.Unmarshal(w.Body.Bytes(), &got)
+	require.NoError(t, err)
+	return got
+}
+
+func (a authTest) setUp(t *testing.T, config util.Config) *app.AppContext {
+	store := createConn(config)
+	ctx, err := newTestServer(store, config)
+	require.NoError(t, err)
+	return ctx
+}
+
+func (a authTest) tearDown(t *testing.T, config util.Config) {
+	store := createConn(config)
+
+	queries := []string{
+		"TRUNCATE TABLE login_attempts RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE sessions RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE operators RESTART IDENTITY CASCADE;",
+	}
+	for _, query := range queries {
+		if _, err := store.ExecQuery(context.Background(), query); err != nil {
+			t.Fatalf("Failed to truncate table: %v", err)
+		}
+	}
+
+	// ログインの試行回数とロックを消去
+	rdb := cache.NewRedisClient(config)
+	if err := rdb.Del(context.Background(), []string{cache.LoginThrottlePrefix + "*"}); err != nil {
+		t.Fatalf("Failed to delete redis data: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package admin
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"mime/multipart"
 	"net/http"
@@ -10,27 +11,25 @@ import (
 	db "shin-monta-no-mori/internal/db/sqlc"
 	model "shin-monta-no-mori/internal/domains/models"
 	"shin-monta-no-mori/internal/domains/service"
+	"shin-monta-no-mori/pkg/lib/apperror"
 	"shin-monta-no-mori/pkg/lib/binder"
+	"shin-monta-no-mori/pkg/lib/cursor"
 	"strconv"
-	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-const (
-	IMAGE_TYPE_CATEGORY = "category"
-)
-
 type listCategoriesRequest struct {
-	Page int64 `form:"p"`
+	Page   int64  `form:"p"`
+	Cursor string `form:"cursor"`
 }
 
 type listCategoriesResponse struct {
 	Categories []model.Category `json:"categories"`
 	TotalPages int64            `json:"total_pages"`
 	TotalCount int64            `json:"total_count"`
+	NextCursor string           `json:"next_cursor"`
 }
 
 // ListAllCategories handles the request to list all categories including their parent and child categories.
@@ -39,32 +38,16 @@ type listCategoriesResponse struct {
 // @Tags categories
 // @Produce json
 // @Success 200 {object} listCategoriesResponse
-// @Failure 500 {object} app.ErrorResponse
+// @Failure 500 {object} apperror.Response
 // @param ctx AppContext
 // @Router /api/v1/admin/categories/list/all [get]
 func ListAllCategories(ctx *app.AppContext) {
-	pcates, err := ctx.Server.Store.ListAllParentCategories(ctx)
+	categories, err := ctx.Server.CategoryService.ListAll(ctx)
 	if err != nil {
-		ctx.Server.Logger.Error("failed to ListParentCategories", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to ListParentCategories : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListAllCategories : %w", err))
 		return
 	}
 
-	categories := make([]model.Category, len(pcates))
-	for i, pcate := range pcates {
-		ccates, err := ctx.Server.Store.GetChildCategoriesByParentID(ctx, pcate.ID)
-		if err != nil {
-			ctx.Server.Logger.Error("failed to GetChildCategoriesByParentID", zap.Int("parent_category_id", int(pcate.ID)), zap.Error(err))
-			ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetChildCategoriesByParentID : %w", err)))
-			return
-		}
-
-		categories[i] = model.Category{
-			ParentCategory: pcate,
-			ChildCategory:  ccates,
-		}
-	}
-
 	ctx.JSON(http.StatusOK, listCategoriesResponse{Categories: categories})
 }
 
@@ -74,52 +57,41 @@ func ListAllCategories(ctx *app.AppContext) {
 // @Accept  json
 // @Produce  json
 // @Success 200 {array} model/Category "A list of categories with parent and child category details."
-// @Failure 400 {object} request/JSONResponse{data=string} "Bad Request: The request is malformed or missing required fields."
-// @Failure 404 {object} request/JSONResponse{data=string} "Not Found: Child categories not found for one or more parent categories."
-// @Failure 500 {object} request/JSONResponse{data=string} "Internal Server Error: An error occurred on the server which prevented the completion of the request."
+// @Failure 400 {object} apperror.Response "Bad Request: The request is malformed or missing required fields."
+// @Failure 404 {object} apperror.Response "Not Found: Child categories not found for one or more parent categories."
+// @Failure 500 {object} apperror.Response "Internal Server Error: An error occurred on the server which prevented the completion of the request."
 // @Router /api/v1/admin/categories/list [get]
 func ListCategories(ctx *app.AppContext) {
 	var req listCategoriesRequest
 	if err := binder.BindQuery(ctx.Context, &req); err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(err))
-		return
-	}
-
-	arg := db.ListParentCategoriesParams{
-		Limit:  int32(ctx.Server.Config.CategoryFetchLimit),
-		Offset: int32(int(req.Page) * ctx.Server.Config.CategoryFetchLimit),
-	}
-	pcates, err := ctx.Server.Store.ListParentCategories(ctx, arg)
-	if err != nil {
-		ctx.Server.Logger.Error("failed to ListParentCategories", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to ListParentCategories : %w", err)))
+		ctx.Fail(apperror.BadRequest, err)
 		return
 	}
 
-	categories := make([]model.Category, len(pcates))
-	for i, pcate := range pcates {
-		ccates, err := ctx.Server.Store.GetChildCategoriesByParentID(ctx, pcate.ID)
+	var categories []model.Category
+	var err error
+	if req.Cursor != "" {
+		var c cursor.Cursor
+		c, err = binder.BindCursor(ctx.Context, req.Cursor)
 		if err != nil {
-			if err == sql.ErrNoRows {
-				ctx.JSON(http.StatusNotFound, app.ErrorResponse(fmt.Errorf("failed to GetChildCategoriesByParentID: %w", err)))
-				return
-			}
-
-			ctx.Server.Logger.Error("failed to GetChildCategoriesByParentID", zap.Int("parent_category_id", int(pcate.ID)), zap.Error(err))
-			ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetChildCategoriesByParentID : %w", err)))
 			return
 		}
-
-		categories[i] = model.Category{
-			ParentCategory: pcate,
-			ChildCategory:  ccates,
-		}
+		categories, err = ctx.Server.CategoryService.ListByCursor(ctx, c, int32(ctx.Server.Config.CategoryFetchLimit))
+	} else {
+		categories, err = ctx.Server.CategoryService.List(
+			ctx,
+			int32(ctx.Server.Config.CategoryFetchLimit),
+			int32(int(req.Page)*ctx.Server.Config.CategoryFetchLimit),
+		)
+	}
+	if err != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListCategories : %w", err))
+		return
 	}
 
 	totalCount, err := ctx.Server.Store.CountParentCategories(ctx)
 	if err != nil {
-		ctx.Server.Logger.Error("failed to CountParentCategories", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to CountParentCategories : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to CountParentCategories : %w", err))
 		return
 	}
 	totalPages := (totalCount + int64(ctx.Server.Config.CategoryFetchLimit-1)) / int64(ctx.Server.Config.CategoryFetchLimit)
@@ -128,6 +100,7 @@ func ListCategories(ctx *app.AppContext) {
 		Categories: categories,
 		TotalPages: totalPages,
 		TotalCount: totalCount,
+		NextCursor: nextCategoryCursor(categories, ctx.Server.Config.CategoryFetchLimit),
 	})
 }
 
@@ -137,44 +110,34 @@ type getCategoryResponse struct {
 
 // GetCategory godoc
 // @Summary Retrieve a category
-// @Description Retrieves a parent category along with its child categories by the parent category's ID
+// @Description Retrieves a parent category along with its child categories by the parent category's ID. The ETag header holds the version of the parent category to send as If-Match on edit.
 // @Accept  json
 // @Produce  json
 // @Param   id   path   int  true  "ID of the parent category to retrieve"
 // @Success 200 {object} model/Category "The requested parent category with its child categories"
-// @Failure 400 {object} request/JSONResponse{data=string} "Bad Request: Failed to parse 'id' number from path parameter"
-// @Failure 404 {object} request/JSONResponse{data=string} "Not Found: No parent category found with the given ID or no child categories found for the parent category"
-// @Failure 500 {object} request/JSONResponse{data=string} "Internal Server Error: Failed to retrieve the category from the database"
+// @Header  200 {string} ETag "Version of the parent category"
+// @Failure 400 {object} apperror.Response "Bad Request: Failed to parse 'id' number from path parameter"
+// @Failure 404 {object} apperror.Response "Not Found: No parent category found with the given ID or no child categories found for the parent category"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to retrieve the category from the database"
 // @Router /api/v1/admin/categories/{id} [get]
 func GetCategory(ctx *app.AppContext) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err)))
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
 		return
 	}
 
-	pcate, err := ctx.Server.Store.GetParentCategory(ctx, int64(id))
+	category, err := ctx.Server.CategoryService.Get(ctx, int64(id))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, app.ErrorResponse(fmt.Errorf("failed to GetParentCategory: %w", err)))
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
 			return
 		}
-		ctx.Server.Logger.Error("failed to GetParentCategory", zap.Int("parent_category_id", id), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetParentCategory : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to GetCategory : %w", err))
 		return
 	}
 
-	ccates, err := ctx.Server.Store.GetChildCategoriesByParentID(ctx, pcate.ID)
-	if err != nil {
-		ctx.Server.Logger.Error("failed to GetChildCategoriesByParentID", zap.Int("parent_category_id", id), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetChildCategoriesByParentID : %w", err)))
-		return
-	}
-
-	category := model.NewCategory()
-	category.ParentCategory = pcate
-	category.ChildCategory = ccates
-
+	setETag(ctx, category.ParentCategory.UpdatedAt)
 	ctx.JSON(http.StatusOK, getCategoryResponse{
 		Category: category,
 	})
@@ -192,52 +155,28 @@ type searchCategoriesRequest struct {
 // @Produce  json
 // @Param   q   query   string  true  "Query string to search parent categories"
 // @Success 200 {array} model/Category "List of categories with their corresponding child categories"
-// @Failure 400 {object} request/JSONResponse{data=string} "Bad Request: Failed to bind query parameters"
-// @Failure 404 {object} request/JSONResponse{data=string} "Not Found: No child categories found for a parent category"
-// @Failure 500 {object} request/JSONResponse{data=string} "Internal Server Error: Failed to retrieve categories from the database"
+// @Failure 400 {object} apperror.Response "Bad Request: Failed to bind query parameters"
+// @Failure 404 {object} apperror.Response "Not Found: No child categories found for a parent category"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to retrieve categories from the database"
 // @Router /api/v1/admin/categories/search [get]
 func SearchCategories(ctx *app.AppContext) {
 	// TODO: 親カテゴリの検索のみでなく、子カテゴリようの検索APIも追加する。
 	// それか、検索機能は一つにし、親カテゴリが一致する場合は個カテゴリ全て取得、個カテゴリが一致する場合は、子カテゴリの一部と個カテゴリが持つ親カテゴリのみ取得するようにする
 	var req searchCategoriesRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(fmt.Errorf("failed to c.ShouldBindQuery : %w", err)))
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to c.ShouldBindQuery : %w", err))
 		return
 	}
 
-	q := sql.NullString{
-		String: req.Query,
-		Valid:  true,
-	}
-	pcates, err := ctx.Server.Store.SearchParentCategories(ctx, q)
+	categories, err := ctx.Server.CategoryService.Search(ctx, req.Query)
 	if err != nil {
-		ctx.Server.Logger.Error("failed to SearchParentCategories", zap.String("query", req.Query), zap.Int("page", req.Page), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to SearchParentCategories : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to SearchParentCategories : %w", err))
 		return
 	}
 
-	categories := make([]model.Category, len(pcates))
-	for i, pcate := range pcates {
-		ccates, err := ctx.Server.Store.GetChildCategoriesByParentID(ctx, pcate.ID)
-		if err != nil {
-			ctx.Server.Logger.Error("failed to GetChildCategoriesByParentID", zap.Int("parent_category_id", int(pcate.ID)), zap.String("query", req.Query), zap.Int("page", req.Page), zap.Error(err))
-			ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetChildCategoriesByParentID : %w", err)))
-			return
-		}
-
-		categories[i] = model.Category{
-			ParentCategory: pcate,
-			ChildCategory:  ccates,
-		}
-	}
-
-	totalCount, err := ctx.Server.Store.CountSearchParentCategories(ctx, sql.NullString{
-		String: req.Query,
-		Valid:  true,
-	})
+	totalCount, err := ctx.Server.CategoryService.CountSearch(ctx, req.Query)
 	if err != nil {
-		ctx.Server.Logger.Error("failed to CountSearchParentCategories", zap.String("query", req.Query), zap.Int("page", req.Page), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to CountSearchParentCategories : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to CountSearchParentCategories : %w", err))
 		return
 	}
 	totalPages := (totalCount + int64(ctx.Server.Config.CategoryFetchLimit-1)) / int64(ctx.Server.Config.CategoryFetchLimit)
@@ -270,68 +209,37 @@ type createParentCategoryResponse struct {
 // @Param   filename   formData   string  true  "Filename for the uploaded image"
 // @Param   image_file formData   file    true  "Image file for the parent category"
 // @Success 200 {object} gin/H "Returns the created parent category and a success message"
-// @Failure 400 {object} request/JSONResponse{data=string} "Bad Request: Error in data binding or validation"
-// @Failure 500 {object} request/JSONResponse{data=string} "Internal Server Error: Failed to create the parent category due to a server error"
+// @Failure 400 {object} apperror.Response "Bad Request: Error in data binding or validation"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to create the parent category due to a server error"
 // @Router /api/v1/admin/categories/parent/create [post]
 func CreateParentCategory(ctx *app.AppContext) {
 	var req createParentCategoryRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(fmt.Errorf("failed to c.ShouldBindQuery : %w", err)))
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to c.ShouldBindQuery : %w", err))
 		return
 	}
-	req.Filename = strings.ReplaceAll(req.Filename, " ", "-")
 
-	var parentCategory db.ParentCategory
-	txErr := ctx.Server.Store.ExecTx(ctx.Request.Context(), func(q *db.Queries) error {
-		src, err := service.UploadImageSrc(ctx.Context, &ctx.Server.Config, "image_file", req.Filename, IMAGE_TYPE_CATEGORY, false)
-		if err != nil {
-			ctx.Server.Logger.Error("failed to UploadImageSrc",
-				zap.String("name", req.Name),
-				zap.String("filename", req.Filename),
-				zap.Int("priority_level", int(req.PriorityLevel)),
-				zap.Error(err),
-			)
-			return fmt.Errorf("failed to UploadImage: %w", err)
-		}
-
-		arg := db.CreateParentCategoryParams{
-			Name: req.Name,
-			Src:  src,
-			Filename: sql.NullString{
-				String: req.Filename,
-				Valid:  true,
-			},
-			PriorityLevel: req.PriorityLevel,
-		}
-
-		parentCategory, err = ctx.Server.Store.CreateParentCategory(ctx, arg)
-		if err != nil {
-			ctx.Server.Logger.Error("failed to CreateParentCategory",
-				zap.String("name", req.Name),
-				zap.String("filename", req.Filename),
-				zap.Int("priority_level", int(req.PriorityLevel)),
-				zap.Error(err),
-			)
-			return fmt.Errorf("failed to CreateParentCategory: %w", err)
-		}
+	image, err := openImageFile(req.ImageFile)
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+	defer closeImageFiles(image)
 
-		return nil
+	parentCategory, err := ctx.Server.CategoryService.CreateParentCategory(ctx, service.CreateParentCategoryParams{
+		Name:          req.Name,
+		Filename:      req.Filename,
+		PriorityLevel: req.PriorityLevel,
+		Image:         image,
 	})
-
-	if txErr != nil {
-		ctx.Server.Logger.Error("CreateParentCategory transaction was failed",
-			zap.String("name", req.Name),
-			zap.String("filename", req.Filename),
-			zap.Int("priority_level", int(req.PriorityLevel)),
-			zap.Error(txErr),
-		)
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("CreateParentCategory transaction was failed : %w", txErr)))
+	if err != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to CreateParentCategory : %w", err))
 		return
 	}
 
 	// redisキャッシュの削除
-	keyPattern := []string{cache.CategoriesPrefix + "*"}
-	err := ctx.Server.RedisClient.Del(ctx, keyPattern)
+	keyPattern := []string{cache.CategoriesPrefix + "*", cache.SuggestionsPrefix + "*"}
+	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
 	if err != nil {
 		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
 	}
@@ -356,115 +264,211 @@ type editParentCategoryResponse struct {
 
 // EditParentCategory godoc
 // @Summary Edit an existing parent category
-// @Description Edits a parent category by ID, allowing updates to the category's name, filename, and associated image.
+// @Description Edits a parent category by ID, allowing updates to the category's name, filename, and associated image. The If-Match header must hold the ETag returned when the category was retrieved.
 // @Accept  multipart/form-data
 // @Produce  json
 // @Param   id         path     int    true  "ID of the parent category to edit"
+// @Param   If-Match   header   string true  "ETag of the parent category"
 // @Param   name       formData string true  "New name of the parent category"
 // @Param   filename   formData string true  "New filename for the uploaded image"
 // @Param   image_file formData file   false "New image file for the parent category (optional)"
 // @Success 200 {object} gin/H "Returns the updated parent category and a success message"
-// @Failure 400 {object} request/JSONResponse{data=string} "Bad Request: Error in data binding or missing required fields"
-// @Failure 404 {object} request/JSONResponse{data=string} "Not Found: No parent category found with the given ID"
-// @Failure 500 {object} request/JSONResponse{data=string} "Internal Server Error: Failed to update the parent category due to a server error"
+// @Failure 400 {object} apperror.Response "Bad Request: Error in data binding or missing required fields"
+// @Failure 404 {object} apperror.Response "Not Found: No parent category found with the given ID"
+// @Failure 412 {object} apperror.Response "Precondition Failed: The parent category was modified after it was retrieved. Returns the current parent category"
+// @Failure 428 {object} apperror.Response "Precondition Required: The If-Match header is missing"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to update the parent category due to a server error"
 // @Router /api/v1/admin/categories/parent/{id} [put]
 func EditParentCategory(ctx *app.AppContext) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(fmt.Errorf("failed to c.ShouldBindQuery : %w", err)))
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to c.ShouldBindQuery : %w", err))
+		return
+	}
+	version, err := binder.BindIfMatch(ctx.Context)
+	if err != nil {
 		return
 	}
 	var req editParentCategoryRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(err))
+		ctx.Fail(apperror.BadRequest, err)
 		return
 	}
-	req.Filename = strings.ReplaceAll(req.Filename, " ", "-")
 
-	pcate, err := ctx.Server.Store.GetParentCategory(ctx, int64(id))
+	image, err := openImageFile(req.ImageFile)
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+	defer closeImageFiles(image)
+
+	pcate, err := ctx.Server.CategoryService.EditParentCategory(ctx, int64(id), service.EditParentCategoryParams{
+		Name:          req.Name,
+		Filename:      req.Filename,
+		PriorityLevel: req.PriorityLevel,
+		Image:         image,
+		Version:       version,
+	})
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, app.ErrorResponse(fmt.Errorf("failed to GetParentCategory : %w", err)))
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
 			return
 		}
-		ctx.Server.Logger.Error("failed to GetParentCategory",
-			zap.Int("parent_category_id", id),
-			zap.String("name", req.Name),
-			zap.String("filename", req.Filename),
-			zap.Int("priority_level", int(req.PriorityLevel)),
-			zap.Error(err),
-		)
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetParentCategory : %w", err)))
-		return
-	}
-
-	txErr := ctx.Server.Store.ExecTx(ctx.Request.Context(), func(q *db.Queries) error {
-		src := pcate.Src
-		if pcate.Filename.String != req.Filename {
-			err := service.DeleteImageSrc(ctx.Context, &ctx.Server.Config, pcate.Src)
-			if err != nil {
-				ctx.Server.Logger.Error("failed to DeleteImageSrc",
-					zap.Int("parent_category_id", id),
-					zap.String("name", req.Name),
-					zap.String("filename", req.Filename),
-					zap.String("src", src),
-					zap.Int("priority_level", int(req.PriorityLevel)),
-					zap.Error(err),
-				)
-				return err
-			}
-
-			src, err = service.UploadImageSrc(ctx.Context, &ctx.Server.Config, "image_file", req.Filename, IMAGE_TYPE_CATEGORY, false)
-			if err != nil {
-				ctx.Server.Logger.Error("failed to UploadImageSrc",
-					zap.Int("parent_category_id", id),
-					zap.String("name", req.Name),
-					zap.String("filename", req.Filename),
-					zap.String("src", src),
-					zap.Int("priority_level", int(req.PriorityLevel)),
-					zap.Error(err),
-				)
-				return err
-			}
+		if errors.Is(err, service.ErrVersionMismatch) {
+			respondParentCategoryVersionMismatch(ctx, id, err)
+			return
 		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to EditParentCategory : %w", err))
+		return
+	}
+
+	// redisキャッシュの削除
+	keyPattern := []string{cache.CategoriesPrefix + "*", cache.SuggestionsPrefix + "*"}
+	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
+	if err != nil {
+		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
+	}
+
+	setETag(ctx, pcate.UpdatedAt)
+	ctx.JSON(http.StatusOK, editParentCategoryResponse{
+		ParentCategory: pcate,
+		Message:        "parent_categoryの編集に成功しました",
+	})
+}
+
+// patchParentCategoryRequest は省略またはnullの項目を変更しない
+type patchParentCategoryRequest struct {
+	Name          *string `json:"name" binding:"omitempty,min=1"`
+	PriorityLevel *int16  `json:"priority_level"`
+}
+
+// PatchParentCategory godoc
+// @Summary Partially update a parent category
+// @Description Updates only the supplied fields of a parent category with merge-patch semantics. Omitted or null fields are left unchanged. The image is replaced with the image sub-resource. The If-Match header must hold the ETag returned when the category was retrieved.
+// @Accept  json
+// @Produce  json
+// @Param   id        path    int                         true  "ID of the parent category to update"
+// @Param   If-Match  header  string                      true  "ETag of the parent category"
+// @Param   body      body    patchParentCategoryRequest  true  "Fields to update"
+// @Success 200 {object} editParentCategoryResponse "Returns the updated parent category and a success message"
+// @Failure 400 {object} apperror.Response "Bad Request: Error in parsing the 'id' or the JSON body"
+// @Failure 404 {object} apperror.Response "Not Found: No parent category found with the given ID"
+// @Failure 412 {object} apperror.Response "Precondition Failed: The parent category was modified after it was retrieved. Returns the current parent category"
+// @Failure 428 {object} apperror.Response "Precondition Required: The If-Match header is missing"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to update the parent category due to a server error"
+// @Router /api/v1/admin/categories/parent/{id} [patch]
+func PatchParentCategory(ctx *app.AppContext) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
+		return
+	}
+	version, err := binder.BindIfMatch(ctx.Context)
+	if err != nil {
+		return
+	}
+	var req patchParentCategoryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
 
-		arg := db.UpdateParentCategoryParams{
-			ID:            pcate.ID,
-			Name:          req.Name,
-			Src:           src,
-			Filename:      sql.NullString{String: pcate.Filename.String, Valid: true},
-			PriorityLevel: req.PriorityLevel,
-			UpdatedAt:     time.Now(),
+	pcate, err := ctx.Server.CategoryService.PatchParentCategory(ctx, int64(id), service.PatchParentCategoryParams{
+		Name:          req.Name,
+		PriorityLevel: req.PriorityLevel,
+		Version:       version,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
 		}
-		if pcate.Filename.String != req.Filename {
-			arg.Filename = sql.NullString{String: req.Filename, Valid: true}
+		if errors.Is(err, service.ErrVersionMismatch) {
+			respondParentCategoryVersionMismatch(ctx, id, err)
+			return
 		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to PatchParentCategory : %w", err))
+		return
+	}
 
-		pcate, err = ctx.Server.Store.UpdateParentCategory(ctx, arg)
-		if err != nil {
-			ctx.Server.Logger.Error("failed to UpdateParentCategory",
-				zap.Int("parent_category_id", id),
-				zap.String("name", req.Name),
-				zap.String("filename", req.Filename),
-				zap.String("src", src),
-				zap.Int("priority_level", int(req.PriorityLevel)),
-				zap.Error(err),
-			)
-			return err
-		}
+	// redisキャッシュの削除
+	keyPattern := []string{cache.CategoriesPrefix + "*", cache.SuggestionsPrefix + "*"}
+	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
+	if err != nil {
+		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
+	}
 
-		return nil
+	setETag(ctx, pcate.UpdatedAt)
+	ctx.JSON(http.StatusOK, editParentCategoryResponse{
+		ParentCategory: pcate,
+		Message:        "parent_categoryの編集に成功しました",
 	})
+}
 
-	if txErr != nil {
-		ctx.Server.Logger.Error("EditParentCategory transaction was failed",
-			zap.Int("parent_category_id", id),
-			zap.String("name", req.Name),
-			zap.String("filename", req.Filename),
-			zap.Int("priority_level", int(req.PriorityLevel)),
-			zap.Error(err),
-		)
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("EditParentCategory transaction was failed : %w", txErr)))
+type replaceParentCategoryImageRequest struct {
+	// 省略した場合は現在のファイル名を引き継ぐ
+	Filename  string               `form:"filename"`
+	ImageFile multipart.FileHeader `form:"image_file" binding:"required"`
+}
+
+// ReplaceParentCategoryImage godoc
+// @Summary Replace the image of a parent category
+// @Description Replaces the image file of a parent category, optionally with a new filename. The name and priority level are left unchanged. The If-Match header must hold the ETag returned when the category was retrieved.
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param   id          path      int     true   "ID of the parent category"
+// @Param   If-Match    header    string  true   "ETag of the parent category"
+// @Param   filename    formData  string  false  "New filename for the uploaded image"
+// @Param   image_file  formData  file    true   "New image file for the parent category"
+// @Success 200 {object} editParentCategoryResponse "Returns the updated parent category and a success message"
+// @Failure 400 {object} apperror.Response "Bad Request: Missing image file or an image that is not png"
+// @Failure 404 {object} apperror.Response "Not Found: No parent category found with the given ID"
+// @Failure 412 {object} apperror.Response "Precondition Failed: The parent category was modified after it was retrieved. Returns the current parent category"
+// @Failure 428 {object} apperror.Response "Precondition Required: The If-Match header is missing"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to replace the image due to a server error"
+// @Router /api/v1/admin/categories/parent/{id}/image [put]
+func ReplaceParentCategoryImage(ctx *app.AppContext) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
+		return
+	}
+	version, err := binder.BindIfMatch(ctx.Context)
+	if err != nil {
+		return
+	}
+	var req replaceParentCategoryImageRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+
+	image, err := openImageFile(req.ImageFile)
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+	defer closeImageFiles(image)
+
+	pcate, err := ctx.Server.CategoryService.ReplaceParentCategoryImage(ctx, int64(id), service.ReplaceParentCategoryImageParams{
+		Filename: req.Filename,
+		Image:    image,
+		Version:  version,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
+		}
+		if errors.Is(err, service.ErrVersionMismatch) {
+			respondParentCategoryVersionMismatch(ctx, id, err)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidImageExtension) {
+			ctx.Fail(apperror.BadRequest, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ReplaceParentCategoryImage : %w", err))
 		return
 	}
 
@@ -475,9 +479,10 @@ func EditParentCategory(ctx *app.AppContext) {
 		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
 	}
 
+	setETag(ctx, pcate.UpdatedAt)
 	ctx.JSON(http.StatusOK, editParentCategoryResponse{
 		ParentCategory: pcate,
-		Message:        "parent_categoryの編集に成功しました",
+		Message:        "parent_categoryの画像の差し替えに成功しました",
 	})
 }
 
@@ -487,109 +492,34 @@ type deleteParentCategoryResponse struct {
 
 // DeleteParentCategory godoc
 // @Summary Delete a parent category
-// @Description Deletes an existing parent category identified by its ID along with all its associated child categories and related image relations.
+// @Description Moves an existing parent category identified by its ID to the trash along with all its child categories. Relations to illustrations are kept so that it can be restored until the retention period passes.
 // @Accept  json
 // @Produce  json
 // @Param   id   path   int  true  "ID of the parent category to delete"
 // @Success 200 {object} gin/H "Returns a success message indicating the parent category and all related entities have been deleted"
-// @Failure 400 {object} request/JSONResponse{data=string} "Bad Request: Error in parsing the parent category ID"
-// @Failure 404 {object} request/JSONResponse{data=string} "Not Found: No parent category found with the given ID"
-// @Failure 500 {object} request/JSONResponse{data=string} "Internal Server Error: Failed to delete the parent category or its related entities due to a server error"
+// @Failure 400 {object} apperror.Response "Bad Request: Error in parsing the parent category ID"
+// @Failure 404 {object} apperror.Response "Not Found: No parent category found with the given ID"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to delete the parent category or its related entities due to a server error"
 // @Router /api/v1/admin/categories/parent/{id} [delete]
 func DeleteParentCategory(ctx *app.AppContext) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(err))
+		ctx.Fail(apperror.BadRequest, err)
 		return
 	}
-	pcate, err := ctx.Server.Store.GetParentCategory(ctx, int64(id))
+
+	err = ctx.Server.CategoryService.DeleteParentCategory(ctx, int64(id))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, app.ErrorResponse(fmt.Errorf("failed to GetParentCategory : %w", err)))
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
 			return
 		}
-		ctx.Server.Logger.Error("failed to GetParentCategory",
-			zap.Int("parent_category_id", id),
-			zap.Error(err),
-		)
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetParentCategory : %w", err)))
-		return
-	}
-
-	txErr := ctx.Server.Store.ExecTx(ctx.Request.Context(), func(q *db.Queries) error {
-		err = service.DeleteImageSrc(ctx.Context, &ctx.Server.Config, pcate.Src)
-		if err != nil {
-			ctx.Server.Logger.Error("failed to DeleteImageSrc",
-				zap.Int("parent_category_id", id),
-				zap.Error(err),
-			)
-			return fmt.Errorf("failed to DeleteImageSrc: %w", err)
-		}
-
-		// images_parent_category_relationsの削除
-		err = ctx.Server.Store.DeleteAllImageParentCategoryRelationsByParentCategoryID(ctx, pcate.ID)
-		if err != nil {
-			ctx.Server.Logger.Error("failed to DeleteAllImageParentCategoryRelationsByParentCategoryID",
-				zap.Int("parent_category_id", id),
-				zap.Error(err),
-			)
-			return fmt.Errorf("failed to DeleteAllImageParentCategoryRelationsByParentCategoryID : %w", err)
-		}
-
-		// parent_category_idと関連するimage_child_category_relationsの削除
-		ccates, err := ctx.Server.Store.GetChildCategoriesByParentID(ctx, pcate.ID)
-		if err != nil {
-			ctx.Server.Logger.Error("failed to GetChildCategoriesByParentID",
-				zap.Int("parent_category_id", id),
-				zap.Error(err),
-			)
-			return fmt.Errorf("failed to GetChildCategoriesByParentID: %w", err)
-		}
-		for _, ccate := range ccates {
-			err = ctx.Server.Store.DeleteAllImageChildCategoryRelationsByChildCategoryID(ctx, ccate.ID)
-			if err != nil {
-				ctx.Server.Logger.Error("failed to DeleteAllImageChildCategoryRelationsByChildCategoryID",
-					zap.Int("parent_category_id", id),
-					zap.Int("child_category_id", int(ccate.ID)),
-					zap.Error(err),
-				)
-				return fmt.Errorf("failed to DeleteAllImageChildCategoryRelationsByChildCategoryID: %w", err)
-			}
-		}
-
-		// 関係するchild_categoriesの全削除
-		err = ctx.Server.Store.DeleteAllChildCategoriesByParentCategoryID(ctx, pcate.ID)
-		if err != nil {
-			ctx.Server.Logger.Error("failed to DeleteAllChildCategoriesByParentCategoryID",
-				zap.Int("parent_category_id", id),
-				zap.Error(err),
-			)
-			return fmt.Errorf("failed to DeleteAllChildCategoriesByParentCategoryID : %w", err)
-		}
-
-		// parent_categoryの削除
-		err = ctx.Server.Store.DeleteParentCategory(ctx, pcate.ID)
-		if err != nil {
-			ctx.Server.Logger.Error("failed to DeleteParentCategory",
-				zap.Int("parent_category_id", id),
-				zap.Error(err),
-			)
-			return fmt.Errorf("failed to DeleteParentCategory : %w", err)
-		}
-
-		return nil
-	})
-	if txErr != nil {
-		ctx.Server.Logger.Error("DeleteParentCategory transaction was failed",
-			zap.Int("parent_category_id", id),
-			zap.Error(err),
-		)
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("DeleteParentCategory transaction was failed : %w", txErr)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to DeleteParentCategory : %w", err))
 		return
 	}
 
 	// redisキャッシュの削除
-	keyPattern := []string{cache.CategoriesPrefix + "*"}
+	keyPattern := []string{cache.CategoriesPrefix + "*", cache.RelatedIllustrationsPrefix + "*", cache.SuggestionsPrefix + "*"}
 	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
 	if err != nil {
 		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
@@ -606,32 +536,30 @@ type getChildCategoryResponse struct {
 
 // GetChildCategory godoc
 // @Summary Get a child category by ID
-// @Description Retrieves a specific child category based on the provided ID.
+// @Description Retrieves a specific child category based on the provided ID. The ETag header holds the version of the child category to send as If-Match on edit.
 // @Tags ChildCategories
 // @Accept json
 // @Produce json
 // @Param id path int true "Child Category ID"
 // @Success 200 {object} getChildCategoryResponse "A child category object"
-// @Failure 400 {object} app.JSONResponse{data=string} "Bad Request: The request is malformed or missing required fields."
-// @Failure 500 {object} app.JSONResponse{data=string} "Internal Server Error: An error occurred on the server which prevented the completion of the request."
+// @Header  200 {string} ETag "Version of the child category"
+// @Failure 400 {object} apperror.Response "Bad Request: The request is malformed or missing required fields."
+// @Failure 500 {object} apperror.Response "Internal Server Error: An error occurred on the server which prevented the completion of the request."
 // @Router /api/v1/admin/categories/child/{id} [get]
 func GetChildCategory(ctx *app.AppContext) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err)))
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
 		return
 	}
 
 	child_category, err := ctx.Server.Store.GetChildCategory(ctx, int64(id))
 	if err != nil {
-		ctx.Server.Logger.Error("failed to GetChildCategory",
-			zap.Int("child_category_id", id),
-			zap.Error(err),
-		)
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetChildCategory : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to GetChildCategory : %w", err))
 		return
 	}
 
+	setETag(ctx, child_category.UpdatedAt)
 	ctx.JSON(http.StatusOK, getChildCategoryResponse{
 		ChildCategory: child_category,
 	})
@@ -656,35 +584,28 @@ type createChildCategoryResponse struct {
 // @Param   name       formData   string  true  "Name of the child category"
 // @Param   parent_id  formData   int     true  "Parent category ID to which the child category belongs"
 // @Success 200 {object} gin/H "Returns the created child category along with a success message"
-// @Failure 400 {object} request/JSONResponse{data=string} "Bad Request: Error in data binding or missing required fields"
-// @Failure 500 {object} request/JSONResponse{data=string} "Internal Server Error: Failed to create the child category due to server-side error"
+// @Failure 400 {object} apperror.Response "Bad Request: Error in data binding or missing required fields"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to create the child category due to server-side error"
 // @Router /api/v1/admin/categories/child/create [post]
 func CreateChildCategory(ctx *app.AppContext) {
 	var req createChildCategoryRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(fmt.Errorf("failed to c.ShouldBindQuery : %w", err)))
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to c.ShouldBindQuery : %w", err))
 		return
 	}
 
-	arg := db.CreateChildCategoryParams{
+	childCategory, err := ctx.Server.CategoryService.CreateChildCategory(ctx, service.CreateChildCategoryParams{
 		Name:          req.Name,
 		ParentID:      int64(req.ParentID),
 		PriorityLevel: req.PriorityLevel,
-	}
-	childCategory, err := ctx.Server.Store.CreateChildCategory(ctx, arg)
+	})
 	if err != nil {
-		ctx.Server.Logger.Error("failed to CreateChildCategory",
-			zap.String("name", req.Name),
-			zap.Int("parent_category_id", req.ParentID),
-			zap.Int("priority_level", int(req.PriorityLevel)),
-			zap.Error(err),
-		)
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to CreateChildCategory : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to CreateChildCategory : %w", err))
 		return
 	}
 
 	// redisキャッシュの削除
-	keyPattern := []string{cache.CategoriesPrefix + "*"}
+	keyPattern := []string{cache.CategoriesPrefix + "*", cache.SuggestionsPrefix + "*"}
 	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
 	if err != nil {
 		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
@@ -709,89 +630,134 @@ type editChildCategoryResponse struct {
 
 // EditChildCategory godoc
 // @Summary Edit a child category
-// @Description Edits an existing child category identified by its ID with new name and parent ID.
+// @Description Edits an existing child category identified by its ID with new name and parent ID. The If-Match header must hold the ETag returned when the child category was retrieved.
 // @Accept  multipart/form-data
 // @Produce  json
 // @Param   id        path     int    true  "ID of the child category to edit"
+// @Param   If-Match  header   string true  "ETag of the child category"
 // @Param   name      formData string true  "New name for the child category"
 // @Param   parent_id formData int    true  "New parent ID for the child category"
 // @Success 200 {object} gin/H "Returns the updated child category and a success message"
-// @Failure 400 {object} request/JSONResponse{data=string} "Bad Request: Error in binding query parameters or the request data"
-// @Failure 404 {object} request/JSONResponse{data=string} "Not Found: No child category found with the given ID"
-// @Failure 500 {object} request/JSONResponse{data=string} "Internal Server Error: Failed to update the child category in the database"
+// @Failure 400 {object} apperror.Response "Bad Request: Error in binding query parameters or the request data"
+// @Failure 404 {object} apperror.Response "Not Found: No child category found with the given ID"
+// @Failure 412 {object} apperror.Response "Precondition Failed: The child category was modified after it was retrieved. Returns the current child category"
+// @Failure 428 {object} apperror.Response "Precondition Required: The If-Match header is missing"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to update the child category in the database"
 // @Router /api/v1/admin/categories/child/{id} [put]
 func EditChildCategory(ctx *app.AppContext) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(fmt.Errorf("failed to c.ShouldBindQuery : %w", err)))
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to c.ShouldBindQuery : %w", err))
+		return
+	}
+	version, err := binder.BindIfMatch(ctx.Context)
+	if err != nil {
 		return
 	}
 	var req editChildCategoryRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(err))
+		ctx.Fail(apperror.BadRequest, err)
 		return
 	}
 
-	ccate, err := ctx.Server.Store.GetChildCategory(ctx, int64(id))
+	ccate, err := ctx.Server.CategoryService.EditChildCategory(ctx, int64(id), service.EditChildCategoryParams{
+		Name:          req.Name,
+		ParentID:      int64(req.ParentID),
+		PriorityLevel: req.PriorityLevel,
+		Version:       version,
+	})
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, app.ErrorResponse(fmt.Errorf("failed to GetChildCategory : %w", err)))
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
 			return
 		}
-		ctx.Server.Logger.Error("failed to GetChildCategory",
-			zap.Int("child_category_id", id),
-			zap.String("name", req.Name),
-			zap.Int("parent_category_id", req.ParentID),
-			zap.Int("priority_level", int(req.PriorityLevel)),
-			zap.Error(err),
-		)
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetChildCategory : %w", err)))
+		if errors.Is(err, service.ErrVersionMismatch) {
+			respondChildCategoryVersionMismatch(ctx, id, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to EditChildCategory : %w", err))
 		return
 	}
 
-	txErr := ctx.Server.Store.ExecTx(ctx.Request.Context(), func(q *db.Queries) error {
-		arg := db.UpdateChildCategoryParams{
-			ID:            ccate.ID,
-			Name:          req.Name,
-			ParentID:      int64(req.ParentID),
-			PriorityLevel: req.PriorityLevel,
-			UpdatedAt:     time.Now(),
-		}
-
-		ccate, err = ctx.Server.Store.UpdateChildCategory(ctx, arg)
-		if err != nil {
-			ctx.Server.Logger.Error("failed to UpdateChildCategory",
-				zap.Int("child_category_id", id),
-				zap.String("name", req.Name),
-				zap.Int("parent_category_id", req.ParentID),
-				zap.Int("priority_level", int(req.PriorityLevel)),
-				zap.Error(err),
-			)
-			return err
-		}
+	// redisキャッシュの削除
+	keyPattern := []string{cache.CategoriesPrefix + "*", cache.RelatedIllustrationsPrefix + "*", cache.SuggestionsPrefix + "*"}
+	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
+	if err != nil {
+		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
+	}
 
-		return nil
+	setETag(ctx, ccate.UpdatedAt)
+	ctx.JSON(http.StatusOK, editChildCategoryResponse{
+		ChildCategory: ccate,
+		Message:       "child_categoryの編集に成功しました",
 	})
+}
 
-	if txErr != nil {
-		ctx.Server.Logger.Error("EditChildCategory transaction was failed",
-			zap.Int("child_category_id", id),
-			zap.String("name", req.Name),
-			zap.Int("parent_category_id", req.ParentID),
-			zap.Int("priority_level", int(req.PriorityLevel)),
-			zap.Error(err),
-		)
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("EditChildCategory transaction was failed : %w", txErr)))
+// patchChildCategoryRequest は省略またはnullの項目を変更しない
+type patchChildCategoryRequest struct {
+	Name          *string `json:"name" binding:"omitempty,min=1"`
+	ParentID      *int64  `json:"parent_id" binding:"omitempty,min=1"`
+	PriorityLevel *int16  `json:"priority_level"`
+}
+
+// PatchChildCategory godoc
+// @Summary Partially update a child category
+// @Description Updates only the supplied fields of a child category with merge-patch semantics. Omitted or null fields are left unchanged. The If-Match header must hold the ETag returned when the child category was retrieved.
+// @Accept  json
+// @Produce  json
+// @Param   id        path    int                        true  "ID of the child category to update"
+// @Param   If-Match  header  string                     true  "ETag of the child category"
+// @Param   body      body    patchChildCategoryRequest  true  "Fields to update"
+// @Success 200 {object} editChildCategoryResponse "Returns the updated child category and a success message"
+// @Failure 400 {object} apperror.Response "Bad Request: Error in parsing the 'id' or the JSON body"
+// @Failure 404 {object} apperror.Response "Not Found: No child category found with the given ID"
+// @Failure 412 {object} apperror.Response "Precondition Failed: The child category was modified after it was retrieved. Returns the current child category"
+// @Failure 428 {object} apperror.Response "Precondition Required: The If-Match header is missing"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to update the child category in the database"
+// @Router /api/v1/admin/categories/child/{id} [patch]
+func PatchChildCategory(ctx *app.AppContext) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
+		return
+	}
+	version, err := binder.BindIfMatch(ctx.Context)
+	if err != nil {
+		return
+	}
+	var req patchChildCategoryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+
+	ccate, err := ctx.Server.CategoryService.PatchChildCategory(ctx, int64(id), service.PatchChildCategoryParams{
+		Name:          req.Name,
+		ParentID:      req.ParentID,
+		PriorityLevel: req.PriorityLevel,
+		Version:       version,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
+		}
+		if errors.Is(err, service.ErrVersionMismatch) {
+			respondChildCategoryVersionMismatch(ctx, id, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to PatchChildCategory : %w", err))
 		return
 	}
 
 	// redisキャッシュの削除
-	keyPattern := []string{cache.CategoriesPrefix + "*"}
+	keyPattern := []string{cache.CategoriesPrefix + "*", cache.RelatedIllustrationsPrefix + "*", cache.SuggestionsPrefix + "*"}
 	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
 	if err != nil {
 		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
 	}
 
+	setETag(ctx, ccate.UpdatedAt)
 	ctx.JSON(http.StatusOK, editChildCategoryResponse{
 		ChildCategory: ccate,
 		Message:       "child_categoryの編集に成功しました",
@@ -805,45 +771,90 @@ func EditChildCategory(ctx *app.AppContext) {
 // @Produce  json
 // @Param   id   path   int  true  "ID of the child category to delete"
 // @Success 200 {object} gin/H "Returns a success message indicating the child category has been deleted"
-// @Failure 400 {object} request/JSONResponse{data=string} "Bad Request: Error in parsing the child category ID"
-// @Failure 404 {object} request/JSONResponse{data=string} "Not Found: No child category found with the given ID or error in deleting the child category"
-// @Failure 500 {object} request/JSONResponse{data=string} "Internal Server Error: Failed to retrieve or delete the child category from the database"
+// @Failure 400 {object} apperror.Response "Bad Request: Error in parsing the child category ID"
+// @Failure 404 {object} apperror.Response "Not Found: No child category found with the given ID or error in deleting the child category"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to retrieve or delete the child category from the database"
 // @Router /api/v1/admin/categories/child/{id} [delete]
 func DeleteChildCategory(ctx *app.AppContext) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(err))
+		ctx.Fail(apperror.BadRequest, err)
 		return
 	}
 
-	ccate, err := ctx.Server.Store.GetChildCategory(ctx, int64(id))
+	err = ctx.Server.CategoryService.DeleteChildCategory(ctx, int64(id))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, app.ErrorResponse(fmt.Errorf("failed to GetChildCategory : %w", err)))
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
 			return
 		}
-		ctx.Server.Logger.Error("failed to GetChildCategory",
-			zap.Int("child_category_id", id),
-			zap.Error(err),
-		)
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetChildCategory : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to DeleteChildCategory : %w", err))
+		return
+	}
+
+	// redisキャッシュの削除
+	keyPattern := []string{cache.CategoriesPrefix + "*", cache.RelatedIllustrationsPrefix + "*", cache.SuggestionsPrefix + "*"}
+	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
+	if err != nil {
+		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "child_categoryの削除に成功しました",
+	})
+}
+
+type reorderCategoriesRequest struct {
+	ParentCategoryIDs []int64 `form:"parent_ids[]"`
+	ChildCategoryIDs  []int64 `form:"child_ids[]"`
+}
+
+// ReorderCategories godoc
+// @Summary Reorder categories
+// @Description Rewrites priority_level of all parent categories and/or all child categories in one transaction so that they are listed in the given order of IDs. At least one of the lists is required.
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param   parent_ids[]  formData  []int  false  "Ordered list of every parent category ID, highest priority first"
+// @Param   child_ids[]   formData  []int  false  "Ordered list of every child category ID, highest priority first"
+// @Success 200 {object} gin/H "Returns a success message"
+// @Failure 400 {object} apperror.Response "Bad Request: The lists are empty, missing categories or contain duplicated or nonexistent IDs"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to update the priority levels"
+// @Router /api/v1/admin/categories/order [put]
+func ReorderCategories(ctx *app.AppContext) {
+	var req reorderCategoriesRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Fail(apperror.BadRequest, err)
 		return
 	}
 
-	err = ctx.Server.Store.DeleteChildCategory(ctx, ccate.ID)
+	err := ctx.Server.CategoryService.Reorder(ctx, service.ReorderParams{
+		ParentCategoryIDs: req.ParentCategoryIDs,
+		ChildCategoryIDs:  req.ChildCategoryIDs,
+	})
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, app.ErrorResponse(fmt.Errorf("failed to DeleteChildCategory : %w", err)))
+		if errors.Is(err, service.ErrInvalidPriorityOrder) {
+			ctx.Fail(apperror.BadRequest, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ReorderCategories : %w", err))
 		return
 	}
 
 	// redisキャッシュの削除
-	keyPattern := []string{cache.CategoriesPrefix + "*"}
+	keyPattern := []string{cache.CategoriesPrefix + "*", cache.SuggestionsPrefix + "*"}
 	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
 	if err != nil {
 		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "child_categoryの削除に成功しました",
+		"message": "categoryの並び替えに成功しました",
+	})
+}
+
+// nextCategoryCursor は次ページ取得用のカーソルを生成する
+func nextCategoryCursor(categories []model.Category, limit int) string {
+	return cursor.Next(categories, limit, func(c model.Category) cursor.Cursor {
+		return cursor.Cursor{ID: c.ParentCategory.ID, PriorityLevel: c.ParentCategory.PriorityLevel}
 	})
 }
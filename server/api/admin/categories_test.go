@@ -411,6 +411,8 @@ func TestEditParentCategory(t *testing.T) {
 		want         db.ParentCategory
 		wantErr      bool
 		expectedCode int
+		// 取得した時点のETagからIf-Matchヘッダーの値を決める。nilの場合は取得した時点のETagをそのまま使う
+		ifMatch func(current string) string
 	}{
 		{
 			name: "正常系",
@@ -473,6 +475,48 @@ func TestEditParentCategory(t *testing.T) {
 			wantErr:      true,
 			expectedCode: http.StatusNotFound,
 		},
+		{
+			name: "異常系（If-Matchヘッダーがない場合）",
+			arg: args{
+				ID: "11001",
+			},
+			prepare: func() (*bytes.Buffer, string) {
+				body := &bytes.Buffer{}
+				writer := multipart.NewWriter(body)
+				defer writer.Close()
+
+				_ = writer.WriteField("name", "test_parent_category_name_11001_edited")
+				_ = writer.WriteField("filename", "test_parent_category_filename_11001")
+
+				return body, writer.FormDataContentType()
+			},
+			wantErr:      true,
+			expectedCode: http.StatusPreconditionRequired,
+			ifMatch: func(current string) string {
+				return ""
+			},
+		},
+		{
+			name: "異常系（取得した後に他の操作で更新されている場合）",
+			arg: args{
+				ID: "11001",
+			},
+			prepare: func() (*bytes.Buffer, string) {
+				body := &bytes.Buffer{}
+				writer := multipart.NewWriter(body)
+				defer writer.Close()
+
+				_ = writer.WriteField("name", "test_parent_category_name_11001_edited")
+				_ = writer.WriteField("filename", "test_parent_category_filename_11001")
+
+				return body, writer.FormDataContentType()
+			},
+			wantErr:      true,
+			expectedCode: http.StatusPreconditionFailed,
+			ifMatch: func(current string) string {
+				return staleETag
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -481,6 +525,13 @@ func TestEditParentCategory(t *testing.T) {
 			req, _ := http.NewRequest(http.MethodPut, "/api/v1/admin/categories/parent/"+tt.arg.ID, body)
 			req.Header.Set("Content-Type", contentType)
 			req.Header.Set("Authorization", "Bearer "+accessToken)
+			ifMatch := getETag(t, ctx, accessToken, "/api/v1/admin/categories/"+tt.arg.ID)
+			if tt.ifMatch != nil {
+				ifMatch = tt.ifMatch(ifMatch)
+			}
+			if ifMatch != "" {
+				req.Header.Set("If-Match", ifMatch)
+			}
 
 			ctx.Server.Router.ServeHTTP(w, req)
 
@@ -488,7 +539,13 @@ func TestEditParentCategory(t *testing.T) {
 
 			if tt.wantErr {
 				require.NotEmpty(t, w.Body.String())
+				if tt.expectedCode == http.StatusPreconditionFailed {
+					// 現在の状態とETagを返す
+					require.Contains(t, w.Body.String(), `"parent_category"`)
+					require.NotEqual(t, staleETag, w.Header().Get("ETag"))
+				}
 			} else {
+				require.NotEmpty(t, w.Header().Get("ETag"))
 				type wantType struct {
 					ParentCategory db.ParentCategory `json:"parent_category"`
 					Message        string            `json:"message"`
@@ -529,6 +586,8 @@ func TestEditChildCategory(t *testing.T) {
 		want         db.ChildCategory
 		wantErr      bool
 		expectedCode int
+		// 取得した時点のETagからIf-Matchヘッダーの値を決める。nilの場合は取得した時点のETagをそのまま使う
+		ifMatch func(current string) string
 	}{
 		{
 			name: "正常系",
@@ -592,6 +651,48 @@ func TestEditChildCategory(t *testing.T) {
 			wantErr:      true,
 			expectedCode: http.StatusNotFound,
 		},
+		{
+			name: "異常系（If-Matchヘッダーがない場合）",
+			arg: args{
+				ID: "12001",
+			},
+			prepare: func() (*bytes.Buffer, string) {
+				body := &bytes.Buffer{}
+				writer := multipart.NewWriter(body)
+				defer writer.Close()
+
+				_ = writer.WriteField("name", "test_child_category_name_12001_edited")
+				_ = writer.WriteField("parent_id", "12001")
+
+				return body, writer.FormDataContentType()
+			},
+			wantErr:      true,
+			expectedCode: http.StatusPreconditionRequired,
+			ifMatch: func(current string) string {
+				return ""
+			},
+		},
+		{
+			name: "異常系（取得した後に他の操作で更新されている場合）",
+			arg: args{
+				ID: "12001",
+			},
+			prepare: func() (*bytes.Buffer, string) {
+				body := &bytes.Buffer{}
+				writer := multipart.NewWriter(body)
+				defer writer.Close()
+
+				_ = writer.WriteField("name", "test_child_category_name_12001_edited")
+				_ = writer.WriteField("parent_id", "12001")
+
+				return body, writer.FormDataContentType()
+			},
+			wantErr:      true,
+			expectedCode: http.StatusPreconditionFailed,
+			ifMatch: func(current string) string {
+				return staleETag
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -600,6 +701,13 @@ func TestEditChildCategory(t *testing.T) {
 			req, _ := http.NewRequest(http.MethodPut, "/api/v1/admin/categories/child/"+tt.arg.ID, body)
 			req.Header.Set("Content-Type", contentType)
 			req.Header.Set("Authorization", "Bearer "+accessToken)
+			ifMatch := getETag(t, ctx, accessToken, "/api/v1/admin/categories/child/"+tt.arg.ID)
+			if tt.ifMatch != nil {
+				ifMatch = tt.ifMatch(ifMatch)
+			}
+			if ifMatch != "" {
+				req.Header.Set("If-Match", ifMatch)
+			}
 
 			ctx.Server.Router.ServeHTTP(w, req)
 
@@ -607,7 +715,13 @@ func TestEditChildCategory(t *testing.T) {
 
 			if tt.wantErr {
 				require.NotEmpty(t, w.Body.String())
+				if tt.expectedCode == http.StatusPreconditionFailed {
+					// 現在の状態とETagを返す
+					require.Contains(t, w.Body.String(), `"child_category"`)
+					require.NotEqual(t, staleETag, w.Header().Get("ETag"))
+				}
 			} else {
+				require.NotEmpty(t, w.Header().Get("ETag"))
 				type wantType struct {
 					ChildCategory db.ChildCategory `json:"child_category"`
 					Message       string           `json:"message"`
@@ -736,6 +850,162 @@ func compareChildCategoryObjects(t *testing.T, got db.ChildCategory, want db.Chi
 	}
 }
 
+func TestReorderCategories(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	c := categoriesTest{}
+	ctx := c.setUp(t, config)
+	defer c.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	parentIDs := []int64{10004, 99999, 10001, 10002, 10003, 11001, 12001, 13001}
+	childIDs := []int64{13001, 12001, 10003, 10001, 99999}
+
+	tests := []struct {
+		name          string
+		fields        map[string][]int64
+		wantParentIDs []int64
+		wantChildIDs  []int64
+		expectedCode  int
+	}{
+		{
+			name: "正常系（親カテゴリのみ）",
+			fields: map[string][]int64{
+				"parent_ids[]": parentIDs,
+			},
+			wantParentIDs: parentIDs,
+			expectedCode:  http.StatusOK,
+		},
+		{
+			name: "正常系（親カテゴリ・子カテゴリ）",
+			fields: map[string][]int64{
+				"parent_ids[]": parentIDs,
+				"child_ids[]":  childIDs,
+			},
+			wantParentIDs: parentIDs,
+			wantChildIDs:  childIDs,
+			expectedCode:  http.StatusOK,
+		},
+		{
+			name: "異常系（全ての子カテゴリが指定されていない場合）",
+			fields: map[string][]int64{
+				"child_ids[]": {13001, 12001},
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "異常系（存在しないIDが含まれる場合）",
+			fields: map[string][]int64{
+				"child_ids[]": {13001, 12001, 10003, 10001, 999999},
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系（IDが指定されていない場合）",
+			fields:       map[string][]int64{},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			body, contentType := newIDsFormBody(t, tt.fields)
+			req, _ := http.NewRequest(http.MethodPut, "/api/v1/admin/categories/order", body)
+			req.Header.Set("Content-Type", contentType)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.wantParentIDs != nil {
+				pcates, err := ctx.Server.Store.ListAllParentCategories(context.Background())
+				require.NoError(t, err)
+				got := make([]int64, 0, len(pcates))
+				for _, pcate := range pcates {
+					got = append(got, pcate.ID)
+				}
+				require.Equal(t, tt.wantParentIDs, got)
+			}
+			if tt.wantChildIDs != nil {
+				ccates, err := ctx.Server.Store.ListChildCategories(context.Background(), db.ListChildCategoriesParams{
+					Limit:  10,
+					Offset: 0,
+				})
+				require.NoError(t, err)
+				got := make([]int64, 0, len(ccates))
+				for _, ccate := range ccates {
+					got = append(got, ccate.ID)
+				}
+				require.Equal(t, tt.wantChildIDs, got)
+			}
+		})
+	}
+}
+
+func TestEditCategoriesAfterReorder(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	c := categoriesTest{}
+	ctx := c.setUp(t, config)
+	defer c.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	// 並び替える前に取得したETag
+	parentETag := getETag(t, ctx, accessToken, "/api/v1/admin/categories/10001")
+	childETag := getETag(t, ctx, accessToken, "/api/v1/admin/categories/child/10001")
+
+	w := httptest.NewRecorder()
+	body, contentType := newIDsFormBody(t, map[string][]int64{
+		"parent_ids[]": {10004, 99999, 10001, 10002, 10003, 11001, 12001, 13001},
+		"child_ids[]":  {13001, 12001, 10003, 10001, 99999},
+	})
+	req, _ := http.NewRequest(http.MethodPut, "/api/v1/admin/categories/order", body)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	ctx.Server.Router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// 並び替えで更新された優先度を古い内容で上書きできない
+	tests := []struct {
+		name    string
+		url     string
+		ifMatch string
+	}{
+		{
+			name:    "異常系（並び替える前の親カテゴリのETagの場合）",
+			url:     "/api/v1/admin/categories/parent/10001",
+			ifMatch: parentETag,
+		},
+		{
+			name:    "異常系（並び替える前の子カテゴリのETagの場合）",
+			url:     "/api/v1/admin/categories/child/10001",
+			ifMatch: childETag,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPatch, tt.url, bytes.NewBufferString(`{"name": "test"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Header.Set("If-Match", tt.ifMatch)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusPreconditionFailed, w.Code)
+		})
+	}
+}
+
 func (c categoriesTest) setUp(t *testing.T, config util.Config) *app.AppContext {
 	store := createConn(config)
 
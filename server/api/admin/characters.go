@@ -2,6 +2,7 @@ package admin
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"mime/multipart"
 	"net/http"
@@ -9,27 +10,25 @@ import (
 	"shin-monta-no-mori/internal/cache"
 	db "shin-monta-no-mori/internal/db/sqlc"
 	"shin-monta-no-mori/internal/domains/service"
+	"shin-monta-no-mori/pkg/lib/apperror"
 	"shin-monta-no-mori/pkg/lib/binder"
+	"shin-monta-no-mori/pkg/lib/cursor"
 	"strconv"
-	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-const (
-	IMAGE_TYPE_CHARACTER = "character"
-)
-
 type listCharactersRequest struct {
-	Page int64 `form:"p"`
+	Page   int64  `form:"p"`
+	Cursor string `form:"cursor"`
 }
 
 type listCharactersResponse struct {
 	Characters []db.Character `json:"characters"`
 	TotalPages int64          `json:"total_pages"`
 	TotalCount int64          `json:"total_count"`
+	NextCursor string         `json:"next_cursor"`
 }
 
 // ListCharacters godoc
@@ -39,31 +38,42 @@ type listCharactersResponse struct {
 // @Produce  json
 // @Param   p     query   int64  true  "Page number for pagination"
 // @Success 200   {object} gin/H  "Returns a list of characters"
-// @Failure 400   {object} request/JSONResponse{data=string} "Bad Request: Error in data binding or validation"
-// @Failure 500   {object} request/JSONResponse{data=string} "Internal Server Error: Failed to list the characters"
+// @Failure 400   {object} apperror.Response "Bad Request: Error in data binding or validation"
+// @Failure 500   {object} apperror.Response "Internal Server Error: Failed to list the characters"
 // @Router /api/v1/admin/characters/list [get]
 func ListCharacters(ctx *app.AppContext) {
 	var req listCharactersRequest
 	if err := binder.BindQuery(ctx.Context, &req); err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(err))
+		ctx.Fail(apperror.BadRequest, err)
 		return
 	}
 
-	arg := db.ListCharactersParams{
-		Limit:  int32(ctx.Server.Config.CharacterFetchLimit),
-		Offset: int32(int(req.Page) * ctx.Server.Config.CharacterFetchLimit),
+	var characters []db.Character
+	var err error
+	if req.Cursor != "" {
+		var c cursor.Cursor
+		c, err = binder.BindCursor(ctx.Context, req.Cursor)
+		if err != nil {
+			return
+		}
+		characters, err = ctx.Server.Store.ListCharactersByCursor(ctx, db.ListCharactersByCursorParams{
+			Limit:    int32(ctx.Server.Config.CharacterFetchLimit),
+			CursorID: c.ID,
+		})
+	} else {
+		characters, err = ctx.Server.Store.ListCharacters(ctx, db.ListCharactersParams{
+			Limit:  int32(ctx.Server.Config.CharacterFetchLimit),
+			Offset: int32(int(req.Page) * ctx.Server.Config.CharacterFetchLimit),
+		})
 	}
-	characters, err := ctx.Server.Store.ListCharacters(ctx, arg)
 	if err != nil {
-		ctx.Server.Logger.Error("failed to ListAllCharacters", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to ListAllCharacters : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListAllCharacters : %w", err))
 		return
 	}
 
 	totalCount, err := ctx.Server.Store.CountCharacters(ctx)
 	if err != nil {
-		ctx.Server.Logger.Error("failed to CountCharacters", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to CountCharacters : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to CountCharacters : %w", err))
 		return
 	}
 	totalPages := (totalCount + int64(ctx.Server.Config.CharacterFetchLimit-1)) / int64(ctx.Server.Config.CharacterFetchLimit)
@@ -72,6 +82,9 @@ func ListCharacters(ctx *app.AppContext) {
 		Characters: characters,
 		TotalPages: totalPages,
 		TotalCount: totalCount,
+		NextCursor: cursor.Next(characters, ctx.Server.Config.CharacterFetchLimit, func(c db.Character) cursor.Cursor {
+			return cursor.Cursor{ID: c.ID}
+		}),
 	})
 }
 
@@ -86,14 +99,13 @@ type listAllCharactersResponse struct {
 // @Produce  json
 // @Param   p     query   int64  true  "Page number for pagination"
 // @Success 200   {object} gin/H  "Returns a list of characters"
-// @Failure 400   {object} request/JSONResponse{data=string} "Bad Request: Error in data binding or validation"
-// @Failure 500   {object} request/JSONResponse{data=string} "Internal Server Error: Failed to list the characters"
+// @Failure 400   {object} apperror.Response "Bad Request: Error in data binding or validation"
+// @Failure 500   {object} apperror.Response "Internal Server Error: Failed to list the characters"
 // @Router /api/v1/admin/characters/list/all [get]
 func ListAllCharacters(ctx *app.AppContext) {
 	characters, err := ctx.Server.Store.ListAllCharacters(ctx)
 	if err != nil {
-		ctx.Server.Logger.Error("failed to ListAllCharacters", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to ListAllCharacters : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListAllCharacters : %w", err))
 		return
 	}
 
@@ -103,8 +115,9 @@ func ListAllCharacters(ctx *app.AppContext) {
 }
 
 type searchCharactersRequest struct {
-	Page  int    `form:"p"`
-	Query string `form:"q"`
+	Page   int    `form:"p"`
+	Query  string `form:"q"`
+	Cursor string `form:"cursor"`
 }
 
 // SearchCharacters godoc
@@ -115,37 +128,37 @@ type searchCharactersRequest struct {
 // @Param   p     query   int    true  "Page number for pagination"
 // @Param   q     query   string true  "Query string for searching characters by name or other attributes"
 // @Success 200   {object} gin/H  "Returns a list of characters that match the query"
-// @Failure 400   {object} request/JSONResponse{data=string} "Bad Request: Error in data binding or validation"
-// @Failure 500   {object} request/JSONResponse{data=string} "Internal Server Error: Failed to search the characters"
+// @Failure 400   {object} apperror.Response "Bad Request: Error in data binding or validation"
+// @Failure 500   {object} apperror.Response "Internal Server Error: Failed to search the characters"
 // @Router /api/v1/admin/characters/search [get]
 func SearchCharacters(ctx *app.AppContext) {
 	var req searchCharactersRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(err))
+		ctx.Fail(apperror.BadRequest, err)
 		return
 	}
-	arg := db.SearchCharactersParams{
+	arg := service.SearchCharactersParams{
+		Query:  req.Query,
 		Limit:  int32(ctx.Server.Config.CharacterFetchLimit),
 		Offset: int32(req.Page * ctx.Server.Config.CharacterFetchLimit),
-		Query: sql.NullString{
-			String: req.Query,
-			Valid:  true,
-		},
 	}
-	characters, err := ctx.Server.Store.SearchCharacters(ctx, arg)
+	if req.Cursor != "" {
+		c, err := binder.BindCursor(ctx.Context, req.Cursor)
+		if err != nil {
+			return
+		}
+		arg.Cursor = &c
+	}
+
+	characters, err := ctx.Server.CharacterService.Search(ctx, arg)
 	if err != nil {
-		ctx.Server.Logger.Error("failed to SearchCharacters", zap.Int("page", req.Page), zap.String("query", req.Query), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to SearchCharacters : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to SearchCharacters : %w", err))
 		return
 	}
 
-	totalCount, err := ctx.Server.Store.CountSearchCharacters(ctx, sql.NullString{
-		String: req.Query,
-		Valid:  true,
-	})
+	totalCount, err := ctx.Server.CharacterService.CountSearch(ctx, req.Query)
 	if err != nil {
-		ctx.Server.Logger.Error("failed to CountSearchCharacters", zap.Int("page", req.Page), zap.String("query", req.Query), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to CountSearchCharacters : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to CountSearchCharacters : %w", err))
 		return
 	}
 	totalPages := (totalCount + int64(ctx.Server.Config.CharacterFetchLimit-1)) / int64(ctx.Server.Config.CharacterFetchLimit)
@@ -154,6 +167,9 @@ func SearchCharacters(ctx *app.AppContext) {
 		Characters: characters,
 		TotalPages: totalPages,
 		TotalCount: totalCount,
+		NextCursor: cursor.Next(characters, ctx.Server.Config.CharacterFetchLimit, func(c db.Character) cursor.Cursor {
+			return cursor.Cursor{ID: c.ID, PriorityLevel: c.PriorityLevel}
+		}),
 	})
 }
 
@@ -163,34 +179,35 @@ type getCharacterResponse struct {
 
 // GetCharacter godoc
 // @Summary Retrieve a character
-// @Description Retrieves a single character by its ID.
+// @Description Retrieves a single character by its ID. The ETag header holds the version of the character to send as If-Match on edit.
 // @Accept  json
 // @Produce  json
 // @Param   id   path   int  true  "ID of the character to retrieve"
 // @Success 200 {object} gin/H "The requested character"
-// @Failure 400 {object} request/JSONResponse{data=string} "Bad Request: Failed to parse 'id' number from path parameter"
-// @Failure 404 {object} request/JSONResponse{data=string} "Not Found: No character found with the given ID"
-// @Failure 500 {object} request/JSONResponse{data=string} "Internal Server Error: Failed to retrieve the character from the database"
+// @Header  200 {string} ETag "Version of the character"
+// @Failure 400 {object} apperror.Response "Bad Request: Failed to parse 'id' number from path parameter"
+// @Failure 404 {object} apperror.Response "Not Found: No character found with the given ID"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to retrieve the character from the database"
 // @Router /api/v1/admin/characters/{id} [get]
 func GetCharacter(ctx *app.AppContext) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err)))
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
 		return
 	}
 
 	character, err := ctx.Server.Store.GetCharacter(ctx, int64(id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, app.ErrorResponse(fmt.Errorf("failed to GetCharacter: %w", err)))
+			ctx.Fail(apperror.NotFound, fmt.Errorf("failed to GetCharacter: %w", err))
 			return
 		}
 
-		ctx.Server.Logger.Error("failed to GetCharacter", zap.Int("character_id", id), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetCharacter : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to GetCharacter : %w", err))
 		return
 	}
 
+	setETag(ctx, character.UpdatedAt)
 	ctx.JSON(http.StatusOK, getCharacterResponse{
 		Character: character,
 	})
@@ -212,50 +229,37 @@ type createCharacterRequest struct {
 // @Param   filename   formData   string  true  "Filename for the uploaded image"
 // @Param   image_file formData   file    true  "Image file for the character"
 // @Success 200 {object} gin/H "Returns the created character and a success message"
-// @Failure 400 {object} request/JSONResponse{data=string} "Bad Request: Error in data binding or validation"
-// @Failure 500 {object} request/JSONResponse{data=string} "Internal Server Error: Failed to create the character due to a transaction error"
+// @Failure 400 {object} apperror.Response "Bad Request: Error in data binding or validation"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to create the character due to a transaction error"
 // @Router /api/v1/admin/characters/create [post]
 func CreateCharacter(ctx *app.AppContext) {
 	var req createCharacterRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(err))
+		ctx.Fail(apperror.BadRequest, err)
 		return
 	}
-	req.Filename = strings.ReplaceAll(req.Filename, " ", "-")
-
-	var character db.Character
-	txErr := ctx.Server.Store.ExecTx(ctx.Request.Context(), func(q *db.Queries) error {
-		var src string
-		src, err := service.UploadImageSrc(ctx.Context, &ctx.Server.Config, "image_file", req.Filename, IMAGE_TYPE_CHARACTER, false)
-		if err != nil {
-			ctx.Server.Logger.Error("failed to UploadImage", zap.String("name", req.Name), zap.Int16("name", req.PriorityLevel), zap.Error(err))
-			return fmt.Errorf("failed to UploadImage: %w", err)
-		}
 
-		arg := db.CreateCharacterParams{
-			Name:          req.Name,
-			Src:           src,
-			Filename:      sql.NullString{String: req.Filename, Valid: true},
-			PriorityLevel: req.PriorityLevel,
-		}
-		character, err = ctx.Server.Store.CreateCharacter(ctx, arg)
-		if err != nil {
-			ctx.Server.Logger.Error("failed to CreateCharacter", zap.String("name", req.Name), zap.Int16("name", req.PriorityLevel), zap.Error(err))
-			return fmt.Errorf("failed to CreateCharacter: %w", err)
-		}
+	image, err := openImageFile(req.ImageFile)
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+	defer closeImageFiles(image)
 
-		return nil
+	character, err := ctx.Server.CharacterService.Create(ctx, service.CreateCharacterParams{
+		Name:          req.Name,
+		Filename:      req.Filename,
+		PriorityLevel: req.PriorityLevel,
+		Image:         image,
 	})
-
-	if txErr != nil {
-		ctx.Server.Logger.Error("CreateCharacter transaction was failed", zap.Error(txErr))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("CreateCharacter transaction was failed : %w", txErr)))
+	if err != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to CreateCharacter : %w", err))
 		return
 	}
 
 	// redisキャッシュの削除
-	keyPattern := []string{cache.CharactersPrefix + "*"}
-	err := ctx.Server.RedisClient.Del(ctx, keyPattern)
+	keyPattern := []string{cache.CharactersPrefix + "*", cache.SuggestionsPrefix + "*"}
+	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
 	if err != nil {
 		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
 	}
@@ -275,82 +279,215 @@ type editCharacterRequest struct {
 
 // EditCharacter godoc
 // @Summary Edit an existing character
-// @Description Edits an existing character by its ID, updating its name, filename, and image file.
+// @Description Edits an existing character by its ID, updating its name, filename, and image file. The If-Match header must hold the ETag returned when the character was retrieved.
 // @Accept  multipart/form-data
 // @Produce  json
 // @Param   id         path     int                   true  "ID of the character to edit"
+// @Param   If-Match   header   string                true  "ETag of the character"
 // @Param   name       formData string                true  "New name for the character"
 // @Param   filename   formData string                true  "New filename for the uploaded image"
 // @Param   image_file formData file                  true  "New image file for the character"
 // @Success 200        {object} gin/H                 "Returns the updated character and a success message"
-// @Failure 400        {object} request/JSONResponse{data=string} "Bad Request: Error in data binding or path parameter parsing"
-// @Failure 404        {object} request/JSONResponse{data=string} "Not Found: No character found with the given ID"
-// @Failure 500        {object} request/JSONResponse{data=string} "Internal Server Error: Failed to edit the character due to a transaction error"
+// @Failure 400        {object} apperror.Response "Bad Request: Error in data binding or path parameter parsing"
+// @Failure 404        {object} apperror.Response "Not Found: No character found with the given ID"
+// @Failure 412        {object} apperror.Response                 "Precondition Failed: The character was modified after it was retrieved. Returns the current character"
+// @Failure 428        {object} apperror.Response "Precondition Required: The If-Match header is missing"
+// @Failure 500        {object} apperror.Response "Internal Server Error: Failed to edit the character due to a transaction error"
 // @Router /api/v1/admin/characters/{id} [put]
 func EditCharacter(ctx *app.AppContext) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(fmt.Errorf("failed to c.ShouldBindQuery : %w", err)))
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to c.ShouldBindQuery : %w", err))
+		return
+	}
+	version, err := binder.BindIfMatch(ctx.Context)
+	if err != nil {
 		return
 	}
 	var req editCharacterRequest
 	if err := ctx.ShouldBind(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(err))
+		ctx.Fail(apperror.BadRequest, err)
 		return
 	}
-	req.Filename = strings.ReplaceAll(req.Filename, " ", "-")
 
-	character, err := ctx.Server.Store.GetCharacter(ctx, int64(id))
+	image, err := openImageFile(req.ImageFile)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, app.ErrorResponse(fmt.Errorf("failed to GetCharacter : %w", err)))
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+	defer closeImageFiles(image)
+
+	character, err := ctx.Server.CharacterService.Edit(ctx, int64(id), service.EditCharacterParams{
+		Name:          req.Name,
+		Filename:      req.Filename,
+		PriorityLevel: req.PriorityLevel,
+		Image:         image,
+		Version:       version,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
+		}
+		if errors.Is(err, service.ErrVersionMismatch) {
+			respondCharacterVersionMismatch(ctx, id, err)
 			return
 		}
-		ctx.Server.Logger.Error("failed to GetCharacter", zap.Int("character_id", id), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetCharacter : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to EditCharacter : %w", err))
 		return
 	}
 
-	txErr := ctx.Server.Store.ExecTx(ctx.Request.Context(), func(q *db.Queries) error {
-		src := character.Src
-		if character.Filename.String != req.Filename || req.ImageFile.Filename != "" {
-			err := service.DeleteImageSrc(ctx.Context, &ctx.Server.Config, character.Src)
-			if err != nil {
-				ctx.Server.Logger.Error("failed to DeleteImageSrc", zap.Int("character_id", id), zap.Error(err))
-				return fmt.Errorf("failed to DeleteImageSrc : %w", err)
-			}
-
-			src, err = service.UploadImageSrc(ctx.Context, &ctx.Server.Config, "image_file", req.Filename, IMAGE_TYPE_CHARACTER, false)
-			if err != nil {
-				ctx.Server.Logger.Error("failed to UploadImage", zap.Int("character_id", id), zap.Error(err))
-				return fmt.Errorf("failed to UploadImage : %w", err)
-			}
-		}
+	// redisキャッシュの削除
+	keyPattern := []string{cache.CharactersPrefix + "*", cache.SuggestionsPrefix + "*"}
+	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
+	if err != nil {
+		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
+	}
 
-		arg := db.UpdateCharacterParams{
-			ID:            character.ID,
-			Name:          req.Name,
-			Src:           src,
-			Filename:      sql.NullString{String: character.Filename.String, Valid: true},
-			PriorityLevel: req.PriorityLevel,
-			UpdatedAt:     time.Now(),
+	setETag(ctx, character.UpdatedAt)
+	ctx.JSON(http.StatusOK, gin.H{
+		"character": character,
+		"message":   "characterの編集に成功しました",
+	})
+}
+
+type reorderCharactersRequest struct {
+	IDs []int64 `form:"ids[]" binding:"required"`
+}
+
+// patchCharacterRequest は省略またはnullの項目を変更しない
+type patchCharacterRequest struct {
+	Name          *string `json:"name" binding:"omitempty,min=1"`
+	PriorityLevel *int16  `json:"priority_level"`
+}
+
+// PatchCharacter godoc
+// @Summary Partially update a character
+// @Description Updates only the supplied fields of a character with merge-patch semantics. Omitted or null fields are left unchanged. The image is replaced with the image sub-resource. The If-Match header must hold the ETag returned when the character was retrieved.
+// @Accept  json
+// @Produce  json
+// @Param   id        path    int                    true  "ID of the character to update"
+// @Param   If-Match  header  string                 true  "ETag of the character"
+// @Param   body      body    patchCharacterRequest  true  "Fields to update"
+// @Success 200 {object} gin/H "Returns the updated character and a success message"
+// @Failure 400 {object} apperror.Response "Bad Request: Error in parsing the 'id' or the JSON body"
+// @Failure 404 {object} apperror.Response "Not Found: No character found with the given ID"
+// @Failure 412 {object} apperror.Response "Precondition Failed: The character was modified after it was retrieved. Returns the current character"
+// @Failure 428 {object} apperror.Response "Precondition Required: The If-Match header is missing"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to update the character due to a server error"
+// @Router /api/v1/admin/characters/{id} [patch]
+func PatchCharacter(ctx *app.AppContext) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
+		return
+	}
+	version, err := binder.BindIfMatch(ctx.Context)
+	if err != nil {
+		return
+	}
+	var req patchCharacterRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+
+	character, err := ctx.Server.CharacterService.Patch(ctx, int64(id), service.PatchCharacterParams{
+		Name:          req.Name,
+		PriorityLevel: req.PriorityLevel,
+		Version:       version,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
 		}
-		if character.Filename.String != req.Filename {
-			arg.Filename = sql.NullString{String: req.Filename, Valid: true}
+		if errors.Is(err, service.ErrVersionMismatch) {
+			respondCharacterVersionMismatch(ctx, id, err)
+			return
 		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to PatchCharacter : %w", err))
+		return
+	}
 
-		character, err = ctx.Server.Store.UpdateCharacter(ctx, arg)
-		if err != nil {
-			ctx.Server.Logger.Error("failed to UpdateCharacter", zap.Int("character_id", id), zap.Error(err))
-			return err
-		}
+	// redisキャッシュの削除
+	keyPattern := []string{cache.CharactersPrefix + "*", cache.SuggestionsPrefix + "*"}
+	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
+	if err != nil {
+		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
+	}
 
-		return nil
+	setETag(ctx, character.UpdatedAt)
+	ctx.JSON(http.StatusOK, gin.H{
+		"character": character,
+		"message":   "characterの編集に成功しました",
 	})
+}
 
-	if txErr != nil {
-		ctx.Server.Logger.Error("EditCharacter transaction was failed", zap.Int("character_id", id), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("EditCharacter transaction was failed : %w", txErr)))
+type replaceCharacterImageRequest struct {
+	// 省略した場合は現在のファイル名を引き継ぐ
+	Filename  string               `form:"filename"`
+	ImageFile multipart.FileHeader `form:"image_file" binding:"required"`
+}
+
+// ReplaceCharacterImage godoc
+// @Summary Replace the image of a character
+// @Description Replaces the image file of a character, optionally with a new filename. The name and priority level are left unchanged. The If-Match header must hold the ETag returned when the character was retrieved.
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param   id          path      int     true   "ID of the character"
+// @Param   If-Match    header    string  true   "ETag of the character"
+// @Param   filename    formData  string  false  "New filename for the uploaded image"
+// @Param   image_file  formData  file    true   "New image file for the character"
+// @Success 200 {object} gin/H "Returns the updated character and a success message"
+// @Failure 400 {object} apperror.Response "Bad Request: Missing image file or an image that is not png"
+// @Failure 404 {object} apperror.Response "Not Found: No character found with the given ID"
+// @Failure 412 {object} apperror.Response "Precondition Failed: The character was modified after it was retrieved. Returns the current character"
+// @Failure 428 {object} apperror.Response "Precondition Required: The If-Match header is missing"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to replace the image due to a server error"
+// @Router /api/v1/admin/characters/{id}/image [put]
+func ReplaceCharacterImage(ctx *app.AppContext) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
+		return
+	}
+	version, err := binder.BindIfMatch(ctx.Context)
+	if err != nil {
+		return
+	}
+	var req replaceCharacterImageRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+
+	image, err := openImageFile(req.ImageFile)
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+	defer closeImageFiles(image)
+
+	character, err := ctx.Server.CharacterService.ReplaceImage(ctx, int64(id), service.ReplaceCharacterImageParams{
+		Filename: req.Filename,
+		Image:    image,
+		Version:  version,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
+		}
+		if errors.Is(err, service.ErrVersionMismatch) {
+			respondCharacterVersionMismatch(ctx, id, err)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidImageExtension) {
+			ctx.Fail(apperror.BadRequest, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ReplaceCharacterImage : %w", err))
 		return
 	}
 
@@ -361,70 +498,82 @@ func EditCharacter(ctx *app.AppContext) {
 		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
 	}
 
+	setETag(ctx, character.UpdatedAt)
 	ctx.JSON(http.StatusOK, gin.H{
 		"character": character,
-		"message":   "characterの編集に成功しました",
+		"message":   "characterの画像の差し替えに成功しました",
+	})
+}
+
+// ReorderCharacters godoc
+// @Summary Reorder characters
+// @Description Rewrites priority_level of all characters in one transaction so that they are listed in the given order of IDs.
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param   ids[]  formData  []int  true  "Ordered list of every character ID, highest priority first"
+// @Success 200 {object} gin/H "Returns a success message"
+// @Failure 400 {object} apperror.Response "Bad Request: The list is missing characters or contains duplicated or nonexistent IDs"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to update the priority levels"
+// @Router /api/v1/admin/characters/order [put]
+func ReorderCharacters(ctx *app.AppContext) {
+	var req reorderCharactersRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+
+	err := ctx.Server.CharacterService.Reorder(ctx, req.IDs)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPriorityOrder) {
+			ctx.Fail(apperror.BadRequest, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ReorderCharacters : %w", err))
+		return
+	}
+
+	// redisキャッシュの削除
+	keyPattern := []string{cache.CharactersPrefix + "*", cache.SuggestionsPrefix + "*"}
+	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
+	if err != nil {
+		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "characterの並び替えに成功しました",
 	})
 }
 
 // DeleteCharacter godoc
 // @Summary Delete a character
-// @Description Deletes a character by its ID along with associated resources.
+// @Description Moves a character to the trash by its ID. Its relations to illustrations are kept so that it can be restored until the retention period passes.
 // @Accept  json
 // @Produce  json
 // @Param   id   path   int  true  "ID of the character to delete"
 // @Success 200   {object} gin/H "Returns a success message upon successful deletion"
-// @Failure 400   {object} request/JSONResponse{data=string} "Bad Request: Error parsing character ID from path parameter"
-// @Failure 404   {object} request/JSONResponse{data=string} "Not Found: No character found with the given ID"
-// @Failure 500   {object} request/JSONResponse{data=string} "Internal Server Error: Failed to delete the character due to a transaction error"
+// @Failure 400   {object} apperror.Response "Bad Request: Error parsing character ID from path parameter"
+// @Failure 404   {object} apperror.Response "Not Found: No character found with the given ID"
+// @Failure 500   {object} apperror.Response "Internal Server Error: Failed to delete the character due to a transaction error"
 // @Router /api/v1/admin/characters/{id} [delete]
 func DeleteCharacter(ctx *app.AppContext) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(err))
+		ctx.Fail(apperror.BadRequest, err)
 		return
 	}
-	character, err := ctx.Server.Store.GetCharacter(ctx, int64(id))
+
+	err = ctx.Server.CharacterService.Delete(ctx, int64(id))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, app.ErrorResponse(fmt.Errorf("failed to GetCharacter : %w", err)))
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
 			return
 		}
-		ctx.Server.Logger.Error("failed to GetCharacter", zap.Int("character_id", id), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetCharacter : %w", err)))
-		return
-	}
-
-	txErr := ctx.Server.Store.ExecTx(ctx.Request.Context(), func(q *db.Queries) error {
-		err = service.DeleteImageSrc(ctx.Context, &ctx.Server.Config, character.Src)
-		if err != nil {
-			ctx.Server.Logger.Error("failed to DeleteImageSrc", zap.Int("character_id", id), zap.Error(err))
-			return fmt.Errorf("failed to DeleteImageSrc: %w", err)
-		}
-
-		// images_character_relationsの削除
-		err = ctx.Server.Store.DeleteAllImageCharacterRelationsByCharacterID(ctx, character.ID)
-		if err != nil {
-			ctx.Server.Logger.Error("failed to DeleteAllImageCharacterRelationsByCharacterID", zap.Int("character_id", id), zap.Error(err))
-			return fmt.Errorf("failed to DeleteAllImageCharacterRelationsByCharacterID : %w", err)
-		}
-
-		err = ctx.Server.Store.DeleteCharacter(ctx, int64(id))
-		if err != nil {
-			ctx.Server.Logger.Error("failed to DeleteCharacter", zap.Int("character_id", id), zap.Error(err))
-			return fmt.Errorf("failed to DeleteCharacter: %w", err)
-		}
-		return nil
-	})
-
-	if txErr != nil {
-		ctx.Server.Logger.Error("DeleteCharacter transaction was failed", zap.Int("character_id", id), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("DeleteCharacter transaction was failed : %w", txErr)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to DeleteCharacter : %w", err))
 		return
 	}
 
 	// redisキャッシュの削除
-	keyPattern := []string{cache.CharactersPrefix + "*"}
+	keyPattern := []string{cache.CharactersPrefix + "*", cache.RelatedIllustrationsPrefix + "*", cache.SuggestionsPrefix + "*"}
 	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
 	if err != nil {
 		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
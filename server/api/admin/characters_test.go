@@ -143,6 +143,26 @@ func TestSearchCharacters(t *testing.T) {
 			wantErr:      false,
 			expectedCode: http.StatusOK,
 		},
+		{
+			name: "正常系（p=0, q=全角の２０００１）",
+			arg: args{
+				page:         "0",
+				query:        "２０００１",
+				fetchLimit:   1,
+				compareLimit: 1,
+			},
+			want: []db.Character{
+				{
+					ID:            20001,
+					Name:          "test_character_name_20001",
+					Src:           "test_character_src_20001.com",
+					Filename:      sql.NullString{String: "test_character_filename_20001", Valid: true},
+					PriorityLevel: 2,
+				},
+			},
+			wantErr:      false,
+			expectedCode: http.StatusOK,
+		},
 		{
 			name: "正常系（p=9999, q=なし）",
 			arg: args{
@@ -326,6 +346,8 @@ func TestEditCharacter(t *testing.T) {
 		want         db.Character
 		wantErr      bool
 		expectedCode int
+		// 取得した時点のETagからIf-Matchヘッダーの値を決める。nilの場合は取得した時点のETagをそのまま使う
+		ifMatch func(current string) string
 	}{
 		{
 			name: "正常系",
@@ -404,6 +426,48 @@ func TestEditCharacter(t *testing.T) {
 			wantErr:      true,
 			expectedCode: http.StatusNotFound,
 		},
+		{
+			name: "異常系（If-Matchヘッダーがない場合）",
+			arg: args{
+				ID: "20021",
+			},
+			prepare: func() (*bytes.Buffer, string) {
+				body := &bytes.Buffer{}
+				writer := multipart.NewWriter(body)
+				defer writer.Close()
+
+				_ = writer.WriteField("name", "test_character_name_20021_edited")
+				_ = writer.WriteField("filename", "test_character_filename_20021")
+
+				return body, writer.FormDataContentType()
+			},
+			wantErr:      true,
+			expectedCode: http.StatusPreconditionRequired,
+			ifMatch: func(current string) string {
+				return ""
+			},
+		},
+		{
+			name: "異常系（取得した後に他の操作で更新されている場合）",
+			arg: args{
+				ID: "20021",
+			},
+			prepare: func() (*bytes.Buffer, string) {
+				body := &bytes.Buffer{}
+				writer := multipart.NewWriter(body)
+				defer writer.Close()
+
+				_ = writer.WriteField("name", "test_character_name_20021_edited")
+				_ = writer.WriteField("filename", "test_character_filename_20021")
+
+				return body, writer.FormDataContentType()
+			},
+			wantErr:      true,
+			expectedCode: http.StatusPreconditionFailed,
+			ifMatch: func(current string) string {
+				return staleETag
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -412,6 +476,13 @@ func TestEditCharacter(t *testing.T) {
 			req, _ := http.NewRequest(http.MethodPut, "/api/v1/admin/characters/"+tt.arg.ID, body)
 			req.Header.Set("Content-Type", contentType)
 			req.Header.Set("Authorization", "Bearer "+accessToken)
+			ifMatch := getETag(t, ctx, accessToken, "/api/v1/admin/characters/"+tt.arg.ID)
+			if tt.ifMatch != nil {
+				ifMatch = tt.ifMatch(ifMatch)
+			}
+			if ifMatch != "" {
+				req.Header.Set("If-Match", ifMatch)
+			}
 
 			ctx.Server.Router.ServeHTTP(w, req)
 
@@ -419,7 +490,13 @@ func TestEditCharacter(t *testing.T) {
 
 			if tt.wantErr {
 				require.NotEmpty(t, w.Body.String())
+				if tt.expectedCode == http.StatusPreconditionFailed {
+					// 現在の状態とETagを返す
+					require.Contains(t, w.Body.String(), `"character"`)
+					require.NotEqual(t, staleETag, w.Header().Get("ETag"))
+				}
 			} else {
+				require.NotEmpty(t, w.Header().Get("ETag"))
 				type wantType struct {
 					Character db.Character `json:"character"`
 				}
@@ -435,12 +512,212 @@ func TestEditCharacter(t *testing.T) {
 	}
 }
 
+func TestPatchCharacter(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	c := charactersTest{}
+	ctx := c.setUp(t, config)
+	defer c.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	tests := []struct {
+		name         string
+		id           string
+		body         string
+		want         db.Character
+		wantErr      bool
+		expectedCode int
+	}{
+		{
+			name: "正常系（名前のみを変更した場合はファイル名と画像を維持する）",
+			id:   "20021",
+			body: `{"name": "test_character_name_20021_patched"}`,
+			want: db.Character{
+				ID:   20021,
+				Name: "test_character_name_20021_patched",
+				Filename: sql.NullString{
+					String: "test_character_filename_20021",
+					Valid:  true,
+				},
+				Src:           "test_character_src_20021.com",
+				PriorityLevel: 2,
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "正常系（優先度のみを変更した場合）",
+			id:   "20021",
+			body: `{"name": null, "priority_level": 3}`,
+			want: db.Character{
+				ID:   20021,
+				Name: "test_character_name_20021_patched",
+				Filename: sql.NullString{
+					String: "test_character_filename_20021",
+					Valid:  true,
+				},
+				Src:           "test_character_src_20021.com",
+				PriorityLevel: 3,
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（名前が空文字の場合）",
+			id:           "20021",
+			body:         `{"name": ""}`,
+			wantErr:      true,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系（存在しないcharacterのIDを指定した場合）",
+			id:           "999999",
+			body:         `{"name": "test"}`,
+			wantErr:      true,
+			expectedCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPatch, "/api/v1/admin/characters/"+tt.id, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Header.Set("If-Match", getETag(t, ctx, accessToken, "/api/v1/admin/characters/"+tt.id))
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.wantErr {
+				require.NotEmpty(t, w.Body.String())
+				return
+			}
+
+			var got struct {
+				Character db.Character `json:"character"`
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &got)
+			require.NoError(t, err)
+			ignoreFields := map[string][]string{
+				"Other": {"CreatedAt", "UpdatedAt"},
+			}
+			compareCharactersObjects(t, got.Character, tt.want, ignoreFields)
+		})
+	}
+}
+
 func compareCharactersObjects(t *testing.T, got db.Character, want db.Character, ignoreFieldsMap map[string][]string) {
 	if d := cmp.Diff(got, want, cmpopts.IgnoreFields(got, ignoreFieldsMap["Other"]...)); len(d) != 0 {
 		t.Errorf("differs: (-got +want)\n%s", d)
 	}
 }
 
+func TestReorderCharacters(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	c := charactersTest{}
+	ctx := c.setUp(t, config)
+	defer c.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	tests := []struct {
+		name         string
+		ids          []int64
+		want         []int64
+		expectedCode int
+	}{
+		{
+			name:         "正常系",
+			ids:          []int64{20011, 29001, 20021, 20001},
+			want:         []int64{20011, 29001, 20021, 20001},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（全てのキャラクターが指定されていない場合）",
+			ids:          []int64{20001, 29001, 20021},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系（IDが重複している場合）",
+			ids:          []int64{20001, 29001, 20021, 20021},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系（存在しないIDが含まれる場合）",
+			ids:          []int64{20001, 29001, 20021, 999999},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系（IDが指定されていない場合）",
+			ids:          []int64{},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			body, contentType := newIDsFormBody(t, map[string][]int64{"ids[]": tt.ids})
+			req, _ := http.NewRequest(http.MethodPut, "/api/v1/admin/characters/order", body)
+			req.Header.Set("Content-Type", contentType)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.want != nil {
+				characters, err := ctx.Server.Store.ListAllCharacters(context.Background())
+				require.NoError(t, err)
+				got := make([]int64, 0, len(characters))
+				for _, character := range characters {
+					got = append(got, character.ID)
+				}
+				require.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEditCharacterAfterReorder(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	c := charactersTest{}
+	ctx := c.setUp(t, config)
+	defer c.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	// 並び替える前に取得したETag
+	oldETag := getETag(t, ctx, accessToken, "/api/v1/admin/characters/20021")
+
+	w := httptest.NewRecorder()
+	body, contentType := newIDsFormBody(t, map[string][]int64{"ids[]": {20011, 29001, 20021, 20001}})
+	req, _ := http.NewRequest(http.MethodPut, "/api/v1/admin/characters/order", body)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	ctx.Server.Router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// 並び替えで更新された優先度を古い内容で上書きできない
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPatch, "/api/v1/admin/characters/20021", bytes.NewBufferString(`{"name": "test"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("If-Match", oldETag)
+	ctx.Server.Router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
 func (c charactersTest) setUp(t *testing.T, config util.Config) *app.AppContext {
 	store := createConn(config)
 
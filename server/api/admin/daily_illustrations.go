@@ -0,0 +1,179 @@
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/internal/cache"
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/pkg/lib/apperror"
+	"shin-monta-no-mori/pkg/lib/binder"
+	"shin-monta-no-mori/pkg/util"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// dailyIllustration は管理者が指定した今日のイラスト
+type dailyIllustration struct {
+	Date      string    `json:"date"`
+	ImageID   int64     `json:"image_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newDailyIllustration(d db.DailyIllustration) dailyIllustration {
+	return dailyIllustration{
+		Date:      d.Date.Format(time.DateOnly),
+		ImageID:   d.ImageID,
+		UpdatedAt: d.UpdatedAt,
+	}
+}
+
+type listDailyIllustrationsRequest struct {
+	From string `form:"from"`
+}
+
+type listDailyIllustrationsResponse struct {
+	DailyIllustrations []dailyIllustration `json:"daily_illustrations"`
+}
+
+// ListDailyIllustrations godoc
+// @Summary List overridden illustrations of the day
+// @Description Retrieves the illustrations of the day specified by admins on or after the given JST date, ordered by date. Defaults to today.
+// @Accept  json
+// @Produce  json
+// @Param   from  query  string  false  "Start date (YYYY-MM-DD)"
+// @Success 200 {object} listDailyIllustrationsResponse "A list of overridden illustrations of the day"
+// @Failure 400 {object} apperror.Response "Bad Request: The date is malformed"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to list the illustrations of the day"
+// @Router /api/v1/admin/illustrations/daily/list [get]
+func ListDailyIllustrations(ctx *app.AppContext) {
+	var req listDailyIllustrationsRequest
+	if err := binder.BindQuery(ctx.Context, &req); err != nil {
+		return
+	}
+
+	from := time.Now()
+	if req.From != "" {
+		f, err := util.ParseDate(req.From)
+		if err != nil {
+			ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'from' : %w", err))
+			return
+		}
+		from = f
+	}
+
+	overrides, err := ctx.Server.IllustrationService.ListDailyOverrides(ctx, from)
+	if err != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListDailyIllustrations : %w", err))
+		return
+	}
+
+	dailyIllustrations := make([]dailyIllustration, len(overrides))
+	for i, o := range overrides {
+		dailyIllustrations[i] = newDailyIllustration(o)
+	}
+
+	ctx.JSON(http.StatusOK, listDailyIllustrationsResponse{
+		DailyIllustrations: dailyIllustrations,
+	})
+}
+
+type setDailyIllustrationRequest struct {
+	ImageID int64 `form:"image_id" binding:"required"`
+}
+
+type setDailyIllustrationResponse struct {
+	DailyIllustration dailyIllustration `json:"daily_illustration"`
+	Message           string            `json:"message"`
+}
+
+// SetDailyIllustration godoc
+// @Summary Override the illustration of the day
+// @Description Sets the illustration of the day for the given JST date. An illustration already picked for the date is replaced.
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param   date      path      string  true  "Date (YYYY-MM-DD)"
+// @Param   image_id  formData  int     true  "ID of the illustration"
+// @Success 200 {object} setDailyIllustrationResponse "Returns the illustration of the day and a success message"
+// @Failure 400 {object} apperror.Response "Bad Request: The date is malformed or image_id is missing"
+// @Failure 404 {object} apperror.Response "Not Found: No illustration found with the given ID"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to set the illustration of the day"
+// @Router /api/v1/admin/illustrations/daily/{date} [put]
+func SetDailyIllustration(ctx *app.AppContext) {
+	date, err := util.ParseDate(ctx.Param("date"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'date' from path parameter : %w", err))
+		return
+	}
+	var req setDailyIllustrationRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+
+	daily, err := ctx.Server.IllustrationService.SetDailyOverride(ctx, date, req.ImageID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to SetDailyIllustration : %w", err))
+		return
+	}
+
+	// redisキャッシュの削除
+	keyPattern := []string{cache.DailyIllustrationPrefix + "*"}
+	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
+	if err != nil {
+		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
+	}
+
+	ctx.JSON(http.StatusOK, setDailyIllustrationResponse{
+		DailyIllustration: newDailyIllustration(daily),
+		Message:           "今日のイラストの設定に成功しました",
+	})
+}
+
+// DeleteDailyIllustration godoc
+// @Summary Cancel an overridden illustration of the day
+// @Description Cancels the illustration of the day specified by admins for the given JST date. An illustration is picked again on the next request for the date.
+// @Accept  json
+// @Produce  json
+// @Param   date  path  string  true  "Date (YYYY-MM-DD)"
+// @Success 200 {object} gin/H "Returns a success message"
+// @Failure 400 {object} apperror.Response "Bad Request: The date is malformed"
+// @Failure 404 {object} apperror.Response "Not Found: No overridden illustration for the date"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to cancel the illustration of the day"
+// @Router /api/v1/admin/illustrations/daily/{date} [delete]
+func DeleteDailyIllustration(ctx *app.AppContext) {
+	date, err := util.ParseDate(ctx.Param("date"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'date' from path parameter : %w", err))
+		return
+	}
+
+	err = ctx.Server.IllustrationService.DeleteDailyOverride(ctx, date)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to DeleteDailyIllustration : %w", err))
+		return
+	}
+
+	// redisキャッシュの削除
+	keyPattern := []string{cache.DailyIllustrationPrefix + "*"}
+	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
+	if err != nil {
+		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "今日のイラストの設定の取り消しに成功しました",
+	})
+}
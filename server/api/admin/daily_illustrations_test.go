@@ -0,0 +1,207 @@
+package admin_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/pkg/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type dailyIllustrationsTest struct{}
+
+func TestSetDailyIllustration(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	d := dailyIllustrationsTest{}
+	ctx := d.setUp(t, config)
+	defer d.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	tests := []struct {
+		name         string
+		date         string
+		imageID      string
+		expectedCode int
+	}{
+		{
+			name:         "正常系",
+			date:         "2024-01-01",
+			imageID:      "32001",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（既に指定されている日付を上書き）",
+			date:         "2024-01-01",
+			imageID:      "32002",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（日付の形式が不正な場合）",
+			date:         "20240101",
+			imageID:      "32001",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系（image_idが空の場合）",
+			date:         "2024-01-01",
+			imageID:      "",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系（存在しないイラストを指定した場合）",
+			date:         "2024-01-01",
+			imageID:      "999999",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := new(bytes.Buffer)
+			writer := multipart.NewWriter(body)
+			require.NoError(t, writer.WriteField("image_id", tt.imageID))
+			require.NoError(t, writer.Close())
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPut, "/api/v1/admin/illustrations/daily/"+tt.date, body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+
+	// 最後に指定したイラストが一覧に含まれること
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/illustrations/daily/list?from=2024-01-01", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	ctx.Server.Router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	type dailyIllustration struct {
+		Date    string `json:"date"`
+		ImageID int64  `json:"image_id"`
+	}
+	type wantType struct {
+		DailyIllustrations []dailyIllustration `json:"daily_illustrations"`
+	}
+	var got wantType
+	err = json.Unmarshal(w.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Equal(t, []dailyIllustration{{Date: "2024-01-01", ImageID: 32002}}, got.DailyIllustrations)
+}
+
+func TestDeleteDailyIllustration(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	d := dailyIllustrationsTest{}
+	ctx := d.setUp(t, config)
+	defer d.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	_, err = ctx.Server.Store.ExecQuery(context.Background(), `
+		INSERT INTO daily_illustrations (date, image_id, is_override)
+		VALUES
+		('2024-01-01', 32001, true),
+		('2024-01-02', 32002, false);
+	`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		date         string
+		expectedCode int
+	}{
+		{
+			name:         "正常系",
+			date:         "2024-01-01",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（取り消し済みの日付の場合）",
+			date:         "2024-01-01",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "異常系（管理者が指定していない日付の場合）",
+			date:         "2024-01-02",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "異常系（日付の形式が不正な場合）",
+			date:         "aaa",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodDelete, "/api/v1/admin/illustrations/daily/"+tt.date, nil)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func (d dailyIllustrationsTest) setUp(t *testing.T, config util.Config) *app.AppContext {
+	store := createConn(config)
+
+	queries := []string{
+		fmt.Sprintln(`
+		INSERT INTO images (id, title, original_src, simple_src, original_filename)
+		VALUES
+		(32001, 'test_image_title_32001', 'test_image_original_src_32001.com', 'test_image_simple_src_32001.com', 'test_image_original_filename_32001'),
+		(32002, 'test_image_title_32002', 'test_image_original_src_32002.com', 'test_image_simple_src_32002.com', 'test_image_original_filename_32002');
+		`),
+	}
+
+	for _, query := range queries {
+		if _, err := store.ExecQuery(context.Background(), query); err != nil {
+			t.Fatalf("Failed to exec query: %v", err)
+		}
+	}
+
+	server, err := newTestServer(store, config)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	return server
+}
+
+func (d dailyIllustrationsTest) tearDown(t *testing.T, config util.Config) {
+	store := createConn(config)
+
+	queries := []string{
+		"TRUNCATE TABLE daily_illustrations RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE images RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE operators RESTART IDENTITY CASCADE;",
+	}
+	for _, query := range queries {
+		if _, err := store.ExecQuery(context.Background(), query); err != nil {
+			t.Fatalf("Failed to truncate table: %v", err)
+		}
+	}
+}
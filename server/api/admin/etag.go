@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"fmt"
+	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/pkg/lib/apperror"
+	"shin-monta-no-mori/pkg/lib/etag"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag は更新日時から生成したETagをレスポンスヘッダーに設定する
+// 編集時はこの値をIf-Matchヘッダーで送信する
+func setETag(ctx *app.AppContext, updatedAt time.Time) {
+	ctx.Header("ETag", etag.Format(updatedAt))
+}
+
+// respondIllustrationVersionMismatch は現在のイラストとETagを412で返す
+// クライアントが変更内容を確認して編集し直せるよう、現在の状態を含める
+func respondIllustrationVersionMismatch(ctx *app.AppContext, id int, err error) {
+	current, getErr := ctx.Server.IllustrationService.Get(ctx, int64(id))
+	if getErr != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to GetIllustration : %w", getErr))
+		return
+	}
+	setETag(ctx, current.Image.UpdatedAt)
+	ctx.FailWith(apperror.PreconditionFailed, err, gin.H{"illustration": current})
+}
+
+// respondCharacterVersionMismatch は現在のキャラクターとETagを412で返す
+func respondCharacterVersionMismatch(ctx *app.AppContext, id int, err error) {
+	current, getErr := ctx.Server.Store.GetCharacter(ctx, int64(id))
+	if getErr != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to GetCharacter : %w", getErr))
+		return
+	}
+	setETag(ctx, current.UpdatedAt)
+	ctx.FailWith(apperror.PreconditionFailed, err, gin.H{"character": current})
+}
+
+// respondParentCategoryVersionMismatch は現在の親カテゴリとETagを412で返す
+func respondParentCategoryVersionMismatch(ctx *app.AppContext, id int, err error) {
+	current, getErr := ctx.Server.Store.GetParentCategory(ctx, int64(id))
+	if getErr != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to GetParentCategory : %w", getErr))
+		return
+	}
+	setETag(ctx, current.UpdatedAt)
+	ctx.FailWith(apperror.PreconditionFailed, err, gin.H{"parent_category": current})
+}
+
+// respondChildCategoryVersionMismatch は現在の子カテゴリとETagを412で返す
+func respondChildCategoryVersionMismatch(ctx *app.AppContext, id int, err error) {
+	current, getErr := ctx.Server.Store.GetChildCategory(ctx, int64(id))
+	if getErr != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to GetChildCategory : %w", getErr))
+		return
+	}
+	setETag(ctx, current.UpdatedAt)
+	ctx.FailWith(apperror.PreconditionFailed, err, gin.H{"child_category": current})
+}
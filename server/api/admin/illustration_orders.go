@@ -0,0 +1,121 @@
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/internal/cache"
+	"shin-monta-no-mori/internal/domains/service"
+	"shin-monta-no-mori/pkg/lib/apperror"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type reorderIllustrationsRequest struct {
+	// 先頭から順に表示するイラストのID. 空の場合は表示順の指定を全て解除する
+	ImageIDs []int64 `form:"image_ids[]"`
+}
+
+// ReorderCharacterIllustrations godoc
+// @Summary Reorder illustrations of a character
+// @Description Sets the display order of illustrations on the character's page to the given order of IDs. Illustrations not included are listed after them, newest first.
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param   id           path      int    true   "ID of the character"
+// @Param   image_ids[]  formData  []int  false  "Ordered list of illustration IDs related to the character"
+// @Success 200 {object} gin/H "Returns a success message"
+// @Failure 400 {object} apperror.Response "Bad Request: The list contains duplicated illustrations or illustrations not related to the character"
+// @Failure 404 {object} apperror.Response "Not Found: No character found with the given ID"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to update the display order"
+// @Router /api/v1/admin/characters/{id}/illustrations/order [put]
+func ReorderCharacterIllustrations(ctx *app.AppContext) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
+		return
+	}
+	var req reorderIllustrationsRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+
+	err = ctx.Server.CharacterService.ReorderIllustrations(ctx, int64(id), req.ImageIDs)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidIllustrationOrder) {
+			ctx.Fail(apperror.BadRequest, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ReorderCharacterIllustrations : %w", err))
+		return
+	}
+
+	// redisキャッシュの削除
+	keyPattern := []string{cache.IllustrationsPrefix + "*"}
+	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
+	if err != nil {
+		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "illustrationの並び替えに成功しました",
+	})
+}
+
+// ReorderChildCategoryIllustrations godoc
+// @Summary Reorder illustrations of a child category
+// @Description Sets the display order of illustrations on the child category's page to the given order of IDs. Illustrations not included are listed after them, newest first.
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param   id           path      int    true   "ID of the child category"
+// @Param   image_ids[]  formData  []int  false  "Ordered list of illustration IDs related to the child category"
+// @Success 200 {object} gin/H "Returns a success message"
+// @Failure 400 {object} apperror.Response "Bad Request: The list contains duplicated illustrations or illustrations not related to the child category"
+// @Failure 404 {object} apperror.Response "Not Found: No child category found with the given ID"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to update the display order"
+// @Router /api/v1/admin/categories/child/{id}/illustrations/order [put]
+func ReorderChildCategoryIllustrations(ctx *app.AppContext) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
+		return
+	}
+	var req reorderIllustrationsRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+
+	err = ctx.Server.CategoryService.ReorderIllustrations(ctx, int64(id), req.ImageIDs)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidIllustrationOrder) {
+			ctx.Fail(apperror.BadRequest, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ReorderChildCategoryIllustrations : %w", err))
+		return
+	}
+
+	// redisキャッシュの削除
+	keyPattern := []string{cache.IllustrationsPrefix + "*"}
+	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
+	if err != nil {
+		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "illustrationの並び替えに成功しました",
+	})
+}
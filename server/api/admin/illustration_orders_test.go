@@ -0,0 +1,264 @@
+package admin_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"shin-monta-no-mori/internal/app"
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/pkg/util"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type illustrationOrdersTest struct{}
+
+func TestReorderCharacterIllustrations(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	o := illustrationOrdersTest{}
+	ctx := o.setUp(t, config)
+	defer o.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	tests := []struct {
+		name         string
+		id           string
+		imageIDs     []int64
+		want         []int64
+		expectedCode int
+	}{
+		{
+			name:     "正常系",
+			id:       "51001",
+			imageIDs: []int64{51001, 51003},
+			// 並び順が指定されていないイラストは、指定されたイラストの後に新しい順で並ぶ
+			want:         []int64{51001, 51003, 51004, 51002},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（空の場合は並び順の指定を解除する）",
+			id:           "51001",
+			imageIDs:     []int64{},
+			want:         []int64{51004, 51003, 51002, 51001},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（キャラクターに紐づかないイラストが含まれる場合）",
+			id:           "51001",
+			imageIDs:     []int64{51001, 51005},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系（イラストが重複している場合）",
+			id:           "51001",
+			imageIDs:     []int64{51001, 51001},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系（存在しないキャラクターの場合）",
+			id:           "999999",
+			imageIDs:     []int64{51001},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "異常系（idの値が不正な場合）",
+			id:           "aaa",
+			imageIDs:     []int64{51001},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			body, contentType := newIDsFormBody(t, map[string][]int64{"image_ids[]": tt.imageIDs})
+			req, _ := http.NewRequest(http.MethodPut, "/api/v1/admin/characters/"+tt.id+"/illustrations/order", body)
+			req.Header.Set("Content-Type", contentType)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.want != nil {
+				rows, err := ctx.Server.Store.ListImagesByCharacterOrderByPosition(context.Background(), db.ListImagesByCharacterOrderByPositionParams{
+					CharacterID: 51001,
+					MaxResults:  10,
+				})
+				require.NoError(t, err)
+				got := make([]int64, 0, len(rows))
+				for _, row := range rows {
+					got = append(got, row.Image.ID)
+				}
+				require.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestReorderChildCategoryIllustrations(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	o := illustrationOrdersTest{}
+	ctx := o.setUp(t, config)
+	defer o.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	tests := []struct {
+		name         string
+		id           string
+		imageIDs     []int64
+		want         []int64
+		expectedCode int
+	}{
+		{
+			name:         "正常系",
+			id:           "51001",
+			imageIDs:     []int64{51002},
+			want:         []int64{51002, 51004, 51003},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（子カテゴリに紐づかないイラストが含まれる場合）",
+			id:           "51001",
+			imageIDs:     []int64{51001},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系（存在しない子カテゴリの場合）",
+			id:           "999999",
+			imageIDs:     []int64{51002},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			body, contentType := newIDsFormBody(t, map[string][]int64{"image_ids[]": tt.imageIDs})
+			req, _ := http.NewRequest(http.MethodPut, "/api/v1/admin/categories/child/"+tt.id+"/illustrations/order", body)
+			req.Header.Set("Content-Type", contentType)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.want != nil {
+				rows, err := ctx.Server.Store.ListImagesByChildCategoryOrderByPosition(context.Background(), db.ListImagesByChildCategoryOrderByPositionParams{
+					ChildCategoryID: 51001,
+					MaxResults:      10,
+				})
+				require.NoError(t, err)
+				got := make([]int64, 0, len(rows))
+				for _, row := range rows {
+					got = append(got, row.Image.ID)
+				}
+				require.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+// newIDsFormBody はフィールドごとのIDの一覧をmultipart/form-dataのリクエストボディに変換する
+func newIDsFormBody(t *testing.T, fields map[string][]int64) (*bytes.Buffer, string) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for field, ids := range fields {
+		for _, id := range ids {
+			require.NoError(t, writer.WriteField(field, strconv.FormatInt(id, 10)))
+		}
+	}
+	require.NoError(t, writer.Close())
+	return body, writer.FormDataContentType()
+}
+
+func (o illustrationOrdersTest) setUp(t *testing.T, config util.Config) *app.AppContext {
+	store := createConn(config)
+
+	queries := []string{
+		fmt.Sprintln(`
+		INSERT INTO images (id, title, original_src, simple_src, original_filename)
+		VALUES
+		(51001, 'test_image_title_51001', 'test_image_original_src_51001.com', 'test_image_simple_src_51001.com', 'test_image_original_filename_51001'),
+		(51002, 'test_image_title_51002', 'test_image_original_src_51002.com', 'test_image_simple_src_51002.com', 'test_image_original_filename_51002'),
+		(51003, 'test_image_title_51003', 'test_image_original_src_51003.com', 'test_image_simple_src_51003.com', 'test_image_original_filename_51003'),
+		(51004, 'test_image_title_51004', 'test_image_original_src_51004.com', 'test_image_simple_src_51004.com', 'test_image_original_filename_51004'),
+		(51005, 'test_image_title_51005', 'test_image_original_src_51005.com', 'test_image_simple_src_51005.com', 'test_image_original_filename_51005');
+		`),
+		fmt.Sprintln(`
+		INSERT INTO characters (id, name, src)
+		VALUES
+		(51001, 'test_character_name_51001', 'test_character_src_51001.com');
+		`),
+		fmt.Sprintln(`
+		INSERT INTO image_characters_relations (id, image_id, character_id)
+		VALUES
+		(51001, 51001, 51001),
+		(51002, 51002, 51001),
+		(51003, 51003, 51001),
+		(51004, 51004, 51001);
+		`),
+		fmt.Sprintln(`
+		INSERT INTO parent_categories (id, name, src)
+		VALUES
+		(51001, 'test_parent_category_name_51001', 'test_parent_category_src_51001.com');
+		`),
+		fmt.Sprintln(`
+		INSERT INTO child_categories (id, name, parent_id)
+		VALUES
+		(51001, 'test_child_category_name_51001', 51001);
+		`),
+		fmt.Sprintln(`
+		INSERT INTO image_child_categories_relations (id, image_id, child_category_id)
+		VALUES
+		(51002, 51002, 51001),
+		(51003, 51003, 51001),
+		(51004, 51004, 51001);
+		`),
+	}
+
+	for _, query := range queries {
+		if _, err := store.ExecQuery(context.Background(), query); err != nil {
+			t.Fatalf("Failed to exec query: %v", err)
+		}
+	}
+
+	s, err := newTestServer(store, config)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	return s
+}
+
+func (o illustrationOrdersTest) tearDown(t *testing.T, config util.Config) {
+	store := createConn(config)
+
+	queries := []string{
+		"TRUNCATE TABLE image_child_categories_relations RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE image_characters_relations RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE child_categories RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE parent_categories RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE characters RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE images RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE operators RESTART IDENTITY CASCADE;",
+	}
+	for _, query := range queries {
+		if _, err := store.ExecQuery(context.Background(), query); err != nil {
+			t.Fatalf("Failed to truncate table: %v", err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/internal/cache"
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/internal/domains/service"
+	"shin-monta-no-mori/pkg/lib/apperror"
+	"shin-monta-no-mori/pkg/lib/binder"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type (
+	listIllustrationRevisionsRequest struct {
+		Page int64 `form:"p"`
+	}
+
+	listIllustrationRevisionsResponse struct {
+		Revisions  []db.ImageRevision `json:"revisions"`
+		TotalPages int64              `json:"total_pages"`
+		TotalCount int64              `json:"total_count"`
+	}
+
+	diffIllustrationRevisionsRequest struct {
+		From int64 `form:"from" binding:"required,min=1"`
+		// 省略した場合は現在のイラストと比較する
+		To int64 `form:"to" binding:"omitempty,min=1"`
+	}
+)
+
+// ListIllustrationRevisions godoc
+// @Summary List revisions of an illustration
+// @Description Retrieves the edit history of an illustration, newest first. Each revision is a snapshot of the title, filenames, sources and related character and category IDs before an edit, with the operator who made it.
+// @Tags illustrations
+// @Accept  json
+// @Produce  json
+// @Param   id  path   int  true   "ID of the illustration"
+// @Param   p   query  int  false  "Page number for pagination"
+// @Success 200 {object} listIllustrationRevisionsResponse "A list of revisions"
+// @Failure 400 {object} apperror.Response "Bad Request: Error parsing the 'id' or query parameters"
+// @Failure 404 {object} apperror.Response "Not Found: No illustration found with the given ID"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to list the revisions"
+// @Router /api/v1/admin/illustrations/{id}/revisions [get]
+func ListIllustrationRevisions(ctx *app.AppContext) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+	var req listIllustrationRevisionsRequest
+	if err := binder.BindQuery(ctx.Context, &req); err != nil {
+		return
+	}
+
+	limit := ctx.Server.Config.ImageFetchLimit
+	revisions, totalCount, err := ctx.Server.IllustrationService.ListRevisions(ctx, int64(id), int32(limit), int32(int(req.Page)*limit))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListIllustrationRevisions : %w", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, listIllustrationRevisionsResponse{
+		Revisions:  revisions,
+		TotalPages: (totalCount + int64(limit-1)) / int64(limit),
+		TotalCount: totalCount,
+	})
+}
+
+// DiffIllustrationRevisions godoc
+// @Summary Show the differences between revisions of an illustration
+// @Description Compares two revisions of an illustration and returns the changed fields and the added and removed character and category IDs. If 'to' is omitted, the revision is compared with the current illustration.
+// @Tags illustrations
+// @Accept  json
+// @Produce  json
+// @Param   id    path   int  true   "ID of the illustration"
+// @Param   from  query  int  true   "ID of the older revision"
+// @Param   to    query  int  false  "ID of the newer revision"
+// @Success 200 {object} service.RevisionDiff "Differences between the revisions"
+// @Failure 400 {object} apperror.Response "Bad Request: Error parsing the 'id' or query parameters"
+// @Failure 404 {object} apperror.Response "Not Found: No illustration or revision found with the given ID"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to compare the revisions"
+// @Router /api/v1/admin/illustrations/{id}/revisions/diff [get]
+func DiffIllustrationRevisions(ctx *app.AppContext) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+	var req diffIllustrationRevisionsRequest
+	if err := binder.BindQuery(ctx.Context, &req); err != nil {
+		return
+	}
+
+	diff, err := ctx.Server.IllustrationService.DiffRevisions(ctx, int64(id), req.From, req.To)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to DiffIllustrationRevisions : %w", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, diff)
+}
+
+// RollbackIllustration godoc
+// @Summary Roll back an illustration to a revision
+// @Description Restores the title and the related characters and categories of an illustration from a revision. Image files are kept as they are because replaced files are removed on edit. The state before the rollback is saved as a new revision. The If-Match header must hold the ETag returned when the illustration was retrieved.
+// @Tags illustrations
+// @Accept  json
+// @Produce  json
+// @Param   id           path  int  true  "ID of the illustration"
+// @Param   revision_id  path  int  true  "ID of the revision to roll back to"
+// @Param   If-Match     header  string  true  "ETag of the illustration"
+// @Success 200 {object} model.Illustration "The illustration after the rollback"
+// @Failure 400 {object} apperror.Response "Bad Request: Error parsing the path parameters"
+// @Failure 404 {object} apperror.Response "Not Found: No illustration or revision found with the given ID"
+// @Failure 412 {object} apperror.Response "Precondition Failed: The illustration was modified after it was retrieved. Returns the current illustration"
+// @Failure 428 {object} apperror.Response "Precondition Required: The If-Match header is missing"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to roll back the illustration"
+// @Router /api/v1/admin/illustrations/{id}/revisions/{revision_id}/rollback [post]
+func RollbackIllustration(ctx *app.AppContext) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+	revisionID, err := strconv.Atoi(ctx.Param("revision_id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'revision_id' number from from path parameter : %w", err))
+		return
+	}
+
+	version, err := binder.BindIfMatch(ctx.Context)
+	if err != nil {
+		return
+	}
+
+	illustration, err := ctx.Server.IllustrationService.Rollback(ctx, int64(id), service.RollbackIllustrationParams{
+		RevisionID:   int64(revisionID),
+		OperatorName: operatorName(ctx),
+		Version:      version,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
+		}
+		if errors.Is(err, service.ErrVersionMismatch) {
+			respondIllustrationVersionMismatch(ctx, id, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to RollbackIllustration : %w", err))
+		return
+	}
+
+	// redisキャッシュの削除
+	keyPattern := []string{
+		cache.IllustrationsPrefix + "*",
+		cache.GetIllustrationKey(id),
+		cache.RelatedIllustrationsPrefix + "*",
+		cache.DailyIllustrationPrefix + "*",
+		cache.SuggestionsPrefix + "*",
+	}
+	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
+	if err != nil {
+		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"illustration": illustration,
+		"message":      "illustrationのロールバックに成功しました",
+	})
+}
@@ -0,0 +1,321 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/internal/domains/service"
+	"shin-monta-no-mori/pkg/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type illustrationRevisionsTest struct{}
+
+func TestListIllustrationRevisions(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	r := illustrationRevisionsTest{}
+	ctx := r.setUp(t, config)
+	defer r.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	tests := []struct {
+		name         string
+		id           string
+		want         []int64
+		expectedCode int
+	}{
+		{
+			name:         "正常系",
+			id:           "71001",
+			want:         []int64{71002, 71001},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（編集履歴がない場合）",
+			id:           "71002",
+			want:         []int64{},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（存在しないイラストの場合）",
+			id:           "999999",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "異常系（idの値が不正な場合）",
+			id:           "aaa",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/illustrations/"+tt.id+"/revisions", nil)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.want != nil {
+				var res struct {
+					Revisions []struct {
+						ID int64 `json:"id"`
+					} `json:"revisions"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+				got := make([]int64, 0, len(res.Revisions))
+				for _, revision := range res.Revisions {
+					got = append(got, revision.ID)
+				}
+				require.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDiffIllustrationRevisions(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	r := illustrationRevisionsTest{}
+	ctx := r.setUp(t, config)
+	defer r.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	tests := []struct {
+		name         string
+		query        string
+		want         *service.RevisionDiff
+		expectedCode int
+	}{
+		{
+			name:  "正常系（編集履歴同士の比較）",
+			query: "from=71001&to=71002",
+			want: &service.RevisionDiff{
+				FromRevisionID: 71001,
+				ToRevisionID:   71002,
+				Fields: []service.FieldChange{
+					{Field: "title", From: "test_image_title_71001_v1", To: "test_image_title_71001_v2"},
+				},
+				Relations: []service.RelationChange{
+					{Relation: "characters", Added: []int64{71002}, Removed: []int64{71001}},
+				},
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:  "正常系（現在のイラストとの比較）",
+			query: "from=71002",
+			want: &service.RevisionDiff{
+				FromRevisionID: 71002,
+				ToRevisionID:   0,
+				Fields: []service.FieldChange{
+					{Field: "title", From: "test_image_title_71001_v2", To: "test_image_title_71001"},
+				},
+				Relations: []service.RelationChange{},
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（存在しない編集履歴の場合）",
+			query:        "from=999999",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "異常系（fromが指定されていない場合）",
+			query:        "to=71002",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/illustrations/71001/revisions/diff?"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.want != nil {
+				var got service.RevisionDiff
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				require.Equal(t, *tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRollbackIllustration(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	r := illustrationRevisionsTest{}
+	ctx := r.setUp(t, config)
+	defer r.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	tests := []struct {
+		name         string
+		id           string
+		revisionID   string
+		wantTitle    string
+		wantChars    []int64
+		expectedCode int
+		// 取得した時点のETagからIf-Matchヘッダーの値を決める。nilの場合は取得した時点のETagをそのまま使う
+		ifMatch func(current string) string
+	}{
+		{
+			name:         "異常系（If-Matchヘッダーがない場合）",
+			id:           "71001",
+			revisionID:   "71001",
+			expectedCode: http.StatusPreconditionRequired,
+			ifMatch: func(current string) string {
+				return ""
+			},
+		},
+		{
+			name:         "異常系（取得した後に他の操作で更新されている場合）",
+			id:           "71001",
+			revisionID:   "71001",
+			expectedCode: http.StatusPreconditionFailed,
+			ifMatch: func(current string) string {
+				return staleETag
+			},
+		},
+		{
+			name:         "正常系",
+			id:           "71001",
+			revisionID:   "71001",
+			wantTitle:    "test_image_title_71001_v1",
+			wantChars:    []int64{71001},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（他のイラストの編集履歴の場合）",
+			id:           "71002",
+			revisionID:   "71001",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "異常系（revision_idの値が不正な場合）",
+			id:           "71001",
+			revisionID:   "aaa",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/illustrations/"+tt.id+"/revisions/"+tt.revisionID+"/rollback", nil)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			ifMatch := getETag(t, ctx, accessToken, "/api/v1/admin/illustrations/"+tt.id)
+			if tt.ifMatch != nil {
+				ifMatch = tt.ifMatch(ifMatch)
+			}
+			if ifMatch != "" {
+				req.Header.Set("If-Match", ifMatch)
+			}
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedCode == http.StatusOK {
+				image, err := ctx.Server.Store.GetImage(context.Background(), 71001)
+				require.NoError(t, err)
+				require.Equal(t, tt.wantTitle, image.Title)
+
+				relations, err := ctx.Server.Store.ListImageCharacterRelationsByImageID(context.Background(), 71001)
+				require.NoError(t, err)
+				got := make([]int64, 0, len(relations))
+				for _, rel := range relations {
+					got = append(got, rel.CharacterID)
+				}
+				require.Equal(t, tt.wantChars, got)
+
+				// ロールバック前の状態が編集履歴として保存される
+				count, err := ctx.Server.Store.CountImageRevisions(context.Background(), 71001)
+				require.NoError(t, err)
+				require.Equal(t, int64(3), count)
+			}
+		})
+	}
+}
+
+func (r illustrationRevisionsTest) setUp(t *testing.T, config util.Config) *app.AppContext {
+	store := createConn(config)
+
+	queries := []string{
+		fmt.Sprintln(`
+		INSERT INTO images (id, title, original_src, simple_src, original_filename)
+		VALUES
+		(71001, 'test_image_title_71001', 'test_image_original_src_71001.com', 'test_image_simple_src_71001.com', 'test_image_original_filename_71001'),
+		(71002, 'test_image_title_71002', 'test_image_original_src_71002.com', 'test_image_simple_src_71002.com', 'test_image_original_filename_71002');
+		`),
+		fmt.Sprintln(`
+		INSERT INTO characters (id, name, src)
+		VALUES
+		(71001, 'test_character_name_71001', 'test_character_src_71001.com'),
+		(71002, 'test_character_name_71002', 'test_character_src_71002.com');
+		`),
+		fmt.Sprintln(`
+		INSERT INTO image_characters_relations (id, image_id, character_id)
+		VALUES
+		(71001, 71001, 71002);
+		`),
+		fmt.Sprintln(`
+		INSERT INTO image_revisions (id, image_id, title, original_src, simple_src, original_filename, character_ids, operator_name)
+		VALUES
+		(71001, 71001, 'test_image_title_71001_v1', 'test_image_original_src_71001.com', 'test_image_simple_src_71001.com', 'test_image_original_filename_71001', '{71001}', 'testuser'),
+		(71002, 71001, 'test_image_title_71001_v2', 'test_image_original_src_71001.com', 'test_image_simple_src_71001.com', 'test_image_original_filename_71001', '{71002}', 'testuser');
+		`),
+	}
+
+	for _, query := range queries {
+		if _, err := store.ExecQuery(context.Background(), query); err != nil {
+			t.Fatalf("Failed to exec query: %v", err)
+		}
+	}
+
+	s, err := newTestServer(store, config)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	return s
+}
+
+func (r illustrationRevisionsTest) tearDown(t *testing.T, config util.Config) {
+	store := createConn(config)
+
+	queries := []string{
+		"TRUNCATE TABLE image_revisions RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE image_characters_relations RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE characters RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE images RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE operators RESTART IDENTITY CASCADE;",
+	}
+	for _, query := range queries {
+		if _, err := store.ExecQuery(context.Background(), query); err != nil {
+			t.Fatalf("Failed to truncate table: %v", err)
+		}
+	}
+}
@@ -12,12 +12,16 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"shin-monta-no-mori/api"
 	"shin-monta-no-mori/internal/app"
 	"shin-monta-no-mori/internal/cache"
 	db "shin-monta-no-mori/internal/db/sqlc"
 	model "shin-monta-no-mori/internal/domains/models"
+	"shin-monta-no-mori/internal/domains/service"
+	"shin-monta-no-mori/pkg/lib/cursor"
+	"shin-monta-no-mori/pkg/lib/etag"
 	"shin-monta-no-mori/pkg/lib/logger"
 	"shin-monta-no-mori/pkg/lib/password"
 	"shin-monta-no-mori/pkg/token"
@@ -48,6 +52,7 @@ func TestListIllustrations(t *testing.T) {
 
 	type args struct {
 		page            string
+		cursor          string
 		imageFetchLimit int
 	}
 
@@ -160,6 +165,57 @@ func TestListIllustrations(t *testing.T) {
 			wantErr:      false,
 			expectedCode: http.StatusOK,
 		},
+		{
+			name: "正常系（cursorを指定したとき）",
+			arg: args{
+				cursor:          cursor.Encode(cursor.Cursor{ID: 999991}),
+				imageFetchLimit: 1,
+			},
+			want: []model.Illustration{
+				{
+					Image: db.Image{
+						ID:          999990,
+						Title:       "test_image_title_999990",
+						OriginalSrc: "test_image_original_src_999990.com",
+						SimpleSrc: sql.NullString{
+							String: "test_image_simple_src_999990.com",
+							Valid:  true,
+						},
+						OriginalFilename: "test_image_original_filename_999990",
+					},
+					Characters: []*model.Character{
+						{
+							Character: db.Character{
+								ID:            11001,
+								Name:          "test_character_name_11001",
+								Src:           "test_character_src_11001.com",
+								PriorityLevel: 2,
+							},
+						},
+					},
+					Categories: []*model.Category{
+						{
+							ParentCategory: db.ParentCategory{
+								ID:            11001,
+								Name:          "test_parent_category_name_11001",
+								Src:           "test_parent_category_src_11001.com",
+								PriorityLevel: 2,
+							},
+							ChildCategory: []db.ChildCategory{
+								{
+									ID:            11001,
+									Name:          "test_child_category_name_11001",
+									ParentID:      11001,
+									PriorityLevel: 2,
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr:      false,
+			expectedCode: http.StatusOK,
+		},
 		{
 			name: "正常系（データが存在しない場合)",
 			arg: args{
@@ -170,6 +226,16 @@ func TestListIllustrations(t *testing.T) {
 			wantErr:      false,
 			expectedCode: http.StatusOK,
 		},
+		{
+			name: "異常系（cursorの値が不正な場合)",
+			arg: args{
+				cursor:          "invalid-cursor",
+				imageFetchLimit: 1,
+			},
+			want:         []model.Illustration{},
+			wantErr:      true,
+			expectedCode: http.StatusBadRequest,
+		},
 		{
 			name: "異常系（クエリパラメータの値が不正な場合)",
 			arg: args{
@@ -185,7 +251,11 @@ func TestListIllustrations(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// 取得するイメージの数を1にする
 			c.Server.Config.ImageFetchLimit = tt.arg.imageFetchLimit
-			req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/illustrations/list?p="+tt.arg.page, nil)
+			url := "/api/v1/admin/illustrations/list?p=" + tt.arg.page
+			if tt.arg.cursor != "" {
+				url = "/api/v1/admin/illustrations/list?cursor=" + tt.arg.cursor
+			}
+			req, _ := http.NewRequest(http.MethodGet, url, nil)
 			req.Header.Set("Authorization", "Bearer "+accessToken)
 
 			w := httptest.NewRecorder()
@@ -200,10 +270,15 @@ func TestListIllustrations(t *testing.T) {
 					Illustration []model.Illustration `json:"illustrations"`
 					TotalPages   int64                `json:"total_pages"`
 					TotalCount   int64                `json:"total_count"`
+					NextCursor   string               `json:"next_cursor"`
 				}
 				var got wantType
 				err := json.Unmarshal(w.Body.Bytes(), &got)
 				require.NoError(t, err)
+				// 取得件数がlimitに達している場合は最後のイラストが次のカーソルになる
+				if len(tt.want) == tt.arg.imageFetchLimit {
+					require.Equal(t, cursor.Encode(cursor.Cursor{ID: tt.want[len(tt.want)-1].Image.ID}), got.NextCursor)
+				}
 				ignoreFields := map[string][]string{
 					"Image": {"CreatedAt", "UpdatedAt"},
 					"Other": {"CreatedAt", "UpdatedAt"},
@@ -657,6 +732,8 @@ func TestEditIllustration(t *testing.T) {
 		want         model.Illustration
 		wantErr      bool
 		expectedCode int
+		// 取得した時点のETagからIf-Matchヘッダーの値を決める。nilの場合は取得した時点のETagをそのまま使う
+		ifMatch func(current string) string
 	}{
 		{
 			name: "正常系",
@@ -784,7 +861,7 @@ func TestEditIllustration(t *testing.T) {
 			},
 			want:         model.Illustration{},
 			wantErr:      true,
-			expectedCode: http.StatusInternalServerError,
+			expectedCode: http.StatusUnprocessableEntity,
 		},
 		{
 			name: "異常系（存在しないparent_categoryのIDを指定している場合）",
@@ -803,7 +880,7 @@ func TestEditIllustration(t *testing.T) {
 			},
 			want:         model.Illustration{},
 			wantErr:      true,
-			expectedCode: http.StatusInternalServerError,
+			expectedCode: http.StatusUnprocessableEntity,
 		},
 		{
 			name: "異常系（存在しないchild_categoryのIDを指定している場合）",
@@ -822,7 +899,63 @@ func TestEditIllustration(t *testing.T) {
 			},
 			want:         model.Illustration{},
 			wantErr:      true,
-			expectedCode: http.StatusInternalServerError,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "異常系（他のイラストと同じファイル名を指定している場合）",
+			arg:  "14003",
+			prepare: func() (*bytes.Buffer, string) {
+				body := &bytes.Buffer{}
+				writer := multipart.NewWriter(body)
+				defer writer.Close()
+
+				// テキストフィールドを追加
+				_ = writer.WriteField("title", "test_image_title_14003_edited")
+				_ = writer.WriteField("filename", "test_image_original_filename_14002")
+
+				return body, writer.FormDataContentType()
+			},
+			want:         model.Illustration{},
+			wantErr:      true,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "異常系（If-Matchヘッダーがない場合）",
+			arg:  "14001",
+			prepare: func() (*bytes.Buffer, string) {
+				body := &bytes.Buffer{}
+				writer := multipart.NewWriter(body)
+				defer writer.Close()
+
+				_ = writer.WriteField("title", "test_image_title_14001_edited")
+				_ = writer.WriteField("filename", "test_image_original_filename_14001")
+
+				return body, writer.FormDataContentType()
+			},
+			wantErr:      true,
+			expectedCode: http.StatusPreconditionRequired,
+			ifMatch: func(current string) string {
+				return ""
+			},
+		},
+		{
+			name: "異常系（取得した後に他の操作で更新されている場合）",
+			arg:  "14001",
+			prepare: func() (*bytes.Buffer, string) {
+				body := &bytes.Buffer{}
+				writer := multipart.NewWriter(body)
+				defer writer.Close()
+
+				_ = writer.WriteField("title", "test_image_title_14001_edited")
+				_ = writer.WriteField("filename", "test_image_original_filename_14001")
+
+				return body, writer.FormDataContentType()
+			},
+			wantErr:      true,
+			expectedCode: http.StatusPreconditionFailed,
+			ifMatch: func(current string) string {
+				return staleETag
+			},
 		},
 	}
 	for _, tt := range tests {
@@ -831,6 +964,13 @@ func TestEditIllustration(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/illustrations/"+tt.arg, body)
 			req.Header.Set("Content-Type", contentType)
 			req.Header.Set("Authorization", "Bearer "+accessToken)
+			ifMatch := getETag(t, c, accessToken, "/api/v1/admin/illustrations/"+tt.arg)
+			if tt.ifMatch != nil {
+				ifMatch = tt.ifMatch(ifMatch)
+			}
+			if ifMatch != "" {
+				req.Header.Set("If-Match", ifMatch)
+			}
 
 			w := httptest.NewRecorder()
 			c.Server.Router.ServeHTTP(w, req)
@@ -839,7 +979,13 @@ func TestEditIllustration(t *testing.T) {
 
 			if tt.wantErr {
 				require.NotEmpty(t, w.Body.String())
+				if tt.expectedCode == http.StatusPreconditionFailed {
+					// 現在の状態とETagを返す
+					require.Contains(t, w.Body.String(), `"illustration"`)
+					require.NotEqual(t, staleETag, w.Header().Get("ETag"))
+				}
 			} else {
+				require.NotEmpty(t, w.Header().Get("ETag"))
 				var got struct {
 					Illustration model.Illustration `json:"illustration"`
 				}
@@ -855,6 +1001,140 @@ func TestEditIllustration(t *testing.T) {
 	}
 }
 
+func TestPatchIllustration(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	i := illustrationTest{}
+	c := i.setUp(t, config)
+	defer i.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, c)
+
+	tests := []struct {
+		name              string
+		arg               string
+		body              string
+		ifMatch           func(current string) string
+		wantTitle         string
+		wantCharacters    []int64
+		wantChildCategory []int64
+		wantErr           bool
+		expectedCode      int
+	}{
+		{
+			name:              "正常系（タイトルのみを変更した場合は紐づけを維持する）",
+			arg:               "14001",
+			body:              `{"title": "test_image_title_14001_patched"}`,
+			wantTitle:         "test_image_title_14001_patched",
+			wantCharacters:    []int64{14001},
+			wantChildCategory: []int64{14001},
+			expectedCode:      http.StatusOK,
+		},
+		{
+			name:              "正常系（キャラクターの追加と削除）",
+			arg:               "14001",
+			body:              `{"characters": {"add": [14002], "remove": [14001]}}`,
+			wantTitle:         "test_image_title_14001_patched",
+			wantCharacters:    []int64{14002},
+			wantChildCategory: []int64{14001},
+			expectedCode:      http.StatusOK,
+		},
+		{
+			name:              "正常系（親カテゴリが選択されていない子カテゴリを追加した場合は親カテゴリも紐づける）",
+			arg:               "14001",
+			body:              `{"child_categories": {"add": [14002]}}`,
+			wantTitle:         "test_image_title_14001_patched",
+			wantCharacters:    []int64{14002},
+			wantChildCategory: []int64{14001, 14002},
+			expectedCode:      http.StatusOK,
+		},
+		{
+			name:         "異常系（存在しないキャラクターを追加した場合）",
+			arg:          "14001",
+			body:         `{"characters": {"add": [999999]}}`,
+			wantErr:      true,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "異常系（タイトルが空文字の場合）",
+			arg:          "14001",
+			body:         `{"title": ""}`,
+			wantErr:      true,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系（JSONが不正な場合）",
+			arg:          "14001",
+			body:         `{"title": `,
+			wantErr:      true,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系（存在しないイラストのIDを指定した場合）",
+			arg:          "999999",
+			body:         `{"title": "test"}`,
+			wantErr:      true,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "異常系（取得した後に他の操作で更新されている場合）",
+			arg:  "14001",
+			body: `{"title": "test"}`,
+			ifMatch: func(current string) string {
+				return staleETag
+			},
+			wantErr:      true,
+			expectedCode: http.StatusPreconditionFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/admin/illustrations/"+tt.arg, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			ifMatch := getETag(t, c, accessToken, "/api/v1/admin/illustrations/"+tt.arg)
+			if tt.ifMatch != nil {
+				ifMatch = tt.ifMatch(ifMatch)
+			}
+			req.Header.Set("If-Match", ifMatch)
+
+			w := httptest.NewRecorder()
+			c.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.wantErr {
+				require.NotEmpty(t, w.Body.String())
+				return
+			}
+
+			var got struct {
+				Illustration model.Illustration `json:"illustration"`
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &got)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantTitle, got.Illustration.Image.Title)
+
+			characterIDs := []int64{}
+			for _, ch := range got.Illustration.Characters {
+				characterIDs = append(characterIDs, ch.Character.ID)
+			}
+			require.Equal(t, tt.wantCharacters, characterIDs)
+
+			childCategoryIDs := []int64{}
+			for _, ca := range got.Illustration.Categories {
+				for _, cc := range ca.ChildCategory {
+					childCategoryIDs = append(childCategoryIDs, cc.ID)
+				}
+			}
+			require.Equal(t, tt.wantChildCategory, childCategoryIDs)
+		})
+	}
+}
+
 func compareIllustrationsObjects(t *testing.T, got model.Illustration, want model.Illustration, ignoreFieldsMap map[string][]string) {
 	// イメージ比較
 	if d := cmp.Diff(got.Image, want.Image, cmpopts.IgnoreFields(got.Image, ignoreFieldsMap["Image"]...)); len(d) != 0 {
@@ -911,6 +1191,7 @@ func newTestServer(store *db.Store, config util.Config) (*app.AppContext, error)
 		RedisClient: rdb,
 		Logger:      logger,
 	}
+	s.SetServices(service.NewGCSStorageService(config))
 	router := gin.Default()
 	s.Router = router
 	api.SetAdminRouters(s)
@@ -929,6 +1210,7 @@ func newTestUserCreation(ctx *app.AppContext, name, pw, email string) (db.Operat
 		Name:           name,
 		HashedPassword: hashedPassword,
 		Email:          email,
+		Role:           string(token.RoleOwner),
 	}
 
 	user, err := ctx.Server.Store.CreateOperator(ctx, arg)
@@ -951,18 +1233,23 @@ func setAuthUser(t *testing.T, c *app.AppContext) string {
 	require.NoError(t, err)
 	accessToken, _, err := c.Server.TokenMaker.CreateToken(
 		user.Name,
+		token.Role(user.Role),
+		token.TokenTypeAccess,
 		c.Server.Config.AccessTokenDuration,
 	)
 	require.NoError(t, err)
 
 	refreshToken, refreshPayload, err := c.Server.TokenMaker.CreateToken(
 		user.Name,
+		token.Role(user.Role),
+		token.TokenTypeRefresh,
 		c.Server.Config.RefreshTokenDuration,
 	)
 	require.NoError(t, err)
 
 	_, err = c.Server.Store.CreateSession(c, db.CreateSessionParams{
 		ID:           refreshPayload.ID,
+		FamilyID:     refreshPayload.ID,
 		Name:         user.Name,
 		Email:        sql.NullString{String: user.Email, Valid: true},
 		RefreshToken: refreshToken,
@@ -975,6 +1262,25 @@ func setAuthUser(t *testing.T, c *app.AppContext) string {
 	return accessToken
 }
 
+// getETag は編集対象を取得し、If-Matchヘッダーに指定するETagを返す
+// 対象を取得できない場合は、どの更新日時とも一致しないETagを返す
+func getETag(t *testing.T, c *app.AppContext, accessToken, url string) string {
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	w := httptest.NewRecorder()
+	c.Server.Router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		return staleETag
+	}
+
+	require.NotEmpty(t, w.Header().Get("ETag"))
+	return w.Header().Get("ETag")
+}
+
+// staleETag は編集対象の取得後に他の操作で更新された場合を再現するためのETag
+var staleETag = etag.Format(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+
 func (i illustrationTest) setUp(t *testing.T, config util.Config) *app.AppContext {
 	store := createConn(config)
 
@@ -1057,6 +1363,11 @@ func (i illustrationTest) setUp(t *testing.T, config util.Config) *app.AppContex
 	if err != nil {
 		t.Fatalf("Failed to create server: %v", err)
 	}
+
+	// 直接投入したイラストを検索対象にする
+	if _, err := s.Server.IllustrationService.IndexMissingSearchDocuments(context.Background()); err != nil {
+		t.Fatalf("Failed to index search documents: %v", err)
+	}
 	return s
 }
 
@@ -0,0 +1,39 @@
+package admin
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+
+	"shin-monta-no-mori/internal/domains/service"
+)
+
+// openImageFile はmultipartで受け取った画像ファイルを開き、サービス層に渡せる形に変換する
+// ファイルが送信されていない場合はnilを返す
+func openImageFile(fh multipart.FileHeader) (*service.ImageFile, error) {
+	if fh.Filename == "" {
+		return nil, nil
+	}
+
+	file, err := fh.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	return &service.ImageFile{
+		Name:    fh.Filename,
+		Content: file,
+	}, nil
+}
+
+// closeImageFiles はopenImageFileで開いたファイルを閉じる
+func closeImageFiles(files ...*service.ImageFile) {
+	for _, f := range files {
+		if f == nil {
+			continue
+		}
+		if c, ok := f.Content.(io.Closer); ok {
+			c.Close()
+		}
+	}
+}
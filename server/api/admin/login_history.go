@@ -0,0 +1,91 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"shin-monta-no-mori/internal/app"
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/internal/domains/service"
+	"shin-monta-no-mori/pkg/lib/apperror"
+	"shin-monta-no-mori/pkg/lib/binder"
+)
+
+const (
+	// 期間が指定されなかった場合に取得する日数
+	defaultLoginHistoryDays = 30
+	// 1ページあたりのログイン履歴の件数
+	loginHistoryFetchLimit = 50
+)
+
+type (
+	listLoginHistoryRequest struct {
+		Page     int64  `form:"p"`
+		Operator string `form:"operator"`
+		Email    string `form:"email"`
+		Result   string `form:"result" binding:"omitempty,oneof=success failure"`
+		From     string `form:"from"`
+		To       string `form:"to"`
+	}
+
+	listLoginHistoryResponse struct {
+		LoginAttempts []db.LoginAttempt `json:"login_attempts"`
+		TotalPages    int64             `json:"total_pages"`
+		TotalCount    int64             `json:"total_count"`
+	}
+)
+
+// succeeded は結果の絞り込みを返す。指定されなかった場合はnilを返す
+func (req listLoginHistoryRequest) succeeded() *bool {
+	if req.Result == "" {
+		return nil
+	}
+	succeeded := req.Result == "success"
+	return &succeeded
+}
+
+// ListLoginHistory godoc
+// @Summary List login history
+// @Description Retrieves the login attempts to the admin, newest first, including failed ones with unregistered emails. Each attempt has the email, operator, result, failure reason (unknown_email, wrong_password), client IP and user agent. Defaults to the last 30 days (JST dates, both inclusive).
+// @Accept  json
+// @Produce  json
+// @Param   p         query  int     false  "Page number for pagination"
+// @Param   operator  query  string  false  "Name of the operator"
+// @Param   email     query  string  false  "Email used for the login"
+// @Param   result    query  string  false  "Result of the login (success, failure)"
+// @Param   from      query  string  false  "Start date (YYYY-MM-DD)"
+// @Param   to        query  string  false  "End date (YYYY-MM-DD)"
+// @Success 200 {object} listLoginHistoryResponse "A list of login attempts"
+// @Failure 400 {object} apperror.Response "Bad Request: The filters or the period are malformed"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to list the login history"
+// @Router /api/v1/admin/login-history [get]
+func ListLoginHistory(ctx *app.AppContext) {
+	var req listLoginHistoryRequest
+	if err := binder.BindQuery(ctx.Context, &req); err != nil {
+		return
+	}
+	from, to, err := parsePeriod(req.From, req.To, defaultLoginHistoryDays)
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+
+	attempts, totalCount, err := ctx.Server.AuthService.ListLoginAttempts(ctx, service.ListLoginAttemptsParams{
+		OperatorName: req.Operator,
+		Email:        req.Email,
+		Succeeded:    req.succeeded(),
+		From:         from,
+		To:           to,
+		Limit:        loginHistoryFetchLimit,
+		Offset:       int32(req.Page * loginHistoryFetchLimit),
+	})
+	if err != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListLoginAttempts : %w", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, listLoginHistoryResponse{
+		LoginAttempts: attempts,
+		TotalPages:    (totalCount + loginHistoryFetchLimit - 1) / loginHistoryFetchLimit,
+		TotalCount:    totalCount,
+	})
+}
@@ -0,0 +1,126 @@
+package admin_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/pkg/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListLoginHistory(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	a := authTest{}
+	ctx := a.setUp(t, config)
+	defer a.tearDown(t, config)
+
+	_, err = newTestUserCreation(ctx, "testuser", "testtest", "test@test.com")
+	require.NoError(t, err)
+
+	// 失敗したログインも記録する
+	attempts := []struct {
+		email        string
+		password     string
+		expectedCode int
+	}{
+		{email: "unknown@test.com", password: "testtest", expectedCode: http.StatusUnauthorized},
+		{email: "test@test.com", password: "wrongpassword", expectedCode: http.StatusUnauthorized},
+		{email: "test@test.com", password: "testtest", expectedCode: http.StatusOK},
+	}
+	var accessToken string
+	for _, at := range attempts {
+		body, err := json.Marshal(map[string]string{"email": at.email, "password": at.password})
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "login-test")
+		ctx.Server.Router.ServeHTTP(w, req)
+		require.Equal(t, at.expectedCode, w.Code)
+
+		if w.Code == http.StatusOK {
+			var got tokenResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			accessToken = got.AccessToken
+		}
+	}
+
+	tests := []struct {
+		name         string
+		query        string
+		want         []db.LoginAttempt
+		expectedCode int
+	}{
+		{
+			name:  "正常系",
+			query: "",
+			want: []db.LoginAttempt{
+				{Email: "test@test.com", OperatorName: "testuser", Succeeded: true},
+				{Email: "test@test.com", OperatorName: "testuser", FailureReason: "wrong_password"},
+				{Email: "unknown@test.com", FailureReason: "unknown_email"},
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:  "正常系（オペレーターと結果で絞り込む場合）",
+			query: "?operator=testuser&result=failure",
+			want: []db.LoginAttempt{
+				{Email: "test@test.com", OperatorName: "testuser", FailureReason: "wrong_password"},
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:  "正常系（メールアドレスで絞り込む場合）",
+			query: "?email=unknown@test.com",
+			want: []db.LoginAttempt{
+				{Email: "unknown@test.com", FailureReason: "unknown_email"},
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（結果が不正な場合）",
+			query:        "?result=unknown",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/login-history"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode != http.StatusOK {
+				require.NotEmpty(t, w.Body.String())
+				return
+			}
+
+			var got struct {
+				LoginAttempts []db.LoginAttempt `json:"login_attempts"`
+				TotalCount    int64             `json:"total_count"`
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &got)
+			require.NoError(t, err)
+			require.Len(t, got.LoginAttempts, len(tt.want))
+			require.Equal(t, int64(len(tt.want)), got.TotalCount)
+			for i, g := range got.LoginAttempts {
+				require.Equal(t, tt.want[i].Email, g.Email)
+				require.Equal(t, tt.want[i].OperatorName, g.OperatorName)
+				require.Equal(t, tt.want[i].Succeeded, g.Succeeded)
+				require.Equal(t, tt.want[i].FailureReason, g.FailureReason)
+				require.Equal(t, "login-test", g.UserAgent)
+				require.NotEmpty(t, g.ClientIp)
+			}
+		})
+	}
+}
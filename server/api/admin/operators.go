@@ -0,0 +1,257 @@
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"shin-monta-no-mori/internal/app"
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/internal/domains/service"
+	"shin-monta-no-mori/pkg/lib/apperror"
+	"shin-monta-no-mori/pkg/token"
+)
+
+type (
+	// operatorResponse は管理画面のオペレーター
+	// パスワードのハッシュは含めない
+	operatorResponse struct {
+		ID    int64      `json:"id"`
+		Name  string     `json:"name"`
+		Email string     `json:"email"`
+		Role  token.Role `json:"role"`
+		// 無効化されていない場合はnull
+		DisabledAt *time.Time `json:"disabled_at"`
+		CreatedAt  time.Time  `json:"created_at"`
+	}
+
+	listOperatorsResponse struct {
+		Operators []operatorResponse `json:"operators"`
+	}
+
+	inviteOperatorRequest struct {
+		Name  string `json:"name" binding:"required"`
+		Email string `json:"email" binding:"required,email"`
+		Role  string `json:"role" binding:"required,oneof=owner editor viewer"`
+	}
+
+	inviteOperatorResponse struct {
+		Operator operatorResponse `json:"operator"`
+		// 招待したオペレーターに伝える初期パスワード。このレスポンスでのみ返す
+		InitialPassword string `json:"initial_password"`
+		Message         string `json:"message"`
+	}
+
+	editOperatorRequest struct {
+		Email string `json:"email" binding:"required,email"`
+		Role  string `json:"role" binding:"required,oneof=owner editor viewer"`
+	}
+
+	operatorMessageResponse struct {
+		Operator operatorResponse `json:"operator"`
+		Message  string           `json:"message"`
+	}
+)
+
+func newOperatorResponse(operator db.Operator) operatorResponse {
+	rsp := operatorResponse{
+		ID:        operator.ID,
+		Name:      operator.Name,
+		Email:     operator.Email,
+		Role:      token.Role(operator.Role),
+		CreatedAt: operator.CreatedAt,
+	}
+	if operator.DisabledAt.Valid {
+		rsp.DisabledAt = &operator.DisabledAt.Time
+	}
+	return rsp
+}
+
+// ListOperators godoc
+// @Summary List operators
+// @Description Retrieves all operators of the admin panel including disabled ones. Only owners can access this endpoint.
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} listOperatorsResponse "A list of operators"
+// @Failure 403 {object} apperror.Response "Forbidden: The operator is not an owner"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to list the operators"
+// @Router /api/v1/admin/operators [get]
+func ListOperators(ctx *app.AppContext) {
+	operators, err := ctx.Server.OperatorService.List(ctx)
+	if err != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListOperators : %w", err))
+		return
+	}
+
+	rsp := listOperatorsResponse{
+		Operators: make([]operatorResponse, 0, len(operators)),
+	}
+	for _, o := range operators {
+		rsp.Operators = append(rsp.Operators, newOperatorResponse(o))
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// InviteOperator godoc
+// @Summary Invite an operator
+// @Description Registers an operator with the given role and returns a generated initial password. The initial password is not stored and is returned only in this response. Only owners can access this endpoint.
+// @Accept  json
+// @Produce  json
+// @Param   request  body  inviteOperatorRequest  true  "Name, email and role (owner, editor or viewer) of the operator"
+// @Success 200 {object} inviteOperatorResponse "Returns the invited operator and the initial password"
+// @Failure 400 {object} apperror.Response "Bad Request: Error in binding the request data"
+// @Failure 403 {object} apperror.Response "Forbidden: The operator is not an owner"
+// @Failure 409 {object} apperror.Response "Conflict: An operator with the same name or email already exists"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to invite the operator"
+// @Router /api/v1/admin/operators [post]
+func InviteOperator(ctx *app.AppContext) {
+	var req inviteOperatorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+
+	operator, initialPassword, err := ctx.Server.OperatorService.Invite(ctx, service.InviteOperatorParams{
+		Name:  req.Name,
+		Email: req.Email,
+		Role:  token.Role(req.Role),
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrOperatorAlreadyExists) {
+			ctx.Fail(apperror.Conflict, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to InviteOperator : %w", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, inviteOperatorResponse{
+		Operator:        newOperatorResponse(operator),
+		InitialPassword: initialPassword,
+		Message:         "operatorの招待に成功しました",
+	})
+}
+
+// EditOperator godoc
+// @Summary Edit an operator
+// @Description Edits the email and role of an operator. Changing the role revokes all sessions of the operator. The last active owner cannot be demoted. Only owners can access this endpoint.
+// @Accept  json
+// @Produce  json
+// @Param   id       path  int                  true  "ID of the operator to edit"
+// @Param   request  body  editOperatorRequest  true  "Email and role (owner, editor or viewer) of the operator"
+// @Success 200 {object} operatorMessageResponse "Returns the updated operator and a success message"
+// @Failure 400 {object} apperror.Response "Bad Request: Error in binding the request data"
+// @Failure 403 {object} apperror.Response "Forbidden: The operator is not an owner"
+// @Failure 404 {object} apperror.Response "Not Found: No operator found with the given ID"
+// @Failure 409 {object} apperror.Response "Conflict: The email is already used or the operator is the last active owner"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to update the operator"
+// @Router /api/v1/admin/operators/{id} [put]
+func EditOperator(ctx *app.AppContext) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
+		return
+	}
+	var req editOperatorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+
+	operator, err := ctx.Server.OperatorService.Edit(ctx, int64(id), service.EditOperatorParams{
+		Email: req.Email,
+		Role:  token.Role(req.Role),
+	})
+	if err != nil {
+		failOperator(ctx, "failed to EditOperator", err)
+		return
+	}
+
+	deleteSessionBlockedCache(ctx)
+
+	ctx.JSON(http.StatusOK, operatorMessageResponse{
+		Operator: newOperatorResponse(operator),
+		Message:  "operatorの編集に成功しました",
+	})
+}
+
+// DisableOperator godoc
+// @Summary Disable an operator
+// @Description Disables an operator and revokes all sessions of the operator. Disabled operators cannot log in. The last active owner cannot be disabled. Only owners can access this endpoint.
+// @Accept  json
+// @Produce  json
+// @Param   id  path  int  true  "ID of the operator to disable"
+// @Success 200 {object} operatorMessageResponse "Returns the disabled operator and a success message"
+// @Failure 400 {object} apperror.Response "Bad Request: Error in parsing the operator ID"
+// @Failure 403 {object} apperror.Response "Forbidden: The operator is not an owner"
+// @Failure 404 {object} apperror.Response "Not Found: No operator found with the given ID"
+// @Failure 409 {object} apperror.Response "Conflict: The operator is the last active owner"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to disable the operator"
+// @Router /api/v1/admin/operators/{id}/disable [post]
+func DisableOperator(ctx *app.AppContext) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
+		return
+	}
+
+	operator, err := ctx.Server.OperatorService.Disable(ctx, int64(id))
+	if err != nil {
+		failOperator(ctx, "failed to DisableOperator", err)
+		return
+	}
+
+	deleteSessionBlockedCache(ctx)
+
+	ctx.JSON(http.StatusOK, operatorMessageResponse{
+		Operator: newOperatorResponse(operator),
+		Message:  "operatorの無効化に成功しました",
+	})
+}
+
+// EnableOperator godoc
+// @Summary Enable an operator
+// @Description Enables a disabled operator so that the operator can log in again. Only owners can access this endpoint.
+// @Accept  json
+// @Produce  json
+// @Param   id  path  int  true  "ID of the operator to enable"
+// @Success 200 {object} operatorMessageResponse "Returns the enabled operator and a success message"
+// @Failure 400 {object} apperror.Response "Bad Request: Error in parsing the operator ID"
+// @Failure 403 {object} apperror.Response "Forbidden: The operator is not an owner"
+// @Failure 404 {object} apperror.Response "Not Found: No operator found with the given ID"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to enable the operator"
+// @Router /api/v1/admin/operators/{id}/enable [post]
+func EnableOperator(ctx *app.AppContext) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
+		return
+	}
+
+	operator, err := ctx.Server.OperatorService.Enable(ctx, int64(id))
+	if err != nil {
+		failOperator(ctx, "failed to EnableOperator", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, operatorMessageResponse{
+		Operator: newOperatorResponse(operator),
+		Message:  "operatorの有効化に成功しました",
+	})
+}
+
+// failOperator はオペレーターの更新に失敗した場合のエラーレスポンスを返す
+func failOperator(ctx *app.AppContext, msg string, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		ctx.Fail(apperror.NotFound, err)
+	case errors.Is(err, service.ErrOperatorAlreadyExists), errors.Is(err, service.ErrLastOwner):
+		ctx.Fail(apperror.Conflict, err)
+	default:
+		ctx.Fail(apperror.Internal, fmt.Errorf("%s : %w", msg, err))
+	}
+}
@@ -0,0 +1,290 @@
+package admin_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/pkg/lib/apperror"
+	"shin-monta-no-mori/pkg/token"
+	"shin-monta-no-mori/pkg/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type operatorsTest struct {
+	authTest
+}
+
+type invitedOperator struct {
+	Operator struct {
+		ID    int64      `json:"id"`
+		Email string     `json:"email"`
+		Role  token.Role `json:"role"`
+	} `json:"operator"`
+	InitialPassword string `json:"initial_password"`
+}
+
+func TestOperators(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	o := operatorsTest{}
+	ctx := o.setUp(t, config)
+	defer o.tearDown(t, config)
+
+	owner, err := newTestUserCreation(ctx, "testuser", "testtest", "test@test.com")
+	require.NoError(t, err)
+	ownerToken := login(t, ctx, "test@test.com", "testtest").AccessToken
+
+	editor := inviteOperator(t, ctx, ownerToken, "editor", "editor@test.com", token.RoleEditor)
+	viewer := inviteOperator(t, ctx, ownerToken, "viewer", "viewer@test.com", token.RoleViewer)
+	require.NotEmpty(t, editor.InitialPassword)
+	require.NotEqual(t, editor.InitialPassword, viewer.InitialPassword)
+	editorTokens := login(t, ctx, "editor@test.com", editor.InitialPassword)
+	viewerTokens := login(t, ctx, "viewer@test.com", viewer.InitialPassword)
+	editorToken := editorTokens.AccessToken
+	viewerToken := viewerTokens.AccessToken
+
+	// ケースは順に実行し、前のケースの変更を後続のケースで確認する
+	tests := []struct {
+		name         string
+		method       string
+		url          string
+		accessToken  string
+		body         map[string]string
+		expectedCode int
+		wantCode     apperror.Code
+	}{
+		{
+			name:         "正常系（ownerはオペレーターの一覧を取得できる）",
+			method:       http.MethodGet,
+			url:          "/api/v1/admin/operators",
+			accessToken:  ownerToken,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（editorはオペレーターを管理できない）",
+			method:       http.MethodGet,
+			url:          "/api/v1/admin/operators",
+			accessToken:  editorToken,
+			expectedCode: http.StatusForbidden,
+			wantCode:     apperror.Forbidden,
+		},
+		{
+			name:         "異常系（viewerはオペレーターを招待できない）",
+			method:       http.MethodPost,
+			url:          "/api/v1/admin/operators",
+			accessToken:  viewerToken,
+			body:         map[string]string{"name": "other", "email": "other@test.com", "role": "owner"},
+			expectedCode: http.StatusForbidden,
+			wantCode:     apperror.Forbidden,
+		},
+		{
+			name:         "正常系（viewerはコンテンツを閲覧できる）",
+			method:       http.MethodGet,
+			url:          "/api/v1/admin/synonyms/list",
+			accessToken:  viewerToken,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（viewerはコンテンツを更新できない）",
+			method:       http.MethodDelete,
+			url:          "/api/v1/admin/synonyms/1",
+			accessToken:  viewerToken,
+			expectedCode: http.StatusForbidden,
+			wantCode:     apperror.Forbidden,
+		},
+		{
+			name:         "異常系（editorは監査ログを閲覧できない）",
+			method:       http.MethodGet,
+			url:          "/api/v1/admin/audit-logs",
+			accessToken:  editorToken,
+			expectedCode: http.StatusForbidden,
+			wantCode:     apperror.Forbidden,
+		},
+		{
+			name:         "異常系（メールアドレスが登録済みの場合）",
+			method:       http.MethodPost,
+			url:          "/api/v1/admin/operators",
+			accessToken:  ownerToken,
+			body:         map[string]string{"name": "other", "email": "editor@test.com", "role": "editor"},
+			expectedCode: http.StatusConflict,
+			wantCode:     apperror.Conflict,
+		},
+		{
+			name:         "異常系（定義されていない権限の場合）",
+			method:       http.MethodPost,
+			url:          "/api/v1/admin/operators",
+			accessToken:  ownerToken,
+			body:         map[string]string{"name": "other", "email": "other@test.com", "role": "admin"},
+			expectedCode: http.StatusBadRequest,
+			wantCode:     apperror.BadRequest,
+		},
+		{
+			name:         "異常系（最後のownerの権限を変更する場合）",
+			method:       http.MethodPut,
+			url:          fmt.Sprintf("/api/v1/admin/operators/%d", owner.ID),
+			accessToken:  ownerToken,
+			body:         map[string]string{"email": "test@test.com", "role": "editor"},
+			expectedCode: http.StatusConflict,
+			wantCode:     apperror.Conflict,
+		},
+		{
+			name:         "異常系（最後のownerを無効化する場合）",
+			method:       http.MethodPost,
+			url:          fmt.Sprintf("/api/v1/admin/operators/%d/disable", owner.ID),
+			accessToken:  ownerToken,
+			expectedCode: http.StatusConflict,
+			wantCode:     apperror.Conflict,
+		},
+		{
+			name:         "異常系（存在しないオペレーターの場合）",
+			method:       http.MethodPost,
+			url:          "/api/v1/admin/operators/99999/disable",
+			accessToken:  ownerToken,
+			expectedCode: http.StatusNotFound,
+			wantCode:     apperror.NotFound,
+		},
+		{
+			name:         "正常系（オペレーターを無効化する）",
+			method:       http.MethodPost,
+			url:          fmt.Sprintf("/api/v1/admin/operators/%d/disable", editor.Operator.ID),
+			accessToken:  ownerToken,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（無効化されたオペレーターのアクセストークンの場合）",
+			method:       http.MethodGet,
+			url:          "/api/v1/admin/synonyms/list",
+			accessToken:  editorToken,
+			expectedCode: http.StatusUnauthorized,
+			wantCode:     apperror.Unauthorized,
+		},
+		{
+			name:         "異常系（無効化されたオペレーターのリフレッシュトークンをアクセストークンとして使用した場合）",
+			method:       http.MethodGet,
+			url:          "/api/v1/admin/synonyms/list",
+			accessToken:  editorTokens.RefreshToken,
+			expectedCode: http.StatusUnauthorized,
+			wantCode:     apperror.Unauthorized,
+		},
+		{
+			name:         "異常系（無効化されたオペレーターはログインできない）",
+			method:       http.MethodPost,
+			url:          "/api/v1/auth/login",
+			body:         map[string]string{"email": "editor@test.com", "password": editor.InitialPassword},
+			expectedCode: http.StatusUnauthorized,
+			wantCode:     apperror.InvalidCredentials,
+		},
+		{
+			name:         "正常系（オペレーターを有効に戻す）",
+			method:       http.MethodPost,
+			url:          fmt.Sprintf("/api/v1/admin/operators/%d/enable", editor.Operator.ID),
+			accessToken:  ownerToken,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（有効に戻したオペレーターはログインできる）",
+			method:       http.MethodPost,
+			url:          "/api/v1/auth/login",
+			body:         map[string]string{"email": "editor@test.com", "password": editor.InitialPassword},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（オペレーターの権限を変更する）",
+			method:       http.MethodPut,
+			url:          fmt.Sprintf("/api/v1/admin/operators/%d", viewer.Operator.ID),
+			accessToken:  ownerToken,
+			body:         map[string]string{"email": "viewer@test.com", "role": "owner"},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（権限を変更する前のアクセストークンの場合）",
+			method:       http.MethodGet,
+			url:          "/api/v1/admin/synonyms/list",
+			accessToken:  viewerToken,
+			expectedCode: http.StatusUnauthorized,
+			wantCode:     apperror.Unauthorized,
+		},
+		{
+			name:         "異常系（権限を変更する前のリフレッシュトークンをアクセストークンとして使用した場合）",
+			method:       http.MethodGet,
+			url:          "/api/v1/admin/synonyms/list",
+			accessToken:  viewerTokens.RefreshToken,
+			expectedCode: http.StatusUnauthorized,
+			wantCode:     apperror.Unauthorized,
+		},
+		{
+			name:         "正常系（他にownerがいる場合は権限を変更できる）",
+			method:       http.MethodPut,
+			url:          fmt.Sprintf("/api/v1/admin/operators/%d", owner.ID),
+			accessToken:  ownerToken,
+			body:         map[string]string{"email": "test@test.com", "role": "editor"},
+			expectedCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			if tt.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(tt.body))
+			}
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.url, &body)
+			req.Header.Set("Content-Type", "application/json")
+			if tt.accessToken != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.accessToken)
+			}
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusOK {
+				return
+			}
+
+			var got apperror.Response
+			err := json.Unmarshal(w.Body.Bytes(), &got)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCode, got.Error.Code)
+		})
+	}
+}
+
+// inviteOperator はオペレーターを招待し、初期パスワードを含むレスポンスを返す
+func inviteOperator(t *testing.T, c *app.AppContext, accessToken, name, email string, role token.Role) invitedOperator {
+	body, err := json.Marshal(map[string]string{"name": name, "email": email, "role": string(role)})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/operators", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	c.Server.Router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var got invitedOperator
+	err = json.Unmarshal(w.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Equal(t, email, got.Operator.Email)
+	require.Equal(t, role, got.Operator.Role)
+	return got
+}
+
+func (o operatorsTest) tearDown(t *testing.T, config util.Config) {
+	o.authTest.tearDown(t, config)
+
+	store := createConn(config)
+	if _, err := store.ExecQuery(context.Background(), "TRUNCATE TABLE audit_logs RESTART IDENTITY CASCADE;"); err != nil {
+		t.Fatalf("Failed to truncate table: %v", err)
+	}
+}
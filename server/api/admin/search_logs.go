@@ -0,0 +1,183 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/internal/domains/service"
+	"shin-monta-no-mori/pkg/lib/apperror"
+	"shin-monta-no-mori/pkg/lib/binder"
+	"shin-monta-no-mori/pkg/util"
+	"time"
+)
+
+const (
+	// 期間が指定されなかった場合に集計する日数
+	defaultSearchLogDays = 30
+	// 集計する検索語の件数
+	defaultSearchLogLimit = 20
+	maxSearchLogLimit     = 100
+)
+
+type searchLogsRequest struct {
+	From  string `form:"from"`
+	To    string `form:"to"`
+	Limit int    `form:"limit" binding:"omitempty,min=1"`
+	Query string `form:"q"`
+}
+
+// period は日本時間の日付で指定された期間を、開始日時と終了日時（終了日の翌日0時）に変換する
+// 期間が指定されなかった場合は、今日までの30日間とする
+func (req searchLogsRequest) period() (time.Time, time.Time, error) {
+	return parsePeriod(req.From, req.To, defaultSearchLogDays)
+}
+
+// parsePeriod は日本時間の日付で指定された期間を、開始日時と終了日時（終了日の翌日0時）に変換する
+// 期間が指定されなかった場合は、今日までのdefaultDays日間とする
+func parsePeriod(fromDate, toDate string, defaultDays int) (time.Time, time.Time, error) {
+	now := time.Now().In(util.JST)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, util.JST)
+	if toDate != "" {
+		t, err := util.ParseDate(toDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("failed to parse 'to' : %w", err)
+		}
+		to = t
+	}
+	to = to.AddDate(0, 0, 1)
+
+	from := to.AddDate(0, 0, -defaultDays)
+	if fromDate != "" {
+		f, err := util.ParseDate(fromDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("failed to parse 'from' : %w", err)
+		}
+		from = f
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("'from' must be on or before 'to'")
+	}
+
+	return from, to, nil
+}
+
+func (req searchLogsRequest) limit() int32 {
+	if req.Limit == 0 {
+		return defaultSearchLogLimit
+	}
+	if req.Limit > maxSearchLogLimit {
+		return maxSearchLogLimit
+	}
+	return int32(req.Limit)
+}
+
+type listSearchQueriesResponse struct {
+	Queries []service.SearchQueryStat `json:"queries"`
+}
+
+// ListTopSearchQueries godoc
+// @Summary List top search queries
+// @Description Retrieves the most frequent normalized search queries in the given period (JST dates, both inclusive). Defaults to the last 30 days.
+// @Accept  json
+// @Produce  json
+// @Param   from   query  string  false  "Start date (YYYY-MM-DD)"
+// @Param   to     query  string  false  "End date (YYYY-MM-DD)"
+// @Param   limit  query  int     false  "Number of queries (default 20, max 100)"
+// @Success 200 {object} listSearchQueriesResponse "A list of queries with their counts"
+// @Failure 400 {object} apperror.Response "Bad Request: The period is malformed"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to aggregate the search logs"
+// @Router /api/v1/admin/search-logs/top [get]
+func ListTopSearchQueries(ctx *app.AppContext) {
+	var req searchLogsRequest
+	if err := binder.BindQuery(ctx.Context, &req); err != nil {
+		return
+	}
+	from, to, err := req.period()
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+
+	queries, err := ctx.Server.SearchLogService.ListTopQueries(ctx, from, to, req.limit())
+	if err != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListTopSearchQueries : %w", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, listSearchQueriesResponse{
+		Queries: queries,
+	})
+}
+
+// ListZeroResultSearchQueries godoc
+// @Summary List zero-result search queries
+// @Description Retrieves normalized search queries that returned no illustrations in the given period (JST dates, both inclusive), ordered by frequency. Defaults to the last 30 days.
+// @Accept  json
+// @Produce  json
+// @Param   from   query  string  false  "Start date (YYYY-MM-DD)"
+// @Param   to     query  string  false  "End date (YYYY-MM-DD)"
+// @Param   limit  query  int     false  "Number of queries (default 20, max 100)"
+// @Success 200 {object} listSearchQueriesResponse "A list of queries with their counts"
+// @Failure 400 {object} apperror.Response "Bad Request: The period is malformed"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to aggregate the search logs"
+// @Router /api/v1/admin/search-logs/zero-results [get]
+func ListZeroResultSearchQueries(ctx *app.AppContext) {
+	var req searchLogsRequest
+	if err := binder.BindQuery(ctx.Context, &req); err != nil {
+		return
+	}
+	from, to, err := req.period()
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+
+	queries, err := ctx.Server.SearchLogService.ListZeroResultQueries(ctx, from, to, req.limit())
+	if err != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListZeroResultSearchQueries : %w", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, listSearchQueriesResponse{
+		Queries: queries,
+	})
+}
+
+type listSearchTrendsResponse struct {
+	Trends []service.SearchTrend `json:"trends"`
+}
+
+// ListSearchTrends godoc
+// @Summary List search trends
+// @Description Retrieves the number of searches and zero-result searches per JST day in the given period. When q is given, only searches for that query are counted. Defaults to the last 30 days.
+// @Accept  json
+// @Produce  json
+// @Param   from  query  string  false  "Start date (YYYY-MM-DD)"
+// @Param   to    query  string  false  "End date (YYYY-MM-DD)"
+// @Param   q     query  string  false  "Search query to count"
+// @Success 200 {object} listSearchTrendsResponse "Daily search counts"
+// @Failure 400 {object} apperror.Response "Bad Request: The period is malformed"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to aggregate the search logs"
+// @Router /api/v1/admin/search-logs/trends [get]
+func ListSearchTrends(ctx *app.AppContext) {
+	var req searchLogsRequest
+	if err := binder.BindQuery(ctx.Context, &req); err != nil {
+		return
+	}
+	from, to, err := req.period()
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+
+	trends, err := ctx.Server.SearchLogService.ListTrends(ctx, from, to, req.Query)
+	if err != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListSearchTrends : %w", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, listSearchTrendsResponse{
+		Trends: trends,
+	})
+}
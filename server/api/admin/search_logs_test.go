@@ -0,0 +1,223 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/internal/domains/service"
+	"shin-monta-no-mori/pkg/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type searchLogsTest struct{}
+
+func TestListTopSearchQueries(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	s := searchLogsTest{}
+	ctx := s.setUp(t, config)
+	defer s.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	tests := []struct {
+		name         string
+		query        string
+		want         []string
+		expectedCode int
+	}{
+		{
+			name:         "正常系",
+			query:        "from=2024-01-01&to=2024-01-02",
+			want:         []string{"ねこ", "いぬ", "うさぎ"},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（limitを指定した場合）",
+			query:        "from=2024-01-01&to=2024-01-02&limit=1",
+			want:         []string{"ねこ"},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（期間を1日に絞った場合）",
+			query:        "from=2024-01-02&to=2024-01-02",
+			want:         []string{"うさぎ"},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（日付の形式が不正な場合）",
+			query:        "from=2024/01/01",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系（fromがtoより後の場合）",
+			query:        "from=2024-01-03&to=2024-01-01",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/search-logs/top?"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedCode == http.StatusOK {
+				type wantType struct {
+					Queries []service.SearchQueryStat `json:"queries"`
+				}
+				var got wantType
+				err := json.Unmarshal(w.Body.Bytes(), &got)
+				require.NoError(t, err)
+
+				queries := []string{}
+				for _, q := range got.Queries {
+					queries = append(queries, q.Query)
+				}
+				require.Equal(t, tt.want, queries)
+			}
+		})
+	}
+}
+
+func TestListZeroResultSearchQueries(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	s := searchLogsTest{}
+	ctx := s.setUp(t, config)
+	defer s.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/search-logs/zero-results?from=2024-01-01&to=2024-01-02", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	ctx.Server.Router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	type wantType struct {
+		Queries []service.SearchQueryStat `json:"queries"`
+	}
+	var got wantType
+	err = json.Unmarshal(w.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Len(t, got.Queries, 1)
+	require.Equal(t, "うさぎ", got.Queries[0].Query)
+	require.Equal(t, int64(1), got.Queries[0].Count)
+}
+
+func TestListSearchTrends(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	s := searchLogsTest{}
+	ctx := s.setUp(t, config)
+	defer s.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	tests := []struct {
+		name  string
+		query string
+		want  []service.SearchTrend
+	}{
+		{
+			name:  "正常系",
+			query: "from=2024-01-01&to=2024-01-02",
+			want: []service.SearchTrend{
+				{Date: "2024-01-01", Count: 5, ZeroResultCount: 0},
+				{Date: "2024-01-02", Count: 1, ZeroResultCount: 1},
+			},
+		},
+		{
+			name:  "正常系（検索語を指定した場合）",
+			query: "from=2024-01-01&to=2024-01-02&q=ネコ",
+			want: []service.SearchTrend{
+				{Date: "2024-01-01", Count: 3, ZeroResultCount: 0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/search-logs/trends?"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+
+			type wantType struct {
+				Trends []service.SearchTrend `json:"trends"`
+			}
+			var got wantType
+			err := json.Unmarshal(w.Body.Bytes(), &got)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.Trends)
+		})
+	}
+}
+
+func (s searchLogsTest) setUp(t *testing.T, config util.Config) *app.AppContext {
+	store := createConn(config)
+
+	// searched_atは日本時間で2024-01-01、2024-01-02となるように指定する
+	queries := []string{
+		fmt.Sprintln(`
+		INSERT INTO search_logs (query, result_count, searched_at)
+		VALUES
+		('ねこ', 3, '2024-01-01 09:00:00+09'),
+		('ねこ', 3, '2024-01-01 12:00:00+09'),
+		('ねこ', 2, '2024-01-01 23:59:00+09'),
+		('いぬ', 1, '2024-01-01 10:00:00+09'),
+		('いぬ', 1, '2024-01-01 11:00:00+09'),
+		('うさぎ', 0, '2024-01-02 00:00:00+09'),
+		('ねこ', 3, '2024-01-03 00:00:00+09');
+		`),
+	}
+
+	for _, query := range queries {
+		if _, err := store.ExecQuery(context.Background(), query); err != nil {
+			t.Fatalf("Failed to exec query: %v", err)
+		}
+	}
+
+	server, err := newTestServer(store, config)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	return server
+}
+
+func (s searchLogsTest) tearDown(t *testing.T, config util.Config) {
+	store := createConn(config)
+
+	queries := []string{
+		"TRUNCATE TABLE search_logs RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE operators RESTART IDENTITY CASCADE;",
+	}
+	for _, query := range queries {
+		if _, err := store.ExecQuery(context.Background(), query); err != nil {
+			t.Fatalf("Failed to truncate table: %v", err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/internal/cache"
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/pkg/lib/apperror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type (
+	// sessionResponse はログイン中のセッション
+	// リフレッシュトークンは含めない
+	sessionResponse struct {
+		ID        uuid.UUID `json:"id"`
+		UserAgent string    `json:"user_agent"`
+		ClientIP  string    `json:"client_ip"`
+		// リクエストに使用したアクセストークンのセッションの場合はtrue
+		Current   bool      `json:"current"`
+		ExpiresAt time.Time `json:"expires_at"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	listSessionsResponse struct {
+		Sessions []sessionResponse `json:"sessions"`
+	}
+)
+
+func newSessionResponse(session db.Session, accessTokenID uuid.UUID) sessionResponse {
+	return sessionResponse{
+		ID:        session.ID,
+		UserAgent: session.UserAgent,
+		ClientIP:  session.ClientIp,
+		Current:   session.AccessTokenID.Valid && session.AccessTokenID.UUID == accessTokenID,
+		ExpiresAt: session.ExpiresAt,
+		CreatedAt: session.CreatedAt,
+	}
+}
+
+// ListSessions godoc
+// @Summary List the operator's sessions
+// @Description Retrieves the sessions of the logged-in operator that are not logged out or revoked, newest first. Each login is listed once with its latest session.
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} listSessionsResponse "A list of sessions"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to list the sessions"
+// @Router /api/v1/admin/sessions [get]
+func ListSessions(ctx *app.AppContext) {
+	payload := ctx.AuthPayload()
+
+	sessions, err := ctx.Server.AuthService.ListActiveSessions(ctx, payload.Username)
+	if err != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListActiveSessions : %w", err))
+		return
+	}
+
+	rsp := listSessionsResponse{
+		Sessions: make([]sessionResponse, 0, len(sessions)),
+	}
+	for _, s := range sessions {
+		rsp.Sessions = append(rsp.Sessions, newSessionResponse(s, payload.ID))
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Blocks the session of the logged-in operator with all sessions of the same login. The access token and refresh token of the session can no longer be used.
+// @Accept  json
+// @Produce  json
+// @Param   id   path   string  true  "ID of the session"
+// @Success 200 {object} gin/H "Returns a success message indicating the session has been revoked"
+// @Failure 400 {object} apperror.Response "Bad Request: Failed to parse 'id' from path parameter"
+// @Failure 404 {object} apperror.Response "Not Found: No session of the operator found with the given ID"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to revoke the session"
+// @Router /api/v1/admin/sessions/{id} [delete]
+func RevokeSession(ctx *app.AppContext) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' : %w", err))
+		return
+	}
+
+	operatorName := operatorName(ctx)
+	err = ctx.Server.AuthService.RevokeSession(ctx, operatorName, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to RevokeSession : %w", err))
+		return
+	}
+
+	deleteSessionBlockedCache(ctx)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "sessionの無効化に成功しました",
+	})
+}
+
+// deleteSessionBlockedCache はセッションを無効化した後に、アクセストークンの判定結果のキャッシュを削除する
+func deleteSessionBlockedCache(ctx *app.AppContext) {
+	keyPattern := []string{cache.SessionBlockedPrefix + "*"}
+	err := ctx.Server.RedisClient.Del(ctx, keyPattern)
+	if err != nil {
+		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
+	}
+}
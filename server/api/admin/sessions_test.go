@@ -0,0 +1,135 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"shin-monta-no-mori/pkg/util"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type sessionResponse struct {
+	ID      uuid.UUID `json:"id"`
+	Current bool      `json:"current"`
+}
+
+func TestListSessions(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	a := authTest{}
+	ctx := a.setUp(t, config)
+	defer a.tearDown(t, config)
+
+	_, err = newTestUserCreation(ctx, "testuser", "testtest", "test@test.com")
+	require.NoError(t, err)
+	login(t, ctx, "test@test.com", "testtest")
+	current := login(t, ctx, "test@test.com", "testtest")
+
+	got := listSessions(t, ctx.Server.Router, current.AccessToken)
+
+	// ログインごとに1件ずつ、新しい順に返す
+	require.Len(t, got, 2)
+	require.True(t, got[0].Current)
+	require.False(t, got[1].Current)
+}
+
+func TestRevokeSession(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	a := authTest{}
+	ctx := a.setUp(t, config)
+	defer a.tearDown(t, config)
+
+	_, err = newTestUserCreation(ctx, "testuser", "testtest", "test@test.com")
+	require.NoError(t, err)
+	_, err = newTestUserCreation(ctx, "otheruser", "testtest", "other@test.com")
+	require.NoError(t, err)
+	revoked := login(t, ctx, "test@test.com", "testtest")
+	current := login(t, ctx, "test@test.com", "testtest")
+	otherOperator := login(t, ctx, "other@test.com", "testtest")
+
+	// 無効化するセッションのIDは、そのセッションのアクセストークンで取得した一覧から特定する
+	sessions := listSessions(t, ctx.Server.Router, revoked.AccessToken)
+	require.Len(t, sessions, 2)
+	var revokedID uuid.UUID
+	for _, s := range sessions {
+		if s.Current {
+			revokedID = s.ID
+		}
+	}
+	otherSessions := listSessions(t, ctx.Server.Router, otherOperator.AccessToken)
+	require.Len(t, otherSessions, 1)
+
+	tests := []struct {
+		name         string
+		id           string
+		expectedCode int
+	}{
+		{
+			name:         "正常系",
+			id:           revokedID.String(),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（他のオペレーターのセッションの場合）",
+			id:           otherSessions[0].ID.String(),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "異常系（存在しないセッションの場合）",
+			id:           uuid.NewString(),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "異常系（IDが不正な場合）",
+			id:           "invalid",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodDelete, "/api/v1/admin/sessions/"+tt.id, nil)
+			req.Header.Set("Authorization", "Bearer "+current.AccessToken)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+			require.NotEmpty(t, w.Body.String())
+		})
+	}
+
+	// 無効化したセッションのアクセストークンは使用できない
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/sessions", nil)
+	req.Header.Set("Authorization", "Bearer "+revoked.AccessToken)
+	ctx.Server.Router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	require.Len(t, listSessions(t, ctx.Server.Router, current.AccessToken), 1)
+}
+
+// listSessions はアクセストークンのオペレーターのセッションを取得する
+func listSessions(t *testing.T, router http.Handler, accessToken string) []sessionResponse {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/sessions", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var got struct {
+		Sessions []sessionResponse `json:"sessions"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &got)
+	require.NoError(t, err)
+	return got.Sessions
+}
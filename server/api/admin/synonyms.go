@@ -0,0 +1,227 @@
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/internal/cache"
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/internal/domains/service"
+	"shin-monta-no-mori/pkg/lib/apperror"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type listAllSynonymsResponse struct {
+	Synonyms []db.Synonym `json:"synonyms"`
+}
+
+// ListAllSynonyms godoc
+// @Summary List all synonyms
+// @Description Retrieves all synonyms used to expand search queries, ordered by word.
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} listAllSynonymsResponse "Returns a list of synonyms"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to list the synonyms"
+// @Router /api/v1/admin/synonyms/list [get]
+func ListAllSynonyms(ctx *app.AppContext) {
+	synonyms, err := ctx.Server.SynonymService.ListAll(ctx)
+	if err != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListAllSynonyms : %w", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, listAllSynonymsResponse{
+		Synonyms: synonyms,
+	})
+}
+
+type getSynonymResponse struct {
+	Synonym db.Synonym `json:"synonym"`
+}
+
+// GetSynonym godoc
+// @Summary Get a synonym by ID
+// @Description Retrieves a specific synonym based on the provided ID.
+// @Accept  json
+// @Produce  json
+// @Param   id  path  int  true  "Synonym ID"
+// @Success 200 {object} getSynonymResponse "A synonym object"
+// @Failure 400 {object} apperror.Response "Bad Request: Error in parsing the synonym ID"
+// @Failure 404 {object} apperror.Response "Not Found: No synonym found with the given ID"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to retrieve the synonym"
+// @Router /api/v1/admin/synonyms/{id} [get]
+func GetSynonym(ctx *app.AppContext) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
+		return
+	}
+
+	synonym, err := ctx.Server.SynonymService.Get(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to GetSynonym : %w", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getSynonymResponse{
+		Synonym: synonym,
+	})
+}
+
+type synonymRequest struct {
+	Word    string `form:"word" binding:"required"`
+	Synonym string `form:"synonym" binding:"required"`
+}
+
+type createSynonymResponse struct {
+	Synonym db.Synonym `json:"synonym"`
+	Message string     `json:"message"`
+}
+
+// CreateSynonym godoc
+// @Summary Create a new synonym
+// @Description Registers a synonym that a search word is expanded to. Words are normalized when searching.
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param   word     formData  string  true  "Word entered in search queries"
+// @Param   synonym  formData  string  true  "Synonym the word is expanded to"
+// @Success 200 {object} createSynonymResponse "Returns the created synonym along with a success message"
+// @Failure 400 {object} apperror.Response "Bad Request: Error in data binding or missing required fields"
+// @Failure 409 {object} apperror.Response "Conflict: The synonym is already registered for the word"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to create the synonym"
+// @Router /api/v1/admin/synonyms/create [post]
+func CreateSynonym(ctx *app.AppContext) {
+	var req synonymRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+
+	synonym, err := ctx.Server.SynonymService.Create(ctx, service.SynonymParams{
+		Word:    req.Word,
+		Synonym: req.Synonym,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrSynonymAlreadyExists) {
+			ctx.Fail(apperror.Conflict, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to CreateSynonym : %w", err))
+		return
+	}
+
+	deleteSynonymsCache(ctx)
+
+	ctx.JSON(http.StatusOK, createSynonymResponse{
+		Synonym: synonym,
+		Message: "synonymの作成に成功しました",
+	})
+}
+
+type editSynonymResponse struct {
+	Synonym db.Synonym `json:"synonym"`
+	Message string     `json:"message"`
+}
+
+// EditSynonym godoc
+// @Summary Edit a synonym
+// @Description Edits an existing synonym identified by its ID.
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param   id       path      int     true  "ID of the synonym to edit"
+// @Param   word     formData  string  true  "Word entered in search queries"
+// @Param   synonym  formData  string  true  "Synonym the word is expanded to"
+// @Success 200 {object} editSynonymResponse "Returns the updated synonym and a success message"
+// @Failure 400 {object} apperror.Response "Bad Request: Error in binding the request data"
+// @Failure 404 {object} apperror.Response "Not Found: No synonym found with the given ID"
+// @Failure 409 {object} apperror.Response "Conflict: The synonym is already registered for the word"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to update the synonym"
+// @Router /api/v1/admin/synonyms/{id} [put]
+func EditSynonym(ctx *app.AppContext) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
+		return
+	}
+	var req synonymRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Fail(apperror.BadRequest, err)
+		return
+	}
+
+	synonym, err := ctx.Server.SynonymService.Edit(ctx, int64(id), service.SynonymParams{
+		Word:    req.Word,
+		Synonym: req.Synonym,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
+		}
+		if errors.Is(err, service.ErrSynonymAlreadyExists) {
+			ctx.Fail(apperror.Conflict, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to EditSynonym : %w", err))
+		return
+	}
+
+	deleteSynonymsCache(ctx)
+
+	ctx.JSON(http.StatusOK, editSynonymResponse{
+		Synonym: synonym,
+		Message: "synonymの編集に成功しました",
+	})
+}
+
+// DeleteSynonym godoc
+// @Summary Delete a synonym
+// @Description Deletes an existing synonym identified by its ID.
+// @Accept  json
+// @Produce  json
+// @Param   id  path  int  true  "ID of the synonym to delete"
+// @Success 200 {object} gin/H "Returns a success message indicating the synonym has been deleted"
+// @Failure 400 {object} apperror.Response "Bad Request: Error in parsing the synonym ID"
+// @Failure 404 {object} apperror.Response "Not Found: No synonym found with the given ID"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to delete the synonym"
+// @Router /api/v1/admin/synonyms/{id} [delete]
+func DeleteSynonym(ctx *app.AppContext) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
+		return
+	}
+
+	err = ctx.Server.SynonymService.Delete(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to DeleteSynonym : %w", err))
+		return
+	}
+
+	deleteSynonymsCache(ctx)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "synonymの削除に成功しました",
+	})
+}
+
+// deleteSynonymsCache は同義語を更新した後に、検索語の展開に使用する同義語辞書のキャッシュを削除する
+func deleteSynonymsCache(ctx *app.AppContext) {
+	err := ctx.Server.RedisClient.DelKeys(ctx, []string{cache.SynonymsKey})
+	if err != nil {
+		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
+	}
+}
@@ -0,0 +1,246 @@
+package admin_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/internal/cache"
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/pkg/util"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+)
+
+type synonymsTest struct{}
+
+func TestListAllSynonyms(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	s := synonymsTest{}
+	ctx := s.setUp(t, config)
+	defer s.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/synonyms/list", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	ctx.Server.Router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	type wantType struct {
+		Synonyms []db.Synonym `json:"synonyms"`
+	}
+	var got wantType
+	err = json.Unmarshal(w.Body.Bytes(), &got)
+	require.NoError(t, err)
+
+	want := []db.Synonym{
+		{ID: 31002, Word: "いぬ", Synonym: "犬"},
+		{ID: 31001, Word: "ねこ", Synonym: "猫"},
+	}
+	require.Len(t, got.Synonyms, len(want))
+	for i, g := range got.Synonyms {
+		compareSynonymObjects(t, g, want[i])
+	}
+}
+
+func TestCreateSynonym(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	s := synonymsTest{}
+	ctx := s.setUp(t, config)
+	defer s.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	type args struct {
+		word    string
+		synonym string
+	}
+	tests := []struct {
+		name         string
+		arg          args
+		want         db.Synonym
+		wantErr      bool
+		expectedCode int
+	}{
+		{
+			name: "正常系",
+			arg: args{
+				word:    "ねこ",
+				synonym: "キャット",
+			},
+			want: db.Synonym{
+				Word:    "ねこ",
+				Synonym: "キャット",
+			},
+			wantErr:      false,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "異常系（同じ組み合わせが登録済みの場合）",
+			arg: args{
+				word:    "ねこ",
+				synonym: "猫",
+			},
+			wantErr:      true,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name: "異常系（synonymが空の場合）",
+			arg: args{
+				word:    "ねこ",
+				synonym: "",
+			},
+			wantErr:      true,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := new(bytes.Buffer)
+			writer := multipart.NewWriter(body)
+			require.NoError(t, writer.WriteField("word", tt.arg.word))
+			require.NoError(t, writer.WriteField("synonym", tt.arg.synonym))
+			require.NoError(t, writer.Close())
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/synonyms/create", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.wantErr {
+				require.NotEmpty(t, w.Body.String())
+			} else {
+				type wantType struct {
+					Synonym db.Synonym `json:"synonym"`
+				}
+				var got wantType
+				err := json.Unmarshal(w.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.NotZero(t, got.Synonym.ID)
+				tt.want.ID = got.Synonym.ID
+				compareSynonymObjects(t, got.Synonym, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteSynonym(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	s := synonymsTest{}
+	ctx := s.setUp(t, config)
+	defer s.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	tests := []struct {
+		name         string
+		id           string
+		expectedCode int
+	}{
+		{
+			name:         "正常系",
+			id:           "31001",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（idの値が不正な場合）",
+			id:           "aaa",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系（存在しないsynonymを削除しようとした場合）",
+			id:           "999999",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodDelete, "/api/v1/admin/synonyms/"+tt.id, nil)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func compareSynonymObjects(t *testing.T, got db.Synonym, want db.Synonym) {
+	if d := cmp.Diff(got, want, cmpopts.IgnoreFields(got, "CreatedAt", "UpdatedAt")); len(d) != 0 {
+		t.Errorf("differs: (-got +want)\n%s", d)
+	}
+}
+
+func (s synonymsTest) setUp(t *testing.T, config util.Config) *app.AppContext {
+	store := createConn(config)
+
+	queries := []string{
+		fmt.Sprintln(`
+		INSERT INTO synonyms (id, word, synonym)
+		VALUES
+		(31001, 'ねこ', '猫'),
+		(31002, 'いぬ', '犬');
+		`),
+	}
+
+	for _, query := range queries {
+		if _, err := store.ExecQuery(context.Background(), query); err != nil {
+			t.Fatalf("Failed to exec query: %v", err)
+		}
+	}
+
+	server, err := newTestServer(store, config)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	return server
+}
+
+func (s synonymsTest) tearDown(t *testing.T, config util.Config) {
+	store := createConn(config)
+
+	queries := []string{
+		"TRUNCATE TABLE synonyms RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE operators RESTART IDENTITY CASCADE;",
+	}
+	for _, query := range queries {
+		if _, err := store.ExecQuery(context.Background(), query); err != nil {
+			t.Fatalf("Failed to truncate table: %v", err)
+		}
+	}
+
+	// 同義語辞書のキャッシュを消去
+	rdb := cache.NewRedisClient(config)
+	if err := rdb.DelKeys(context.Background(), []string{cache.SynonymsKey}); err != nil {
+		t.Fatalf("Failed to delete redis data: %v", err)
+	}
+}
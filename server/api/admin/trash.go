@@ -0,0 +1,135 @@
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/internal/cache"
+	"shin-monta-no-mori/internal/domains/service"
+	"shin-monta-no-mori/pkg/lib/apperror"
+	"shin-monta-no-mori/pkg/lib/binder"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type listTrashRequest struct {
+	Page int64 `form:"p"`
+}
+
+type listTrashResponse struct {
+	Items      []service.TrashItem `json:"items"`
+	TotalPages int64               `json:"total_pages"`
+	TotalCount int64               `json:"total_count"`
+}
+
+// ListTrash godoc
+// @Summary List items in the trash
+// @Description Retrieves a paginated list of illustrations, characters or parent categories moved to the trash, most recently deleted first. Each item has the time it will be permanently deleted.
+// @Accept  json
+// @Produce  json
+// @Param   type  path   string  true   "Type of the trash (illustrations, characters, categories)"
+// @Param   p     query  int     false  "Page number for pagination"
+// @Success 200 {object} listTrashResponse "A list of items in the trash"
+// @Failure 400 {object} apperror.Response "Bad Request: Unknown trash type or error in binding query parameters"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to list the trash"
+// @Router /api/v1/admin/trash/{type} [get]
+func ListTrash(ctx *app.AppContext) {
+	trashType := ctx.Param("type")
+	var req listTrashRequest
+	if err := binder.BindQuery(ctx.Context, &req); err != nil {
+		return
+	}
+
+	limit := trashFetchLimit(ctx, trashType)
+	items, totalCount, err := ctx.Server.TrashService.List(
+		ctx,
+		trashType,
+		int32(limit),
+		int32(int(req.Page)*limit),
+		service.TrashRetention(ctx.Server.Config.TrashRetentionDays),
+	)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownTrashType) {
+			ctx.Fail(apperror.BadRequest, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListTrash : %w", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, listTrashResponse{
+		Items:      items,
+		TotalPages: (totalCount + int64(limit-1)) / int64(limit),
+		TotalCount: totalCount,
+	})
+}
+
+// RestoreTrash godoc
+// @Summary Restore an item from the trash
+// @Description Restores an illustration, character or parent category from the trash along with its relations to illustrations. Child categories moved to the trash with the parent category are restored together.
+// @Accept  json
+// @Produce  json
+// @Param   type  path  string  true  "Type of the trash (illustrations, characters, categories)"
+// @Param   id    path  int     true  "ID of the item to restore"
+// @Success 200 {object} gin/H "Returns a success message"
+// @Failure 400 {object} apperror.Response "Bad Request: Unknown trash type or error in parsing the ID"
+// @Failure 404 {object} apperror.Response "Not Found: No item in the trash with the given ID"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to restore the item"
+// @Router /api/v1/admin/trash/{type}/{id}/restore [post]
+func RestoreTrash(ctx *app.AppContext) {
+	trashType := ctx.Param("type")
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
+		return
+	}
+
+	err = ctx.Server.TrashService.Restore(ctx, trashType, int64(id))
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownTrashType) {
+			ctx.Fail(apperror.BadRequest, err)
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to RestoreTrash : %w", err))
+		return
+	}
+
+	// redisキャッシュの削除
+	keyPattern := []string{cache.RelatedIllustrationsPrefix + "*", cache.SuggestionsPrefix + "*"}
+	switch trashType {
+	case service.TRASH_TYPE_ILLUSTRATIONS:
+		keyPattern = append(keyPattern, cache.IllustrationsPrefix+"*", cache.GetIllustrationKey(id))
+	case service.TRASH_TYPE_CHARACTERS:
+		keyPattern = append(keyPattern, cache.CharactersPrefix+"*")
+	case service.TRASH_TYPE_CATEGORIES:
+		keyPattern = append(keyPattern, cache.CategoriesPrefix+"*")
+	}
+	err = ctx.Server.RedisClient.Del(ctx, keyPattern)
+	if err != nil {
+		ctx.Server.Logger.Warn("failed redis data delete", zap.Error(err))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "ゴミ箱からの復元に成功しました",
+	})
+}
+
+// trashFetchLimit はゴミ箱の種類ごとに、通常の一覧と同じ取得件数を返す
+func trashFetchLimit(ctx *app.AppContext, trashType string) int {
+	switch trashType {
+	case service.TRASH_TYPE_CHARACTERS:
+		return ctx.Server.Config.CharacterFetchLimit
+	case service.TRASH_TYPE_CATEGORIES:
+		return ctx.Server.Config.CategoryFetchLimit
+	default:
+		return ctx.Server.Config.ImageFetchLimit
+	}
+}
@@ -0,0 +1,263 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/pkg/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type trashTest struct{}
+
+func TestListTrash(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	tr := trashTest{}
+	ctx := tr.setUp(t, config)
+	defer tr.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	tests := []struct {
+		name         string
+		trashType    string
+		want         []int64
+		expectedCode int
+	}{
+		{
+			name:      "正常系（イラスト）",
+			trashType: "illustrations",
+			// 削除日時の新しい順に並ぶ
+			want:         []int64{61003, 61002},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（キャラクター）",
+			trashType:    "characters",
+			want:         []int64{61002},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（カテゴリ）",
+			trashType:    "categories",
+			want:         []int64{61002},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（存在しない種類の場合）",
+			trashType:    "unknown",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/trash/"+tt.trashType, nil)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.want != nil {
+				var res struct {
+					Items []struct {
+						ID int64 `json:"id"`
+					} `json:"items"`
+					TotalCount int64 `json:"total_count"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+				got := make([]int64, 0, len(res.Items))
+				for _, item := range res.Items {
+					got = append(got, item.ID)
+				}
+				require.Equal(t, tt.want, got)
+				require.Equal(t, int64(len(tt.want)), res.TotalCount)
+			}
+		})
+	}
+}
+
+func TestRestoreTrash(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	tr := trashTest{}
+	ctx := tr.setUp(t, config)
+	defer tr.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	tests := []struct {
+		name         string
+		trashType    string
+		id           string
+		expectedCode int
+	}{
+		{
+			name:         "正常系（イラスト）",
+			trashType:    "illustrations",
+			id:           "61002",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（キャラクター）",
+			trashType:    "characters",
+			id:           "61002",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（カテゴリ）",
+			trashType:    "categories",
+			id:           "61002",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（ゴミ箱にないイラストの場合）",
+			trashType:    "illustrations",
+			id:           "61001",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "異常系（存在しない種類の場合）",
+			trashType:    "unknown",
+			id:           "61002",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系（idの値が不正な場合）",
+			trashType:    "illustrations",
+			id:           "aaa",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/trash/"+tt.trashType+"/"+tt.id+"/restore", nil)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+
+			ctx.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+
+	// 親カテゴリと一緒に削除された子カテゴリも復元される
+	child, err := ctx.Server.Store.GetChildCategory(context.Background(), 61002)
+	require.NoError(t, err)
+	require.Equal(t, int64(61002), child.ParentID)
+}
+
+func TestDeleteAndRestoreIllustration(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	tr := trashTest{}
+	ctx := tr.setUp(t, config)
+	defer tr.tearDown(t, config)
+
+	// 認証用トークンの生成
+	accessToken := setAuthUser(t, ctx)
+
+	serve := func(method, path string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		ctx.Server.Router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusOK, serve(http.MethodDelete, "/api/v1/admin/illustrations/61001"))
+	// ゴミ箱に移動したイラストは取得できず、再度削除することもできない
+	require.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/api/v1/admin/illustrations/61001"))
+	require.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/api/v1/admin/illustrations/61001"))
+
+	require.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/v1/admin/trash/illustrations/61001/restore"))
+	require.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/v1/admin/illustrations/61001"))
+
+	// キャラクターとの紐づけも元に戻る
+	relations, err := ctx.Server.Store.ListImageCharacterRelationsByImageID(context.Background(), 61001)
+	require.NoError(t, err)
+	require.Len(t, relations, 1)
+}
+
+func (tr trashTest) setUp(t *testing.T, config util.Config) *app.AppContext {
+	store := createConn(config)
+
+	queries := []string{
+		fmt.Sprintln(`
+		INSERT INTO images (id, title, original_src, simple_src, original_filename, deleted_at)
+		VALUES
+		(61001, 'test_image_title_61001', 'test_image_original_src_61001.com', 'test_image_simple_src_61001.com', 'test_image_original_filename_61001', NULL),
+		(61002, 'test_image_title_61002', 'test_image_original_src_61002.com', 'test_image_simple_src_61002.com', 'test_image_original_filename_61002', now() - interval '2 days'),
+		(61003, 'test_image_title_61003', 'test_image_original_src_61003.com', 'test_image_simple_src_61003.com', 'test_image_original_filename_61003', now() - interval '1 day');
+		`),
+		fmt.Sprintln(`
+		INSERT INTO characters (id, name, src, deleted_at)
+		VALUES
+		(61001, 'test_character_name_61001', 'test_character_src_61001.com', NULL),
+		(61002, 'test_character_name_61002', 'test_character_src_61002.com', now() - interval '1 day');
+		`),
+		fmt.Sprintln(`
+		INSERT INTO image_characters_relations (id, image_id, character_id)
+		VALUES
+		(61001, 61001, 61001);
+		`),
+		fmt.Sprintln(`
+		INSERT INTO parent_categories (id, name, src, deleted_at)
+		VALUES
+		(61001, 'test_parent_category_name_61001', 'test_parent_category_src_61001.com', NULL),
+		(61002, 'test_parent_category_name_61002', 'test_parent_category_src_61002.com', '2024-01-01 00:00:00+00');
+		`),
+		fmt.Sprintln(`
+		INSERT INTO child_categories (id, name, parent_id, deleted_at)
+		VALUES
+		(61001, 'test_child_category_name_61001', 61001, NULL),
+		(61002, 'test_child_category_name_61002', 61002, '2024-01-01 00:00:00+00');
+		`),
+	}
+
+	for _, query := range queries {
+		if _, err := store.ExecQuery(context.Background(), query); err != nil {
+			t.Fatalf("Failed to exec query: %v", err)
+		}
+	}
+
+	s, err := newTestServer(store, config)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	return s
+}
+
+func (tr trashTest) tearDown(t *testing.T, config util.Config) {
+	store := createConn(config)
+
+	queries := []string{
+		"TRUNCATE TABLE image_characters_relations RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE child_categories RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE parent_categories RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE characters RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE images RESTART IDENTITY CASCADE;",
+		"TRUNCATE TABLE operators RESTART IDENTITY CASCADE;",
+	}
+	for _, query := range queries {
+		if _, err := store.ExecQuery(context.Background(), query); err != nil {
+			t.Fatalf("Failed to truncate table: %v", err)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package admin
+
+import (
+	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/internal/domains/service"
+	"shin-monta-no-mori/pkg/lib/apperror"
+)
+
+// failValidation は項目ごとのエラーを含めて422を返す
+// クライアントが該当する入力欄にエラーを表示できるようにする
+func failValidation(ctx *app.AppContext, err *service.ValidationError) {
+	details := make([]apperror.Detail, 0, len(err.Errors))
+	for _, fe := range err.Errors {
+		details = append(details, apperror.Detail{
+			Field:  fe.Field,
+			Reason: fe.Reason,
+			IDs:    fe.IDs,
+		})
+	}
+	ctx.Fail(apperror.ValidationFailed, err, details...)
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/internal/cache"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// SessionDenylist はアクセストークンのセッションが無効化されているかを判定する
+type SessionDenylist interface {
+	IsBlocked(ctx context.Context, accessTokenID uuid.UUID) (bool, error)
+}
+
+// sessionDenylist はsessions.is_blockedの判定結果をRedisにキャッシュし、リクエストごとのDBへの問い合わせを減らす
+// セッションを無効化した際は、cache.SessionBlockedPrefixのキャッシュを削除する
+type sessionDenylist struct {
+	server *app.Server
+}
+
+func NewSessionDenylist(s *app.Server) SessionDenylist {
+	return &sessionDenylist{
+		server: s,
+	}
+}
+
+func (d *sessionDenylist) IsBlocked(ctx context.Context, accessTokenID uuid.UUID) (bool, error) {
+	cacheKey := cache.GetSessionBlockedKey(accessTokenID.String())
+
+	var blocked bool
+	err := d.server.RedisClient.Get(ctx, cacheKey, &blocked)
+	if err == nil {
+		return blocked, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		d.server.Logger.Info("failed to redis err", zap.String("redis_key", cacheKey), zap.Error(err))
+	}
+
+	blocked, err = d.server.AuthService.IsAccessTokenBlocked(ctx, accessTokenID)
+	if err != nil {
+		return false, err
+	}
+
+	err = d.server.RedisClient.Set(ctx, cacheKey, blocked, cache.CacheDurationHour)
+	if err != nil {
+		d.server.Logger.Warn("failed redis data set", zap.String("redis_key", cacheKey), zap.Error(err))
+	}
+
+	return blocked, nil
+}
@@ -3,8 +3,9 @@ package middleware
 import (
 	"errors"
 	"fmt"
-	"net/http"
 	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/internal/domains/service"
+	"shin-monta-no-mori/pkg/lib/apperror"
 	"shin-monta-no-mori/pkg/token"
 	"strings"
 
@@ -14,10 +15,10 @@ import (
 const (
 	authorizationHeaderKey  = "authorization"
 	authorizationTypeBearer = "bearer"
-	authorizationPayloadKey = "authorization_payload"
 )
 
-func AuthMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+// AuthMiddleware はアクセストークンを検証し、無効化されたセッションのアクセストークンを拒否する
+func AuthMiddleware(tokenMaker token.Maker, denylist SessionDenylist) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		// 認証ヘッダーを取得
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
@@ -25,7 +26,7 @@ func AuthMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 		// ヘッダーが存在しない場合、エラーを返す
 		if len(authorizationHeader) == 0 {
 			err := errors.New("authorization header is not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, app.ErrorResponse(err))
+			app.AbortWithError(ctx, apperror.Unauthorized, err)
 			return
 		}
 
@@ -33,7 +34,7 @@ func AuthMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 		fields := strings.Fields(authorizationHeader)
 		if len(fields) < 2 {
 			err := errors.New("invalid authorization header format")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, app.ErrorResponse(err))
+			app.AbortWithError(ctx, apperror.Unauthorized, err)
 			return
 		}
 
@@ -41,7 +42,7 @@ func AuthMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 		authorizationType := strings.ToLower(fields[0])
 		if authorizationType != authorizationTypeBearer {
 			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, app.ErrorResponse(err))
+			app.AbortWithError(ctx, apperror.Unauthorized, err)
 			return
 		}
 
@@ -49,12 +50,34 @@ func AuthMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 		accessToken := fields[1]
 		payload, err := tokenMaker.VerifyToken(accessToken)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, app.ErrorResponse(err))
+			app.AbortWithError(ctx, apperror.Unauthorized, err)
+			return
+		}
+		// リフレッシュトークンはセッションの無効化をアクセストークンのIDで確認できないため拒否
+		if err := payload.Expect(token.TokenTypeAccess); err != nil {
+			app.AbortWithError(ctx, apperror.Unauthorized, err)
+			return
+		}
+
+		// ログアウトなどで無効化されたセッションのトークンを拒否
+		blocked, err := denylist.IsBlocked(ctx, payload.ID)
+		if err != nil {
+			app.AbortWithError(ctx, apperror.Internal, err)
+			return
+		}
+		if blocked {
+			app.AbortWithError(ctx, apperror.Unauthorized, service.ErrSessionBlocked)
 			return
 		}
 
 		// トークンのペイロードをコンテキストに保存して、次のハンドラに進む
-		ctx.Set(authorizationPayloadKey, payload)
+		ctx.Set(app.AuthorizationPayloadKey, payload)
+		// 更新処理で監査ログに記録する操作者の情報を保存する
+		ctx.Set(service.AuditActorKey, service.AuditActor{
+			OperatorName: payload.Username,
+			ClientIP:     ctx.ClientIP(),
+			UserAgent:    ctx.Request.UserAgent(),
+		})
 		ctx.Next()
 	}
 }
@@ -1,6 +1,7 @@
 package middleware_test
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"net/http/httptest"
@@ -12,6 +13,7 @@ import (
 	"shin-monta-no-mori/pkg/util"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -21,6 +23,15 @@ const (
 	authorizationPayloadKey = "authorization_payload"
 )
 
+// stubDenylist はセッションの無効化の判定結果を固定で返す
+type stubDenylist struct {
+	blocked bool
+}
+
+func (d stubDenylist) IsBlocked(ctx context.Context, accessTokenID uuid.UUID) (bool, error) {
+	return d.blocked, nil
+}
+
 func TestAuthMiddleware(t *testing.T) {
 	config, err := util.LoadConfig("../../")
 	if err != nil {
@@ -33,13 +44,14 @@ func TestAuthMiddleware(t *testing.T) {
 	// テストケースを定義
 	testCases := []struct {
 		name          string
+		blocked       bool
 		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "正常系",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				accessToken, _, err := tokenMaker.CreateToken("testuser", time.Minute)
+				accessToken, _, err := tokenMaker.CreateToken("testuser", token.RoleOwner, token.TokenTypeAccess, time.Minute)
 				require.NoError(t, err)
 				request.Header.Set(authorizationHeaderKey, authorizationTypeBearer+" "+accessToken)
 			},
@@ -68,7 +80,7 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "異常系（UnsupportedAuthorizationType）",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				accessToken, _, err := tokenMaker.CreateToken("testuser", time.Minute)
+				accessToken, _, err := tokenMaker.CreateToken("testuser", token.RoleOwner, token.TokenTypeAccess, time.Minute)
 				require.NoError(t, err)
 				request.Header.Set(authorizationHeaderKey, "basic "+accessToken)
 			},
@@ -79,7 +91,30 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "異常系（ExpiredToken）",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				accessToken, _, err := tokenMaker.CreateToken("testuser", -time.Minute)
+				accessToken, _, err := tokenMaker.CreateToken("testuser", token.RoleOwner, token.TokenTypeAccess, -time.Minute)
+				require.NoError(t, err)
+				request.Header.Set(authorizationHeaderKey, authorizationTypeBearer+" "+accessToken)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "異常系（RefreshToken）",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				refreshToken, _, err := tokenMaker.CreateToken("testuser", token.RoleOwner, token.TokenTypeRefresh, time.Minute)
+				require.NoError(t, err)
+				request.Header.Set(authorizationHeaderKey, authorizationTypeBearer+" "+refreshToken)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:    "異常系（BlockedSession）",
+			blocked: true,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				accessToken, _, err := tokenMaker.CreateToken("testuser", token.RoleOwner, token.TokenTypeAccess, time.Minute)
 				require.NoError(t, err)
 				request.Header.Set(authorizationHeaderKey, authorizationTypeBearer+" "+accessToken)
 			},
@@ -92,7 +127,7 @@ func TestAuthMiddleware(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			router := gin.New()
-			router.Use(middleware.AuthMiddleware(tokenMaker))
+			router.Use(middleware.AuthMiddleware(tokenMaker, stubDenylist{blocked: tc.blocked}))
 			router.GET("/api/v1/admin/illustrations/list", func(ctx *gin.Context) {
 				ctx.JSON(http.StatusOK, gin.H{"status": "success"})
 			})
@@ -113,3 +148,89 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestRoleMiddleware(t *testing.T) {
+	config, err := util.LoadConfig("../../")
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name         string
+		role         token.Role
+		method       string
+		path         string
+		expectedCode int
+	}{
+		{
+			name:         "正常系（ownerはオペレーターを管理できる）",
+			role:         token.RoleOwner,
+			method:       http.MethodPost,
+			path:         "/operators",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（editorはオペレーターを管理できない）",
+			role:         token.RoleEditor,
+			method:       http.MethodGet,
+			path:         "/operators",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "正常系（editorはコンテンツを更新できる）",
+			role:         token.RoleEditor,
+			method:       http.MethodPut,
+			path:         "/illustrations",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（viewerはコンテンツを閲覧できる）",
+			role:         token.RoleViewer,
+			method:       http.MethodGet,
+			path:         "/illustrations",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（viewerはコンテンツを更新できない）",
+			role:         token.RoleViewer,
+			method:       http.MethodPut,
+			path:         "/illustrations",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "異常系（権限を含まないトークンの場合）",
+			role:         "",
+			method:       http.MethodPut,
+			path:         "/illustrations",
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+			}
+			router := gin.New()
+			router.Use(middleware.AuthMiddleware(tokenMaker, stubDenylist{}))
+			operators := router.Group("/operators", middleware.RoleMiddleware(token.RoleOwner))
+			operators.Handle(tc.method, "", handler)
+			illustrations := router.Group("/illustrations", middleware.WriteRoleMiddleware(token.RoleOwner, token.RoleEditor))
+			illustrations.Handle(tc.method, "", handler)
+
+			accessToken, _, err := tokenMaker.CreateToken("testuser", tc.role, token.TokenTypeAccess, time.Minute)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest(tc.method, tc.path, nil)
+			request.Header.Set(authorizationHeaderKey, authorizationTypeBearer+" "+accessToken)
+
+			router.ServeHTTP(recorder, request)
+
+			require.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/pkg/lib/apperror"
+	"shin-monta-no-mori/pkg/token"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleMiddleware はアクセストークンの権限が許可された権限のいずれかであるかを確認する
+// AuthMiddlewareの後に使用する
+func RoleMiddleware(roles ...token.Role) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !hasRole(ctx, roles) {
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// WriteRoleMiddleware は参照以外のリクエストのみ、アクセストークンの権限を確認する
+// 参照はすべての権限で許可し、更新は許可された権限のみに制限する場合に使用する
+func WriteRoleMiddleware(roles ...token.Role) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		method := ctx.Request.Method
+		if method != http.MethodGet && method != http.MethodHead && !hasRole(ctx, roles) {
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// hasRole はトークンの権限が許可されているかを確認し、許可されていない場合はリクエストを中断する
+func hasRole(ctx *gin.Context, roles []token.Role) bool {
+	value, _ := ctx.Get(app.AuthorizationPayloadKey)
+	payload, ok := value.(*token.Payload)
+	if !ok {
+		app.AbortWithError(ctx, apperror.Unauthorized, errors.New("authorization payload is not found"))
+		return false
+	}
+
+	if !slices.Contains(roles, payload.Role) {
+		err := fmt.Errorf("role %q is not allowed to %s %s", payload.Role, ctx.Request.Method, ctx.FullPath())
+		app.AbortWithError(ctx, apperror.Forbidden, err)
+		return false
+	}
+
+	return true
+}
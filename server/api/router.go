@@ -5,6 +5,7 @@ import (
 	"shin-monta-no-mori/api/middleware"
 	"shin-monta-no-mori/api/user"
 	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/pkg/token"
 )
 
 func SetUserRouters(s *app.Server) {
@@ -15,7 +16,11 @@ func SetUserRouters(s *app.Server) {
 			illustrations.GET("/:id", app.HandlerFuncWrapper(s, user.GetIllustration))
 			illustrations.GET("/list", app.HandlerFuncWrapper(s, user.ListIllustrations))
 			illustrations.GET("/search", app.HandlerFuncWrapper(s, user.SearchIllustrations))
+			illustrations.GET("/filter", app.HandlerFuncWrapper(s, user.FilterIllustrations))
+			illustrations.GET("/suggest", app.HandlerFuncWrapper(s, user.SuggestIllustrations))
 			illustrations.GET("/random", app.HandlerFuncWrapper(s, user.FetchRandomIllustrations))
+			illustrations.GET("/daily", app.HandlerFuncWrapper(s, user.GetDailyIllustration))
+			illustrations.GET("/:id/related", app.HandlerFuncWrapper(s, user.ListRelatedIllustrations))
 			illustrations.GET("/character/:id", app.HandlerFuncWrapper(s, user.ListIllustrationsByCharacterID))
 			illustrations.GET("/category/child/:id", app.HandlerFuncWrapper(s, user.ListIllustrationsByChildCategoryID))
 		}
@@ -40,17 +45,24 @@ func SetUserRouters(s *app.Server) {
 func SetAdminRouters(s *app.Server) {
 	v1 := s.Router.Group("/api/v1")
 
+	authMiddleware := middleware.AuthMiddleware(s.TokenMaker, middleware.NewSessionDenylist(s))
+	// viewerはコンテンツの閲覧のみ、オペレーターの管理はownerのみ
+	contentRole := middleware.WriteRoleMiddleware(token.RoleOwner, token.RoleEditor)
+	ownerRole := middleware.RoleMiddleware(token.RoleOwner)
+
 	auth := v1.Group("/auth")
 	{
 		auth.POST("/verify", app.HandlerFuncWrapper(s, admin.VerifyAccessToken))
 		auth.POST("/login", app.HandlerFuncWrapper(s, admin.Login))
+		auth.POST("/refresh", app.HandlerFuncWrapper(s, admin.RefreshToken))
+		auth.POST("/logout", authMiddleware, app.HandlerFuncWrapper(s, admin.Logout))
 	}
 
 	adminGroup := v1.Group("/admin")
 	// ログイン認証
-	adminGroup.Use(middleware.AuthMiddleware(s.TokenMaker))
+	adminGroup.Use(authMiddleware)
 	{
-		illustrations := adminGroup.Group("/illustrations")
+		illustrations := adminGroup.Group("/illustrations", contentRole)
 		{
 			illustrations.GET("/:id", app.HandlerFuncWrapper(s, admin.GetIllustration))
 			illustrations.GET("/list", app.HandlerFuncWrapper(s, admin.ListIllustrations))
@@ -58,8 +70,16 @@ func SetAdminRouters(s *app.Server) {
 			illustrations.POST("/create", app.HandlerFuncWrapper(s, admin.CreateIllustration))
 			illustrations.DELETE("/:id", app.HandlerFuncWrapper(s, admin.DeleteIllustration))
 			illustrations.PUT("/:id", app.HandlerFuncWrapper(s, admin.EditIllustration))
+			illustrations.PATCH("/:id", app.HandlerFuncWrapper(s, admin.PatchIllustration))
+			illustrations.PUT("/:id/image", app.HandlerFuncWrapper(s, admin.ReplaceIllustrationImage))
+			illustrations.GET("/:id/revisions", app.HandlerFuncWrapper(s, admin.ListIllustrationRevisions))
+			illustrations.GET("/:id/revisions/diff", app.HandlerFuncWrapper(s, admin.DiffIllustrationRevisions))
+			illustrations.POST("/:id/revisions/:revision_id/rollback", app.HandlerFuncWrapper(s, admin.RollbackIllustration))
+			illustrations.GET("/daily/list", app.HandlerFuncWrapper(s, admin.ListDailyIllustrations))
+			illustrations.PUT("/daily/:date", app.HandlerFuncWrapper(s, admin.SetDailyIllustration))
+			illustrations.DELETE("/daily/:date", app.HandlerFuncWrapper(s, admin.DeleteDailyIllustration))
 		}
-		characters := adminGroup.Group("/characters")
+		characters := adminGroup.Group("/characters", contentRole)
 		{
 			characters.GET("/list", app.HandlerFuncWrapper(s, admin.ListCharacters))
 			characters.GET("/list/all", app.HandlerFuncWrapper(s, admin.ListAllCharacters))
@@ -67,18 +87,25 @@ func SetAdminRouters(s *app.Server) {
 			characters.GET("/:id", app.HandlerFuncWrapper(s, admin.GetCharacter))
 			characters.POST("/create", app.HandlerFuncWrapper(s, admin.CreateCharacter))
 			characters.DELETE("/:id", app.HandlerFuncWrapper(s, admin.DeleteCharacter))
+			characters.PUT("/order", app.HandlerFuncWrapper(s, admin.ReorderCharacters))
 			characters.PUT("/:id", app.HandlerFuncWrapper(s, admin.EditCharacter))
+			characters.PATCH("/:id", app.HandlerFuncWrapper(s, admin.PatchCharacter))
+			characters.PUT("/:id/image", app.HandlerFuncWrapper(s, admin.ReplaceCharacterImage))
+			characters.PUT("/:id/illustrations/order", app.HandlerFuncWrapper(s, admin.ReorderCharacterIllustrations))
 		}
-		categories := adminGroup.Group("/categories")
+		categories := adminGroup.Group("/categories", contentRole)
 		{
 			categories.GET("/list", app.HandlerFuncWrapper(s, admin.ListCategories))
 			categories.GET("/list/all", app.HandlerFuncWrapper(s, admin.ListAllCategories))
 			categories.GET("/search", app.HandlerFuncWrapper(s, admin.SearchCategories))
 			categories.GET("/:id", app.HandlerFuncWrapper(s, admin.GetCategory))
+			categories.PUT("/order", app.HandlerFuncWrapper(s, admin.ReorderCategories))
 			parent_categories := categories.Group("/parent")
 			{
 				parent_categories.POST("/create", app.HandlerFuncWrapper(s, admin.CreateParentCategory))
 				parent_categories.PUT("/:id", app.HandlerFuncWrapper(s, admin.EditParentCategory))
+				parent_categories.PATCH("/:id", app.HandlerFuncWrapper(s, admin.PatchParentCategory))
+				parent_categories.PUT("/:id/image", app.HandlerFuncWrapper(s, admin.ReplaceParentCategoryImage))
 				parent_categories.DELETE("/:id", app.HandlerFuncWrapper(s, admin.DeleteParentCategory))
 			}
 			child_categories := categories.Group("/child")
@@ -86,8 +113,44 @@ func SetAdminRouters(s *app.Server) {
 				child_categories.GET("/:id", app.HandlerFuncWrapper(s, admin.GetChildCategory))
 				child_categories.POST("/create", app.HandlerFuncWrapper(s, admin.CreateChildCategory))
 				child_categories.PUT("/:id", app.HandlerFuncWrapper(s, admin.EditChildCategory))
+				child_categories.PATCH("/:id", app.HandlerFuncWrapper(s, admin.PatchChildCategory))
 				child_categories.DELETE("/:id", app.HandlerFuncWrapper(s, admin.DeleteChildCategory))
+				child_categories.PUT("/:id/illustrations/order", app.HandlerFuncWrapper(s, admin.ReorderChildCategoryIllustrations))
 			}
 		}
+		synonyms := adminGroup.Group("/synonyms", contentRole)
+		{
+			synonyms.GET("/list", app.HandlerFuncWrapper(s, admin.ListAllSynonyms))
+			synonyms.GET("/:id", app.HandlerFuncWrapper(s, admin.GetSynonym))
+			synonyms.POST("/create", app.HandlerFuncWrapper(s, admin.CreateSynonym))
+			synonyms.PUT("/:id", app.HandlerFuncWrapper(s, admin.EditSynonym))
+			synonyms.DELETE("/:id", app.HandlerFuncWrapper(s, admin.DeleteSynonym))
+		}
+		trash := adminGroup.Group("/trash", contentRole)
+		{
+			trash.GET("/:type", app.HandlerFuncWrapper(s, admin.ListTrash))
+			trash.POST("/:type/:id/restore", app.HandlerFuncWrapper(s, admin.RestoreTrash))
+		}
+		searchLogs := adminGroup.Group("/search-logs")
+		{
+			searchLogs.GET("/top", app.HandlerFuncWrapper(s, admin.ListTopSearchQueries))
+			searchLogs.GET("/zero-results", app.HandlerFuncWrapper(s, admin.ListZeroResultSearchQueries))
+			searchLogs.GET("/trends", app.HandlerFuncWrapper(s, admin.ListSearchTrends))
+		}
+		adminGroup.GET("/audit-logs", ownerRole, app.HandlerFuncWrapper(s, admin.ListAuditLogs))
+		adminGroup.GET("/login-history", ownerRole, app.HandlerFuncWrapper(s, admin.ListLoginHistory))
+		sessions := adminGroup.Group("/sessions")
+		{
+			sessions.GET("", app.HandlerFuncWrapper(s, admin.ListSessions))
+			sessions.DELETE("/:id", app.HandlerFuncWrapper(s, admin.RevokeSession))
+		}
+		operators := adminGroup.Group("/operators", ownerRole)
+		{
+			operators.GET("", app.HandlerFuncWrapper(s, admin.ListOperators))
+			operators.POST("", app.HandlerFuncWrapper(s, admin.InviteOperator))
+			operators.PUT("/:id", app.HandlerFuncWrapper(s, admin.EditOperator))
+			operators.POST("/:id/disable", app.HandlerFuncWrapper(s, admin.DisableOperator))
+			operators.POST("/:id/enable", app.HandlerFuncWrapper(s, admin.EnableOperator))
+		}
 	}
 }
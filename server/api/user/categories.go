@@ -8,24 +8,24 @@ import (
 	"shin-monta-no-mori/internal/cache"
 	db "shin-monta-no-mori/internal/db/sqlc"
 	model "shin-monta-no-mori/internal/domains/models"
+	"shin-monta-no-mori/pkg/lib/apperror"
 	"shin-monta-no-mori/pkg/lib/binder"
+	"shin-monta-no-mori/pkg/lib/cursor"
 	"strconv"
 
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
-const (
-	IMAGE_TYPE_CATEGORY = "category"
-)
-
 // TODO: 将来的にpager機能を持たせた方がいいかも？
 type listCategoriesRequest struct {
-	Page int64 `form:"p"`
+	Page   int64  `form:"p"`
+	Cursor string `form:"cursor"`
 }
 
 type listCategoriesResponse struct {
 	Categories []model.Category `json:"categories"`
+	NextCursor string           `json:"next_cursor"`
 }
 
 // ListCategories godoc
@@ -34,9 +34,9 @@ type listCategoriesResponse struct {
 // @Accept  json
 // @Produce  json
 // @Success 200 {array} model/Category "A list of categories with parent and child category details."
-// @Failure 400 {object} request/JSONResponse{data=string} "Bad Request: The request is malformed or missing required fields."
-// @Failure 404 {object} request/JSONResponse{data=string} "Not Found: Child categories not found for one or more parent categories."
-// @Failure 500 {object} request/JSONResponse{data=string} "Internal Server Error: An error occurred on the server which prevented the completion of the request."
+// @Failure 400 {object} apperror.Response "Bad Request: The request is malformed or missing required fields."
+// @Failure 404 {object} apperror.Response "Not Found: Child categories not found for one or more parent categories."
+// @Failure 500 {object} apperror.Response "Internal Server Error: An error occurred on the server which prevented the completion of the request."
 // @Router /api/v1/categories/list [get]
 func ListCategories(ctx *app.AppContext) {
 	var req listCategoriesRequest
@@ -44,33 +44,28 @@ func ListCategories(ctx *app.AppContext) {
 		return
 	}
 
-	arg := db.ListParentCategoriesParams{
-		Limit:  int32(ctx.Server.Config.CategoryFetchLimit),
-		Offset: int32(int(req.Page) * ctx.Server.Config.CategoryFetchLimit),
-	}
-	pcates, err := ctx.Server.Store.ListParentCategories(ctx, arg)
-	if err != nil {
-		ctx.Server.Logger.Error("failed to ListParentCategories", zap.Int32("offset", arg.Offset), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to ListParentCategories : %w", err)))
-		return
-	}
-
-	categories := make([]model.Category, len(pcates))
-	for i, pcate := range pcates {
-		ccates, err := ctx.Server.Store.GetChildCategoriesByParentID(ctx, pcate.ID)
+	var categories []model.Category
+	var err error
+	offset := int32(int(req.Page) * ctx.Server.Config.CategoryFetchLimit)
+	if req.Cursor != "" {
+		var c cursor.Cursor
+		c, err = binder.BindCursor(ctx.Context, req.Cursor)
 		if err != nil {
-			ctx.Server.Logger.Error("failed to GetChildCategoriesByParentID", zap.Int64("parent_category_id", pcate.ID), zap.Error(err))
-			ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetChildCategoriesByParentID : %w", err)))
 			return
 		}
-
-		categories[i] = model.Category{
-			ParentCategory: pcate,
-			ChildCategory:  ccates,
-		}
+		categories, err = ctx.Server.CategoryService.ListByCursor(ctx, c, int32(ctx.Server.Config.CategoryFetchLimit))
+	} else {
+		categories, err = ctx.Server.CategoryService.List(ctx, int32(ctx.Server.Config.CategoryFetchLimit), offset)
+	}
+	if err != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListCategories : %w", err))
+		return
 	}
 
-	ctx.JSON(http.StatusOK, listCategoriesResponse{Categories: categories})
+	ctx.JSON(http.StatusOK, listCategoriesResponse{
+		Categories: categories,
+		NextCursor: nextCategoryCursor(categories, ctx.Server.Config.CategoryFetchLimit),
+	})
 }
 
 // ListCategoriesAll handles the request to list all categories including their parent and child categories.
@@ -79,7 +74,7 @@ func ListCategories(ctx *app.AppContext) {
 // @Tags categories
 // @Produce json
 // @Success 200 {object} listCategoriesResponse
-// @Failure 500 {object} app.ErrorResponse
+// @Failure 500 {object} apperror.Response
 // @param ctx AppContext
 // @Router /categories/all [get]
 func ListAllCategories(ctx *app.AppContext) {
@@ -101,28 +96,12 @@ func ListAllCategories(ctx *app.AppContext) {
 		return
 	}
 
-	pcates, err := ctx.Server.Store.ListAllParentCategories(ctx)
+	categories, err := ctx.Server.CategoryService.ListAll(ctx)
 	if err != nil {
-		ctx.Server.Logger.Error("failed to ListAllParentCategories", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to ListAllParentCategories : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListAllCategories : %w", err))
 		return
 	}
 
-	categories := make([]model.Category, len(pcates))
-	for i, pcate := range pcates {
-		ccates, err := ctx.Server.Store.GetChildCategoriesByParentID(ctx, pcate.ID)
-		if err != nil {
-			ctx.Server.Logger.Error("failed to GetChildCategoriesByParentID", zap.Int64("parent_category_id", pcate.ID), zap.Error(err))
-			ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetChildCategoriesByParentID : %w", err)))
-			return
-		}
-
-		categories[i] = model.Category{
-			ParentCategory: pcate,
-			ChildCategory:  ccates,
-		}
-	}
-
 	if len(categories) > 0 {
 		// レスポンスをキャッシュに保存
 		response := listCategoriesResponse{
@@ -149,7 +128,7 @@ type listChildCategoriesResponse struct {
 // @Accept json
 // @Produce json
 // @Success 200 {object} listChildCategoriesResponse
-// @Failure 500 {object} app.ErrorResponse "内部サーバーエラー"
+// @Failure 500 {object} apperror.Response "内部サーバーエラー"
 // @Router /api/v1/categories/child/list [get]
 func ListChildCategories(ctx *app.AppContext) {
 	const FetchLimit = 5
@@ -159,8 +138,7 @@ func ListChildCategories(ctx *app.AppContext) {
 	}
 	childCategories, err := ctx.Server.Store.ListChildCategories(ctx, arg)
 	if err != nil {
-		ctx.Server.Logger.Error("failed to ListChildCategories", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to ListChildCategories : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListChildCategories : %w", err))
 		return
 	}
 
@@ -180,21 +158,27 @@ type getChildCategoryResponse struct {
 // @Produce json
 // @Param id path int true "子カテゴリID"
 // @Success 200 {object} getChildCategoryResponse
-// @Failure 400 {object} app.ErrorResponse "無効なリクエストパラメータ"
-// @Failure 500 {object} app.ErrorResponse "内部サーバーエラー"
+// @Failure 400 {object} apperror.Response "無効なリクエストパラメータ"
+// @Failure 500 {object} apperror.Response "内部サーバーエラー"
 // @Router /api/v1/categories/child/{id} [get]
 func GetChildCategory(ctx *app.AppContext) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err)))
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
 		return
 	}
 	childCategory, err := ctx.Server.Store.GetChildCategory(ctx, int64(id))
 	if err != nil {
-		ctx.Server.Logger.Error("failed to GetChildCategory", zap.Int("child_category_id", id), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetChildCategory : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to GetChildCategory : %w", err))
 		return
 	}
 
 	ctx.JSON(http.StatusOK, getChildCategoryResponse{ChildCategory: childCategory})
 }
+
+// nextCategoryCursor は次ページ取得用のカーソルを生成する
+func nextCategoryCursor(categories []model.Category, limit int) string {
+	return cursor.Next(categories, limit, func(c model.Category) cursor.Cursor {
+		return cursor.Cursor{ID: c.ParentCategory.ID, PriorityLevel: c.ParentCategory.PriorityLevel}
+	})
+}
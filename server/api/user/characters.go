@@ -8,16 +8,13 @@ import (
 	"shin-monta-no-mori/internal/app"
 	"shin-monta-no-mori/internal/cache"
 	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/pkg/lib/apperror"
 	"strconv"
 
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
-const (
-	IMAGE_TYPE_CHARACTER = "character"
-)
-
 type listAllCharactersResponse struct {
 	Characters []db.Character `json:"characters"`
 }
@@ -29,8 +26,8 @@ type listAllCharactersResponse struct {
 // @Produce  json
 // @Param   p     query   int64  true  "Page number for pagination"
 // @Success 200   {object} gin/H  "Returns a list of characters"
-// @Failure 400   {object} request/JSONResponse{data=string} "Bad Request: Error in data binding or validation"
-// @Failure 500   {object} request/JSONResponse{data=string} "Internal Server Error: Failed to list the characters"
+// @Failure 400   {object} apperror.Response "Bad Request: Error in data binding or validation"
+// @Failure 500   {object} apperror.Response "Internal Server Error: Failed to list the characters"
 // @Router /api/v1/characters/list/all [get]
 func ListAllCharacters(ctx *app.AppContext) {
 	// TODO: redis周りの処理は関数化したい
@@ -53,8 +50,7 @@ func ListAllCharacters(ctx *app.AppContext) {
 
 	characters, err := ctx.Server.Store.ListAllCharacters(ctx)
 	if err != nil {
-		ctx.Server.Logger.Error("failed to ListAllCharacters", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to ListAllCharacters : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListAllCharacters : %w", err))
 		return
 	}
 
@@ -86,26 +82,25 @@ type getCharacterResponse struct {
 // @Produce  json
 // @Param   id   path   int  true  "ID of the character to retrieve"
 // @Success 200 {object} gin/H "The requested character"
-// @Failure 400 {object} request/JSONResponse{data=string} "Bad Request: Failed to parse 'id' number from path parameter"
-// @Failure 404 {object} request/JSONResponse{data=string} "Not Found: No character found with the given ID"
-// @Failure 500 {object} request/JSONResponse{data=string} "Internal Server Error: Failed to retrieve the character from the database"
+// @Failure 400 {object} apperror.Response "Bad Request: Failed to parse 'id' number from path parameter"
+// @Failure 404 {object} apperror.Response "Not Found: No character found with the given ID"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to retrieve the character from the database"
 // @Router /api/v1/characters/{id} [get]
 func GetCharacter(ctx *app.AppContext) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err)))
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
 		return
 	}
 
 	character, err := ctx.Server.Store.GetCharacter(ctx, int64(id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, app.ErrorResponse(fmt.Errorf("failed to GetCharacter: %w", err)))
+			ctx.Fail(apperror.NotFound, fmt.Errorf("failed to GetCharacter: %w", err))
 			return
 		}
 
-		ctx.Server.Logger.Error("failed to GetCharacter", zap.Int("character_id", id), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetCharacter : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to GetCharacter : %w", err))
 		return
 	}
 
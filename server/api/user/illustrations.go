@@ -7,26 +7,30 @@ import (
 	"net/http"
 	"shin-monta-no-mori/internal/app"
 	"shin-monta-no-mori/internal/cache"
-	db "shin-monta-no-mori/internal/db/sqlc"
 	model "shin-monta-no-mori/internal/domains/models"
 	"shin-monta-no-mori/internal/domains/service"
+	"shin-monta-no-mori/pkg/lib/apperror"
 	"shin-monta-no-mori/pkg/lib/binder"
+	"shin-monta-no-mori/pkg/lib/cursor"
+	"shin-monta-no-mori/pkg/lib/textsearch"
+	"shin-monta-no-mori/pkg/util"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
-const (
-	IMAGE_TYPE_IMAGE = "image"
-)
-
 type listIllustrationsRequest struct {
-	Page int64 `form:"p"`
+	Page   int64  `form:"p"`
+	Cursor string `form:"cursor"`
+	Sort   string `form:"sort" binding:"omitempty,oneof=newest oldest title updated popular"`
 }
 
 type listIllustrationsResponse struct {
 	Illustrations []*model.Illustration `json:"illustrations"`
+	NextCursor    string                `json:"next_cursor"`
 }
 
 // ListIllustrations godoc
@@ -34,10 +38,11 @@ type listIllustrationsResponse struct {
 // @Description Retrieves a paginated list of illustrations based on the provided page number.
 // @Accept  json
 // @Produce  json
-// @Param   p   query   int  true  "Page number for pagination"
+// @Param   p     query   int     true   "Page number for pagination"
+// @Param   sort  query   string  false  "Sort order (newest, oldest, title, updated, popular). Defaults to newest"
 // @Success 200 {array} model/Illustration "A list of illustrations"
-// @Failure 400 {object} request/JSONResponse{data=string} "Bad Request: The request is malformed or missing required fields."
-// @Failure 500 {object} request/JSONResponse{data=string} "Internal Server Error: An error occurred on the server which prevented the completion of the request."
+// @Failure 400 {object} apperror.Response "Bad Request: The request is malformed or missing required fields."
+// @Failure 500 {object} apperror.Response "Internal Server Error: An error occurred on the server which prevented the completion of the request."
 // @Router /api/v1/illustrations/list [get]
 func ListIllustrations(ctx *app.AppContext) {
 	var req listIllustrationsRequest
@@ -45,13 +50,27 @@ func ListIllustrations(ctx *app.AppContext) {
 		return
 	}
 
+	// cursorが指定されている場合はキーセットページネーション、指定されていない場合はoffsetでページネーションする
+	var c cursor.Cursor
+	var err error
+	if req.Cursor != "" {
+		c, err = binder.BindCursor(ctx.Context, req.Cursor)
+		if err != nil {
+			return
+		}
+	}
+
 	// TODO: redis周りの処理は関数化したい
 	// Redisのキャッシュキーを設定
-	cacheKey := cache.GetIllustrationsListKey(int(req.Page))
+	sort := illustrationSort(req.Sort)
+	cacheKey := cache.GetIllustrationsListKey(sort, int(req.Page))
+	if req.Cursor != "" {
+		cacheKey = cache.GetIllustrationsListCursorKey(sort, cursor.Encode(c))
+	}
 
 	// Redisからキャッシュを取得
 	var cachedResponse listIllustrationsResponse
-	err := ctx.Server.RedisClient.Get(ctx.Context, cacheKey, &cachedResponse)
+	err = ctx.Server.RedisClient.Get(ctx.Context, cacheKey, &cachedResponse)
 	if err != nil && !errors.Is(err, redis.Nil) {
 		ctx.Server.Logger.Info("failed to redis err", zap.String("redis_key", cacheKey), zap.Error(err))
 	}
@@ -62,28 +81,39 @@ func ListIllustrations(ctx *app.AppContext) {
 		return
 	}
 
-	arg := db.ListImageParams{
+	arg := service.ListImagesParams{
+		Sort:   sort,
 		Limit:  int32(ctx.Server.Config.ImageFetchLimit),
 		Offset: int32(int(req.Page) * ctx.Server.Config.ImageFetchLimit),
 	}
-	images, err := ctx.Server.Store.ListImage(ctx, arg)
+	if req.Cursor != "" {
+		arg.Cursor = &c
+	}
+	result, err := ctx.Server.IllustrationService.ListImages(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to ListImage : %w", err)))
+		if errors.Is(err, cursor.ErrInvalidCursor) {
+			ctx.Fail(apperror.BadRequest, err)
+			return
+		}
+
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListImage : %w", err))
 		return
 	}
 
 	illustrations := []*model.Illustration{}
-	for _, i := range images {
+	for _, i := range result.Images {
 		il := model.NewIllustration()
 		il.Image = i
 
 		illustrations = append(illustrations, il)
 	}
 
+	response := listIllustrationsResponse{
+		Illustrations: illustrations,
+		NextCursor:    result.NextCursor,
+	}
 	if len(illustrations) > 0 {
-		response := listIllustrationsResponse{
-			Illustrations: illustrations,
-		}
+		// レスポンスをキャッシュに保存
 		// Redisへのセットが失敗しても処理を続行
 		err = ctx.Server.RedisClient.Set(ctx.Context, cacheKey, response, cache.CacheDurationDay)
 		if err != nil {
@@ -91,9 +121,7 @@ func ListIllustrations(ctx *app.AppContext) {
 		}
 	}
 
-	ctx.JSON(http.StatusOK, listIllustrationsResponse{
-		Illustrations: illustrations,
-	})
+	ctx.JSON(http.StatusOK, response)
 }
 
 type getIllustrationsResponse struct {
@@ -107,14 +135,14 @@ type getIllustrationsResponse struct {
 // @Produce  json
 // @Param   id   path   int  true  "ID of the illustration to retrieve"
 // @Success 200 {object} model/Illustration "The requested illustration"
-// @Failure 400 {object} request/JSONResponse{data=string} "Bad Request: Failed to parse 'id' number from path parameter"
-// @Failure 404 {object} request/JSONResponse{data=string} "Not Found: No illustration found with the given ID"
-// @Failure 500 {object} request/JSONResponse{data=string} "Internal Server Error: Failed to retrieve the illustration from the database"
+// @Failure 400 {object} apperror.Response "Bad Request: Failed to parse 'id' number from path parameter"
+// @Failure 404 {object} apperror.Response "Not Found: No illustration found with the given ID"
+// @Failure 500 {object} apperror.Response "Internal Server Error: Failed to retrieve the illustration from the database"
 // @Router /api/v1/illustrations/{id} [get]
 func GetIllustration(ctx *app.AppContext) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err)))
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
 		return
 	}
 
@@ -131,24 +159,24 @@ func GetIllustration(ctx *app.AppContext) {
 	}
 
 	if err == nil {
+		// 人気順の並び替えに使用するため、キャッシュの有無に関わらず閲覧数を数える
+		ctx.Server.ViewCountService.Record(int64(id))
 		ctx.JSON(http.StatusOK, cachedResponse)
 		return
 	}
 
-	image, err := ctx.Server.Store.GetImage(ctx.Context, int64(id))
+	illustration, err := ctx.Server.IllustrationService.Get(ctx, int64(id))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, app.ErrorResponse(fmt.Errorf("failed to GetImage: %w", err)))
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
 			return
 		}
 
-		ctx.Server.Logger.Error("failed to GetImage", zap.Int("id", id), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetImage : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to GetIllustration : %w", err))
 		return
 	}
 
-	illustration := &model.Illustration{}
-	illustration = service.FetchRelationInfoForIllustrations(ctx.Context, ctx.Server.Store, image)
+	ctx.Server.ViewCountService.Record(int64(id))
 
 	// レスポンスをキャッシュに保存
 	response := getIllustrationsResponse{
@@ -166,48 +194,62 @@ func GetIllustration(ctx *app.AppContext) {
 }
 
 type searchIllustrationsRequest struct {
-	Page  int    `form:"p"`
-	Query string `form:"q"`
+	Page   int    `form:"p"`
+	Query  string `form:"q"`
+	Cursor string `form:"cursor"`
+	Sort   string `form:"sort" binding:"omitempty,oneof=newest oldest title updated popular"`
 }
 
-// TODO: imageだけでなく、カテゴリでも検索ができるようにする。
-// また、検索結果をtrimし、被りがないようにする
 // SearchIllustrations godoc
 // @Summary Search illustrations
-// @Description Searches for illustrations based on a query and page number.
+// @Description Full-text searches illustrations by title, filename, character name and category name, ordered by relevance. Multiple terms separated by spaces are combined with AND.
 // @Accept  json
 // @Produce  json
 // @Param   p     query   int    true  "Page number for pagination"
-// @Param   q     query   string true  "Query string for searching illustrations by title or category"
+// @Param   q     query   string true  "Query string for searching illustrations"
+// @Param   sort  query   string false "Sort order (newest, oldest, title, updated, popular). Defaults to relevance"
 // @Success 200   {array} model/Illustration "List of matched illustrations"
-// @Failure 400   {object} request/JSONResponse{data=string} "Bad Request: The request is malformed or missing required fields."
-// @Failure 500   {object} request/JSONResponse{data=string} "Internal Server Error: An error occurred on the server which prevented the completion of the request."
+// @Failure 400   {object} apperror.Response "Bad Request: The request is malformed or missing required fields."
+// @Failure 500   {object} apperror.Response "Internal Server Error: An error occurred on the server which prevented the completion of the request."
 // @Router /api/v1/illustrations/search [get]
 func SearchIllustrations(ctx *app.AppContext) {
 	var req searchIllustrationsRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(err))
+		ctx.Fail(apperror.BadRequest, err)
 		return
 	}
 
-	arg := db.SearchImagesParams{
+	arg := service.SearchParams{
+		Query:  req.Query,
+		Sort:   req.Sort,
 		Limit:  int32(ctx.Server.Config.ImageFetchLimit),
 		Offset: int32(req.Page * ctx.Server.Config.ImageFetchLimit),
-		Query: sql.NullString{
-			String: req.Query,
-			Valid:  true,
-		},
+	}
+	if req.Cursor != "" {
+		c, err := binder.BindCursor(ctx.Context, req.Cursor)
+		if err != nil {
+			return
+		}
+		arg.Cursor = &c
 	}
 
-	images, err := ctx.Server.Store.SearchImages(ctx, arg)
+	result, err := ctx.Server.IllustrationService.SearchImages(ctx, arg)
 	if err != nil {
-		ctx.Server.Logger.Error("failed to SearchImages", zap.String("query", req.Query), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to SearchImages : %w", err)))
+		if errors.Is(err, cursor.ErrInvalidCursor) {
+			ctx.Fail(apperror.BadRequest, err)
+			return
+		}
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to SearchImages : %w", err))
 		return
 	}
 
+	// ページ送りを重複して数えないよう、1ページ目の検索のみ記録する
+	if req.Page == 0 && req.Cursor == "" {
+		ctx.Server.SearchLogService.Record(req.Query, result.TotalCount)
+	}
+
 	illustrations := []*model.Illustration{}
-	for _, i := range images {
+	for _, i := range result.Images {
 		il := model.NewIllustration()
 		il.Image = i
 
@@ -216,38 +258,233 @@ func SearchIllustrations(ctx *app.AppContext) {
 
 	ctx.JSON(http.StatusOK, listIllustrationsResponse{
 		Illustrations: illustrations,
+		NextCursor:    result.NextCursor,
 	})
 }
 
+// 検索候補の取得件数
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 20
+)
+
+type suggestIllustrationsRequest struct {
+	Query string `form:"q"`
+	Limit int    `form:"limit" binding:"omitempty,min=1"`
+}
+
+type suggestIllustrationsResponse struct {
+	Suggestions []service.Suggestion `json:"suggestions"`
+}
+
+// SuggestIllustrations godoc
+// @Summary Suggest search terms
+// @Description Returns ranked completions drawn from illustration titles, character names and category names that start with the given query after kana and width normalization.
+// @Accept  json
+// @Produce  json
+// @Param   q      query  string  true   "Prefix typed in the search box"
+// @Param   limit  query  int     false  "Number of suggestions (default 10, max 20)"
+// @Success 200 {object} suggestIllustrationsResponse "A list of suggestions"
+// @Failure 400 {object} apperror.Response "Bad Request: The request is malformed."
+// @Failure 500 {object} apperror.Response "Internal Server Error: An error occurred on the server which prevented the completion of the request."
+// @Router /api/v1/illustrations/suggest [get]
+func SuggestIllustrations(ctx *app.AppContext) {
+	var req suggestIllustrationsRequest
+	if err := binder.BindQuery(ctx.Context, &req); err != nil {
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultSuggestLimit
+	}
+	if req.Limit > maxSuggestLimit {
+		req.Limit = maxSuggestLimit
+	}
+
+	prefix := textsearch.Normalize(strings.TrimSpace(req.Query))
+	if prefix == "" {
+		ctx.JSON(http.StatusOK, suggestIllustrationsResponse{
+			Suggestions: []service.Suggestion{},
+		})
+		return
+	}
+
+	// Redisからキャッシュを取得
+	cacheKey := cache.GetSuggestionsKey(prefix, req.Limit)
+	var cachedResponse suggestIllustrationsResponse
+	err := ctx.Server.RedisClient.Get(ctx.Context, cacheKey, &cachedResponse)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		// キャッシュの取得に失敗したが、デフォルトの動作としてDBからデータを取得する処理を続ける
+		ctx.Server.Logger.Info("failed to redis err", zap.String("redis_key", cacheKey), zap.Error(err))
+	}
+
+	if err == nil {
+		// キャッシュが存在する場合、それをレスポンスとして返す
+		ctx.JSON(http.StatusOK, cachedResponse)
+		return
+	}
+
+	suggestions, err := ctx.Server.IllustrationService.Suggest(ctx, prefix, int32(req.Limit))
+	if err != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to SuggestIllustrations : %w", err))
+		return
+	}
+
+	response := suggestIllustrationsResponse{
+		Suggestions: suggestions,
+	}
+	// レスポンスをキャッシュに保存
+	// Redisへのセットが失敗しても処理を続行
+	err = ctx.Server.RedisClient.Set(ctx.Context, cacheKey, response, cache.CacheDurationDay)
+	if err != nil {
+		ctx.Server.Logger.Warn("failed redis data set", zap.String("redis_key", cacheKey), zap.Error(err))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+type filterIllustrationsRequest struct {
+	Page            int64   `form:"p" binding:"min=0"`
+	Query           string  `form:"q"`
+	Characters      []int64 `form:"characters[]"`
+	ChildCategories []int64 `form:"child_categories[]"`
+	Mode            string  `form:"mode" binding:"omitempty,oneof=and or"`
+}
+
+type filterIllustrationsResponse struct {
+	Illustrations []*model.Illustration `json:"illustrations"`
+	TotalPages    int64                 `json:"total_pages"`
+	TotalCount    int64                 `json:"total_count"`
+	Facets        illustrationFacets    `json:"facets"`
+}
+
+type illustrationFacets struct {
+	Characters      []service.CharacterFacet     `json:"characters"`
+	ChildCategories []service.ChildCategoryFacet `json:"child_categories"`
+}
+
+// FilterIllustrations godoc
+// @Summary Filter illustrations
+// @Description Filters illustrations by multiple characters and child categories, optionally combined with a search query.
+// @Description With mode=and (default) illustrations related to all of the given characters and child categories are returned, with mode=or those related to any of them.
+// @Description Facets contain the number of matched illustrations related to each character and child category.
+// @Accept  json
+// @Produce  json
+// @Param   p                   query  int     false  "Page number for pagination"
+// @Param   q                   query  string  false  "Query string for searching illustrations"
+// @Param   characters[]        query  []int   false  "Character IDs"
+// @Param   child_categories[]  query  []int   false  "Child category IDs"
+// @Param   mode                query  string  false  "and or or"
+// @Success 200 {object} filterIllustrationsResponse "Matched illustrations and facet counts"
+// @Failure 400 {object} apperror.Response "Bad Request: The request is malformed."
+// @Failure 500 {object} apperror.Response "Internal Server Error: An error occurred on the server which prevented the completion of the request."
+// @Router /api/v1/illustrations/filter [get]
+func FilterIllustrations(ctx *app.AppContext) {
+	var req filterIllustrationsRequest
+	if err := binder.BindQuery(ctx.Context, &req); err != nil {
+		return
+	}
+
+	result, err := ctx.Server.IllustrationService.Filter(ctx, service.FilterParams{
+		Query:            req.Query,
+		CharacterIDs:     req.Characters,
+		ChildCategoryIDs: req.ChildCategories,
+		MatchAll:         req.Mode != "or",
+		Limit:            int32(ctx.Server.Config.ImageFetchLimit),
+		Offset:           int32(int(req.Page) * ctx.Server.Config.ImageFetchLimit),
+	})
+	if err != nil {
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to FilterIllustrations : %w", err))
+		return
+	}
+
+	illustrations := []*model.Illustration{}
+	for _, i := range result.Images {
+		il := model.NewIllustration()
+		il.Image = i
+
+		illustrations = append(illustrations, il)
+	}
+
+	ctx.JSON(http.StatusOK, filterIllustrationsResponse{
+		Illustrations: illustrations,
+		TotalPages:    (result.TotalCount + int64(ctx.Server.Config.ImageFetchLimit-1)) / int64(ctx.Server.Config.ImageFetchLimit),
+		TotalCount:    result.TotalCount,
+		Facets: illustrationFacets{
+			Characters:      result.Characters,
+			ChildCategories: result.ChildCategories,
+		},
+	})
+}
+
+const (
+	defaultRandomLimit = 10
+	maxRandomLimit     = 50
+)
+
 type listFetchRandomIllustrationsRequest struct {
-	Limit       int64 `form:"limit"`
-	ExclusionID int64 `form:"exclusion_id"`
+	Page            int64   `form:"p" binding:"min=0"`
+	Limit           int64   `form:"limit" binding:"omitempty,min=1"`
+	ExclusionID     int64   `form:"exclusion_id"`
+	Seed            *int64  `form:"seed"`
+	Characters      []int64 `form:"characters[]"`
+	ChildCategories []int64 `form:"child_categories[]"`
+	Weighted        bool    `form:"weighted"`
+}
+
+type fetchRandomIllustrationsResponse struct {
+	Illustrations []*model.Illustration `json:"illustrations"`
+	Seed          int64                 `json:"seed"`
 }
 
 // FetchRandomIllustrations godoc
 // @Summary Fetch random illustrations
-// @Description Retrieves a list of illustrations randomly selected from the database.
+// @Description Retrieves a list of illustrations randomly selected from the database. The same seed returns the same order, so pages of a shuffled view can be fetched with p. When seed is omitted, a new one is generated and returned.
 // @Accept  json
 // @Produce  json
-// @Param   limit   query   int  true  "Number of illustrations to retrieve"
-// @Success 200 {array} models.Illustration "A list of illustrations"
-// @Failure 400 {object} app.ErrorResponse "Bad Request: The request is malformed or missing required fields."
-// @Failure 500 {object} app.ErrorResponse "Internal Server Error: An error occurred on the server which prevented the completion of the request."
+// @Param   limit               query  int     false  "Number of illustrations to retrieve (default 10, max 50)"
+// @Param   p                   query  int     false  "Page number for pagination"
+// @Param   seed                query  int     false  "Seed of the random order"
+// @Param   exclusion_id        query  int     false  "ID of the illustration to exclude"
+// @Param   characters[]        query  []int   false  "Character IDs to filter by"
+// @Param   child_categories[]  query  []int   false  "Child category IDs to filter by"
+// @Param   weighted            query  bool    false  "Prefer illustrations related to characters and categories with a higher priority level"
+// @Success 200 {object} fetchRandomIllustrationsResponse "A list of illustrations and the seed"
+// @Failure 400 {object} apperror.Response "Bad Request: The request is malformed or missing required fields."
+// @Failure 500 {object} apperror.Response "Internal Server Error: An error occurred on the server which prevented the completion of the request."
 // @Router /api/v1/illustrations/random [get]
 func FetchRandomIllustrations(ctx *app.AppContext) {
 	var req listFetchRandomIllustrationsRequest
 	if err := binder.BindQuery(ctx.Context, &req); err != nil {
 		return
 	}
+	if req.Limit == 0 {
+		req.Limit = defaultRandomLimit
+	}
+	if req.Limit > maxRandomLimit {
+		req.Limit = maxRandomLimit
+	}
+
+	seed := service.NewSampleSeed()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
 
-	arg := db.FetchRandomImageParams{
-		Limit: int32(req.Limit),
-		ID:    req.ExclusionID,
+	exclusionIDs := []int64{}
+	if req.ExclusionID != 0 {
+		exclusionIDs = append(exclusionIDs, req.ExclusionID)
 	}
-	images, err := ctx.Server.Store.FetchRandomImage(ctx, arg)
+
+	images, err := ctx.Server.IllustrationService.Sample(ctx, service.SampleParams{
+		Seed:             seed,
+		ExclusionIDs:     exclusionIDs,
+		CharacterIDs:     req.Characters,
+		ChildCategoryIDs: req.ChildCategories,
+		Weighted:         req.Weighted,
+		Limit:            int32(req.Limit),
+		Offset:           int32(req.Page * req.Limit),
+	})
 	if err != nil {
-		ctx.Server.Logger.Error("failed to FetchRandomImage", zap.Int("exclusion_id", int(req.ExclusionID)), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to FetchRandomImage : %w", err)))
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to FetchRandomIllustrations : %w", err))
 		return
 	}
 
@@ -259,13 +496,154 @@ func FetchRandomIllustrations(ctx *app.AppContext) {
 		illustrations = append(illustrations, il)
 	}
 
-	ctx.JSON(http.StatusOK, listIllustrationsResponse{
+	ctx.JSON(http.StatusOK, fetchRandomIllustrationsResponse{
 		Illustrations: illustrations,
+		Seed:          seed,
 	})
 }
 
+// GetDailyIllustration godoc
+// @Summary Retrieve the illustration of the day
+// @Description Retrieves the illustration of the day for the current date in Asia/Tokyo. Every visitor gets the same illustration on the same day.
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} getIllustrationsResponse "The illustration of the day"
+// @Failure 404 {object} apperror.Response "Not Found: There is no illustration to pick"
+// @Failure 500 {object} apperror.Response "Internal Server Error: An error occurred on the server which prevented the completion of the request."
+// @Router /api/v1/illustrations/daily [get]
+func GetDailyIllustration(ctx *app.AppContext) {
+	now := time.Now()
+	date := service.DailyDate(now).Format(time.DateOnly)
+
+	// Redisからキャッシュを取得
+	cacheKey := cache.GetDailyIllustrationKey(date)
+	var cachedResponse getIllustrationsResponse
+	err := ctx.Server.RedisClient.Get(ctx.Context, cacheKey, &cachedResponse)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		// キャッシュの取得に失敗したが、デフォルトの動作としてDBからデータを取得する処理を続ける
+		ctx.Server.Logger.Info("failed to redis err", zap.String("redis_key", cacheKey), zap.Error(err))
+	}
+
+	if err == nil {
+		// キャッシュが存在する場合、それをレスポンスとして返す
+		ctx.JSON(http.StatusOK, cachedResponse)
+		return
+	}
+
+	illustration, err := ctx.Server.IllustrationService.Daily(ctx, now, ctx.Server.Config.DailyIllustrationNoRepeatDays)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
+		}
+
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to GetDailyIllustration : %w", err))
+		return
+	}
+
+	response := getIllustrationsResponse{
+		Illustration: illustration,
+	}
+	// 日本時間の翌日0時までキャッシュに保存
+	// Redisへのセットが失敗しても処理を続行
+	err = ctx.Server.RedisClient.Set(ctx.Context, cacheKey, response, util.UntilNextDay(now))
+	if err != nil {
+		ctx.Server.Logger.Warn("failed redis data set", zap.String("redis_key", cacheKey), zap.Error(err))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+const (
+	defaultRelatedLimit = 10
+	maxRelatedLimit     = 20
+)
+
+type listRelatedIllustrationsRequest struct {
+	Limit int `form:"limit" binding:"omitempty,min=1"`
+}
+
+// ListRelatedIllustrations godoc
+// @Summary List related illustrations
+// @Description Retrieves illustrations that share characters, child categories or parent categories with the given illustration, ordered by the number of shared items with random tie-breaking. When there are not enough related illustrations, random ones are added.
+// @Accept  json
+// @Produce  json
+// @Param   id     path   int  true   "ID of the illustration"
+// @Param   limit  query  int  false  "Number of illustrations (default 10, max 20)"
+// @Success 200 {array} models.Illustration "A list of illustrations"
+// @Failure 400 {object} apperror.Response "Bad Request: The request is malformed or missing required fields."
+// @Failure 404 {object} apperror.Response "Not Found: No illustration found with the given ID"
+// @Failure 500 {object} apperror.Response "Internal Server Error: An error occurred on the server which prevented the completion of the request."
+// @Router /api/v1/illustrations/{id}/related [get]
+func ListRelatedIllustrations(ctx *app.AppContext) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
+		return
+	}
+
+	var req listRelatedIllustrationsRequest
+	if err := binder.BindQuery(ctx.Context, &req); err != nil {
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultRelatedLimit
+	}
+	if req.Limit > maxRelatedLimit {
+		req.Limit = maxRelatedLimit
+	}
+
+	// Redisからキャッシュを取得
+	cacheKey := cache.GetRelatedIllustrationsKey(id, req.Limit)
+	var cachedResponse listIllustrationsResponse
+	err = ctx.Server.RedisClient.Get(ctx.Context, cacheKey, &cachedResponse)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		// キャッシュの取得に失敗したが、デフォルトの動作としてDBからデータを取得する処理を続ける
+		ctx.Server.Logger.Info("failed to redis err", zap.String("redis_key", cacheKey), zap.Error(err))
+	}
+
+	if err == nil {
+		// キャッシュが存在する場合、それをレスポンスとして返す
+		ctx.JSON(http.StatusOK, cachedResponse)
+		return
+	}
+
+	images, err := ctx.Server.IllustrationService.Related(ctx, int64(id), int32(req.Limit))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Fail(apperror.NotFound, err)
+			return
+		}
+
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListRelatedIllustrations : %w", err))
+		return
+	}
+
+	illustrations := []*model.Illustration{}
+	for _, i := range images {
+		il := model.NewIllustration()
+		il.Image = i
+
+		illustrations = append(illustrations, il)
+	}
+
+	response := listIllustrationsResponse{
+		Illustrations: illustrations,
+	}
+	// レスポンスをキャッシュに保存
+	// Redisへのセットが失敗しても処理を続行
+	err = ctx.Server.RedisClient.Set(ctx.Context, cacheKey, response, cache.CacheDurationDay)
+	if err != nil {
+		ctx.Server.Logger.Warn("failed redis data set", zap.String("redis_key", cacheKey), zap.Error(err))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
 type listIllustrationsByCharacterIDRequest struct {
-	Page int64 `form:"p"`
+	Page   int64  `form:"p"`
+	Cursor string `form:"cursor"`
+	Sort   string `form:"sort" binding:"omitempty,oneof=manual newest oldest title updated popular"`
 }
 
 // ListIllustrationsByCharacterID godoc
@@ -273,17 +651,18 @@ type listIllustrationsByCharacterIDRequest struct {
 // @Description Retrieves a paginated list of illustrations associated with a given character ID.
 // @Accept  json
 // @Produce  json
-// @Param   id   path   int  true  "ID of the character"
-// @Param   p    query  int  true  "Page number for pagination"
+// @Param   id    path   int     true   "ID of the character"
+// @Param   p     query  int     true   "Page number for pagination"
+// @Param   sort  query  string  false  "Sort order (manual, newest, oldest, title, updated, popular). Defaults to manual, which lists curated illustrations first and the rest newest first"
 // @Success 200 {array} models.Illustration "A list of illustrations"
-// @Failure 400 {object} app.ErrorResponse "Bad Request: The request is malformed or missing required fields."
-// @Failure 404 {object} app.ErrorResponse "Not Found: No illustrations found for the given character ID."
-// @Failure 500 {object} app.ErrorResponse "Internal Server Error: An error occurred on the server which prevented the completion of the request."
+// @Failure 400 {object} apperror.Response "Bad Request: The request is malformed or missing required fields."
+// @Failure 404 {object} apperror.Response "Not Found: No illustrations found for the given character ID."
+// @Failure 500 {object} apperror.Response "Internal Server Error: An error occurred on the server which prevented the completion of the request."
 // @Router /api/v1/illustrations/character/{id} [get]
 func ListIllustrationsByCharacterID(ctx *app.AppContext) {
 	charaID, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err)))
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
 		return
 	}
 
@@ -292,9 +671,21 @@ func ListIllustrationsByCharacterID(ctx *app.AppContext) {
 		return
 	}
 
+	var c cursor.Cursor
+	if req.Cursor != "" {
+		c, err = binder.BindCursor(ctx.Context, req.Cursor)
+		if err != nil {
+			return
+		}
+	}
+
 	// TODO: redis周りの処理は関数化したい
 	// Redisのキャッシュキーを設定
-	cacheKey := cache.GetIllustrationsListByCharacterKey(charaID, int(req.Page))
+	sort := relationIllustrationSort(req.Sort)
+	cacheKey := cache.GetIllustrationsListByCharacterKey(charaID, sort, int(req.Page))
+	if req.Cursor != "" {
+		cacheKey = cache.GetIllustrationsListByCharacterCursorKey(charaID, sort, cursor.Encode(c))
+	}
 
 	// Redisからキャッシュを取得
 	var cachedResponse listIllustrationsResponse
@@ -310,50 +701,40 @@ func ListIllustrationsByCharacterID(ctx *app.AppContext) {
 		return
 	}
 
-	// charaIDを元にimage_characters_relationsを取得する
-	arg := db.ListImageCharacterRelationsByCharacterIDWIthPaginationParams{
+	arg := service.ListImagesParams{
+		Sort:        sort,
+		CharacterID: int64(charaID),
 		Limit:       int32(ctx.Server.Config.ImageFetchLimit),
 		Offset:      int32(int(req.Page) * ctx.Server.Config.ImageFetchLimit),
-		CharacterID: int64(charaID),
 	}
-	icrs, err := ctx.Server.Store.ListImageCharacterRelationsByCharacterIDWIthPagination(ctx, arg)
-	if err != nil {
-		ctx.Server.Logger.Error("failed to ListImageCharacterRelationsByCharacterID", zap.Int("character_id", charaID), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to ListImageCharacterRelationsByCharacterID : %w", err)))
-		return
+	if req.Cursor != "" {
+		arg.Cursor = &c
 	}
-
-	// image_characters_relationsを元にimageを取得する
-	images := []db.Image{}
-	for _, icr := range icrs {
-		image, err := ctx.Server.Store.GetImage(ctx, icr.ImageID)
-		if err != nil {
-			if err == sql.ErrNoRows {
-				ctx.JSON(http.StatusNotFound, app.ErrorResponse(fmt.Errorf("failed to GetImage: %w", err)))
-				return
-			}
-
-			ctx.Server.Logger.Error("failed to GetImage", zap.Int64("image_id", icr.ImageID), zap.Int("character_id", charaID), zap.Error(err))
-			ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetImage : %w", err)))
+	result, err := ctx.Server.IllustrationService.ListImages(ctx, arg)
+	if err != nil {
+		if errors.Is(err, cursor.ErrInvalidCursor) {
+			ctx.Fail(apperror.BadRequest, err)
 			return
 		}
 
-		images = append(images, image)
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListIllustrationsByCharacterID : %w", err))
+		return
 	}
 
 	illustrations := []*model.Illustration{}
-	for _, i := range images {
+	for _, i := range result.Images {
 		il := model.NewIllustration()
 		il.Image = i
 
 		illustrations = append(illustrations, il)
 	}
 
+	response := listIllustrationsResponse{
+		Illustrations: illustrations,
+		NextCursor:    result.NextCursor,
+	}
 	if len(illustrations) > 0 {
 		// レスポンスをキャッシュに保存
-		response := listIllustrationsResponse{
-			Illustrations: illustrations,
-		}
 		// Redisへのセットが失敗しても処理を続行
 		err = ctx.Server.RedisClient.Set(ctx.Context, cacheKey, response, cache.CacheDurationDay)
 		if err != nil {
@@ -361,13 +742,13 @@ func ListIllustrationsByCharacterID(ctx *app.AppContext) {
 		}
 	}
 
-	ctx.JSON(http.StatusOK, listIllustrationsResponse{
-		Illustrations: illustrations,
-	})
+	ctx.JSON(http.StatusOK, response)
 }
 
 type listIllustrationsByChildCategoryIDRequest struct {
-	Page int64 `form:"p"`
+	Page   int64  `form:"p"`
+	Cursor string `form:"cursor"`
+	Sort   string `form:"sort" binding:"omitempty,oneof=manual newest oldest title updated popular"`
 }
 
 // ListIllustrationsByParentCategoryID godoc
@@ -375,17 +756,18 @@ type listIllustrationsByChildCategoryIDRequest struct {
 // @Description Retrieves a paginated list of illustrations associated with a given parent category ID.
 // @Accept  json
 // @Produce  json
-// @Param   id   path   int  true  "ID of the parent category"
-// @Param   p    query  int  true  "Page number for pagination"
+// @Param   id    path   int     true   "ID of the parent category"
+// @Param   p     query  int     true   "Page number for pagination"
+// @Param   sort  query  string  false  "Sort order (manual, newest, oldest, title, updated, popular). Defaults to manual, which lists curated illustrations first and the rest newest first"
 // @Success 200 {array} models.Illustration "A list of illustrations"
-// @Failure 400 {object} app.ErrorResponse "Bad Request: The request is malformed or missing required fields."
-// @Failure 404 {object} app.ErrorResponse "Not Found: No illustrations found for the given parent category ID."
-// @Failure 500 {object} app.ErrorResponse "Internal Server Error: An error occurred on the server which prevented the completion of the request."
+// @Failure 400 {object} apperror.Response "Bad Request: The request is malformed or missing required fields."
+// @Failure 404 {object} apperror.Response "Not Found: No illustrations found for the given parent category ID."
+// @Failure 500 {object} apperror.Response "Internal Server Error: An error occurred on the server which prevented the completion of the request."
 // @Router /api/v1/illustrations/category/child/{id} [get]
 func ListIllustrationsByChildCategoryID(ctx *app.AppContext) {
 	cCateID, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err)))
+		ctx.Fail(apperror.BadRequest, fmt.Errorf("failed to parse 'id' number from from path parameter : %w", err))
 		return
 	}
 	var req listIllustrationsByChildCategoryIDRequest
@@ -393,9 +775,21 @@ func ListIllustrationsByChildCategoryID(ctx *app.AppContext) {
 		return
 	}
 
+	var c cursor.Cursor
+	if req.Cursor != "" {
+		c, err = binder.BindCursor(ctx.Context, req.Cursor)
+		if err != nil {
+			return
+		}
+	}
+
 	// TODO: redis周りの処理は関数化したい
 	// Redisのキャッシュキーを設定
-	cacheKey := cache.GetIllustrationsListByCategoryKey(cCateID, int(req.Page))
+	sort := relationIllustrationSort(req.Sort)
+	cacheKey := cache.GetIllustrationsListByCategoryKey(cCateID, sort, int(req.Page))
+	if req.Cursor != "" {
+		cacheKey = cache.GetIllustrationsListByCategoryCursorKey(cCateID, sort, cursor.Encode(c))
+	}
 
 	// Redisからキャッシュを取得
 	var cachedResponse listIllustrationsResponse
@@ -411,50 +805,40 @@ func ListIllustrationsByChildCategoryID(ctx *app.AppContext) {
 		return
 	}
 
-	// pCateIDを元にimage_parent_categories_relationsを取得する
-	arg := db.ListImageChildCategoryRelationsByChildCategoryIDWithPaginationParams{
+	arg := service.ListImagesParams{
+		Sort:            sort,
+		ChildCategoryID: int64(cCateID),
 		Limit:           int32(ctx.Server.Config.ImageFetchLimit),
 		Offset:          int32(int(req.Page) * ctx.Server.Config.ImageFetchLimit),
-		ChildCategoryID: int64(cCateID),
 	}
-	icrs, err := ctx.Server.Store.ListImageChildCategoryRelationsByChildCategoryIDWithPagination(ctx, arg)
-	if err != nil {
-		ctx.Server.Logger.Error("failed to ListImageChildCategoryRelationsByChildCategoryIDWithPagination", zap.Int("child_category_id", cCateID), zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to ListImageChildCategoryRelationsByChildCategoryIDWithPagination : %w", err)))
-		return
+	if req.Cursor != "" {
+		arg.Cursor = &c
 	}
-
-	// image_parent_categories_relationsを元にimageを取得する
-	images := []db.Image{}
-	for _, icr := range icrs {
-		image, err := ctx.Server.Store.GetImage(ctx, icr.ImageID)
-		if err != nil {
-			if err == sql.ErrNoRows {
-				ctx.JSON(http.StatusNotFound, app.ErrorResponse(fmt.Errorf("failed to GetImage: %w", err)))
-				return
-			}
-
-			ctx.Server.Logger.Error("failed to GetImage", zap.Int64("image_id", icr.ImageID), zap.Int("child_category_id", cCateID), zap.Error(err))
-			ctx.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetImage : %w", err)))
+	result, err := ctx.Server.IllustrationService.ListImages(ctx, arg)
+	if err != nil {
+		if errors.Is(err, cursor.ErrInvalidCursor) {
+			ctx.Fail(apperror.BadRequest, err)
 			return
 		}
 
-		images = append(images, image)
+		ctx.Fail(apperror.Internal, fmt.Errorf("failed to ListIllustrationsByChildCategoryID : %w", err))
+		return
 	}
 
 	illustrations := []*model.Illustration{}
-	for _, i := range images {
+	for _, i := range result.Images {
 		il := model.NewIllustration()
 		il.Image = i
 
 		illustrations = append(illustrations, il)
 	}
 
+	response := listIllustrationsResponse{
+		Illustrations: illustrations,
+		NextCursor:    result.NextCursor,
+	}
 	if len(illustrations) > 0 {
 		// レスポンスをキャッシュに保存
-		response := listIllustrationsResponse{
-			Illustrations: illustrations,
-		}
 		// Redisへのセットが失敗しても処理を続行
 		err = ctx.Server.RedisClient.Set(ctx.Context, cacheKey, response, cache.CacheDurationDay)
 		if err != nil {
@@ -462,7 +846,21 @@ func ListIllustrationsByChildCategoryID(ctx *app.AppContext) {
 		}
 	}
 
-	ctx.JSON(http.StatusOK, listIllustrationsResponse{
-		Illustrations: illustrations,
-	})
+	ctx.JSON(http.StatusOK, response)
+}
+
+// illustrationSort は並び順が指定されていない場合に、新しい順とする
+func illustrationSort(sort string) string {
+	if sort == "" {
+		return service.ILLUSTRATION_SORT_NEWEST
+	}
+	return sort
+}
+
+// relationIllustrationSort はキャラクター・子カテゴリごとの一覧で並び順が指定されていない場合に、管理者が指定した表示順とする
+func relationIllustrationSort(sort string) string {
+	if sort == "" {
+		return service.ILLUSTRATION_SORT_MANUAL
+	}
+	return sort
 }
@@ -14,9 +14,12 @@ import (
 	"shin-monta-no-mori/internal/cache"
 	db "shin-monta-no-mori/internal/db/sqlc"
 	model "shin-monta-no-mori/internal/domains/models"
+	"shin-monta-no-mori/internal/domains/service"
+	"shin-monta-no-mori/pkg/lib/cursor"
 	"shin-monta-no-mori/pkg/lib/logger"
 	"shin-monta-no-mori/pkg/util"
 	"testing"
+	"time"
 
 	_ "github.com/lib/pq"
 
@@ -172,6 +175,113 @@ func TestListIllustrations(t *testing.T) {
 	}
 }
 
+func TestListIllustrationsWithSort(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	i := illustrationTest{}
+	c := i.setUp(t, config)
+	defer i.tearDown(t, config)
+
+	// 前回のテストで保存された一覧のキャッシュを削除
+	err = c.Server.RedisClient.Del(context.Background(), []string{cache.IllustrationsPrefix + "*"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		path         string
+		want         []int64
+		expectedCode int
+	}{
+		{
+			name:         "正常系（新しい順）",
+			path:         "/api/v1/illustrations/list?sort=newest",
+			want:         []int64{999991, 999990, 25001, 24001, 23001, 22001, 21001},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（古い順）",
+			path:         "/api/v1/illustrations/list?sort=oldest",
+			want:         []int64{21001, 22001, 23001, 24001, 25001, 999990, 999991},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（タイトル順）",
+			path:         "/api/v1/illustrations/list?sort=title",
+			want:         []int64{21001, 22001, 23001, 24001, 25001, 999990, 999991},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（キャラクターに紐づくイラストを古い順）",
+			path:         "/api/v1/illustrations/character/21001?sort=oldest",
+			want:         []int64{999990, 999991},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（検索結果を古い順）",
+			path:         "/api/v1/illustrations/search?q=test_character_name_21001&sort=oldest",
+			want:         []int64{21001, 999990, 999991},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（カーソルを指定した時はページ番号を無視する）",
+			path:         "/api/v1/illustrations/list?sort=oldest&p=1&cursor=" + cursor.Encode(cursor.Cursor{ID: 23001, Sort: "oldest"}),
+			want:         []int64{24001, 25001, 999990, 999991},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（タイトル順のカーソル）",
+			path:         "/api/v1/illustrations/list?sort=title&cursor=" + cursor.Encode(cursor.Cursor{ID: 23001, Sort: "title", Title: "test_image_title_23001"}),
+			want:         []int64{24001, 25001, 999990, 999991},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（IDが同じでも起点の値が異なるカーソルはキャッシュを共有しない）",
+			path:         "/api/v1/illustrations/list?sort=title&cursor=" + cursor.Encode(cursor.Cursor{ID: 23001, Sort: "title", Title: "test_image_title_25001"}),
+			want:         []int64{25001, 999990, 999991},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（sortの値が不正な時）",
+			path:         "/api/v1/illustrations/list?sort=random",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系（別の並び順のカーソルを指定した時）",
+			path:         "/api/v1/illustrations/list?sort=title&cursor=" + cursor.Encode(cursor.Cursor{ID: 25001}),
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+
+			c.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+
+			type wantType struct {
+				Illustrations []model.Illustration `json:"illustrations"`
+			}
+			var got wantType
+			err := json.Unmarshal(w.Body.Bytes(), &got)
+			require.NoError(t, err)
+
+			ids := []int64{}
+			for _, il := range got.Illustrations {
+				ids = append(ids, il.Image.ID)
+			}
+			require.Equal(t, tt.want, ids)
+		})
+	}
+}
+
 func TestGetIllustration(t *testing.T) {
 	config, err := util.LoadConfig(AppEnvPath)
 	if err != nil {
@@ -286,6 +396,61 @@ func TestGetIllustration(t *testing.T) {
 	}
 }
 
+func TestCountIllustrationViews(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	i := illustrationTest{}
+	c := i.setUp(t, config)
+	defer i.tearDown(t, config)
+
+	// 2回目以降はキャッシュから取得した場合も閲覧数を数える
+	paths := []string{
+		"/api/v1/illustrations/21001",
+		"/api/v1/illustrations/21001",
+		"/api/v1/illustrations/22001",
+		"/api/v1/illustrations/99999",
+	}
+	for _, path := range paths {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		c.Server.Router.ServeHTTP(w, req)
+	}
+
+	// 書き込むまではDBを更新しない
+	image, err := c.Server.Store.GetImage(context.Background(), 21001)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), image.ViewCount)
+
+	err = c.Server.ViewCountService.Flush(context.Background())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		id   int64
+		want int64
+	}{
+		{
+			name: "正常系（キャッシュから取得した閲覧も数える）",
+			id:   21001,
+			want: 2,
+		},
+		{
+			name: "正常系",
+			id:   22001,
+			want: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image, err := c.Server.Store.GetImage(context.Background(), tt.id)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, image.ViewCount)
+		})
+	}
+}
+
 func TestSearchIllustrations(t *testing.T) {
 	config, err := util.LoadConfig(AppEnvPath)
 	if err != nil {
@@ -420,6 +585,454 @@ func TestSearchIllustrations(t *testing.T) {
 	}
 }
 
+func TestRecordSearchLog(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	i := illustrationTest{}
+	c := i.setUp(t, config)
+	defer i.tearDown(t, config)
+	defer func() {
+		store := createConn(config)
+		if _, err := store.ExecQuery(context.Background(), "TRUNCATE TABLE search_logs RESTART IDENTITY CASCADE;"); err != nil {
+			t.Fatalf("Failed to truncate table: %v", err)
+		}
+	}()
+
+	tests := []struct {
+		name          string
+		q             string
+		wantZeroCount int64
+	}{
+		{
+			name:          "正常系（検索結果がある場合）",
+			q:             "test_image_title_22001",
+			wantZeroCount: 0,
+		},
+		{
+			name:          "正常系（検索結果が0件の場合）",
+			q:             "not_exist_illustration",
+			wantZeroCount: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.Server.Config.ImageFetchLimit = 1
+			from := time.Now().Add(-time.Minute)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/illustrations/search?p=0&q="+tt.q, nil)
+			c.Server.Router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			// 検索時の件数がバックグラウンドで記録される
+			require.Eventually(t, func() bool {
+				trends, err := c.Server.SearchLogService.ListTrends(context.Background(), from, time.Now().Add(time.Minute), tt.q)
+				require.NoError(t, err)
+				var count, zeroCount int64
+				for _, trend := range trends {
+					count += trend.Count
+					zeroCount += trend.ZeroResultCount
+				}
+				return count == 1 && zeroCount == tt.wantZeroCount
+			}, 5*time.Second, 100*time.Millisecond)
+		})
+	}
+}
+
+func TestFilterIllustrations(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	i := illustrationTest{}
+	c := i.setUp(t, config)
+	defer i.tearDown(t, config)
+
+	type facet struct {
+		ID    int64 `json:"id"`
+		Count int64 `json:"count"`
+	}
+	type want struct {
+		imageIDs        []int64
+		totalCount      int64
+		characters      []facet
+		childCategories []facet
+	}
+
+	tests := []struct {
+		name         string
+		query        string
+		want         want
+		wantErr      bool
+		expectedCode int
+	}{
+		{
+			name:  "正常系（キャラクター1つで絞り込んだ時）",
+			query: "characters[]=21001",
+			want: want{
+				imageIDs:        []int64{999991, 999990},
+				totalCount:      2,
+				characters:      []facet{{ID: 21001, Count: 2}},
+				childCategories: []facet{},
+			},
+			wantErr:      false,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:  "正常系（mode=orでキャラクターと子カテゴリのいずれかに一致する時）",
+			query: "characters[]=22001&child_categories[]=23001&mode=or",
+			want: want{
+				imageIDs:        []int64{23001, 22001},
+				totalCount:      2,
+				characters:      []facet{{ID: 23001, Count: 1}, {ID: 22001, Count: 1}},
+				childCategories: []facet{{ID: 23001, Count: 1}, {ID: 22001, Count: 1}},
+			},
+			wantErr:      false,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:  "正常系（mode=andで全てに一致するイラストがない時）",
+			query: "characters[]=22001&child_categories[]=23001",
+			want: want{
+				imageIDs:        []int64{},
+				totalCount:      0,
+				characters:      []facet{},
+				childCategories: []facet{},
+			},
+			wantErr:      false,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:  "正常系（検索語と組み合わせた時）",
+			query: "characters[]=21001&q=test_image_title_999990",
+			want: want{
+				imageIDs:        []int64{999990},
+				totalCount:      1,
+				characters:      []facet{{ID: 21001, Count: 1}},
+				childCategories: []facet{},
+			},
+			wantErr:      false,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（modeの値が不正な時）",
+			query:        "characters[]=21001&mode=xor",
+			wantErr:      true,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.Server.Config.ImageFetchLimit = 10
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/illustrations/filter?"+tt.query, nil)
+
+			c.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.wantErr {
+				require.NotEmpty(t, w.Body.String())
+				return
+			}
+
+			type wantType struct {
+				Illustrations []model.Illustration `json:"illustrations"`
+				TotalCount    int64                `json:"total_count"`
+				Facets        struct {
+					Characters      []facet `json:"characters"`
+					ChildCategories []facet `json:"child_categories"`
+				} `json:"facets"`
+			}
+			var got wantType
+			err := json.Unmarshal(w.Body.Bytes(), &got)
+			require.NoError(t, err)
+
+			imageIDs := []int64{}
+			for _, il := range got.Illustrations {
+				imageIDs = append(imageIDs, il.Image.ID)
+			}
+			require.Equal(t, tt.want.imageIDs, imageIDs)
+			require.Equal(t, tt.want.totalCount, got.TotalCount)
+			require.Equal(t, tt.want.characters, got.Facets.Characters)
+			require.Equal(t, tt.want.childCategories, got.Facets.ChildCategories)
+		})
+	}
+}
+
+func TestSuggestIllustrations(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	i := illustrationTest{}
+	c := i.setUp(t, config)
+	defer i.tearDown(t, config)
+
+	// 前回のテストで保存された検索候補のキャッシュを削除
+	err = c.Server.RedisClient.Del(context.Background(), []string{cache.SuggestionsPrefix + "*"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		query        string
+		want         []service.Suggestion
+		wantErr      bool
+		expectedCode int
+	}{
+		{
+			name:  "正常系（関連するイラストが多い順に前方一致する候補を取得）",
+			query: "q=TEST_CHARACTER_NAME_2100&limit=2",
+			want: []service.Suggestion{
+				{Text: "test_character_name_21001", Kind: service.SUGGESTION_KIND_CHARACTER},
+				{Text: "test_character_name_21002", Kind: service.SUGGESTION_KIND_CHARACTER},
+			},
+			wantErr:      false,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:  "正常系（完全一致する候補を先頭に取得）",
+			query: "q=test_image_title_22001",
+			want: []service.Suggestion{
+				{Text: "test_image_title_22001", Kind: service.SUGGESTION_KIND_TITLE},
+			},
+			wantErr:      false,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（qが空の時）",
+			query:        "q=",
+			want:         []service.Suggestion{},
+			wantErr:      false,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（limitの値が不正な時）",
+			query:        "q=test&limit=-1",
+			wantErr:      true,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/illustrations/suggest?"+tt.query, nil)
+
+			c.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.wantErr {
+				require.NotEmpty(t, w.Body.String())
+				return
+			}
+
+			type wantType struct {
+				Suggestions []service.Suggestion `json:"suggestions"`
+			}
+			var got wantType
+			err := json.Unmarshal(w.Body.Bytes(), &got)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.Suggestions)
+		})
+	}
+}
+
+func TestFetchRandomIllustrations(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	i := illustrationTest{}
+	c := i.setUp(t, config)
+	defer i.tearDown(t, config)
+
+	type responseType struct {
+		Illustrations []model.Illustration `json:"illustrations"`
+		Seed          int64                `json:"seed"`
+	}
+	fetch := func(t *testing.T, query string) (int, responseType) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/illustrations/random?"+query, nil)
+		c.Server.Router.ServeHTTP(w, req)
+
+		var got responseType
+		if w.Code == http.StatusOK {
+			err := json.Unmarshal(w.Body.Bytes(), &got)
+			require.NoError(t, err)
+		}
+		return w.Code, got
+	}
+	ids := func(got responseType) []int64 {
+		ids := []int64{}
+		for _, il := range got.Illustrations {
+			ids = append(ids, il.Image.ID)
+		}
+		return ids
+	}
+
+	t.Run("正常系（同じseedでは同じ順に取得できる）", func(t *testing.T) {
+		code, first := fetch(t, "limit=3")
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, first.Illustrations, 3)
+
+		code, second := fetch(t, fmt.Sprintf("limit=3&seed=%d", first.Seed))
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, first.Seed, second.Seed)
+		require.Equal(t, ids(first), ids(second))
+	})
+
+	t.Run("正常系（同じseedでページングすると重複なく全件を取得できる）", func(t *testing.T) {
+		got := []int64{}
+		for p := 0; p < 3; p++ {
+			code, res := fetch(t, fmt.Sprintf("limit=3&seed=12345&p=%d", p))
+			require.Equal(t, http.StatusOK, code)
+			got = append(got, ids(res)...)
+		}
+		require.ElementsMatch(t, []int64{21001, 999990, 999991, 22001, 23001, 24001, 25001}, got)
+	})
+
+	t.Run("正常系（キャラクターで絞り込み、exclusion_idを除外）", func(t *testing.T) {
+		code, res := fetch(t, "characters[]=21001&exclusion_id=999990&weighted=true")
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, []int64{999991}, ids(res))
+	})
+
+	t.Run("異常系（limitの値が不正な時）", func(t *testing.T) {
+		code, _ := fetch(t, "limit=-1")
+		require.Equal(t, http.StatusBadRequest, code)
+	})
+}
+
+func TestGetDailyIllustration(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	i := illustrationTest{}
+	c := i.setUp(t, config)
+	defer i.tearDown(t, config)
+
+	fetch := func(t *testing.T) model.Illustration {
+		// 前回のリクエストで保存されたキャッシュを削除し、DBから取得する
+		err := c.Server.RedisClient.Del(context.Background(), []string{cache.DailyIllustrationPrefix + "*"})
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/illustrations/daily", nil)
+		c.Server.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		type wantType struct {
+			Illustration model.Illustration `json:"illustration"`
+		}
+		var got wantType
+		err = json.Unmarshal(w.Body.Bytes(), &got)
+		require.NoError(t, err)
+		return got.Illustration
+	}
+
+	t.Run("正常系（同じ日は同じイラストを取得）", func(t *testing.T) {
+		first := fetch(t)
+		require.NotZero(t, first.Image.ID)
+
+		second := fetch(t)
+		require.Equal(t, first.Image.ID, second.Image.ID)
+	})
+
+	t.Run("正常系（管理者が指定したイラストを取得）", func(t *testing.T) {
+		_, err := c.Server.IllustrationService.SetDailyOverride(context.Background(), time.Now(), 25001)
+		require.NoError(t, err)
+
+		got := fetch(t)
+		require.Equal(t, int64(25001), got.Image.ID)
+	})
+}
+
+func TestListRelatedIllustrations(t *testing.T) {
+	config, err := util.LoadConfig(AppEnvPath)
+	if err != nil {
+		log.Fatal("cannot load config :", err)
+	}
+	i := illustrationTest{}
+	c := i.setUp(t, config)
+	defer i.tearDown(t, config)
+
+	// 前回のテストで保存された関連イラストのキャッシュを削除
+	err = c.Server.RedisClient.Del(context.Background(), []string{cache.RelatedIllustrationsPrefix + "*"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		id           string
+		query        string
+		wantFirstID  int64
+		wantLen      int
+		wantErr      bool
+		expectedCode int
+	}{
+		{
+			name:         "正常系（キャラクター・カテゴリが共通するイラストを取得）",
+			id:           "999990",
+			query:        "limit=1",
+			wantFirstID:  999991,
+			wantLen:      1,
+			wantErr:      false,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "正常系（関連するイラストが足りない場合はランダムなイラストで補う）",
+			id:           "999990",
+			query:        "limit=3",
+			wantFirstID:  999991,
+			wantLen:      3,
+			wantErr:      false,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系（存在しないidの時）",
+			id:           "999999",
+			wantErr:      true,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "異常系（idの値が不正な時）",
+			id:           "aaa",
+			wantErr:      true,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/illustrations/"+tt.id+"/related?"+tt.query, nil)
+
+			c.Server.Router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.wantErr {
+				require.NotEmpty(t, w.Body.String())
+				return
+			}
+
+			type wantType struct {
+				Illustrations []model.Illustration `json:"illustrations"`
+			}
+			var got wantType
+			err := json.Unmarshal(w.Body.Bytes(), &got)
+			require.NoError(t, err)
+			require.Len(t, got.Illustrations, tt.wantLen)
+			require.Equal(t, tt.wantFirstID, got.Illustrations[0].Image.ID)
+			for _, il := range got.Illustrations {
+				require.NotEqual(t, int64(999990), il.Image.ID)
+			}
+		})
+	}
+}
+
 func TestListIllustrationsByCharacterID(t *testing.T) {
 	config, err := util.LoadConfig(AppEnvPath)
 	if err != nil {
@@ -716,6 +1329,7 @@ func newTestServer(store *db.Store, config util.Config) (*app.AppContext, error)
 		RedisClient: rdb,
 		Logger:      logger,
 	}
+	s.SetServices(service.NewGCSStorageService(config))
 	router := gin.Default()
 	s.Router = router
 	api.SetUserRouters(s)
@@ -812,6 +1426,11 @@ func (i illustrationTest) setUp(t *testing.T, config util.Config) *app.AppContex
 	if err != nil {
 		t.Fatalf("Failed to create server: %v", err)
 	}
+
+	// 直接投入したイラストを検索対象にする
+	if _, err := s.Server.IllustrationService.IndexMissingSearchDocuments(context.Background()); err != nil {
+		t.Fatalf("Failed to index search documents: %v", err)
+	}
 	return s
 }
 
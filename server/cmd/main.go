@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"shin-monta-no-mori/api"
 	"shin-monta-no-mori/internal/app"
 	"shin-monta-no-mori/internal/cache"
 	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/internal/domains/service"
 	"shin-monta-no-mori/pkg/lib/logger"
 	"shin-monta-no-mori/pkg/token"
 	"shin-monta-no-mori/pkg/util"
@@ -14,6 +16,7 @@ import (
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"go.uber.org/zap"
 
 	_ "github.com/lib/pq"
 )
@@ -49,6 +52,28 @@ func main() {
 	logger := logger.New()
 	server := app.NewServer(config, store, rdb, logger, token)
 	server.Router.Use(app.CORSMiddleware(config))
+	// 設定したプロキシが付与したヘッダーのみを信頼し、クライアントのIPを取得する
+	if err := server.Router.SetTrustedProxies(config.TrustedProxies); err != nil {
+		log.Fatal("cannot set trusted proxies : ", err)
+	}
+
+	// 検索用ドキュメントが未作成のイラストをインデックス
+	indexed, err := server.IllustrationService.IndexMissingSearchDocuments(context.Background())
+	if err != nil {
+		logger.Warn("failed to IndexMissingSearchDocuments", zap.Error(err))
+	} else if indexed > 0 {
+		logger.Info("indexed search documents", zap.Int("count", indexed))
+	}
+
+	// 保持期間を過ぎたゴミ箱のデータを定期的に完全に削除
+	server.TrashService.StartPurgeJob(
+		context.Background(),
+		service.TrashRetention(config.TrashRetentionDays),
+		config.TrashPurgeInterval,
+	)
+
+	// 溜めたイラストの閲覧数を定期的にDBに書き込む
+	server.ViewCountService.StartFlushJob(context.Background(), config.ViewCountFlushInterval)
 
 	// Userサイドのルート設定
 	api.SetUserRouters(server)
@@ -1,9 +1,14 @@
 package app
 
 import (
+	"shin-monta-no-mori/pkg/token"
+
 	"github.com/gin-gonic/gin"
 )
 
+// AuthorizationPayloadKey は認証済みトークンのペイロードを保存するコンテキストのキー
+const AuthorizationPayloadKey = "authorization_payload"
+
 // AppContext は gin.Context を拡張したコンテキスト
 type AppContext struct {
 	*gin.Context
@@ -18,7 +23,13 @@ func NewAppContext(c *gin.Context, s *Server) *AppContext {
 	}
 }
 
-// ErrorResponse はエラーレスポンスを生成
-func ErrorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
+// AuthPayload は認証ミドルウェアが保存したトークンのペイロードを返す
+// 認証が不要なエンドポイントではnilを返す
+func (ctx *AppContext) AuthPayload() *token.Payload {
+	payload, ok := ctx.Get(AuthorizationPayloadKey)
+	if !ok {
+		return nil
+	}
+	p, _ := payload.(*token.Payload)
+	return p
 }
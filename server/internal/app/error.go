@@ -0,0 +1,60 @@
+package app
+
+import (
+	"shin-monta-no-mori/pkg/lib/apperror"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Fail はエラーコードに対応するHTTPステータスでエラーレスポンスを返す
+// 原因のエラーはログにのみ出力し、レスポンスにはエラーコードと利用者向けのメッセージのみを含める
+func (ctx *AppContext) Fail(code apperror.Code, err error, details ...apperror.Detail) {
+	ctx.logFailure(code, err)
+	respond(ctx.Context, apperror.New(code, err, details...))
+}
+
+// FailWith はエラーレスポンスに他の項目を加えて返す
+// 競合時に現在の状態を返す場合など、クライアントが次の操作に必要な情報を含める場合に使用する
+func (ctx *AppContext) FailWith(code apperror.Code, err error, extra gin.H) {
+	ctx.logFailure(code, err)
+
+	lang := apperror.LangFromAcceptLanguage(ctx.GetHeader("Accept-Language"))
+	body := gin.H{"error": apperror.New(code, err).Response(lang).Error}
+	for k, v := range extra {
+		body[k] = v
+	}
+	ctx.JSON(code.Status(), body)
+}
+
+// logFailure はエラーレスポンスの原因をログに出力する
+// ハンドラではログを出力せず、エラーの出力はここに集約する
+func (ctx *AppContext) logFailure(code apperror.Code, err error) {
+	fields := []zap.Field{
+		zap.String("code", string(code)),
+		zap.String("method", ctx.Request.Method),
+		zap.String("path", ctx.FullPath()),
+		zap.String("uri", ctx.Request.URL.RequestURI()),
+		zap.Error(err),
+	}
+	if code == apperror.Internal {
+		ctx.Server.Logger.Error("request failed", fields...)
+		return
+	}
+	ctx.Server.Logger.Info("request failed", fields...)
+}
+
+// AbortWithError はAppContextを持たないミドルウェアなどでエラーレスポンスを返し、以降のハンドラを中断する
+// 原因のエラーはginのコンテキストに記録し、アクセスログに出力する
+func AbortWithError(c *gin.Context, code apperror.Code, err error, details ...apperror.Detail) {
+	if err != nil {
+		_ = c.Error(err)
+	}
+	respond(c, apperror.New(code, err, details...))
+	c.Abort()
+}
+
+func respond(c *gin.Context, appErr *apperror.Error) {
+	lang := apperror.LangFromAcceptLanguage(c.GetHeader("Accept-Language"))
+	c.JSON(appErr.Code.Status(), appErr.Response(lang))
+}
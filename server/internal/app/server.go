@@ -6,6 +6,7 @@ import (
 
 	"shin-monta-no-mori/internal/cache"
 	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/internal/domains/service"
 	"shin-monta-no-mori/pkg/lib/logger"
 	"shin-monta-no-mori/pkg/token"
 	"shin-monta-no-mori/pkg/util"
@@ -21,6 +22,19 @@ type Server struct {
 	RedisClient cache.RedisClient
 	Logger      logger.Logger
 	TokenMaker  token.Maker
+
+	// ユースケース層のサービス
+	IllustrationService  *service.IllustrationService
+	CharacterService     *service.CharacterService
+	CategoryService      *service.CategoryService
+	SynonymService       *service.SynonymService
+	SearchLogService     *service.SearchLogService
+	TrashService         *service.TrashService
+	AuditLogService      *service.AuditLogService
+	AuthService          *service.AuthService
+	LoginThrottleService *service.LoginThrottleService
+	OperatorService      *service.OperatorService
+	ViewCountService     *service.ViewCountService
 }
 
 // NewServer は新しいサーバーインスタンスを作成
@@ -33,6 +47,7 @@ func NewServer(config util.Config, store *db.Store, redis cache.RedisClient, log
 		Logger:      logger,
 		TokenMaker:  tokenMaker,
 	}
+	server.SetServices(service.NewGCSStorageService(config))
 
 	router := gin.Default()
 	server.Router = router
@@ -40,13 +55,29 @@ func NewServer(config util.Config, store *db.Store, redis cache.RedisClient, log
 	return server
 }
 
+// SetServices はストレージを共有するユースケース層のサービスを設定する
+func (server *Server) SetServices(storage service.StorageService) {
+	synonyms := service.NewSynonymDictionary(server.Store, server.RedisClient, server.Logger)
+	server.IllustrationService = service.NewIllustrationService(server.Store, storage, synonyms)
+	server.CharacterService = service.NewCharacterService(server.Store, storage, synonyms)
+	server.CategoryService = service.NewCategoryService(server.Store, storage, synonyms)
+	server.SynonymService = service.NewSynonymService(server.Store)
+	server.SearchLogService = service.NewSearchLogService(server.Store, server.Logger)
+	server.TrashService = service.NewTrashService(server.Store, storage, server.Logger)
+	server.AuditLogService = service.NewAuditLogService(server.Store)
+	server.AuthService = service.NewAuthService(server.Store, server.TokenMaker, server.Config)
+	server.LoginThrottleService = service.NewLoginThrottleService(server.RedisClient, server.Logger)
+	server.OperatorService = service.NewOperatorService(server.Store)
+	server.ViewCountService = service.NewViewCountService(server.Store, server.Logger)
+}
+
 func CORSMiddleware(config util.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		origin := config.Origin
 		c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE, UPDATE")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, Authorization")
-		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, PATCH, DELETE, UPDATE")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, Authorization, If-Match")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length, ETag, Retry-After")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 
 		if c.Request.Method == http.MethodOptions {
@@ -18,6 +18,8 @@ type RedisClient interface {
 	Get(ctx context.Context, key string, i interface{}) error
 	Set(ctx context.Context, key string, i interface{}, expiration time.Duration) error
 	Del(ctx context.Context, key []string) error
+	DelKeys(ctx context.Context, keys []string) error
+	Incr(ctx context.Context, key string, expiration time.Duration) (int64, error)
 }
 
 // RedisContext は、Redis クライアントを管理する構造体です。
@@ -94,3 +96,34 @@ func (r *RedisContext) Del(ctx context.Context, patterns []string) error {
 
 	return nil
 }
+
+// DelKeys は、指定されたキーを Redis から削除します。
+// Del と異なりキーをパターンとして扱わないため、利用者の入力を含むキーの削除に使用します。
+func (r *RedisContext) DelKeys(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if err := r.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to delete keys %s: %w", strings.Join(keys, ", "), err)
+	}
+
+	return nil
+}
+
+// Incr は、キーの値を1増やし、増やした後の値を返します。
+// キーに有効期間が設定されていない場合は、有効期間を設定します。
+// 有効期間のないキーが残らないよう、INCR と EXPIRE は1つのトランザクションで実行します。
+func (r *RedisContext) Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.ExpireNX(ctx, key, expiration)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to incr data in Redis: %w", err)
+	}
+
+	return incr.Val(), nil
+}
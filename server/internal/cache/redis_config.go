@@ -16,9 +16,21 @@ const (
 	illustrationGetKey = "illustration_%d"
 
 	IllustrationsPrefix               = "illustrations_list"
-	illustrationsListKey              = IllustrationsPrefix + "_offset_%d"
-	illustrationsListByCharacterIDKey = IllustrationsPrefix + "_by_character_%d_%d"
-	illustrationsListByCategoryIDKey  = IllustrationsPrefix + "_by_category_%d_%d"
+	illustrationsListKey              = IllustrationsPrefix + "_%s_offset_%d"
+	illustrationsListByCharacterIDKey = IllustrationsPrefix + "_by_character_%d_%s_%d"
+	illustrationsListByCategoryIDKey  = IllustrationsPrefix + "_by_category_%d_%s_%d"
+
+	illustrationsListCursorKey              = IllustrationsPrefix + "_%s_cursor_%s"
+	illustrationsListByCharacterIDCursorKey = IllustrationsPrefix + "_by_character_%d_%s_cursor_%s"
+	illustrationsListByCategoryIDCursorKey  = IllustrationsPrefix + "_by_category_%d_%s_cursor_%s"
+
+	// 関連イラスト
+	RelatedIllustrationsPrefix = "related_illustrations"
+	relatedIllustrationsKey    = RelatedIllustrationsPrefix + "_%d_%d"
+
+	// 今日のイラスト
+	DailyIllustrationPrefix = "daily_illustration"
+	dailyIllustrationKey    = DailyIllustrationPrefix + "_%s"
 
 	// カテゴリ
 	CategoriesPrefix     = "categories_list"
@@ -27,22 +39,63 @@ const (
 	// キャラクター
 	CharactersPrefix     = "characters_list"
 	charactersListAllKey = CharactersPrefix + "_all"
+
+	// 検索語の展開に使用する同義語辞書
+	SynonymsKey = "synonyms"
+
+	// 検索候補
+	SuggestionsPrefix = "suggestions"
+	suggestionsKey    = SuggestionsPrefix + "_%d_%s"
+
+	// アクセストークンのセッションが無効化されているか
+	SessionBlockedPrefix = "session_blocked"
+	sessionBlockedKey    = SessionBlockedPrefix + "_%s"
+
+	// ログインの試行回数・ロック
+	LoginThrottlePrefix  = "login_throttle"
+	loginRateByIPKey     = LoginThrottlePrefix + "_rate_ip_%s"
+	loginRateByEmailKey  = LoginThrottlePrefix + "_rate_email_%s"
+	loginFailuresKey     = LoginThrottlePrefix + "_failures_%s"
+	loginLockoutCountKey = LoginThrottlePrefix + "_lockouts_%s"
+	loginLockedUntilKey  = LoginThrottlePrefix + "_locked_until_%s"
 )
 
-func GetIllustrationsListKey(offset int) string {
-	return fmt.Sprintf(illustrationsListKey, offset)
+func GetIllustrationsListKey(sort string, offset int) string {
+	return fmt.Sprintf(illustrationsListKey, sort, offset)
 }
 
 func GetIllustrationKey(id int) string {
 	return fmt.Sprintf(illustrationGetKey, id)
 }
 
-func GetIllustrationsListByCharacterKey(id, offset int) string {
-	return fmt.Sprintf(illustrationsListByCharacterIDKey, id, offset)
+func GetIllustrationsListByCharacterKey(id int, sort string, offset int) string {
+	return fmt.Sprintf(illustrationsListByCharacterIDKey, id, sort, offset)
+}
+
+func GetIllustrationsListByCategoryKey(id int, sort string, offset int) string {
+	return fmt.Sprintf(illustrationsListByCategoryIDKey, id, sort, offset)
+}
+
+// GetIllustrationsListCursorKey はエンコードしたカーソルをキーに含める
+// IDだけでは、並び順の値が異なるカーソルで同じキャッシュを返してしまう
+func GetIllustrationsListCursorKey(sort string, encodedCursor string) string {
+	return fmt.Sprintf(illustrationsListCursorKey, sort, encodedCursor)
+}
+
+func GetIllustrationsListByCharacterCursorKey(id int, sort string, encodedCursor string) string {
+	return fmt.Sprintf(illustrationsListByCharacterIDCursorKey, id, sort, encodedCursor)
+}
+
+func GetIllustrationsListByCategoryCursorKey(id int, sort string, encodedCursor string) string {
+	return fmt.Sprintf(illustrationsListByCategoryIDCursorKey, id, sort, encodedCursor)
 }
 
-func GetIllustrationsListByCategoryKey(id, offset int) string {
-	return fmt.Sprintf(illustrationsListByCategoryIDKey, id, offset)
+func GetRelatedIllustrationsKey(id, limit int) string {
+	return fmt.Sprintf(relatedIllustrationsKey, id, limit)
+}
+
+func GetDailyIllustrationKey(date string) string {
+	return fmt.Sprintf(dailyIllustrationKey, date)
 }
 
 func GetCategoriesAllKey() string {
@@ -52,3 +105,31 @@ func GetCategoriesAllKey() string {
 func GetCharactersAllKey() string {
 	return charactersListAllKey
 }
+
+func GetSuggestionsKey(prefix string, limit int) string {
+	return fmt.Sprintf(suggestionsKey, limit, prefix)
+}
+
+func GetSessionBlockedKey(accessTokenID string) string {
+	return fmt.Sprintf(sessionBlockedKey, accessTokenID)
+}
+
+func GetLoginRateByIPKey(ip string) string {
+	return fmt.Sprintf(loginRateByIPKey, ip)
+}
+
+func GetLoginRateByEmailKey(email string) string {
+	return fmt.Sprintf(loginRateByEmailKey, email)
+}
+
+func GetLoginFailuresKey(email string) string {
+	return fmt.Sprintf(loginFailuresKey, email)
+}
+
+func GetLoginLockoutCountKey(email string) string {
+	return fmt.Sprintf(loginLockoutCountKey, email)
+}
+
+func GetLoginLockedUntilKey(email string) string {
+	return fmt.Sprintf(loginLockedUntilKey, email)
+}
@@ -0,0 +1,12 @@
+CREATE TABLE "image_search_documents" (
+  "image_id" bigint PRIMARY KEY,
+  "search_vector" tsvector NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "image_search_documents" USING GIN ("search_vector");
+
+ALTER TABLE
+  "image_search_documents"
+ADD
+  FOREIGN KEY ("image_id") REFERENCES "images" ("id") ON DELETE CASCADE;
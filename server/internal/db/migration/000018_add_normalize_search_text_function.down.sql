@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS normalize_search_text(text);
@@ -0,0 +1,15 @@
+-- 検索時の表記揺れを吸収するため、全角・半角をNFKCで統一し、カタカナをひらがなに、英字を小文字に変換する
+-- textsearch.Normalize と同じ変換を行う
+CREATE OR REPLACE FUNCTION normalize_search_text(t text) RETURNS text AS $$
+SELECT lower(
+    translate(
+      normalize(t, NFKC),
+      'ァアィイゥウェエォオカガキギクグケゲコゴサザシジスズセゼソゾタダチヂッツヅテデトドナニヌネノハバパヒビピフブプヘベペホボポマミムメモャヤュユョヨラリルレロヮワヰヱヲンヴヵヶヽヾ',
+      'ぁあぃいぅうぇえぉおかがきぎくぐけげこごさざしじすずせぜそぞただちぢっつづてでとどなにぬねのはばぱひびぴふぶぷへべぺほぼぽまみむめもゃやゅゆょよらりるれろゎわゐゑをんゔゕゖゝゞ'
+    )
+  );
+$$ LANGUAGE sql IMMUTABLE STRICT;
+
+-- 正規化したトークンで作り直すため、既存の検索用ドキュメントを削除する
+-- 削除したドキュメントはサーバー起動時に再作成される
+DELETE FROM "image_search_documents";
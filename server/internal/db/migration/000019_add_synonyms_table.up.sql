@@ -0,0 +1,9 @@
+CREATE TABLE "synonyms" (
+  "id" bigserial PRIMARY KEY,
+  "word" varchar NOT NULL,
+  "synonym" varchar NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "synonyms" ("word", "synonym");
@@ -0,0 +1,10 @@
+CREATE TABLE "search_logs" (
+  "id" bigserial PRIMARY KEY,
+  "query" varchar NOT NULL,
+  "result_count" bigint NOT NULL,
+  "searched_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "search_logs" ("searched_at");
+
+CREATE INDEX ON "search_logs" ("query");
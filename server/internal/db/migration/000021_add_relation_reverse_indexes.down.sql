@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "image_characters_relations_character_id_idx";
+
+DROP INDEX IF EXISTS "image_child_categories_relations_child_category_id_idx";
+
+DROP INDEX IF EXISTS "image_parent_categories_relations_parent_category_id_idx";
@@ -0,0 +1,5 @@
+CREATE INDEX ON "image_characters_relations" ("character_id");
+
+CREATE INDEX ON "image_child_categories_relations" ("child_category_id");
+
+CREATE INDEX ON "image_parent_categories_relations" ("parent_category_id");
@@ -0,0 +1,6 @@
+ALTER TABLE "images"
+ADD COLUMN "random_key" double precision NOT NULL DEFAULT random();
+
+COMMENT ON COLUMN "images"."random_key" IS 'ランダムに抽出する際の並び順.インデックスを使って全件のソートを避けるために使用する.';
+
+CREATE INDEX ON "images" ("random_key");
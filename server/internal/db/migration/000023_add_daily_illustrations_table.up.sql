@@ -0,0 +1,16 @@
+CREATE TABLE "daily_illustrations" (
+  "date" date PRIMARY KEY,
+  "image_id" bigint NOT NULL,
+  "is_override" boolean NOT NULL DEFAULT false,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "daily_illustrations"."date" IS '日本時間の日付';
+
+COMMENT ON COLUMN "daily_illustrations"."is_override" IS '管理者が指定したイラストの場合はtrue';
+
+CREATE INDEX ON "daily_illustrations" ("image_id");
+
+ALTER TABLE "daily_illustrations"
+ADD FOREIGN KEY ("image_id") REFERENCES "images" ("id") ON DELETE CASCADE;
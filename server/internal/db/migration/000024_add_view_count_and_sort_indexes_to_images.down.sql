@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS "image_child_categories_relations_child_category_id_image_id_idx";
+
+DROP INDEX IF EXISTS "image_characters_relations_character_id_image_id_idx";
+
+DROP INDEX IF EXISTS "images_view_count_id_idx";
+
+DROP INDEX IF EXISTS "images_updated_at_id_idx";
+
+DROP INDEX IF EXISTS "images_title_id_idx";
+
+ALTER TABLE "images" DROP COLUMN IF EXISTS "view_count";
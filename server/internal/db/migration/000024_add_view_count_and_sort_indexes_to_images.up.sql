@@ -0,0 +1,14 @@
+ALTER TABLE "images"
+ADD COLUMN "view_count" bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "images"."view_count" IS '閲覧数.人気順の並び替えに使用する.';
+
+CREATE INDEX ON "images" ("title", "id");
+
+CREATE INDEX ON "images" ("updated_at", "id");
+
+CREATE INDEX ON "images" ("view_count", "id");
+
+CREATE INDEX ON "image_characters_relations" ("character_id", "image_id");
+
+CREATE INDEX ON "image_child_categories_relations" ("child_category_id", "image_id");
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "image_child_categories_relations_child_category_id_position_idx";
+
+DROP INDEX IF EXISTS "image_characters_relations_character_id_position_idx";
+
+ALTER TABLE "image_child_categories_relations" DROP COLUMN IF EXISTS "position";
+
+ALTER TABLE "image_characters_relations" DROP COLUMN IF EXISTS "position";
@@ -0,0 +1,13 @@
+ALTER TABLE "image_characters_relations"
+ADD COLUMN "position" integer;
+
+COMMENT ON COLUMN "image_characters_relations"."position" IS 'キャラクターごとのイラスト一覧での表示順.NULLの場合は並び順が指定されたイラストの後に新しい順で並べる.';
+
+ALTER TABLE "image_child_categories_relations"
+ADD COLUMN "position" integer;
+
+COMMENT ON COLUMN "image_child_categories_relations"."position" IS '子カテゴリごとのイラスト一覧での表示順.NULLの場合は並び順が指定されたイラストの後に新しい順で並べる.';
+
+CREATE INDEX ON "image_characters_relations" ("character_id", "position");
+
+CREATE INDEX ON "image_child_categories_relations" ("child_category_id", "position");
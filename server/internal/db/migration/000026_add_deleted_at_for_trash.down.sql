@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS "parent_categories_deleted_at_idx";
+
+DROP INDEX IF EXISTS "characters_deleted_at_idx";
+
+DROP INDEX IF EXISTS "images_deleted_at_idx";
+
+ALTER TABLE "child_categories" DROP COLUMN IF EXISTS "deleted_at";
+
+ALTER TABLE "parent_categories" DROP COLUMN IF EXISTS "deleted_at";
+
+ALTER TABLE "characters" DROP COLUMN IF EXISTS "deleted_at";
+
+ALTER TABLE "images" DROP COLUMN IF EXISTS "deleted_at";
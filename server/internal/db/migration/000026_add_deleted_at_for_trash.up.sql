@@ -0,0 +1,28 @@
+ALTER TABLE "images"
+ADD COLUMN "deleted_at" timestamptz;
+
+COMMENT ON COLUMN "images"."deleted_at" IS 'ゴミ箱に移動した日時.NULLでない場合は公開しない.保持期間を過ぎると完全に削除する.';
+
+ALTER TABLE "characters"
+ADD COLUMN "deleted_at" timestamptz;
+
+COMMENT ON COLUMN "characters"."deleted_at" IS 'ゴミ箱に移動した日時.NULLでない場合は公開しない.保持期間を過ぎると完全に削除する.';
+
+ALTER TABLE "parent_categories"
+ADD COLUMN "deleted_at" timestamptz;
+
+COMMENT ON COLUMN "parent_categories"."deleted_at" IS 'ゴミ箱に移動した日時.NULLでない場合は公開しない.保持期間を過ぎると完全に削除する.';
+
+ALTER TABLE "child_categories"
+ADD COLUMN "deleted_at" timestamptz;
+
+COMMENT ON COLUMN "child_categories"."deleted_at" IS '親カテゴリと合わせてゴミ箱に移動した日時.NULLでない場合は公開しない.';
+
+CREATE INDEX ON "images" ("deleted_at")
+WHERE "deleted_at" IS NOT NULL;
+
+CREATE INDEX ON "characters" ("deleted_at")
+WHERE "deleted_at" IS NOT NULL;
+
+CREATE INDEX ON "parent_categories" ("deleted_at")
+WHERE "deleted_at" IS NOT NULL;
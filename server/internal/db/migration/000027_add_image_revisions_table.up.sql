@@ -0,0 +1,23 @@
+CREATE TABLE "image_revisions" (
+  "id" bigserial PRIMARY KEY,
+  "image_id" bigint NOT NULL,
+  "title" varchar NOT NULL,
+  "original_src" varchar NOT NULL,
+  "simple_src" varchar,
+  "original_filename" varchar NOT NULL,
+  "simple_filename" varchar,
+  "character_ids" bigint [] NOT NULL DEFAULT '{}',
+  "parent_category_ids" bigint [] NOT NULL DEFAULT '{}',
+  "child_category_ids" bigint [] NOT NULL DEFAULT '{}',
+  "operator_name" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE "image_revisions" IS 'イラスト編集前のメタデータのスナップショット.';
+
+COMMENT ON COLUMN "image_revisions"."operator_name" IS '編集したオペレーター.オペレーターの変更・削除後も履歴を残すため外部キーにしない.';
+
+ALTER TABLE "image_revisions"
+ADD FOREIGN KEY ("image_id") REFERENCES "images" ("id") ON DELETE CASCADE;
+
+CREATE INDEX ON "image_revisions" ("image_id", "id");
@@ -0,0 +1,25 @@
+CREATE TABLE "audit_logs" (
+  "id" bigserial PRIMARY KEY,
+  "operator_name" varchar NOT NULL,
+  "action" varchar NOT NULL,
+  "entity_type" varchar NOT NULL,
+  "entity_id" varchar NOT NULL,
+  "diff" jsonb NOT NULL DEFAULT '{}',
+  "client_ip" varchar NOT NULL,
+  "user_agent" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE "audit_logs" IS '管理画面での操作履歴.操作と同じトランザクションで記録する.';
+
+COMMENT ON COLUMN "audit_logs"."operator_name" IS '操作したオペレーター.オペレーターの変更・削除後も履歴を残すため外部キーにしない.';
+
+COMMENT ON COLUMN "audit_logs"."entity_id" IS '操作対象のID.日替わりイラストの場合は日付、一括の並び替えの場合は空文字.';
+
+COMMENT ON COLUMN "audit_logs"."diff" IS '変更された項目ごとの変更前(before)と変更後(after)の値.';
+
+CREATE INDEX ON "audit_logs" ("created_at");
+
+CREATE INDEX ON "audit_logs" ("operator_name", "created_at");
+
+CREATE INDEX ON "audit_logs" ("entity_type", "entity_id", "created_at");
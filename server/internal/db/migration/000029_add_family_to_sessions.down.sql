@@ -0,0 +1,5 @@
+ALTER TABLE "sessions"
+DROP COLUMN IF EXISTS "rotated_at";
+
+ALTER TABLE "sessions"
+DROP COLUMN IF EXISTS "family_id";
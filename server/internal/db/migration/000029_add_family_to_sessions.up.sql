@@ -0,0 +1,16 @@
+ALTER TABLE "sessions"
+ADD COLUMN "family_id" uuid;
+
+UPDATE "sessions" SET "family_id" = "id";
+
+ALTER TABLE "sessions"
+ALTER COLUMN "family_id" SET NOT NULL;
+
+ALTER TABLE "sessions"
+ADD COLUMN "rotated_at" timestamptz;
+
+COMMENT ON COLUMN "sessions"."family_id" IS 'ログイン時のセッションのID.リフレッシュトークンを更新して作成したセッションも同じ値を持つ.';
+
+COMMENT ON COLUMN "sessions"."rotated_at" IS 'リフレッシュトークンを更新した日時.更新済みのリフレッシュトークンが再度使用された場合は漏洩とみなす.';
+
+CREATE INDEX ON "sessions" ("family_id");
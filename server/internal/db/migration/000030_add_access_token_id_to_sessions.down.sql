@@ -0,0 +1,2 @@
+ALTER TABLE "sessions"
+DROP COLUMN IF EXISTS "access_token_id";
@@ -0,0 +1,6 @@
+ALTER TABLE "sessions"
+ADD COLUMN "access_token_id" uuid;
+
+COMMENT ON COLUMN "sessions"."access_token_id" IS 'セッションと同時に発行したアクセストークンのID.セッションが無効化された場合はアクセストークンも拒否する.';
+
+CREATE UNIQUE INDEX ON "sessions" ("access_token_id");
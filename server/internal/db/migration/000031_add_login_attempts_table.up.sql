@@ -0,0 +1,24 @@
+CREATE TABLE "login_attempts" (
+  "id" bigserial PRIMARY KEY,
+  "email" varchar NOT NULL,
+  "operator_name" varchar NOT NULL DEFAULT '',
+  "succeeded" boolean NOT NULL,
+  "failure_reason" varchar NOT NULL DEFAULT '',
+  "client_ip" varchar NOT NULL,
+  "user_agent" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE "login_attempts" IS '管理画面へのログインの履歴.失敗したログインも記録する.';
+
+COMMENT ON COLUMN "login_attempts"."email" IS 'ログインに使用されたメールアドレス.登録されていないメールアドレスも記録する.';
+
+COMMENT ON COLUMN "login_attempts"."operator_name" IS 'メールアドレスに対応するオペレーター.登録されていないメールアドレスの場合は空文字.オペレーターの変更・削除後も履歴を残すため外部キーにしない.';
+
+COMMENT ON COLUMN "login_attempts"."failure_reason" IS '失敗した理由(unknown_email, wrong_password).成功した場合は空文字.';
+
+CREATE INDEX ON "login_attempts" ("created_at");
+
+CREATE INDEX ON "login_attempts" ("operator_name", "created_at");
+
+CREATE INDEX ON "login_attempts" ("email", "created_at");
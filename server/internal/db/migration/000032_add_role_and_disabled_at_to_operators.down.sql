@@ -0,0 +1,8 @@
+ALTER TABLE "operators"
+DROP COLUMN IF EXISTS "disabled_at";
+
+ALTER TABLE "operators"
+DROP CONSTRAINT IF EXISTS "operators_role_check";
+
+ALTER TABLE "operators"
+DROP COLUMN IF EXISTS "role";
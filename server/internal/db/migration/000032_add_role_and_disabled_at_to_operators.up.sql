@@ -0,0 +1,15 @@
+ALTER TABLE "operators"
+ADD COLUMN "role" varchar NOT NULL DEFAULT 'viewer';
+
+-- 既存のオペレーターは、これまでと同じくすべての操作ができるようにする
+UPDATE "operators" SET "role" = 'owner';
+
+ALTER TABLE "operators"
+ADD CONSTRAINT "operators_role_check" CHECK ("role" IN ('owner', 'editor', 'viewer'));
+
+ALTER TABLE "operators"
+ADD COLUMN "disabled_at" timestamptz;
+
+COMMENT ON COLUMN "operators"."role" IS '管理画面での権限(owner: オペレーターの管理を含むすべての操作, editor: コンテンツの閲覧と編集, viewer: コンテンツの閲覧のみ).';
+
+COMMENT ON COLUMN "operators"."disabled_at" IS '無効化した日時.無効化されたオペレーターはログインできない.';
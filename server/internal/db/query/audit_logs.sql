@@ -0,0 +1,64 @@
+-- name: CreateAuditLog :exec
+INSERT INTO audit_logs (
+    operator_name,
+    action,
+    entity_type,
+    entity_id,
+    diff,
+    client_ip,
+    user_agent
+  )
+VALUES (
+    sqlc.arg(operator_name),
+    sqlc.arg(action),
+    sqlc.arg(entity_type),
+    sqlc.arg(entity_id),
+    sqlc.arg(diff),
+    sqlc.arg(client_ip),
+    sqlc.arg(user_agent)
+  );
+-- name: ListAuditLogs :many
+SELECT *
+FROM audit_logs
+WHERE (
+    sqlc.arg(operator_name)::text = ''
+    OR operator_name = sqlc.arg(operator_name)::text
+  )
+  AND (
+    sqlc.arg(action)::text = ''
+    OR action = sqlc.arg(action)::text
+  )
+  AND (
+    sqlc.arg(entity_type)::text = ''
+    OR entity_type = sqlc.arg(entity_type)::text
+  )
+  AND (
+    sqlc.arg(entity_id)::text = ''
+    OR entity_id = sqlc.arg(entity_id)::text
+  )
+  AND created_at >= sqlc.arg(from_time)
+  AND created_at < sqlc.arg(to_time)
+ORDER BY created_at DESC,
+  id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+-- name: CountAuditLogs :one
+SELECT count(*)
+FROM audit_logs
+WHERE (
+    sqlc.arg(operator_name)::text = ''
+    OR operator_name = sqlc.arg(operator_name)::text
+  )
+  AND (
+    sqlc.arg(action)::text = ''
+    OR action = sqlc.arg(action)::text
+  )
+  AND (
+    sqlc.arg(entity_type)::text = ''
+    OR entity_type = sqlc.arg(entity_type)::text
+  )
+  AND (
+    sqlc.arg(entity_id)::text = ''
+    OR entity_id = sqlc.arg(entity_id)::text
+  )
+  AND created_at >= sqlc.arg(from_time)
+  AND created_at < sqlc.arg(to_time);
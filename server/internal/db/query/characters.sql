@@ -6,25 +6,43 @@ RETURNING *;
 SELECT *
 FROM characters
 WHERE id = $1
+  AND deleted_at IS NULL
 LIMIT 1;
+-- name: GetCharacterForUpdate :one
+SELECT *
+FROM characters
+WHERE id = $1
+  AND deleted_at IS NULL
+LIMIT 1 FOR UPDATE;
 -- name: ListCharacters :many
 SELECT *
 FROM characters
+WHERE deleted_at IS NULL
 ORDER BY id DESC
 LIMIT $1 OFFSET $2;
+-- name: ListCharactersByCursor :many
+SELECT *
+FROM characters
+WHERE deleted_at IS NULL
+  AND id < sqlc.arg(cursor_id)
+ORDER BY id DESC
+LIMIT $1;
 -- name: ListAllCharacters :many
 SELECT *
 FROM characters
+WHERE deleted_at IS NULL
 ORDER BY priority_level DESC,
   id DESC;
 -- name: UpdateCharacter :one
+-- updated_atが一致しない場合は他の操作で更新済みのため、更新せずに0件を返す
 UPDATE characters
-SET name = $2,
-  src = $3,
-  filename = $4,
-  updated_at = $5,
-  priority_level = $6
-WHERE id = $1
+SET name = sqlc.arg(name),
+  src = sqlc.arg(src),
+  filename = sqlc.arg(filename),
+  updated_at = sqlc.arg(updated_at),
+  priority_level = sqlc.arg(priority_level)
+WHERE id = sqlc.arg(id)
+  AND updated_at = sqlc.arg(expected_updated_at)
 RETURNING *;
 -- name: DeleteCharacter :exec
 DELETE FROM characters
@@ -32,16 +50,88 @@ WHERE id = $1;
 -- name: SearchCharacters :many
 SELECT DISTINCT *
 FROM characters
-WHERE name LIKE '%' || COALESCE(sqlc.arg(query)) || '%'
-  OR filename LIKE '%' || COALESCE(sqlc.arg(query)) || '%'
+WHERE deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1
+    FROM unnest(sqlc.arg(patterns)::text []) AS p(pattern)
+    WHERE normalize_search_text(name) LIKE p.pattern ESCAPE '\'
+      OR normalize_search_text(filename) LIKE p.pattern ESCAPE '\'
+  )
 ORDER BY priority_level DESC,
   id DESC
 LIMIT $1 OFFSET $2;
+-- name: SearchCharactersByCursor :many
+SELECT DISTINCT *
+FROM characters
+WHERE deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1
+    FROM unnest(sqlc.arg(patterns)::text []) AS p(pattern)
+    WHERE normalize_search_text(name) LIKE p.pattern ESCAPE '\'
+      OR normalize_search_text(filename) LIKE p.pattern ESCAPE '\'
+  )
+  AND (
+    priority_level < sqlc.arg(cursor_priority_level)
+    OR (
+      priority_level = sqlc.arg(cursor_priority_level)
+      AND id < sqlc.arg(cursor_id)
+    )
+  )
+ORDER BY priority_level DESC,
+  id DESC
+LIMIT $1;
 -- name: CountCharacters :one
 SELECT count(*)
-FROM characters;
+FROM characters
+WHERE deleted_at IS NULL;
 -- name: CountSearchCharacters :one
 SELECT DISTINCT count(*)
 FROM characters
-WHERE name LIKE '%' || COALESCE(sqlc.arg(query)) || '%'
-  OR filename LIKE '%' || COALESCE(sqlc.arg(query)) || '%';
\ No newline at end of file
+WHERE deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1
+    FROM unnest(sqlc.arg(patterns)::text []) AS p(pattern)
+    WHERE normalize_search_text(name) LIKE p.pattern ESCAPE '\'
+      OR normalize_search_text(filename) LIKE p.pattern ESCAPE '\'
+  );
+-- name: ReorderCharacters :execrows
+UPDATE characters t
+SET priority_level = (cardinality(sqlc.arg(ids)::bigint []) - ord.idx + 1)::smallint,
+  updated_at = now()
+FROM unnest(sqlc.arg(ids)::bigint []) WITH ORDINALITY AS ord(id, idx)
+WHERE t.id = ord.id
+  AND t.deleted_at IS NULL;
+-- name: SoftDeleteCharacter :execrows
+UPDATE characters
+SET deleted_at = now()
+WHERE id = $1
+  AND deleted_at IS NULL;
+-- name: RestoreCharacter :one
+UPDATE characters
+SET deleted_at = NULL
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+RETURNING *;
+-- name: ListDeletedCharacters :many
+SELECT *
+FROM characters
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC,
+  id DESC
+LIMIT $1 OFFSET $2;
+-- name: CountDeletedCharacters :one
+SELECT count(*)
+FROM characters
+WHERE deleted_at IS NOT NULL;
+-- name: ListPurgeableCharacters :many
+SELECT *
+FROM characters
+WHERE deleted_at < sqlc.arg(deleted_before)::timestamptz
+ORDER BY deleted_at
+LIMIT sqlc.arg(max_results);
+-- name: ListExistingCharacterIDs :many
+SELECT id
+FROM characters
+WHERE id = ANY(sqlc.arg(ids)::bigint [])
+  AND deleted_at IS NULL
+ORDER BY id;
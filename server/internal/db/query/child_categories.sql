@@ -6,28 +6,70 @@ RETURNING *;
 SELECT *
 FROM child_categories
 WHERE id = $1
+  AND deleted_at IS NULL
 LIMIT 1;
 -- name: GetChildCategoriesByParentID :many
 SELECT *
 FROM child_categories
-WHERE parent_id = $1;
+WHERE parent_id = $1
+  AND deleted_at IS NULL;
 -- name: ListChildCategories :many
 SELECT *
 FROM child_categories
+WHERE deleted_at IS NULL
 ORDER BY priority_level DESC,
   id DESC
 LIMIT $1 OFFSET $2;
 -- name: UpdateChildCategory :one
+-- updated_atが一致しない場合は他の操作で更新済みのため、更新せずに0件を返す
 UPDATE child_categories
-SET name = $2,
-  parent_id = $3,
-  updated_at = $4,
-  priority_level = $5
-WHERE id = $1
+SET name = sqlc.arg(name),
+  parent_id = sqlc.arg(parent_id),
+  updated_at = sqlc.arg(updated_at),
+  priority_level = sqlc.arg(priority_level)
+WHERE id = sqlc.arg(id)
+  AND updated_at = sqlc.arg(expected_updated_at)
 RETURNING *;
 -- name: DeleteChildCategory :exec
 DELETE FROM child_categories
 WHERE id = $1;
 -- name: DeleteAllChildCategoriesByParentCategoryID :exec
 DELETE FROM child_categories
-WHERE parent_id = $1;
\ No newline at end of file
+WHERE parent_id = $1;
+-- name: CountChildCategories :one
+SELECT count(*)
+FROM child_categories
+WHERE deleted_at IS NULL;
+-- name: ReorderChildCategories :execrows
+UPDATE child_categories t
+SET priority_level = (cardinality(sqlc.arg(ids)::bigint []) - ord.idx + 1)::smallint,
+  updated_at = now()
+FROM unnest(sqlc.arg(ids)::bigint []) WITH ORDINALITY AS ord(id, idx)
+WHERE t.id = ord.id
+  AND t.deleted_at IS NULL;
+-- name: SoftDeleteChildCategoriesByParentID :exec
+UPDATE child_categories
+SET deleted_at = sqlc.arg(deleted_at)::timestamptz
+WHERE parent_id = sqlc.arg(parent_id)
+  AND deleted_at IS NULL;
+-- name: RestoreChildCategoriesByParentID :exec
+UPDATE child_categories
+SET deleted_at = NULL
+WHERE parent_id = sqlc.arg(parent_id)
+  AND deleted_at = sqlc.arg(deleted_at)::timestamptz;
+-- name: ListChildCategoryIDsByParentIDIncludingDeleted :many
+SELECT id
+FROM child_categories
+WHERE parent_id = $1;
+-- name: ListExistingChildCategoryIDs :many
+SELECT id
+FROM child_categories
+WHERE id = ANY(sqlc.arg(ids)::bigint [])
+  AND deleted_at IS NULL
+ORDER BY id;
+-- name: ListChildCategoriesByIDs :many
+SELECT *
+FROM child_categories
+WHERE id = ANY(sqlc.arg(ids)::bigint [])
+  AND deleted_at IS NULL
+ORDER BY id;
@@ -0,0 +1,47 @@
+-- name: GetDailyIllustration :one
+SELECT *
+FROM daily_illustrations
+WHERE date = sqlc.arg(date)::date
+  AND EXISTS (
+    SELECT 1
+    FROM images
+    WHERE images.id = daily_illustrations.image_id
+      AND images.deleted_at IS NULL
+  )
+LIMIT 1;
+-- name: CreateDailyIllustration :exec
+INSERT INTO daily_illustrations (date, image_id)
+VALUES (sqlc.arg(date)::date, sqlc.arg(image_id)) ON CONFLICT (date) DO
+UPDATE
+SET image_id = EXCLUDED.image_id,
+  is_override = false,
+  updated_at = now()
+WHERE EXISTS (
+    SELECT 1
+    FROM images
+    WHERE images.id = daily_illustrations.image_id
+      AND images.deleted_at IS NOT NULL
+  );
+-- name: UpsertDailyIllustrationOverride :one
+INSERT INTO daily_illustrations (date, image_id, is_override)
+VALUES (sqlc.arg(date)::date, sqlc.arg(image_id), true) ON CONFLICT (date) DO
+UPDATE
+SET image_id = EXCLUDED.image_id,
+  is_override = true,
+  updated_at = now()
+RETURNING *;
+-- name: DeleteDailyIllustrationOverride :execrows
+DELETE FROM daily_illustrations
+WHERE date = sqlc.arg(date)::date
+  AND is_override;
+-- name: ListDailyIllustrationOverrides :many
+SELECT *
+FROM daily_illustrations
+WHERE date >= sqlc.arg(from_date)::date
+  AND is_override
+ORDER BY date;
+-- name: ListDailyImageIDs :many
+SELECT image_id
+FROM daily_illustrations
+WHERE date >= sqlc.arg(from_date)::date
+  AND date < sqlc.arg(to_date)::date;
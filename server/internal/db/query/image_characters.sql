@@ -6,6 +6,23 @@ RETURNING *;
 SELECT *
 FROM image_characters_relations
 WHERE image_id = $1
+  AND EXISTS (
+    SELECT 1
+    FROM characters
+    WHERE characters.id = image_characters_relations.character_id
+      AND characters.deleted_at IS NULL
+  )
+ORDER BY image_id DESC;
+-- name: ListImageCharacterRelationsByCharacterID :many
+SELECT *
+FROM image_characters_relations
+WHERE character_id = $1
+  AND EXISTS (
+    SELECT 1
+    FROM images
+    WHERE images.id = image_characters_relations.image_id
+      AND images.deleted_at IS NULL
+  )
 ORDER BY image_id DESC;
 -- name: ListImageCharacterRelationsByCharacterIDWIthPagination :many
 SELECT *
@@ -27,4 +44,26 @@ DELETE FROM image_characters_relations
 WHERE image_id = $1;
 -- name: DeleteAllImageCharacterRelationsByCharacterID :exec
 DELETE FROM image_characters_relations
-WHERE character_id = $1;
\ No newline at end of file
+WHERE character_id = $1;
+-- name: CountCharactersByImageIDs :many
+SELECT characters.id,
+  characters.name,
+  count(DISTINCT icr.image_id) AS count
+FROM image_characters_relations icr
+  JOIN characters ON characters.id = icr.character_id
+WHERE icr.image_id = ANY(sqlc.arg(image_ids)::bigint [])
+  AND characters.deleted_at IS NULL
+GROUP BY characters.id
+ORDER BY characters.priority_level DESC,
+  characters.id DESC;
+-- name: ClearImageCharacterPositions :exec
+UPDATE image_characters_relations
+SET position = NULL
+WHERE character_id = $1
+  AND position IS NOT NULL;
+-- name: SetImageCharacterPositions :execrows
+UPDATE image_characters_relations icr
+SET position = ord.position
+FROM unnest(sqlc.arg(image_ids)::bigint []) WITH ORDINALITY AS ord(image_id, position)
+WHERE icr.character_id = sqlc.arg(character_id)
+  AND icr.image_id = ord.image_id;
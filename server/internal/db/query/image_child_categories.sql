@@ -6,11 +6,23 @@ RETURNING *;
 SELECT *
 FROM image_child_categories_relations
 WHERE image_id = $1
+  AND EXISTS (
+    SELECT 1
+    FROM child_categories
+    WHERE child_categories.id = image_child_categories_relations.child_category_id
+      AND child_categories.deleted_at IS NULL
+  )
 ORDER BY image_id DESC;
 -- name: ListImageChildCategoryRelationsByChildCategoryID :many
 SELECT *
 FROM image_child_categories_relations
 WHERE child_category_id = $1
+  AND EXISTS (
+    SELECT 1
+    FROM images
+    WHERE images.id = image_child_categories_relations.image_id
+      AND images.deleted_at IS NULL
+  )
 ORDER BY child_category_id DESC;
 -- name: ListImageChildCategoryRelationsByChildCategoryIDWithPagination :many
 SELECT *
@@ -32,4 +44,27 @@ DELETE FROM image_child_categories_relations
 WHERE image_id = $1;
 -- name: DeleteAllImageChildCategoryRelationsByChildCategoryID :exec
 DELETE FROM image_child_categories_relations
-WHERE child_category_id = $1;
\ No newline at end of file
+WHERE child_category_id = $1;
+-- name: CountChildCategoriesByImageIDs :many
+SELECT child_categories.id,
+  child_categories.name,
+  child_categories.parent_id,
+  count(DISTINCT iccr.image_id) AS count
+FROM image_child_categories_relations iccr
+  JOIN child_categories ON child_categories.id = iccr.child_category_id
+WHERE iccr.image_id = ANY(sqlc.arg(image_ids)::bigint [])
+  AND child_categories.deleted_at IS NULL
+GROUP BY child_categories.id
+ORDER BY child_categories.priority_level DESC,
+  child_categories.id DESC;
+-- name: ClearImageChildCategoryPositions :exec
+UPDATE image_child_categories_relations
+SET position = NULL
+WHERE child_category_id = $1
+  AND position IS NOT NULL;
+-- name: SetImageChildCategoryPositions :execrows
+UPDATE image_child_categories_relations iccr
+SET position = ord.position
+FROM unnest(sqlc.arg(image_ids)::bigint []) WITH ORDINALITY AS ord(image_id, position)
+WHERE iccr.child_category_id = sqlc.arg(child_category_id)
+  AND iccr.image_id = ord.image_id;
@@ -6,11 +6,23 @@ RETURNING *;
 SELECT *
 FROM image_parent_categories_relations
 WHERE image_id = $1
+  AND EXISTS (
+    SELECT 1
+    FROM parent_categories
+    WHERE parent_categories.id = image_parent_categories_relations.parent_category_id
+      AND parent_categories.deleted_at IS NULL
+  )
 ORDER BY image_id DESC;
 -- name: ListImageParentCategoryRelationsByParentCategoryID :many
 SELECT *
 FROM image_parent_categories_relations
 WHERE parent_category_id = $1
+  AND EXISTS (
+    SELECT 1
+    FROM images
+    WHERE images.id = image_parent_categories_relations.image_id
+      AND images.deleted_at IS NULL
+  )
 ORDER BY parent_category_id DESC;
 -- name: ListImageParentCategoryRelationsByParentCategoryIDWithPagination :many
 SELECT *
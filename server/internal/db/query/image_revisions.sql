@@ -0,0 +1,42 @@
+-- name: CreateImageRevision :one
+INSERT INTO image_revisions (
+    image_id,
+    title,
+    original_src,
+    simple_src,
+    original_filename,
+    simple_filename,
+    character_ids,
+    parent_category_ids,
+    child_category_ids,
+    operator_name
+  )
+VALUES (
+    sqlc.arg(image_id),
+    sqlc.arg(title),
+    sqlc.arg(original_src),
+    sqlc.arg(simple_src),
+    sqlc.arg(original_filename),
+    sqlc.arg(simple_filename),
+    sqlc.arg(character_ids)::bigint [],
+    sqlc.arg(parent_category_ids)::bigint [],
+    sqlc.arg(child_category_ids)::bigint [],
+    sqlc.arg(operator_name)
+  )
+RETURNING *;
+-- name: GetImageRevision :one
+SELECT *
+FROM image_revisions
+WHERE id = sqlc.arg(id)
+  AND image_id = sqlc.arg(image_id)
+LIMIT 1;
+-- name: ListImageRevisions :many
+SELECT *
+FROM image_revisions
+WHERE image_id = sqlc.arg(image_id)
+ORDER BY id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+-- name: CountImageRevisions :one
+SELECT count(*)
+FROM image_revisions
+WHERE image_id = sqlc.arg(image_id);
@@ -0,0 +1,19 @@
+-- name: UpsertImageSearchDocument :exec
+INSERT INTO image_search_documents (image_id, search_vector, updated_at)
+VALUES (
+    sqlc.arg(image_id),
+    sqlc.arg(search_vector)::text::tsvector,
+    now()
+  ) ON CONFLICT (image_id) DO
+UPDATE
+SET search_vector = EXCLUDED.search_vector,
+  updated_at = EXCLUDED.updated_at;
+-- name: ListImageIDsWithoutSearchDocument :many
+SELECT i.id
+FROM images i
+WHERE NOT EXISTS (
+    SELECT 1
+    FROM image_search_documents d
+    WHERE d.image_id = i.id
+  )
+ORDER BY i.id;
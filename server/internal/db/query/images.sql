@@ -12,47 +12,593 @@ RETURNING *;
 SELECT *
 FROM images
 WHERE id = $1
+  AND deleted_at IS NULL
 LIMIT 1;
+-- name: GetImageForUpdate :one
+SELECT *
+FROM images
+WHERE id = $1
+  AND deleted_at IS NULL
+LIMIT 1 FOR UPDATE;
 -- name: ListImage :many
 SELECT *
 FROM images
+WHERE deleted_at IS NULL
 ORDER BY id DESC
 LIMIT $1 OFFSET $2;
 -- name: UpdateImage :one
+-- updated_atが一致しない場合は他の操作で更新済みのため、更新せずに0件を返す
 UPDATE images
-SET title = $2,
-  original_src = $3,
-  simple_src = $4,
-  original_filename = $5,
-  simple_filename = $6,
-  updated_at = $7
-WHERE id = $1
+SET title = sqlc.arg(title),
+  original_src = sqlc.arg(original_src),
+  simple_src = sqlc.arg(simple_src),
+  original_filename = sqlc.arg(original_filename),
+  simple_filename = sqlc.arg(simple_filename),
+  updated_at = sqlc.arg(updated_at)
+WHERE id = sqlc.arg(id)
+  AND updated_at = sqlc.arg(expected_updated_at)
 RETURNING *;
 -- name: DeleteImage :exec
 DELETE FROM images
 WHERE id = $1;
 -- name: SearchImages :many
-SELECT DISTINCT *
+SELECT sqlc.embed(images),
+  ts_rank(d.search_vector, sqlc.arg(query)::text::tsquery)::real AS rank,
+  count(*) OVER ()::bigint AS total_count
 FROM images
-WHERE title LIKE '%' || COALESCE(sqlc.arg(query)) || '%'
-  OR original_filename LIKE '%' || COALESCE(sqlc.arg(query)) || '%'
-ORDER BY id DESC
+  JOIN image_search_documents d ON d.image_id = images.id
+WHERE d.search_vector @@ sqlc.arg(query)::text::tsquery
+  AND images.deleted_at IS NULL
+ORDER BY rank DESC,
+  images.id DESC
 LIMIT $1 OFFSET $2;
--- name: FetchRandomImage :many
-SELECT i.*
-FROM images i
-WHERE i.id IN (
-    SELECT i.id
-    FROM images i
-    WHERE i.id != $2
-    ORDER BY RANDOM()
-    LIMIT $1
+-- name: SearchImagesByCursor :many
+SELECT sqlc.embed(images),
+  ts_rank(d.search_vector, sqlc.arg(query)::text::tsquery)::real AS rank
+FROM images
+  JOIN image_search_documents d ON d.image_id = images.id
+WHERE d.search_vector @@ sqlc.arg(query)::text::tsquery
+  AND images.deleted_at IS NULL
+  AND (
+    ts_rank(d.search_vector, sqlc.arg(query)::text::tsquery)::real < sqlc.arg(cursor_rank)::real
+    OR (
+      ts_rank(d.search_vector, sqlc.arg(query)::text::tsquery)::real = sqlc.arg(cursor_rank)::real
+      AND images.id < sqlc.arg(cursor_id)
+    )
+  )
+ORDER BY rank DESC,
+  images.id DESC
+LIMIT $1;
+-- name: SampleImagesFrom :many
+SELECT *
+FROM images
+WHERE random_key >= sqlc.arg(pivot)::double precision
+  AND images.deleted_at IS NULL
+  AND images.id != ALL(sqlc.arg(exclusion_ids)::bigint [])
+  AND (
+    cardinality(sqlc.arg(character_ids)::bigint []) = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_characters_relations icr
+      WHERE icr.image_id = images.id
+        AND icr.character_id = ANY(sqlc.arg(character_ids)::bigint [])
+    )
+  )
+  AND (
+    cardinality(sqlc.arg(child_category_ids)::bigint []) = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_child_categories_relations iccr
+      WHERE iccr.image_id = images.id
+        AND iccr.child_category_id = ANY(sqlc.arg(child_category_ids)::bigint [])
+    )
+  )
+ORDER BY random_key
+LIMIT sqlc.arg(max_results) OFFSET sqlc.arg(skip);
+-- name: SampleImagesBefore :many
+SELECT *
+FROM images
+WHERE random_key < sqlc.arg(pivot)::double precision
+  AND images.deleted_at IS NULL
+  AND images.id != ALL(sqlc.arg(exclusion_ids)::bigint [])
+  AND (
+    cardinality(sqlc.arg(character_ids)::bigint []) = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_characters_relations icr
+      WHERE icr.image_id = images.id
+        AND icr.character_id = ANY(sqlc.arg(character_ids)::bigint [])
+    )
+  )
+  AND (
+    cardinality(sqlc.arg(child_category_ids)::bigint []) = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_child_categories_relations iccr
+      WHERE iccr.image_id = images.id
+        AND iccr.child_category_id = ANY(sqlc.arg(child_category_ids)::bigint [])
+    )
+  )
+ORDER BY random_key
+LIMIT sqlc.arg(max_results) OFFSET sqlc.arg(skip);
+-- name: CountSampleImagesFrom :one
+SELECT count(*)
+FROM images
+WHERE random_key >= sqlc.arg(pivot)::double precision
+  AND images.deleted_at IS NULL
+  AND images.id != ALL(sqlc.arg(exclusion_ids)::bigint [])
+  AND (
+    cardinality(sqlc.arg(character_ids)::bigint []) = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_characters_relations icr
+      WHERE icr.image_id = images.id
+        AND icr.character_id = ANY(sqlc.arg(character_ids)::bigint [])
+    )
+  )
+  AND (
+    cardinality(sqlc.arg(child_category_ids)::bigint []) = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_child_categories_relations iccr
+      WHERE iccr.image_id = images.id
+        AND iccr.child_category_id = ANY(sqlc.arg(child_category_ids)::bigint [])
+    )
   );
+-- name: SampleImagesWeighted :many
+-- 起点からrandom_keyのインデックスで辿った候補のみを重み付けし、全件のソートを避ける
+WITH candidates AS (
+  (
+    SELECT images.id,
+      images.random_key - sqlc.arg(pivot)::double precision AS distance
+    FROM images
+    WHERE images.random_key >= sqlc.arg(pivot)::double precision
+      AND images.deleted_at IS NULL
+      AND images.id != ALL(sqlc.arg(exclusion_ids)::bigint [])
+      AND (
+        cardinality(sqlc.arg(character_ids)::bigint []) = 0
+        OR EXISTS (
+          SELECT 1
+          FROM image_characters_relations icr
+          WHERE icr.image_id = images.id
+            AND icr.character_id = ANY(sqlc.arg(character_ids)::bigint [])
+        )
+      )
+      AND (
+        cardinality(sqlc.arg(child_category_ids)::bigint []) = 0
+        OR EXISTS (
+          SELECT 1
+          FROM image_child_categories_relations iccr
+          WHERE iccr.image_id = images.id
+            AND iccr.child_category_id = ANY(sqlc.arg(child_category_ids)::bigint [])
+        )
+      )
+    ORDER BY images.random_key
+    LIMIT sqlc.arg(candidate_count)::int
+  )
+  UNION ALL
+  (
+    -- 末尾まで辿った場合は、先頭から起点の手前までを続けて候補にする
+    SELECT images.id,
+      images.random_key - sqlc.arg(pivot)::double precision + 1 AS distance
+    FROM images
+    WHERE images.random_key < sqlc.arg(pivot)::double precision
+      AND images.deleted_at IS NULL
+      AND images.id != ALL(sqlc.arg(exclusion_ids)::bigint [])
+      AND (
+        cardinality(sqlc.arg(character_ids)::bigint []) = 0
+        OR EXISTS (
+          SELECT 1
+          FROM image_characters_relations icr
+          WHERE icr.image_id = images.id
+            AND icr.character_id = ANY(sqlc.arg(character_ids)::bigint [])
+        )
+      )
+      AND (
+        cardinality(sqlc.arg(child_category_ids)::bigint []) = 0
+        OR EXISTS (
+          SELECT 1
+          FROM image_child_categories_relations iccr
+          WHERE iccr.image_id = images.id
+            AND iccr.child_category_id = ANY(sqlc.arg(child_category_ids)::bigint [])
+        )
+      )
+    ORDER BY images.random_key
+    LIMIT sqlc.arg(candidate_count)::int
+  )
+),
+pool AS (
+  SELECT candidates.id,
+    candidates.distance
+  FROM candidates
+  ORDER BY candidates.distance
+  LIMIT sqlc.arg(candidate_count)::int
+),
+weights AS (
+  SELECT pool.id,
+    GREATEST(
+      COALESCE(
+        (
+          SELECT max(c.priority_level)
+          FROM image_characters_relations icr
+            JOIN characters c ON c.id = icr.character_id
+          WHERE icr.image_id = pool.id
+        ),
+        0
+      ),
+      COALESCE(
+        (
+          SELECT max(cc.priority_level)
+          FROM image_child_categories_relations iccr
+            JOIN child_categories cc ON cc.id = iccr.child_category_id
+          WHERE iccr.image_id = pool.id
+        ),
+        0
+      ),
+      1
+    ) AS weight,
+    -- 起点からの順位を(0, 1)の一様乱数として扱う
+    row_number() OVER (
+      ORDER BY pool.distance
+    )::double precision / (count(*) OVER () + 1) AS u
+  FROM pool
+)
+SELECT images.*
+FROM weights
+  JOIN images ON images.id = weights.id
+ORDER BY power(weights.u, 1.0 / weights.weight) DESC,
+  images.id
+LIMIT sqlc.arg(max_results) OFFSET sqlc.arg(skip);
 -- name: CountImages :one
 SELECT count(*)
-FROM images;
+FROM images
+WHERE deleted_at IS NULL;
 -- name: CountSearchImages :one
-SELECT DISTINCT count(*)
+SELECT count(*)
+FROM image_search_documents d
+  JOIN images ON images.id = d.image_id
+WHERE d.search_vector @@ sqlc.arg(query)::text::tsquery
+  AND images.deleted_at IS NULL;
+-- name: FilterImageIDs :many
+SELECT images.id
+FROM images
+WHERE images.deleted_at IS NULL
+  AND (
+    sqlc.arg(query)::text = ''
+    OR EXISTS (
+      SELECT 1
+      FROM image_search_documents d
+      WHERE d.image_id = images.id
+        AND d.search_vector @@ sqlc.arg(query)::text::tsquery
+    )
+  )
+  AND (
+    CASE
+      WHEN sqlc.arg(match_all)::boolean THEN (
+        SELECT count(DISTINCT icr.character_id)
+        FROM image_characters_relations icr
+        WHERE icr.image_id = images.id
+          AND icr.character_id = ANY(sqlc.arg(character_ids)::bigint [])
+      ) = cardinality(sqlc.arg(character_ids)::bigint [])
+      AND (
+        SELECT count(DISTINCT iccr.child_category_id)
+        FROM image_child_categories_relations iccr
+        WHERE iccr.image_id = images.id
+          AND iccr.child_category_id = ANY(sqlc.arg(child_category_ids)::bigint [])
+      ) = cardinality(sqlc.arg(child_category_ids)::bigint [])
+      ELSE cardinality(sqlc.arg(character_ids)::bigint []) + cardinality(sqlc.arg(child_category_ids)::bigint []) = 0
+      OR EXISTS (
+        SELECT 1
+        FROM image_characters_relations icr
+        WHERE icr.image_id = images.id
+          AND icr.character_id = ANY(sqlc.arg(character_ids)::bigint [])
+      )
+      OR EXISTS (
+        SELECT 1
+        FROM image_child_categories_relations iccr
+        WHERE iccr.image_id = images.id
+          AND iccr.child_category_id = ANY(sqlc.arg(child_category_ids)::bigint [])
+      )
+    END
+  )
+ORDER BY images.id DESC;
+-- name: ListRelatedImages :many
+WITH scores AS (
+  SELECT related.image_id,
+    sum(related.weight) AS score
+  FROM (
+      SELECT r.image_id,
+        sqlc.arg(character_weight)::int AS weight
+      FROM image_characters_relations r
+        JOIN image_characters_relations base ON base.character_id = r.character_id
+      WHERE base.image_id = sqlc.arg(image_id)
+      UNION ALL
+      SELECT r.image_id,
+        sqlc.arg(child_category_weight)::int AS weight
+      FROM image_child_categories_relations r
+        JOIN image_child_categories_relations base ON base.child_category_id = r.child_category_id
+      WHERE base.image_id = sqlc.arg(image_id)
+      UNION ALL
+      SELECT r.image_id,
+        sqlc.arg(parent_category_weight)::int AS weight
+      FROM image_parent_categories_relations r
+        JOIN image_parent_categories_relations base ON base.parent_category_id = r.parent_category_id
+      WHERE base.image_id = sqlc.arg(image_id)
+    ) related
+  WHERE related.image_id != sqlc.arg(image_id)
+  GROUP BY related.image_id
+)
+SELECT images.*
+FROM scores
+  JOIN images ON images.id = scores.image_id
+WHERE images.deleted_at IS NULL
+ORDER BY scores.score DESC,
+  RANDOM()
+LIMIT sqlc.arg(max_results);
+-- name: ListImagesOrderByNewest :many
+SELECT *
+FROM images
+WHERE images.deleted_at IS NULL
+  AND (
+    sqlc.arg(query)::text = ''
+    OR EXISTS (
+      SELECT 1
+      FROM image_search_documents d
+      WHERE d.image_id = images.id
+        AND d.search_vector @@ sqlc.arg(query)::text::tsquery
+    )
+  )
+  AND (
+    sqlc.arg(character_id)::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_characters_relations icr
+      WHERE icr.image_id = images.id
+        AND icr.character_id = sqlc.arg(character_id)::bigint
+    )
+  )
+  AND (
+    sqlc.arg(child_category_id)::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_child_categories_relations iccr
+      WHERE iccr.image_id = images.id
+        AND iccr.child_category_id = sqlc.arg(child_category_id)::bigint
+    )
+  )
+  AND (
+    NOT sqlc.arg(use_cursor)::boolean
+    OR images.id < sqlc.arg(cursor_id)
+  )
+ORDER BY images.id DESC
+LIMIT sqlc.arg(max_results) OFFSET sqlc.arg(skip);
+-- name: ListImagesOrderByOldest :many
+SELECT *
+FROM images
+WHERE images.deleted_at IS NULL
+  AND (
+    sqlc.arg(query)::text = ''
+    OR EXISTS (
+      SELECT 1
+      FROM image_search_documents d
+      WHERE d.image_id = images.id
+        AND d.search_vector @@ sqlc.arg(query)::text::tsquery
+    )
+  )
+  AND (
+    sqlc.arg(character_id)::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_characters_relations icr
+      WHERE icr.image_id = images.id
+        AND icr.character_id = sqlc.arg(character_id)::bigint
+    )
+  )
+  AND (
+    sqlc.arg(child_category_id)::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_child_categories_relations iccr
+      WHERE iccr.image_id = images.id
+        AND iccr.child_category_id = sqlc.arg(child_category_id)::bigint
+    )
+  )
+  AND (
+    NOT sqlc.arg(use_cursor)::boolean
+    OR images.id > sqlc.arg(cursor_id)
+  )
+ORDER BY images.id
+LIMIT sqlc.arg(max_results) OFFSET sqlc.arg(skip);
+-- name: ListImagesOrderByTitle :many
+SELECT *
+FROM images
+WHERE images.deleted_at IS NULL
+  AND (
+    sqlc.arg(query)::text = ''
+    OR EXISTS (
+      SELECT 1
+      FROM image_search_documents d
+      WHERE d.image_id = images.id
+        AND d.search_vector @@ sqlc.arg(query)::text::tsquery
+    )
+  )
+  AND (
+    sqlc.arg(character_id)::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_characters_relations icr
+      WHERE icr.image_id = images.id
+        AND icr.character_id = sqlc.arg(character_id)::bigint
+    )
+  )
+  AND (
+    sqlc.arg(child_category_id)::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_child_categories_relations iccr
+      WHERE iccr.image_id = images.id
+        AND iccr.child_category_id = sqlc.arg(child_category_id)::bigint
+    )
+  )
+  AND (
+    NOT sqlc.arg(use_cursor)::boolean
+    OR (images.title, images.id) > (sqlc.arg(cursor_title)::varchar, sqlc.arg(cursor_id)::bigint)
+  )
+ORDER BY images.title,
+  images.id
+LIMIT sqlc.arg(max_results) OFFSET sqlc.arg(skip);
+-- name: ListImagesOrderByUpdated :many
+SELECT *
+FROM images
+WHERE images.deleted_at IS NULL
+  AND (
+    sqlc.arg(query)::text = ''
+    OR EXISTS (
+      SELECT 1
+      FROM image_search_documents d
+      WHERE d.image_id = images.id
+        AND d.search_vector @@ sqlc.arg(query)::text::tsquery
+    )
+  )
+  AND (
+    sqlc.arg(character_id)::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_characters_relations icr
+      WHERE icr.image_id = images.id
+        AND icr.character_id = sqlc.arg(character_id)::bigint
+    )
+  )
+  AND (
+    sqlc.arg(child_category_id)::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_child_categories_relations iccr
+      WHERE iccr.image_id = images.id
+        AND iccr.child_category_id = sqlc.arg(child_category_id)::bigint
+    )
+  )
+  AND (
+    NOT sqlc.arg(use_cursor)::boolean
+    OR (images.updated_at, images.id) < (sqlc.arg(cursor_updated_at)::timestamptz, sqlc.arg(cursor_id)::bigint)
+  )
+ORDER BY images.updated_at DESC,
+  images.id DESC
+LIMIT sqlc.arg(max_results) OFFSET sqlc.arg(skip);
+-- name: ListImagesOrderByPopular :many
+SELECT *
+FROM images
+WHERE images.deleted_at IS NULL
+  AND (
+    sqlc.arg(query)::text = ''
+    OR EXISTS (
+      SELECT 1
+      FROM image_search_documents d
+      WHERE d.image_id = images.id
+        AND d.search_vector @@ sqlc.arg(query)::text::tsquery
+    )
+  )
+  AND (
+    sqlc.arg(character_id)::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_characters_relations icr
+      WHERE icr.image_id = images.id
+        AND icr.character_id = sqlc.arg(character_id)::bigint
+    )
+  )
+  AND (
+    sqlc.arg(child_category_id)::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_child_categories_relations iccr
+      WHERE iccr.image_id = images.id
+        AND iccr.child_category_id = sqlc.arg(child_category_id)::bigint
+    )
+  )
+  AND (
+    NOT sqlc.arg(use_cursor)::boolean
+    OR (images.view_count, images.id) < (sqlc.arg(cursor_view_count)::bigint, sqlc.arg(cursor_id)::bigint)
+  )
+ORDER BY images.view_count DESC,
+  images.id DESC
+LIMIT sqlc.arg(max_results) OFFSET sqlc.arg(skip);
+-- name: AddImageViewCounts :execrows
+UPDATE images
+SET view_count = images.view_count + v.count
+FROM (
+    SELECT unnest(sqlc.arg(ids)::bigint []) AS id,
+      unnest(sqlc.arg(counts)::bigint []) AS count
+  ) v
+WHERE images.id = v.id
+  AND images.deleted_at IS NULL;
+-- name: ListImagesByCharacterOrderByPosition :many
+SELECT sqlc.embed(images),
+  COALESCE(icr.position, 2147483647)::integer AS position
+FROM images
+  JOIN image_characters_relations icr ON icr.image_id = images.id
+WHERE images.deleted_at IS NULL
+  AND icr.character_id = sqlc.arg(character_id)
+  AND (
+    NOT sqlc.arg(use_cursor)::boolean
+    OR COALESCE(icr.position, 2147483647) > sqlc.arg(cursor_position)::integer
+    OR (
+      COALESCE(icr.position, 2147483647) = sqlc.arg(cursor_position)::integer
+      AND images.id < sqlc.arg(cursor_id)
+    )
+  )
+ORDER BY COALESCE(icr.position, 2147483647),
+  images.id DESC
+LIMIT sqlc.arg(max_results) OFFSET sqlc.arg(skip);
+-- name: ListImagesByChildCategoryOrderByPosition :many
+SELECT sqlc.embed(images),
+  COALESCE(iccr.position, 2147483647)::integer AS position
+FROM images
+  JOIN image_child_categories_relations iccr ON iccr.image_id = images.id
+WHERE images.deleted_at IS NULL
+  AND iccr.child_category_id = sqlc.arg(child_category_id)
+  AND (
+    NOT sqlc.arg(use_cursor)::boolean
+    OR COALESCE(iccr.position, 2147483647) > sqlc.arg(cursor_position)::integer
+    OR (
+      COALESCE(iccr.position, 2147483647) = sqlc.arg(cursor_position)::integer
+      AND images.id < sqlc.arg(cursor_id)
+    )
+  )
+ORDER BY COALESCE(iccr.position, 2147483647),
+  images.id DESC
+LIMIT sqlc.arg(max_results) OFFSET sqlc.arg(skip);
+-- name: SoftDeleteImage :execrows
+UPDATE images
+SET deleted_at = now()
+WHERE id = $1
+  AND deleted_at IS NULL;
+-- name: RestoreImage :one
+UPDATE images
+SET deleted_at = NULL
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+RETURNING *;
+-- name: ListDeletedImages :many
+SELECT *
+FROM images
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC,
+  id DESC
+LIMIT $1 OFFSET $2;
+-- name: CountDeletedImages :one
+SELECT count(*)
+FROM images
+WHERE deleted_at IS NOT NULL;
+-- name: ListPurgeableImages :many
+SELECT *
+FROM images
+WHERE deleted_at < sqlc.arg(deleted_before)::timestamptz
+ORDER BY deleted_at
+LIMIT sqlc.arg(max_results);
+-- name: CountImagesByOriginalFilename :one
+-- ゴミ箱のイラストもunique制約の対象のため、deleted_atでは絞り込まない
+SELECT count(*)
 FROM images
-WHERE title LIKE '%' || COALESCE(sqlc.arg(query)) || '%'
-  OR original_filename LIKE '%' || COALESCE(sqlc.arg(query)) || '%';
\ No newline at end of file
+WHERE original_filename = sqlc.arg(original_filename)
+  AND id <> sqlc.arg(exclude_id);
@@ -0,0 +1,54 @@
+-- name: CreateLoginAttempt :exec
+INSERT INTO login_attempts (
+    email,
+    operator_name,
+    succeeded,
+    failure_reason,
+    client_ip,
+    user_agent
+  )
+VALUES (
+    sqlc.arg(email),
+    sqlc.arg(operator_name),
+    sqlc.arg(succeeded),
+    sqlc.arg(failure_reason),
+    sqlc.arg(client_ip),
+    sqlc.arg(user_agent)
+  );
+-- name: ListLoginAttempts :many
+SELECT *
+FROM login_attempts
+WHERE (
+    sqlc.arg(operator_name)::text = ''
+    OR operator_name = sqlc.arg(operator_name)::text
+  )
+  AND (
+    sqlc.arg(email)::text = ''
+    OR email = sqlc.arg(email)::text
+  )
+  AND (
+    sqlc.narg(succeeded)::boolean IS NULL
+    OR succeeded = sqlc.narg(succeeded)::boolean
+  )
+  AND created_at >= sqlc.arg(from_time)
+  AND created_at < sqlc.arg(to_time)
+ORDER BY created_at DESC,
+  id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+-- name: CountLoginAttempts :one
+SELECT count(*)
+FROM login_attempts
+WHERE (
+    sqlc.arg(operator_name)::text = ''
+    OR operator_name = sqlc.arg(operator_name)::text
+  )
+  AND (
+    sqlc.arg(email)::text = ''
+    OR email = sqlc.arg(email)::text
+  )
+  AND (
+    sqlc.narg(succeeded)::boolean IS NULL
+    OR succeeded = sqlc.narg(succeeded)::boolean
+  )
+  AND created_at >= sqlc.arg(from_time)
+  AND created_at < sqlc.arg(to_time);
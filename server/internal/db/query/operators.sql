@@ -1,12 +1,26 @@
 -- name: CreateOperator :one
-INSERT INTO operators (name, hashed_password, email)
-VALUES ($1, $2, $3)
+INSERT INTO operators (name, hashed_password, email, role)
+VALUES ($1, $2, $3, $4)
 RETURNING *;
+-- name: GetOperator :one
+SELECT *
+FROM operators
+WHERE id = $1
+LIMIT 1;
 -- name: GetOperatorByEmail :one
 SELECT *
 FROM operators
 WHERE email = $1
 LIMIT 1;
+-- name: GetOperatorByName :one
+SELECT *
+FROM operators
+WHERE name = $1
+LIMIT 1;
+-- name: ListOperators :many
+SELECT *
+FROM operators
+ORDER BY id;
 -- name: UpdateOperator :one
 UPDATE operators
 SET name = $2,
@@ -14,11 +28,26 @@ SET name = $2,
   email = $4
 WHERE id = $1
 RETURNING *;
--- -- name: ListOperator :many
--- SELECT *
--- FROM operators
--- ORDER BY id DESC
--- LIMIT $1 OFFSET $2;
--- -- name: DeleteOperator :exec
--- DELETE FROM operators
--- WHERE id = $1;
\ No newline at end of file
+-- name: EditOperator :one
+UPDATE operators
+SET email = sqlc.arg(email),
+  role = sqlc.arg(role)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+-- name: DisableOperator :one
+UPDATE operators
+SET disabled_at = now()
+WHERE id = $1
+RETURNING *;
+-- name: EnableOperator :one
+UPDATE operators
+SET disabled_at = NULL
+WHERE id = $1
+RETURNING *;
+-- name: LockActiveOwners :many
+SELECT id
+FROM operators
+WHERE role = 'owner'
+  AND disabled_at IS NULL
+ORDER BY id
+FOR UPDATE;
@@ -6,26 +6,51 @@ RETURNING *;
 SELECT *
 FROM parent_categories
 WHERE id = $1
+  AND deleted_at IS NULL
 LIMIT 1;
+-- name: GetParentCategoryForUpdate :one
+SELECT *
+FROM parent_categories
+WHERE id = $1
+  AND deleted_at IS NULL
+LIMIT 1 FOR UPDATE;
 -- name: ListParentCategories :many
 SELECT *
 FROM parent_categories
+WHERE deleted_at IS NULL
 ORDER BY priority_level DESC,
   id DESC
 LIMIT $1 OFFSET $2;
+-- name: ListParentCategoriesByCursor :many
+SELECT *
+FROM parent_categories
+WHERE deleted_at IS NULL
+  AND (
+    priority_level < sqlc.arg(cursor_priority_level)
+    OR (
+      priority_level = sqlc.arg(cursor_priority_level)
+      AND id < sqlc.arg(cursor_id)
+    )
+  )
+ORDER BY priority_level DESC,
+  id DESC
+LIMIT $1;
 -- name: ListAllParentCategories :many
 SELECT *
 FROM parent_categories
+WHERE deleted_at IS NULL
 ORDER BY priority_level DESC,
   id DESC;
 -- name: UpdateParentCategory :one
+-- updated_atが一致しない場合は他の操作で更新済みのため、更新せずに0件を返す
 UPDATE parent_categories
-SET name = $2,
-  src = $3,
-  filename = $4,
-  updated_at = $5,
-  priority_level = $6
-WHERE id = $1
+SET name = sqlc.arg(name),
+  src = sqlc.arg(src),
+  filename = sqlc.arg(filename),
+  updated_at = sqlc.arg(updated_at),
+  priority_level = sqlc.arg(priority_level)
+WHERE id = sqlc.arg(id)
+  AND updated_at = sqlc.arg(expected_updated_at)
 RETURNING *;
 -- name: DeleteParentCategory :exec
 DELETE FROM parent_categories
@@ -33,15 +58,74 @@ WHERE id = $1;
 -- name: SearchParentCategories :many
 SELECT DISTINCT *
 FROM parent_categories
-WHERE name LIKE '%' || COALESCE(sqlc.arg(query)) || '%'
-  OR filename LIKE '%' || COALESCE(sqlc.arg(query)) || '%'
+WHERE deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1
+    FROM unnest(sqlc.arg(patterns)::text []) AS p(pattern)
+    WHERE normalize_search_text(name) LIKE p.pattern ESCAPE '\'
+      OR normalize_search_text(filename) LIKE p.pattern ESCAPE '\'
+  )
 ORDER BY priority_level DESC,
   id DESC;
 -- name: CountParentCategories :one
 SELECT count(*)
-FROM parent_categories;
+FROM parent_categories
+WHERE deleted_at IS NULL;
 -- name: CountSearchParentCategories :one
 SELECT DISTINCT count(*)
 FROM parent_categories
-WHERE name LIKE '%' || COALESCE(sqlc.arg(query)) || '%'
-  OR filename LIKE '%' || COALESCE(sqlc.arg(query)) || '%';
\ No newline at end of file
+WHERE deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1
+    FROM unnest(sqlc.arg(patterns)::text []) AS p(pattern)
+    WHERE normalize_search_text(name) LIKE p.pattern ESCAPE '\'
+      OR normalize_search_text(filename) LIKE p.pattern ESCAPE '\'
+  );
+-- name: ReorderParentCategories :execrows
+UPDATE parent_categories t
+SET priority_level = (cardinality(sqlc.arg(ids)::bigint []) - ord.idx + 1)::smallint,
+  updated_at = now()
+FROM unnest(sqlc.arg(ids)::bigint []) WITH ORDINALITY AS ord(id, idx)
+WHERE t.id = ord.id
+  AND t.deleted_at IS NULL;
+-- name: SoftDeleteParentCategory :one
+UPDATE parent_categories
+SET deleted_at = now()
+WHERE id = $1
+  AND deleted_at IS NULL
+RETURNING *;
+-- name: GetDeletedParentCategoryForUpdate :one
+SELECT *
+FROM parent_categories
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+LIMIT 1 FOR UPDATE;
+-- name: RestoreParentCategory :one
+UPDATE parent_categories
+SET deleted_at = NULL
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+RETURNING *;
+-- name: ListDeletedParentCategories :many
+SELECT *
+FROM parent_categories
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC,
+  id DESC
+LIMIT $1 OFFSET $2;
+-- name: CountDeletedParentCategories :one
+SELECT count(*)
+FROM parent_categories
+WHERE deleted_at IS NOT NULL;
+-- name: ListPurgeableParentCategories :many
+SELECT *
+FROM parent_categories
+WHERE deleted_at < sqlc.arg(deleted_before)::timestamptz
+ORDER BY deleted_at
+LIMIT sqlc.arg(max_results);
+-- name: ListExistingParentCategoryIDs :many
+SELECT id
+FROM parent_categories
+WHERE id = ANY(sqlc.arg(ids)::bigint [])
+  AND deleted_at IS NULL
+ORDER BY id;
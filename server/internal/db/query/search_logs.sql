@@ -0,0 +1,41 @@
+-- name: CreateSearchLog :exec
+INSERT INTO search_logs (query, result_count, searched_at)
+VALUES ($1, $2, $3);
+-- name: ListTopSearchQueries :many
+SELECT query,
+  count(*) AS count,
+  max(searched_at)::timestamptz AS last_searched_at
+FROM search_logs
+WHERE searched_at >= sqlc.arg(from_time)
+  AND searched_at < sqlc.arg(to_time)
+GROUP BY query
+ORDER BY count DESC,
+  query
+LIMIT sqlc.arg(max_results);
+-- name: ListZeroResultSearchQueries :many
+SELECT query,
+  count(*) AS count,
+  max(searched_at)::timestamptz AS last_searched_at
+FROM search_logs
+WHERE searched_at >= sqlc.arg(from_time)
+  AND searched_at < sqlc.arg(to_time)
+  AND result_count = 0
+GROUP BY query
+ORDER BY count DESC,
+  query
+LIMIT sqlc.arg(max_results);
+-- name: ListSearchTrends :many
+SELECT (searched_at AT TIME ZONE 'Asia/Tokyo')::date AS date,
+  count(*) AS count,
+  count(*) FILTER (
+    WHERE result_count = 0
+  ) AS zero_result_count
+FROM search_logs
+WHERE searched_at >= sqlc.arg(from_time)
+  AND searched_at < sqlc.arg(to_time)
+  AND (
+    sqlc.arg(query)::text = ''
+    OR query = sqlc.arg(query)::text
+  )
+GROUP BY 1
+ORDER BY 1;
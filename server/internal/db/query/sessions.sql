@@ -1,6 +1,8 @@
 -- name: CreateSession :one
 INSERT INTO sessions (
     id,
+    family_id,
+    access_token_id,
     name,
     email,
     refresh_token,
@@ -8,10 +10,36 @@ INSERT INTO sessions (
     user_agent,
     client_ip
   )
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING *;
 -- name: GetSession :one
 SELECT *
 FROM sessions
 WHERE id = $1
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+-- name: GetSessionByAccessTokenID :one
+SELECT *
+FROM sessions
+WHERE access_token_id = $1
+LIMIT 1;
+-- name: ListActiveSessions :many
+SELECT *
+FROM sessions
+WHERE name = $1
+  AND is_blocked = false
+  AND rotated_at IS NULL
+  AND expires_at > now()
+ORDER BY created_at DESC;
+-- name: RotateSession :execrows
+UPDATE sessions
+SET rotated_at = now()
+WHERE id = $1
+  AND rotated_at IS NULL;
+-- name: BlockSessionFamily :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE family_id = $1;
+-- name: BlockOperatorSessions :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE name = $1;
@@ -0,0 +1,45 @@
+-- name: SuggestSearchTerms :many
+SELECT s.term::text AS term,
+  s.kind::text AS kind,
+  s.score::bigint AS score
+FROM (
+    SELECT characters.name AS term,
+      'character' AS kind,
+      count(icr.id) AS score
+    FROM characters
+      LEFT JOIN image_characters_relations icr ON icr.character_id = characters.id
+    WHERE characters.deleted_at IS NULL
+      AND normalize_search_text(characters.name) LIKE sqlc.arg(pattern)::text ESCAPE '\'
+    GROUP BY characters.id
+    UNION ALL
+    SELECT parent_categories.name,
+      'parent_category',
+      count(ipcr.id)
+    FROM parent_categories
+      LEFT JOIN image_parent_categories_relations ipcr ON ipcr.parent_category_id = parent_categories.id
+    WHERE parent_categories.deleted_at IS NULL
+      AND normalize_search_text(parent_categories.name) LIKE sqlc.arg(pattern)::text ESCAPE '\'
+    GROUP BY parent_categories.id
+    UNION ALL
+    SELECT child_categories.name,
+      'child_category',
+      count(iccr.id)
+    FROM child_categories
+      LEFT JOIN image_child_categories_relations iccr ON iccr.child_category_id = child_categories.id
+    WHERE child_categories.deleted_at IS NULL
+      AND normalize_search_text(child_categories.name) LIKE sqlc.arg(pattern)::text ESCAPE '\'
+    GROUP BY child_categories.id
+    UNION ALL
+    SELECT images.title,
+      'title',
+      count(*)
+    FROM images
+    WHERE images.deleted_at IS NULL
+      AND normalize_search_text(images.title) LIKE sqlc.arg(pattern)::text ESCAPE '\'
+    GROUP BY images.title
+  ) s
+ORDER BY normalize_search_text(s.term) = sqlc.arg(normalized)::text DESC,
+  s.score DESC,
+  length(s.term),
+  s.term
+LIMIT sqlc.arg(max_results);
@@ -0,0 +1,24 @@
+-- name: CreateSynonym :one
+INSERT INTO synonyms (word, synonym)
+VALUES ($1, $2)
+RETURNING *;
+-- name: GetSynonym :one
+SELECT *
+FROM synonyms
+WHERE id = $1
+LIMIT 1;
+-- name: ListAllSynonyms :many
+SELECT *
+FROM synonyms
+ORDER BY word,
+  id;
+-- name: UpdateSynonym :one
+UPDATE synonyms
+SET word = $2,
+  synonym = $3,
+  updated_at = $4
+WHERE id = $1
+RETURNING *;
+-- name: DeleteSynonym :exec
+DELETE FROM synonyms
+WHERE id = $1;
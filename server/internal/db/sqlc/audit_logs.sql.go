@@ -0,0 +1,181 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: audit_logs.sql
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const countAuditLogs = `-- name: CountAuditLogs :one
+SELECT count(*)
+FROM audit_logs
+WHERE (
+    $1::text = ''
+    OR operator_name = $1::text
+  )
+  AND (
+    $2::text = ''
+    OR action = $2::text
+  )
+  AND (
+    $3::text = ''
+    OR entity_type = $3::text
+  )
+  AND (
+    $4::text = ''
+    OR entity_id = $4::text
+  )
+  AND created_at >= $5
+  AND created_at < $6
+`
+
+type CountAuditLogsParams struct {
+	OperatorName string    `json:"operator_name"`
+	Action       string    `json:"action"`
+	EntityType   string    `json:"entity_type"`
+	EntityID     string    `json:"entity_id"`
+	FromTime     time.Time `json:"from_time"`
+	ToTime       time.Time `json:"to_time"`
+}
+
+func (q *Queries) CountAuditLogs(ctx context.Context, arg CountAuditLogsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAuditLogs,
+		arg.OperatorName,
+		arg.Action,
+		arg.EntityType,
+		arg.EntityID,
+		arg.FromTime,
+		arg.ToTime,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAuditLog = `-- name: CreateAuditLog :exec
+INSERT INTO audit_logs (
+    operator_name,
+    action,
+    entity_type,
+    entity_id,
+    diff,
+    client_ip,
+    user_agent
+  )
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+  )
+`
+
+type CreateAuditLogParams struct {
+	OperatorName string          `json:"operator_name"`
+	Action       string          `json:"action"`
+	EntityType   string          `json:"entity_type"`
+	EntityID     string          `json:"entity_id"`
+	Diff         json.RawMessage `json:"diff"`
+	ClientIp     string          `json:"client_ip"`
+	UserAgent    string          `json:"user_agent"`
+}
+
+func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) error {
+	_, err := q.db.ExecContext(ctx, createAuditLog,
+		arg.OperatorName,
+		arg.Action,
+		arg.EntityType,
+		arg.EntityID,
+		arg.Diff,
+		arg.ClientIp,
+		arg.UserAgent,
+	)
+	return err
+}
+
+const listAuditLogs = `-- name: ListAuditLogs :many
+SELECT id, operator_name, action, entity_type, entity_id, diff, client_ip, user_agent, created_at
+FROM audit_logs
+WHERE (
+    $1::text = ''
+    OR operator_name = $1::text
+  )
+  AND (
+    $2::text = ''
+    OR action = $2::text
+  )
+  AND (
+    $3::text = ''
+    OR entity_type = $3::text
+  )
+  AND (
+    $4::text = ''
+    OR entity_id = $4::text
+  )
+  AND created_at >= $5
+  AND created_at < $6
+ORDER BY created_at DESC,
+  id DESC
+LIMIT $8 OFFSET $7
+`
+
+type ListAuditLogsParams struct {
+	OperatorName string    `json:"operator_name"`
+	Action       string    `json:"action"`
+	EntityType   string    `json:"entity_type"`
+	EntityID     string    `json:"entity_id"`
+	FromTime     time.Time `json:"from_time"`
+	ToTime       time.Time `json:"to_time"`
+	Offset       int32     `json:"offset"`
+	Limit        int32     `json:"limit"`
+}
+
+func (q *Queries) ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditLogs,
+		arg.OperatorName,
+		arg.Action,
+		arg.EntityType,
+		arg.EntityID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditLog{}
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.OperatorName,
+			&i.Action,
+			&i.EntityType,
+			&i.EntityID,
+			&i.Diff,
+			&i.ClientIp,
+			&i.UserAgent,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
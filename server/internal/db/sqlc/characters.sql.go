@@ -9,11 +9,14 @@ import (
 	"context"
 	"database/sql"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 const countCharacters = `-- name: CountCharacters :one
 SELECT count(*)
 FROM characters
+WHERE deleted_at IS NULL
 `
 
 func (q *Queries) CountCharacters(ctx context.Context) (int64, error) {
@@ -23,15 +26,33 @@ func (q *Queries) CountCharacters(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const countDeletedCharacters = `-- name: CountDeletedCharacters :one
+SELECT count(*)
+FROM characters
+WHERE deleted_at IS NOT NULL
+`
+
+func (q *Queries) CountDeletedCharacters(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countDeletedCharacters)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countSearchCharacters = `-- name: CountSearchCharacters :one
 SELECT DISTINCT count(*)
 FROM characters
-WHERE name LIKE '%' || COALESCE($1) || '%'
-  OR filename LIKE '%' || COALESCE($1) || '%'
+WHERE deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1
+    FROM unnest($1::text []) AS p(pattern)
+    WHERE normalize_search_text(name) LIKE p.pattern ESCAPE '\'
+      OR normalize_search_text(filename) LIKE p.pattern ESCAPE '\'
+  )
 `
 
-func (q *Queries) CountSearchCharacters(ctx context.Context, query sql.NullString) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countSearchCharacters, query)
+func (q *Queries) CountSearchCharacters(ctx context.Context, patterns []string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSearchCharacters, pq.Array(patterns))
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -40,7 +61,7 @@ func (q *Queries) CountSearchCharacters(ctx context.Context, query sql.NullStrin
 const createCharacter = `-- name: CreateCharacter :one
 INSERT INTO characters (name, src, filename, priority_level)
 VALUES ($1, $2, $3, $4)
-RETURNING id, name, src, updated_at, created_at, filename, priority_level
+RETURNING id, name, src, updated_at, created_at, filename, priority_level, deleted_at
 `
 
 type CreateCharacterParams struct {
@@ -66,6 +87,7 @@ func (q *Queries) CreateCharacter(ctx context.Context, arg CreateCharacterParams
 		&i.CreatedAt,
 		&i.Filename,
 		&i.PriorityLevel,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -81,9 +103,10 @@ func (q *Queries) DeleteCharacter(ctx context.Context, id int64) error {
 }
 
 const getCharacter = `-- name: GetCharacter :one
-SELECT id, name, src, updated_at, created_at, filename, priority_level
+SELECT id, name, src, updated_at, created_at, filename, priority_level, deleted_at
 FROM characters
 WHERE id = $1
+  AND deleted_at IS NULL
 LIMIT 1
 `
 
@@ -98,13 +121,39 @@ func (q *Queries) GetCharacter(ctx context.Context, id int64) (Character, error)
 		&i.CreatedAt,
 		&i.Filename,
 		&i.PriorityLevel,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getCharacterForUpdate = `-- name: GetCharacterForUpdate :one
+SELECT id, name, src, updated_at, created_at, filename, priority_level, deleted_at
+FROM characters
+WHERE id = $1
+  AND deleted_at IS NULL
+LIMIT 1 FOR UPDATE
+`
+
+func (q *Queries) GetCharacterForUpdate(ctx context.Context, id int64) (Character, error) {
+	row := q.db.QueryRowContext(ctx, getCharacterForUpdate, id)
+	var i Character
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Src,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.Filename,
+		&i.PriorityLevel,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const listAllCharacters = `-- name: ListAllCharacters :many
-SELECT id, name, src, updated_at, created_at, filename, priority_level
+SELECT id, name, src, updated_at, created_at, filename, priority_level, deleted_at
 FROM characters
+WHERE deleted_at IS NULL
 ORDER BY priority_level DESC,
   id DESC
 `
@@ -126,6 +175,7 @@ func (q *Queries) ListAllCharacters(ctx context.Context) ([]Character, error) {
 			&i.CreatedAt,
 			&i.Filename,
 			&i.PriorityLevel,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -141,8 +191,9 @@ func (q *Queries) ListAllCharacters(ctx context.Context) ([]Character, error) {
 }
 
 const listCharacters = `-- name: ListCharacters :many
-SELECT id, name, src, updated_at, created_at, filename, priority_level
+SELECT id, name, src, updated_at, created_at, filename, priority_level, deleted_at
 FROM characters
+WHERE deleted_at IS NULL
 ORDER BY id DESC
 LIMIT $1 OFFSET $2
 `
@@ -169,6 +220,7 @@ func (q *Queries) ListCharacters(ctx context.Context, arg ListCharactersParams)
 			&i.CreatedAt,
 			&i.Filename,
 			&i.PriorityLevel,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -183,24 +235,238 @@ func (q *Queries) ListCharacters(ctx context.Context, arg ListCharactersParams)
 	return items, nil
 }
 
+const listCharactersByCursor = `-- name: ListCharactersByCursor :many
+SELECT id, name, src, updated_at, created_at, filename, priority_level, deleted_at
+FROM characters
+WHERE deleted_at IS NULL
+  AND id < $2
+ORDER BY id DESC
+LIMIT $1
+`
+
+type ListCharactersByCursorParams struct {
+	Limit    int32 `json:"limit"`
+	CursorID int64 `json:"cursor_id"`
+}
+
+func (q *Queries) ListCharactersByCursor(ctx context.Context, arg ListCharactersByCursorParams) ([]Character, error) {
+	rows, err := q.db.QueryContext(ctx, listCharactersByCursor, arg.Limit, arg.CursorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Character{}
+	for rows.Next() {
+		var i Character
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Src,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.Filename,
+			&i.PriorityLevel,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeletedCharacters = `-- name: ListDeletedCharacters :many
+SELECT id, name, src, updated_at, created_at, filename, priority_level, deleted_at
+FROM characters
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC,
+  id DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListDeletedCharactersParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListDeletedCharacters(ctx context.Context, arg ListDeletedCharactersParams) ([]Character, error) {
+	rows, err := q.db.QueryContext(ctx, listDeletedCharacters, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Character{}
+	for rows.Next() {
+		var i Character
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Src,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.Filename,
+			&i.PriorityLevel,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExistingCharacterIDs = `-- name: ListExistingCharacterIDs :many
+SELECT id
+FROM characters
+WHERE id = ANY($1::bigint [])
+  AND deleted_at IS NULL
+ORDER BY id
+`
+
+func (q *Queries) ListExistingCharacterIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listExistingCharacterIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPurgeableCharacters = `-- name: ListPurgeableCharacters :many
+SELECT id, name, src, updated_at, created_at, filename, priority_level, deleted_at
+FROM characters
+WHERE deleted_at < $1::timestamptz
+ORDER BY deleted_at
+LIMIT $2
+`
+
+type ListPurgeableCharactersParams struct {
+	DeletedBefore time.Time `json:"deleted_before"`
+	MaxResults    int32     `json:"max_results"`
+}
+
+func (q *Queries) ListPurgeableCharacters(ctx context.Context, arg ListPurgeableCharactersParams) ([]Character, error) {
+	rows, err := q.db.QueryContext(ctx, listPurgeableCharacters, arg.DeletedBefore, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Character{}
+	for rows.Next() {
+		var i Character
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Src,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.Filename,
+			&i.PriorityLevel,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const reorderCharacters = `-- name: ReorderCharacters :execrows
+UPDATE characters t
+SET priority_level = (cardinality($1::bigint []) - ord.idx + 1)::smallint,
+  updated_at = now()
+FROM unnest($1::bigint []) WITH ORDINALITY AS ord(id, idx)
+WHERE t.id = ord.id
+  AND t.deleted_at IS NULL
+`
+
+func (q *Queries) ReorderCharacters(ctx context.Context, ids []int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reorderCharacters, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreCharacter = `-- name: RestoreCharacter :one
+UPDATE characters
+SET deleted_at = NULL
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+RETURNING id, name, src, updated_at, created_at, filename, priority_level, deleted_at
+`
+
+func (q *Queries) RestoreCharacter(ctx context.Context, id int64) (Character, error) {
+	row := q.db.QueryRowContext(ctx, restoreCharacter, id)
+	var i Character
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Src,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.Filename,
+		&i.PriorityLevel,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
 const searchCharacters = `-- name: SearchCharacters :many
-SELECT DISTINCT id, name, src, updated_at, created_at, filename, priority_level
+SELECT DISTINCT id, name, src, updated_at, created_at, filename, priority_level, deleted_at
 FROM characters
-WHERE name LIKE '%' || COALESCE($3) || '%'
-  OR filename LIKE '%' || COALESCE($3) || '%'
+WHERE deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1
+    FROM unnest($3::text []) AS p(pattern)
+    WHERE normalize_search_text(name) LIKE p.pattern ESCAPE '\'
+      OR normalize_search_text(filename) LIKE p.pattern ESCAPE '\'
+  )
 ORDER BY priority_level DESC,
   id DESC
 LIMIT $1 OFFSET $2
 `
 
 type SearchCharactersParams struct {
-	Limit  int32          `json:"limit"`
-	Offset int32          `json:"offset"`
-	Query  sql.NullString `json:"query"`
+	Limit    int32    `json:"limit"`
+	Offset   int32    `json:"offset"`
+	Patterns []string `json:"patterns"`
 }
 
 func (q *Queries) SearchCharacters(ctx context.Context, arg SearchCharactersParams) ([]Character, error) {
-	rows, err := q.db.QueryContext(ctx, searchCharacters, arg.Limit, arg.Offset, arg.Query)
+	rows, err := q.db.QueryContext(ctx, searchCharacters, arg.Limit, arg.Offset, pq.Array(arg.Patterns))
 	if err != nil {
 		return nil, err
 	}
@@ -216,6 +482,7 @@ func (q *Queries) SearchCharacters(ctx context.Context, arg SearchCharactersPara
 			&i.CreatedAt,
 			&i.Filename,
 			&i.PriorityLevel,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -230,34 +497,119 @@ func (q *Queries) SearchCharacters(ctx context.Context, arg SearchCharactersPara
 	return items, nil
 }
 
-const updateCharacter = `-- name: UpdateCharacter :one
+const searchCharactersByCursor = `-- name: SearchCharactersByCursor :many
+SELECT DISTINCT id, name, src, updated_at, created_at, filename, priority_level, deleted_at
+FROM characters
+WHERE deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1
+    FROM unnest($2::text []) AS p(pattern)
+    WHERE normalize_search_text(name) LIKE p.pattern ESCAPE '\'
+      OR normalize_search_text(filename) LIKE p.pattern ESCAPE '\'
+  )
+  AND (
+    priority_level < $3
+    OR (
+      priority_level = $3
+      AND id < $4
+    )
+  )
+ORDER BY priority_level DESC,
+  id DESC
+LIMIT $1
+`
+
+type SearchCharactersByCursorParams struct {
+	Limit               int32    `json:"limit"`
+	Patterns            []string `json:"patterns"`
+	CursorPriorityLevel int16    `json:"cursor_priority_level"`
+	CursorID            int64    `json:"cursor_id"`
+}
+
+func (q *Queries) SearchCharactersByCursor(ctx context.Context, arg SearchCharactersByCursorParams) ([]Character, error) {
+	rows, err := q.db.QueryContext(ctx, searchCharactersByCursor,
+		arg.Limit,
+		pq.Array(arg.Patterns),
+		arg.CursorPriorityLevel,
+		arg.CursorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Character{}
+	for rows.Next() {
+		var i Character
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Src,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.Filename,
+			&i.PriorityLevel,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const softDeleteCharacter = `-- name: SoftDeleteCharacter :execrows
 UPDATE characters
-SET name = $2,
-  src = $3,
-  filename = $4,
-  updated_at = $5,
-  priority_level = $6
+SET deleted_at = now()
 WHERE id = $1
-RETURNING id, name, src, updated_at, created_at, filename, priority_level
+  AND deleted_at IS NULL
+`
+
+func (q *Queries) SoftDeleteCharacter(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, softDeleteCharacter, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateCharacter = `-- name: UpdateCharacter :one
+UPDATE characters
+SET name = $1,
+  src = $2,
+  filename = $3,
+  updated_at = $4,
+  priority_level = $5
+WHERE id = $6
+  AND updated_at = $7
+RETURNING id, name, src, updated_at, created_at, filename, priority_level, deleted_at
 `
 
 type UpdateCharacterParams struct {
-	ID            int64          `json:"id"`
-	Name          string         `json:"name"`
-	Src           string         `json:"src"`
-	Filename      sql.NullString `json:"filename"`
-	UpdatedAt     time.Time      `json:"updated_at"`
-	PriorityLevel int16          `json:"priority_level"`
+	Name              string         `json:"name"`
+	Src               string         `json:"src"`
+	Filename          sql.NullString `json:"filename"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	PriorityLevel     int16          `json:"priority_level"`
+	ID                int64          `json:"id"`
+	ExpectedUpdatedAt time.Time      `json:"expected_updated_at"`
 }
 
+// updated_atが一致しない場合は他の操作で更新済みのため、更新せずに0件を返す
 func (q *Queries) UpdateCharacter(ctx context.Context, arg UpdateCharacterParams) (Character, error) {
 	row := q.db.QueryRowContext(ctx, updateCharacter,
-		arg.ID,
 		arg.Name,
 		arg.Src,
 		arg.Filename,
 		arg.UpdatedAt,
 		arg.PriorityLevel,
+		arg.ID,
+		arg.ExpectedUpdatedAt,
 	)
 	var i Character
 	err := row.Scan(
@@ -268,6 +620,7 @@ func (q *Queries) UpdateCharacter(ctx context.Context, arg UpdateCharacterParams
 		&i.CreatedAt,
 		&i.Filename,
 		&i.PriorityLevel,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -294,3 +294,82 @@ func TestUpdateCharacter(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchCharacters(t *testing.T) {
+	defer TearDown(t, testQueries)
+
+	// 名前・ファイル名はnormalize_search_textで正規化して比較する
+	_, err := testQueries.ExecQuery(context.Background(), `
+		INSERT INTO characters (id, name, src, filename, priority_level)
+		VALUES
+		(10001, 'モンタ', 'test_character_src_10001', 'monta.png', 2),
+		(10002, 'くま', 'test_character_src_10002', 'KUMA_Monta.png', 1),
+		(10003, 'うさぎ', 'test_character_src_10003', NULL, 0);
+	`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		patterns []string
+		wantIDs  []int64
+	}{
+		{
+			name:     "正常系（カタカナの名前にひらがなで一致する場合）",
+			patterns: []string{"%もんた%"},
+			wantIDs:  []int64{10001},
+		},
+		{
+			name:     "正常系（ファイル名に大文字・小文字を区別せず一致する場合）",
+			patterns: []string{"%monta%"},
+			wantIDs:  []int64{10001, 10002},
+		},
+		{
+			name:     "正常系（いずれかのパターンに一致する場合）",
+			patterns: []string{"%うさぎ%", "%kuma%"},
+			wantIDs:  []int64{10002, 10003},
+		},
+		{
+			name:     "正常系（一致しない場合）",
+			patterns: []string{"%ねこ%"},
+			wantIDs:  []int64{},
+		},
+		{
+			name:     "正常系（エスケープした%はワイルドカードとして扱わない）",
+			patterns: []string{`%\%%`},
+			wantIDs:  []int64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			characters, err := testQueries.SearchCharacters(context.Background(), db.SearchCharactersParams{
+				Limit:    10,
+				Offset:   0,
+				Patterns: tt.patterns,
+			})
+			require.NoError(t, err)
+			gotIDs := []int64{}
+			for _, c := range characters {
+				gotIDs = append(gotIDs, c.ID)
+			}
+			require.ElementsMatch(t, tt.wantIDs, gotIDs)
+
+			count, err := testQueries.CountSearchCharacters(context.Background(), tt.patterns)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(tt.wantIDs)), count)
+
+			// 優先度が最も高いキャラクターの次から取得する
+			byCursor, err := testQueries.SearchCharactersByCursor(context.Background(), db.SearchCharactersByCursorParams{
+				Limit:               10,
+				Patterns:            tt.patterns,
+				CursorPriorityLevel: 2,
+				CursorID:            10001,
+			})
+			require.NoError(t, err)
+			for _, c := range byCursor {
+				require.NotEqual(t, int64(10001), c.ID)
+				require.Contains(t, tt.wantIDs, c.ID)
+			}
+		})
+	}
+}
@@ -8,12 +8,27 @@ package db
 import (
 	"context"
 	"time"
+
+	"github.com/lib/pq"
 )
 
+const countChildCategories = `-- name: CountChildCategories :one
+SELECT count(*)
+FROM child_categories
+WHERE deleted_at IS NULL
+`
+
+func (q *Queries) CountChildCategories(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countChildCategories)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createChildCategory = `-- name: CreateChildCategory :one
 INSERT INTO child_categories (name, parent_id, priority_level)
 VALUES ($1, $2, $3)
-RETURNING id, name, parent_id, updated_at, created_at, priority_level
+RETURNING id, name, parent_id, updated_at, created_at, priority_level, deleted_at
 `
 
 type CreateChildCategoryParams struct {
@@ -32,6 +47,7 @@ func (q *Queries) CreateChildCategory(ctx context.Context, arg CreateChildCatego
 		&i.UpdatedAt,
 		&i.CreatedAt,
 		&i.PriorityLevel,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -57,9 +73,10 @@ func (q *Queries) DeleteChildCategory(ctx context.Context, id int64) error {
 }
 
 const getChildCategoriesByParentID = `-- name: GetChildCategoriesByParentID :many
-SELECT id, name, parent_id, updated_at, created_at, priority_level
+SELECT id, name, parent_id, updated_at, created_at, priority_level, deleted_at
 FROM child_categories
 WHERE parent_id = $1
+  AND deleted_at IS NULL
 `
 
 func (q *Queries) GetChildCategoriesByParentID(ctx context.Context, parentID int64) ([]ChildCategory, error) {
@@ -78,6 +95,7 @@ func (q *Queries) GetChildCategoriesByParentID(ctx context.Context, parentID int
 			&i.UpdatedAt,
 			&i.CreatedAt,
 			&i.PriorityLevel,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -93,9 +111,10 @@ func (q *Queries) GetChildCategoriesByParentID(ctx context.Context, parentID int
 }
 
 const getChildCategory = `-- name: GetChildCategory :one
-SELECT id, name, parent_id, updated_at, created_at, priority_level
+SELECT id, name, parent_id, updated_at, created_at, priority_level, deleted_at
 FROM child_categories
 WHERE id = $1
+  AND deleted_at IS NULL
 LIMIT 1
 `
 
@@ -109,13 +128,15 @@ func (q *Queries) GetChildCategory(ctx context.Context, id int64) (ChildCategory
 		&i.UpdatedAt,
 		&i.CreatedAt,
 		&i.PriorityLevel,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const listChildCategories = `-- name: ListChildCategories :many
-SELECT id, name, parent_id, updated_at, created_at, priority_level
+SELECT id, name, parent_id, updated_at, created_at, priority_level, deleted_at
 FROM child_categories
+WHERE deleted_at IS NULL
 ORDER BY priority_level DESC,
   id DESC
 LIMIT $1 OFFSET $2
@@ -142,6 +163,46 @@ func (q *Queries) ListChildCategories(ctx context.Context, arg ListChildCategori
 			&i.UpdatedAt,
 			&i.CreatedAt,
 			&i.PriorityLevel,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChildCategoriesByIDs = `-- name: ListChildCategoriesByIDs :many
+SELECT id, name, parent_id, updated_at, created_at, priority_level, deleted_at
+FROM child_categories
+WHERE id = ANY($1::bigint [])
+  AND deleted_at IS NULL
+ORDER BY id
+`
+
+func (q *Queries) ListChildCategoriesByIDs(ctx context.Context, ids []int64) ([]ChildCategory, error) {
+	rows, err := q.db.QueryContext(ctx, listChildCategoriesByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ChildCategory{}
+	for rows.Next() {
+		var i ChildCategory
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.ParentID,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.PriorityLevel,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -156,31 +217,146 @@ func (q *Queries) ListChildCategories(ctx context.Context, arg ListChildCategori
 	return items, nil
 }
 
+const listChildCategoryIDsByParentIDIncludingDeleted = `-- name: ListChildCategoryIDsByParentIDIncludingDeleted :many
+SELECT id
+FROM child_categories
+WHERE parent_id = $1
+`
+
+func (q *Queries) ListChildCategoryIDsByParentIDIncludingDeleted(ctx context.Context, parentID int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listChildCategoryIDsByParentIDIncludingDeleted, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExistingChildCategoryIDs = `-- name: ListExistingChildCategoryIDs :many
+SELECT id
+FROM child_categories
+WHERE id = ANY($1::bigint [])
+  AND deleted_at IS NULL
+ORDER BY id
+`
+
+func (q *Queries) ListExistingChildCategoryIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listExistingChildCategoryIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const reorderChildCategories = `-- name: ReorderChildCategories :execrows
+UPDATE child_categories t
+SET priority_level = (cardinality($1::bigint []) - ord.idx + 1)::smallint,
+  updated_at = now()
+FROM unnest($1::bigint []) WITH ORDINALITY AS ord(id, idx)
+WHERE t.id = ord.id
+  AND t.deleted_at IS NULL
+`
+
+func (q *Queries) ReorderChildCategories(ctx context.Context, ids []int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reorderChildCategories, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreChildCategoriesByParentID = `-- name: RestoreChildCategoriesByParentID :exec
+UPDATE child_categories
+SET deleted_at = NULL
+WHERE parent_id = $1
+  AND deleted_at = $2::timestamptz
+`
+
+type RestoreChildCategoriesByParentIDParams struct {
+	ParentID  int64     `json:"parent_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+func (q *Queries) RestoreChildCategoriesByParentID(ctx context.Context, arg RestoreChildCategoriesByParentIDParams) error {
+	_, err := q.db.ExecContext(ctx, restoreChildCategoriesByParentID, arg.ParentID, arg.DeletedAt)
+	return err
+}
+
+const softDeleteChildCategoriesByParentID = `-- name: SoftDeleteChildCategoriesByParentID :exec
+UPDATE child_categories
+SET deleted_at = $1::timestamptz
+WHERE parent_id = $2
+  AND deleted_at IS NULL
+`
+
+type SoftDeleteChildCategoriesByParentIDParams struct {
+	DeletedAt time.Time `json:"deleted_at"`
+	ParentID  int64     `json:"parent_id"`
+}
+
+func (q *Queries) SoftDeleteChildCategoriesByParentID(ctx context.Context, arg SoftDeleteChildCategoriesByParentIDParams) error {
+	_, err := q.db.ExecContext(ctx, softDeleteChildCategoriesByParentID, arg.DeletedAt, arg.ParentID)
+	return err
+}
+
 const updateChildCategory = `-- name: UpdateChildCategory :one
 UPDATE child_categories
-SET name = $2,
-  parent_id = $3,
-  updated_at = $4,
-  priority_level = $5
-WHERE id = $1
-RETURNING id, name, parent_id, updated_at, created_at, priority_level
+SET name = $1,
+  parent_id = $2,
+  updated_at = $3,
+  priority_level = $4
+WHERE id = $5
+  AND updated_at = $6
+RETURNING id, name, parent_id, updated_at, created_at, priority_level, deleted_at
 `
 
 type UpdateChildCategoryParams struct {
-	ID            int64     `json:"id"`
-	Name          string    `json:"name"`
-	ParentID      int64     `json:"parent_id"`
-	UpdatedAt     time.Time `json:"updated_at"`
-	PriorityLevel int16     `json:"priority_level"`
+	Name              string    `json:"name"`
+	ParentID          int64     `json:"parent_id"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	PriorityLevel     int16     `json:"priority_level"`
+	ID                int64     `json:"id"`
+	ExpectedUpdatedAt time.Time `json:"expected_updated_at"`
 }
 
+// updated_atが一致しない場合は他の操作で更新済みのため、更新せずに0件を返す
 func (q *Queries) UpdateChildCategory(ctx context.Context, arg UpdateChildCategoryParams) (ChildCategory, error) {
 	row := q.db.QueryRowContext(ctx, updateChildCategory,
-		arg.ID,
 		arg.Name,
 		arg.ParentID,
 		arg.UpdatedAt,
 		arg.PriorityLevel,
+		arg.ID,
+		arg.ExpectedUpdatedAt,
 	)
 	var i ChildCategory
 	err := row.Scan(
@@ -190,6 +366,7 @@ func (q *Queries) UpdateChildCategory(ctx context.Context, arg UpdateChildCatego
 		&i.UpdatedAt,
 		&i.CreatedAt,
 		&i.PriorityLevel,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -0,0 +1,176 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: daily_illustrations.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createDailyIllustration = `-- name: CreateDailyIllustration :exec
+INSERT INTO daily_illustrations (date, image_id)
+VALUES ($1::date, $2) ON CONFLICT (date) DO
+UPDATE
+SET image_id = EXCLUDED.image_id,
+  is_override = false,
+  updated_at = now()
+WHERE EXISTS (
+    SELECT 1
+    FROM images
+    WHERE images.id = daily_illustrations.image_id
+      AND images.deleted_at IS NOT NULL
+  )
+`
+
+type CreateDailyIllustrationParams struct {
+	Date    time.Time `json:"date"`
+	ImageID int64     `json:"image_id"`
+}
+
+func (q *Queries) CreateDailyIllustration(ctx context.Context, arg CreateDailyIllustrationParams) error {
+	_, err := q.db.ExecContext(ctx, createDailyIllustration, arg.Date, arg.ImageID)
+	return err
+}
+
+const deleteDailyIllustrationOverride = `-- name: DeleteDailyIllustrationOverride :execrows
+DELETE FROM daily_illustrations
+WHERE date = $1::date
+  AND is_override
+`
+
+func (q *Queries) DeleteDailyIllustrationOverride(ctx context.Context, date time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteDailyIllustrationOverride, date)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getDailyIllustration = `-- name: GetDailyIllustration :one
+SELECT date, image_id, is_override, updated_at, created_at
+FROM daily_illustrations
+WHERE date = $1::date
+  AND EXISTS (
+    SELECT 1
+    FROM images
+    WHERE images.id = daily_illustrations.image_id
+      AND images.deleted_at IS NULL
+  )
+LIMIT 1
+`
+
+func (q *Queries) GetDailyIllustration(ctx context.Context, date time.Time) (DailyIllustration, error) {
+	row := q.db.QueryRowContext(ctx, getDailyIllustration, date)
+	var i DailyIllustration
+	err := row.Scan(
+		&i.Date,
+		&i.ImageID,
+		&i.IsOverride,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listDailyIllustrationOverrides = `-- name: ListDailyIllustrationOverrides :many
+SELECT date, image_id, is_override, updated_at, created_at
+FROM daily_illustrations
+WHERE date >= $1::date
+  AND is_override
+ORDER BY date
+`
+
+func (q *Queries) ListDailyIllustrationOverrides(ctx context.Context, fromDate time.Time) ([]DailyIllustration, error) {
+	rows, err := q.db.QueryContext(ctx, listDailyIllustrationOverrides, fromDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []DailyIllustration{}
+	for rows.Next() {
+		var i DailyIllustration
+		if err := rows.Scan(
+			&i.Date,
+			&i.ImageID,
+			&i.IsOverride,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDailyImageIDs = `-- name: ListDailyImageIDs :many
+SELECT image_id
+FROM daily_illustrations
+WHERE date >= $1::date
+  AND date < $2::date
+`
+
+type ListDailyImageIDsParams struct {
+	FromDate time.Time `json:"from_date"`
+	ToDate   time.Time `json:"to_date"`
+}
+
+func (q *Queries) ListDailyImageIDs(ctx context.Context, arg ListDailyImageIDsParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listDailyImageIDs, arg.FromDate, arg.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var image_id int64
+		if err := rows.Scan(&image_id); err != nil {
+			return nil, err
+		}
+		items = append(items, image_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertDailyIllustrationOverride = `-- name: UpsertDailyIllustrationOverride :one
+INSERT INTO daily_illustrations (date, image_id, is_override)
+VALUES ($1::date, $2, true) ON CONFLICT (date) DO
+UPDATE
+SET image_id = EXCLUDED.image_id,
+  is_override = true,
+  updated_at = now()
+RETURNING date, image_id, is_override, updated_at, created_at
+`
+
+type UpsertDailyIllustrationOverrideParams struct {
+	Date    time.Time `json:"date"`
+	ImageID int64     `json:"image_id"`
+}
+
+func (q *Queries) UpsertDailyIllustrationOverride(ctx context.Context, arg UpsertDailyIllustrationOverrideParams) (DailyIllustration, error) {
+	row := q.db.QueryRowContext(ctx, upsertDailyIllustrationOverride, arg.Date, arg.ImageID)
+	var i DailyIllustration
+	err := row.Scan(
+		&i.Date,
+		&i.ImageID,
+		&i.IsOverride,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
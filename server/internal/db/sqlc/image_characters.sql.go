@@ -7,12 +7,68 @@ package db
 
 import (
 	"context"
+
+	"github.com/lib/pq"
 )
 
+const clearImageCharacterPositions = `-- name: ClearImageCharacterPositions :exec
+UPDATE image_characters_relations
+SET position = NULL
+WHERE character_id = $1
+  AND position IS NOT NULL
+`
+
+func (q *Queries) ClearImageCharacterPositions(ctx context.Context, characterID int64) error {
+	_, err := q.db.ExecContext(ctx, clearImageCharacterPositions, characterID)
+	return err
+}
+
+const countCharactersByImageIDs = `-- name: CountCharactersByImageIDs :many
+SELECT characters.id,
+  characters.name,
+  count(DISTINCT icr.image_id) AS count
+FROM image_characters_relations icr
+  JOIN characters ON characters.id = icr.character_id
+WHERE icr.image_id = ANY($1::bigint [])
+  AND characters.deleted_at IS NULL
+GROUP BY characters.id
+ORDER BY characters.priority_level DESC,
+  characters.id DESC
+`
+
+type CountCharactersByImageIDsRow struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+func (q *Queries) CountCharactersByImageIDs(ctx context.Context, imageIds []int64) ([]CountCharactersByImageIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, countCharactersByImageIDs, pq.Array(imageIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountCharactersByImageIDsRow{}
+	for rows.Next() {
+		var i CountCharactersByImageIDsRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createImageCharacterRelations = `-- name: CreateImageCharacterRelations :one
 INSERT INTO image_characters_relations (image_id, character_id)
 VALUES ($1, $2)
-RETURNING id, image_id, character_id
+RETURNING id, image_id, character_id, position
 `
 
 type CreateImageCharacterRelationsParams struct {
@@ -23,7 +79,12 @@ type CreateImageCharacterRelationsParams struct {
 func (q *Queries) CreateImageCharacterRelations(ctx context.Context, arg CreateImageCharacterRelationsParams) (ImageCharactersRelation, error) {
 	row := q.db.QueryRowContext(ctx, createImageCharacterRelations, arg.ImageID, arg.CharacterID)
 	var i ImageCharactersRelation
-	err := row.Scan(&i.ID, &i.ImageID, &i.CharacterID)
+	err := row.Scan(
+		&i.ID,
+		&i.ImageID,
+		&i.CharacterID,
+		&i.Position,
+	)
 	return i, err
 }
 
@@ -57,8 +118,49 @@ func (q *Queries) DeleteImageCharacterRelations(ctx context.Context, id int64) e
 	return err
 }
 
+const listImageCharacterRelationsByCharacterID = `-- name: ListImageCharacterRelationsByCharacterID :many
+SELECT id, image_id, character_id, position
+FROM image_characters_relations
+WHERE character_id = $1
+  AND EXISTS (
+    SELECT 1
+    FROM images
+    WHERE images.id = image_characters_relations.image_id
+      AND images.deleted_at IS NULL
+  )
+ORDER BY image_id DESC
+`
+
+func (q *Queries) ListImageCharacterRelationsByCharacterID(ctx context.Context, characterID int64) ([]ImageCharactersRelation, error) {
+	rows, err := q.db.QueryContext(ctx, listImageCharacterRelationsByCharacterID, characterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ImageCharactersRelation{}
+	for rows.Next() {
+		var i ImageCharactersRelation
+		if err := rows.Scan(
+			&i.ID,
+			&i.ImageID,
+			&i.CharacterID,
+			&i.Position,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listImageCharacterRelationsByCharacterIDWIthPagination = `-- name: ListImageCharacterRelationsByCharacterIDWIthPagination :many
-SELECT id, image_id, character_id
+SELECT id, image_id, character_id, position
 FROM image_characters_relations
 WHERE character_id = $3
 ORDER BY image_id DESC
@@ -80,7 +182,12 @@ func (q *Queries) ListImageCharacterRelationsByCharacterIDWIthPagination(ctx con
 	items := []ImageCharactersRelation{}
 	for rows.Next() {
 		var i ImageCharactersRelation
-		if err := rows.Scan(&i.ID, &i.ImageID, &i.CharacterID); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.ImageID,
+			&i.CharacterID,
+			&i.Position,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -95,9 +202,15 @@ func (q *Queries) ListImageCharacterRelationsByCharacterIDWIthPagination(ctx con
 }
 
 const listImageCharacterRelationsByImageID = `-- name: ListImageCharacterRelationsByImageID :many
-SELECT id, image_id, character_id
+SELECT id, image_id, character_id, position
 FROM image_characters_relations
 WHERE image_id = $1
+  AND EXISTS (
+    SELECT 1
+    FROM characters
+    WHERE characters.id = image_characters_relations.character_id
+      AND characters.deleted_at IS NULL
+  )
 ORDER BY image_id DESC
 `
 
@@ -110,7 +223,12 @@ func (q *Queries) ListImageCharacterRelationsByImageID(ctx context.Context, imag
 	items := []ImageCharactersRelation{}
 	for rows.Next() {
 		var i ImageCharactersRelation
-		if err := rows.Scan(&i.ID, &i.ImageID, &i.CharacterID); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.ImageID,
+			&i.CharacterID,
+			&i.Position,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -124,12 +242,33 @@ func (q *Queries) ListImageCharacterRelationsByImageID(ctx context.Context, imag
 	return items, nil
 }
 
+const setImageCharacterPositions = `-- name: SetImageCharacterPositions :execrows
+UPDATE image_characters_relations icr
+SET position = ord.position
+FROM unnest($2::bigint []) WITH ORDINALITY AS ord(image_id, position)
+WHERE icr.character_id = $1
+  AND icr.image_id = ord.image_id
+`
+
+type SetImageCharacterPositionsParams struct {
+	CharacterID int64   `json:"character_id"`
+	ImageIds    []int64 `json:"image_ids"`
+}
+
+func (q *Queries) SetImageCharacterPositions(ctx context.Context, arg SetImageCharacterPositionsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setImageCharacterPositions, arg.CharacterID, pq.Array(arg.ImageIds))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateImageCharacterRelations = `-- name: UpdateImageCharacterRelations :one
 UPDATE image_characters_relations
 SET image_id = $2,
   character_id = $3
 WHERE id = $1
-RETURNING id, image_id, character_id
+RETURNING id, image_id, character_id, position
 `
 
 type UpdateImageCharacterRelationsParams struct {
@@ -141,6 +280,11 @@ type UpdateImageCharacterRelationsParams struct {
 func (q *Queries) UpdateImageCharacterRelations(ctx context.Context, arg UpdateImageCharacterRelationsParams) (ImageCharactersRelation, error) {
 	row := q.db.QueryRowContext(ctx, updateImageCharacterRelations, arg.ID, arg.ImageID, arg.CharacterID)
 	var i ImageCharactersRelation
-	err := row.Scan(&i.ID, &i.ImageID, &i.CharacterID)
+	err := row.Scan(
+		&i.ID,
+		&i.ImageID,
+		&i.CharacterID,
+		&i.Position,
+	)
 	return i, err
 }
@@ -7,12 +7,75 @@ package db
 
 import (
 	"context"
+
+	"github.com/lib/pq"
 )
 
+const clearImageChildCategoryPositions = `-- name: ClearImageChildCategoryPositions :exec
+UPDATE image_child_categories_relations
+SET position = NULL
+WHERE child_category_id = $1
+  AND position IS NOT NULL
+`
+
+func (q *Queries) ClearImageChildCategoryPositions(ctx context.Context, childCategoryID int64) error {
+	_, err := q.db.ExecContext(ctx, clearImageChildCategoryPositions, childCategoryID)
+	return err
+}
+
+const countChildCategoriesByImageIDs = `-- name: CountChildCategoriesByImageIDs :many
+SELECT child_categories.id,
+  child_categories.name,
+  child_categories.parent_id,
+  count(DISTINCT iccr.image_id) AS count
+FROM image_child_categories_relations iccr
+  JOIN child_categories ON child_categories.id = iccr.child_category_id
+WHERE iccr.image_id = ANY($1::bigint [])
+  AND child_categories.deleted_at IS NULL
+GROUP BY child_categories.id
+ORDER BY child_categories.priority_level DESC,
+  child_categories.id DESC
+`
+
+type CountChildCategoriesByImageIDsRow struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	ParentID int64  `json:"parent_id"`
+	Count    int64  `json:"count"`
+}
+
+func (q *Queries) CountChildCategoriesByImageIDs(ctx context.Context, imageIds []int64) ([]CountChildCategoriesByImageIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, countChildCategoriesByImageIDs, pq.Array(imageIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountChildCategoriesByImageIDsRow{}
+	for rows.Next() {
+		var i CountChildCategoriesByImageIDsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.ParentID,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createImageChildCategoryRelations = `-- name: CreateImageChildCategoryRelations :one
 INSERT INTO image_child_categories_relations (image_id, child_category_id)
 VALUES ($1, $2)
-RETURNING id, image_id, child_category_id
+RETURNING id, image_id, child_category_id, position
 `
 
 type CreateImageChildCategoryRelationsParams struct {
@@ -23,7 +86,12 @@ type CreateImageChildCategoryRelationsParams struct {
 func (q *Queries) CreateImageChildCategoryRelations(ctx context.Context, arg CreateImageChildCategoryRelationsParams) (ImageChildCategoriesRelation, error) {
 	row := q.db.QueryRowContext(ctx, createImageChildCategoryRelations, arg.ImageID, arg.ChildCategoryID)
 	var i ImageChildCategoriesRelation
-	err := row.Scan(&i.ID, &i.ImageID, &i.ChildCategoryID)
+	err := row.Scan(
+		&i.ID,
+		&i.ImageID,
+		&i.ChildCategoryID,
+		&i.Position,
+	)
 	return i, err
 }
 
@@ -58,9 +126,15 @@ func (q *Queries) DeleteImageChildCategoryRelations(ctx context.Context, id int6
 }
 
 const listImageChildCategoryRelationsByChildCategoryID = `-- name: ListImageChildCategoryRelationsByChildCategoryID :many
-SELECT id, image_id, child_category_id
+SELECT id, image_id, child_category_id, position
 FROM image_child_categories_relations
 WHERE child_category_id = $1
+  AND EXISTS (
+    SELECT 1
+    FROM images
+    WHERE images.id = image_child_categories_relations.image_id
+      AND images.deleted_at IS NULL
+  )
 ORDER BY child_category_id DESC
 `
 
@@ -73,7 +147,12 @@ func (q *Queries) ListImageChildCategoryRelationsByChildCategoryID(ctx context.C
 	items := []ImageChildCategoriesRelation{}
 	for rows.Next() {
 		var i ImageChildCategoriesRelation
-		if err := rows.Scan(&i.ID, &i.ImageID, &i.ChildCategoryID); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.ImageID,
+			&i.ChildCategoryID,
+			&i.Position,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -88,7 +167,7 @@ func (q *Queries) ListImageChildCategoryRelationsByChildCategoryID(ctx context.C
 }
 
 const listImageChildCategoryRelationsByChildCategoryIDWithPagination = `-- name: ListImageChildCategoryRelationsByChildCategoryIDWithPagination :many
-SELECT id, image_id, child_category_id
+SELECT id, image_id, child_category_id, position
 FROM image_child_categories_relations
 WHERE child_category_id = $3
 ORDER BY image_id DESC
@@ -110,7 +189,12 @@ func (q *Queries) ListImageChildCategoryRelationsByChildCategoryIDWithPagination
 	items := []ImageChildCategoriesRelation{}
 	for rows.Next() {
 		var i ImageChildCategoriesRelation
-		if err := rows.Scan(&i.ID, &i.ImageID, &i.ChildCategoryID); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.ImageID,
+			&i.ChildCategoryID,
+			&i.Position,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -125,9 +209,15 @@ func (q *Queries) ListImageChildCategoryRelationsByChildCategoryIDWithPagination
 }
 
 const listImageChildCategoryRelationsByImageID = `-- name: ListImageChildCategoryRelationsByImageID :many
-SELECT id, image_id, child_category_id
+SELECT id, image_id, child_category_id, position
 FROM image_child_categories_relations
 WHERE image_id = $1
+  AND EXISTS (
+    SELECT 1
+    FROM child_categories
+    WHERE child_categories.id = image_child_categories_relations.child_category_id
+      AND child_categories.deleted_at IS NULL
+  )
 ORDER BY image_id DESC
 `
 
@@ -140,7 +230,12 @@ func (q *Queries) ListImageChildCategoryRelationsByImageID(ctx context.Context,
 	items := []ImageChildCategoriesRelation{}
 	for rows.Next() {
 		var i ImageChildCategoriesRelation
-		if err := rows.Scan(&i.ID, &i.ImageID, &i.ChildCategoryID); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.ImageID,
+			&i.ChildCategoryID,
+			&i.Position,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -154,12 +249,33 @@ func (q *Queries) ListImageChildCategoryRelationsByImageID(ctx context.Context,
 	return items, nil
 }
 
+const setImageChildCategoryPositions = `-- name: SetImageChildCategoryPositions :execrows
+UPDATE image_child_categories_relations iccr
+SET position = ord.position
+FROM unnest($2::bigint []) WITH ORDINALITY AS ord(image_id, position)
+WHERE iccr.child_category_id = $1
+  AND iccr.image_id = ord.image_id
+`
+
+type SetImageChildCategoryPositionsParams struct {
+	ChildCategoryID int64   `json:"child_category_id"`
+	ImageIds        []int64 `json:"image_ids"`
+}
+
+func (q *Queries) SetImageChildCategoryPositions(ctx context.Context, arg SetImageChildCategoryPositionsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setImageChildCategoryPositions, arg.ChildCategoryID, pq.Array(arg.ImageIds))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateImageChildCategoryRelations = `-- name: UpdateImageChildCategoryRelations :one
 UPDATE image_child_categories_relations
 SET image_id = $2,
   child_category_id = $3
 WHERE id = $1
-RETURNING id, image_id, child_category_id
+RETURNING id, image_id, child_category_id, position
 `
 
 type UpdateImageChildCategoryRelationsParams struct {
@@ -171,6 +287,11 @@ type UpdateImageChildCategoryRelationsParams struct {
 func (q *Queries) UpdateImageChildCategoryRelations(ctx context.Context, arg UpdateImageChildCategoryRelationsParams) (ImageChildCategoriesRelation, error) {
 	row := q.db.QueryRowContext(ctx, updateImageChildCategoryRelations, arg.ID, arg.ImageID, arg.ChildCategoryID)
 	var i ImageChildCategoriesRelation
-	err := row.Scan(&i.ID, &i.ImageID, &i.ChildCategoryID)
+	err := row.Scan(
+		&i.ID,
+		&i.ImageID,
+		&i.ChildCategoryID,
+		&i.Position,
+	)
 	return i, err
 }
@@ -61,6 +61,12 @@ const listImageParentCategoryRelationsByImageID = `-- name: ListImageParentCateg
 SELECT id, image_id, parent_category_id
 FROM image_parent_categories_relations
 WHERE image_id = $1
+  AND EXISTS (
+    SELECT 1
+    FROM parent_categories
+    WHERE parent_categories.id = image_parent_categories_relations.parent_category_id
+      AND parent_categories.deleted_at IS NULL
+  )
 ORDER BY image_id DESC
 `
 
@@ -91,6 +97,12 @@ const listImageParentCategoryRelationsByParentCategoryID = `-- name: ListImagePa
 SELECT id, image_id, parent_category_id
 FROM image_parent_categories_relations
 WHERE parent_category_id = $1
+  AND EXISTS (
+    SELECT 1
+    FROM images
+    WHERE images.id = image_parent_categories_relations.image_id
+      AND images.deleted_at IS NULL
+  )
 ORDER BY parent_category_id DESC
 `
 
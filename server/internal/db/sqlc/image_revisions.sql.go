@@ -0,0 +1,181 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: image_revisions.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+const countImageRevisions = `-- name: CountImageRevisions :one
+SELECT count(*)
+FROM image_revisions
+WHERE image_id = $1
+`
+
+func (q *Queries) CountImageRevisions(ctx context.Context, imageID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countImageRevisions, imageID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createImageRevision = `-- name: CreateImageRevision :one
+INSERT INTO image_revisions (
+    image_id,
+    title,
+    original_src,
+    simple_src,
+    original_filename,
+    simple_filename,
+    character_ids,
+    parent_category_ids,
+    child_category_ids,
+    operator_name
+  )
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7::bigint [],
+    $8::bigint [],
+    $9::bigint [],
+    $10
+  )
+RETURNING id, image_id, title, original_src, simple_src, original_filename, simple_filename, character_ids, parent_category_ids, child_category_ids, operator_name, created_at
+`
+
+type CreateImageRevisionParams struct {
+	ImageID           int64          `json:"image_id"`
+	Title             string         `json:"title"`
+	OriginalSrc       string         `json:"original_src"`
+	SimpleSrc         sql.NullString `json:"simple_src"`
+	OriginalFilename  string         `json:"original_filename"`
+	SimpleFilename    sql.NullString `json:"simple_filename"`
+	CharacterIds      []int64        `json:"character_ids"`
+	ParentCategoryIds []int64        `json:"parent_category_ids"`
+	ChildCategoryIds  []int64        `json:"child_category_ids"`
+	OperatorName      string         `json:"operator_name"`
+}
+
+func (q *Queries) CreateImageRevision(ctx context.Context, arg CreateImageRevisionParams) (ImageRevision, error) {
+	row := q.db.QueryRowContext(ctx, createImageRevision,
+		arg.ImageID,
+		arg.Title,
+		arg.OriginalSrc,
+		arg.SimpleSrc,
+		arg.OriginalFilename,
+		arg.SimpleFilename,
+		pq.Array(arg.CharacterIds),
+		pq.Array(arg.ParentCategoryIds),
+		pq.Array(arg.ChildCategoryIds),
+		arg.OperatorName,
+	)
+	var i ImageRevision
+	err := row.Scan(
+		&i.ID,
+		&i.ImageID,
+		&i.Title,
+		&i.OriginalSrc,
+		&i.SimpleSrc,
+		&i.OriginalFilename,
+		&i.SimpleFilename,
+		pq.Array(&i.CharacterIds),
+		pq.Array(&i.ParentCategoryIds),
+		pq.Array(&i.ChildCategoryIds),
+		&i.OperatorName,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getImageRevision = `-- name: GetImageRevision :one
+SELECT id, image_id, title, original_src, simple_src, original_filename, simple_filename, character_ids, parent_category_ids, child_category_ids, operator_name, created_at
+FROM image_revisions
+WHERE id = $1
+  AND image_id = $2
+LIMIT 1
+`
+
+type GetImageRevisionParams struct {
+	ID      int64 `json:"id"`
+	ImageID int64 `json:"image_id"`
+}
+
+func (q *Queries) GetImageRevision(ctx context.Context, arg GetImageRevisionParams) (ImageRevision, error) {
+	row := q.db.QueryRowContext(ctx, getImageRevision, arg.ID, arg.ImageID)
+	var i ImageRevision
+	err := row.Scan(
+		&i.ID,
+		&i.ImageID,
+		&i.Title,
+		&i.OriginalSrc,
+		&i.SimpleSrc,
+		&i.OriginalFilename,
+		&i.SimpleFilename,
+		pq.Array(&i.CharacterIds),
+		pq.Array(&i.ParentCategoryIds),
+		pq.Array(&i.ChildCategoryIds),
+		&i.OperatorName,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listImageRevisions = `-- name: ListImageRevisions :many
+SELECT id, image_id, title, original_src, simple_src, original_filename, simple_filename, character_ids, parent_category_ids, child_category_ids, operator_name, created_at
+FROM image_revisions
+WHERE image_id = $1
+ORDER BY id DESC
+LIMIT $3 OFFSET $2
+`
+
+type ListImageRevisionsParams struct {
+	ImageID int64 `json:"image_id"`
+	Offset  int32 `json:"offset"`
+	Limit   int32 `json:"limit"`
+}
+
+func (q *Queries) ListImageRevisions(ctx context.Context, arg ListImageRevisionsParams) ([]ImageRevision, error) {
+	rows, err := q.db.QueryContext(ctx, listImageRevisions, arg.ImageID, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ImageRevision{}
+	for rows.Next() {
+		var i ImageRevision
+		if err := rows.Scan(
+			&i.ID,
+			&i.ImageID,
+			&i.Title,
+			&i.OriginalSrc,
+			&i.SimpleSrc,
+			&i.OriginalFilename,
+			&i.SimpleFilename,
+			pq.Array(&i.CharacterIds),
+			pq.Array(&i.ParentCategoryIds),
+			pq.Array(&i.ChildCategoryIds),
+			&i.OperatorName,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,66 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: image_search_documents.sql
+
+package db
+
+import (
+	"context"
+)
+
+const listImageIDsWithoutSearchDocument = `-- name: ListImageIDsWithoutSearchDocument :many
+SELECT i.id
+FROM images i
+WHERE NOT EXISTS (
+    SELECT 1
+    FROM image_search_documents d
+    WHERE d.image_id = i.id
+  )
+ORDER BY i.id
+`
+
+func (q *Queries) ListImageIDsWithoutSearchDocument(ctx context.Context) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listImageIDsWithoutSearchDocument)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertImageSearchDocument = `-- name: UpsertImageSearchDocument :exec
+INSERT INTO image_search_documents (image_id, search_vector, updated_at)
+VALUES (
+    $1,
+    $2::text::tsvector,
+    now()
+  ) ON CONFLICT (image_id) DO
+UPDATE
+SET search_vector = EXCLUDED.search_vector,
+  updated_at = EXCLUDED.updated_at
+`
+
+type UpsertImageSearchDocumentParams struct {
+	ImageID      int64  `json:"image_id"`
+	SearchVector string `json:"search_vector"`
+}
+
+func (q *Queries) UpsertImageSearchDocument(ctx context.Context, arg UpsertImageSearchDocumentParams) error {
+	_, err := q.db.ExecContext(ctx, upsertImageSearchDocument, arg.ImageID, arg.SearchVector)
+	return err
+}
@@ -9,11 +9,51 @@ import (
 	"context"
 	"database/sql"
 	"time"
+
+	"github.com/lib/pq"
 )
 
+const addImageViewCounts = `-- name: AddImageViewCounts :execrows
+UPDATE images
+SET view_count = images.view_count + v.count
+FROM (
+    SELECT unnest($1::bigint []) AS id,
+      unnest($2::bigint []) AS count
+  ) v
+WHERE images.id = v.id
+  AND images.deleted_at IS NULL
+`
+
+type AddImageViewCountsParams struct {
+	Ids    []int64 `json:"ids"`
+	Counts []int64 `json:"counts"`
+}
+
+func (q *Queries) AddImageViewCounts(ctx context.Context, arg AddImageViewCountsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, addImageViewCounts, pq.Array(arg.Ids), pq.Array(arg.Counts))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const countDeletedImages = `-- name: CountDeletedImages :one
+SELECT count(*)
+FROM images
+WHERE deleted_at IS NOT NULL
+`
+
+func (q *Queries) CountDeletedImages(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countDeletedImages)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countImages = `-- name: CountImages :one
 SELECT count(*)
 FROM images
+WHERE deleted_at IS NULL
 `
 
 func (q *Queries) CountImages(ctx context.Context) (int64, error) {
@@ -23,14 +63,80 @@ func (q *Queries) CountImages(ctx context.Context) (int64, error) {
 	return count, err
 }
 
-const countSearchImages = `-- name: CountSearchImages :one
-SELECT DISTINCT count(*)
+const countImagesByOriginalFilename = `-- name: CountImagesByOriginalFilename :one
+SELECT count(*)
+FROM images
+WHERE original_filename = $1
+  AND id <> $2
+`
+
+type CountImagesByOriginalFilenameParams struct {
+	OriginalFilename string `json:"original_filename"`
+	ExcludeID        int64  `json:"exclude_id"`
+}
+
+// ゴミ箱のイラストもunique制約の対象のため、deleted_atでは絞り込まない
+func (q *Queries) CountImagesByOriginalFilename(ctx context.Context, arg CountImagesByOriginalFilenameParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countImagesByOriginalFilename, arg.OriginalFilename, arg.ExcludeID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSampleImagesFrom = `-- name: CountSampleImagesFrom :one
+SELECT count(*)
 FROM images
-WHERE title LIKE '%' || COALESCE($1) || '%'
-  OR original_filename LIKE '%' || COALESCE($1) || '%'
+WHERE random_key >= $1::double precision
+  AND images.deleted_at IS NULL
+  AND images.id != ALL($2::bigint [])
+  AND (
+    cardinality($3::bigint []) = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_characters_relations icr
+      WHERE icr.image_id = images.id
+        AND icr.character_id = ANY($3::bigint [])
+    )
+  )
+  AND (
+    cardinality($4::bigint []) = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_child_categories_relations iccr
+      WHERE iccr.image_id = images.id
+        AND iccr.child_category_id = ANY($4::bigint [])
+    )
+  )
+`
+
+type CountSampleImagesFromParams struct {
+	Pivot            float64 `json:"pivot"`
+	ExclusionIds     []int64 `json:"exclusion_ids"`
+	CharacterIds     []int64 `json:"character_ids"`
+	ChildCategoryIds []int64 `json:"child_category_ids"`
+}
+
+func (q *Queries) CountSampleImagesFrom(ctx context.Context, arg CountSampleImagesFromParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSampleImagesFrom,
+		arg.Pivot,
+		pq.Array(arg.ExclusionIds),
+		pq.Array(arg.CharacterIds),
+		pq.Array(arg.ChildCategoryIds),
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSearchImages = `-- name: CountSearchImages :one
+SELECT count(*)
+FROM image_search_documents d
+  JOIN images ON images.id = d.image_id
+WHERE d.search_vector @@ $1::text::tsquery
+  AND images.deleted_at IS NULL
 `
 
-func (q *Queries) CountSearchImages(ctx context.Context, query sql.NullString) (int64, error) {
+func (q *Queries) CountSearchImages(ctx context.Context, query string) (int64, error) {
 	row := q.db.QueryRowContext(ctx, countSearchImages, query)
 	var count int64
 	err := row.Scan(&count)
@@ -46,7 +152,7 @@ INSERT INTO images (
     simple_filename
   )
 VALUES ($1, $2, $3, $4, $5)
-RETURNING id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename
+RETURNING id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename, random_key, view_count, deleted_at
 `
 
 type CreateImageParams struct {
@@ -75,6 +181,9 @@ func (q *Queries) CreateImage(ctx context.Context, arg CreateImageParams) (Image
 		&i.CreatedAt,
 		&i.OriginalFilename,
 		&i.SimpleFilename,
+		&i.RandomKey,
+		&i.ViewCount,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -89,45 +198,76 @@ func (q *Queries) DeleteImage(ctx context.Context, id int64) error {
 	return err
 }
 
-const fetchRandomImage = `-- name: FetchRandomImage :many
-SELECT i.id, i.title, i.original_src, i.simple_src, i.updated_at, i.created_at, i.original_filename, i.simple_filename
-FROM images i
-WHERE i.id IN (
-    SELECT i.id
-    FROM images i
-    WHERE i.id != $2
-    ORDER BY RANDOM()
-    LIMIT $1
+const filterImageIDs = `-- name: FilterImageIDs :many
+SELECT images.id
+FROM images
+WHERE images.deleted_at IS NULL
+  AND (
+    $1::text = ''
+    OR EXISTS (
+      SELECT 1
+      FROM image_search_documents d
+      WHERE d.image_id = images.id
+        AND d.search_vector @@ $1::text::tsquery
+    )
+  )
+  AND (
+    CASE
+      WHEN $2::boolean THEN (
+        SELECT count(DISTINCT icr.character_id)
+        FROM image_characters_relations icr
+        WHERE icr.image_id = images.id
+          AND icr.character_id = ANY($3::bigint [])
+      ) = cardinality($3::bigint [])
+      AND (
+        SELECT count(DISTINCT iccr.child_category_id)
+        FROM image_child_categories_relations iccr
+        WHERE iccr.image_id = images.id
+          AND iccr.child_category_id = ANY($4::bigint [])
+      ) = cardinality($4::bigint [])
+      ELSE cardinality($3::bigint []) + cardinality($4::bigint []) = 0
+      OR EXISTS (
+        SELECT 1
+        FROM image_characters_relations icr
+        WHERE icr.image_id = images.id
+          AND icr.character_id = ANY($3::bigint [])
+      )
+      OR EXISTS (
+        SELECT 1
+        FROM image_child_categories_relations iccr
+        WHERE iccr.image_id = images.id
+          AND iccr.child_category_id = ANY($4::bigint [])
+      )
+    END
   )
+ORDER BY images.id DESC
 `
 
-type FetchRandomImageParams struct {
-	Limit int32 `json:"limit"`
-	ID    int64 `json:"id"`
+type FilterImageIDsParams struct {
+	Query            string  `json:"query"`
+	MatchAll         bool    `json:"match_all"`
+	CharacterIds     []int64 `json:"character_ids"`
+	ChildCategoryIds []int64 `json:"child_category_ids"`
 }
 
-func (q *Queries) FetchRandomImage(ctx context.Context, arg FetchRandomImageParams) ([]Image, error) {
-	rows, err := q.db.QueryContext(ctx, fetchRandomImage, arg.Limit, arg.ID)
+func (q *Queries) FilterImageIDs(ctx context.Context, arg FilterImageIDsParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, filterImageIDs,
+		arg.Query,
+		arg.MatchAll,
+		pq.Array(arg.CharacterIds),
+		pq.Array(arg.ChildCategoryIds),
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Image{}
+	items := []int64{}
 	for rows.Next() {
-		var i Image
-		if err := rows.Scan(
-			&i.ID,
-			&i.Title,
-			&i.OriginalSrc,
-			&i.SimpleSrc,
-			&i.UpdatedAt,
-			&i.CreatedAt,
-			&i.OriginalFilename,
-			&i.SimpleFilename,
-		); err != nil {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, id)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -139,9 +279,10 @@ func (q *Queries) FetchRandomImage(ctx context.Context, arg FetchRandomImagePara
 }
 
 const getImage = `-- name: GetImage :one
-SELECT id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename
+SELECT id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename, random_key, view_count, deleted_at
 FROM images
 WHERE id = $1
+  AND deleted_at IS NULL
 LIMIT 1
 `
 
@@ -157,13 +298,93 @@ func (q *Queries) GetImage(ctx context.Context, id int64) (Image, error) {
 		&i.CreatedAt,
 		&i.OriginalFilename,
 		&i.SimpleFilename,
+		&i.RandomKey,
+		&i.ViewCount,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getImageForUpdate = `-- name: GetImageForUpdate :one
+SELECT id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename, random_key, view_count, deleted_at
+FROM images
+WHERE id = $1
+  AND deleted_at IS NULL
+LIMIT 1 FOR UPDATE
+`
+
+func (q *Queries) GetImageForUpdate(ctx context.Context, id int64) (Image, error) {
+	row := q.db.QueryRowContext(ctx, getImageForUpdate, id)
+	var i Image
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.OriginalSrc,
+		&i.SimpleSrc,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.OriginalFilename,
+		&i.SimpleFilename,
+		&i.RandomKey,
+		&i.ViewCount,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
+const listDeletedImages = `-- name: ListDeletedImages :many
+SELECT id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename, random_key, view_count, deleted_at
+FROM images
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC,
+  id DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListDeletedImagesParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListDeletedImages(ctx context.Context, arg ListDeletedImagesParams) ([]Image, error) {
+	rows, err := q.db.QueryContext(ctx, listDeletedImages, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Image{}
+	for rows.Next() {
+		var i Image
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.OriginalSrc,
+			&i.SimpleSrc,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.OriginalFilename,
+			&i.SimpleFilename,
+			&i.RandomKey,
+			&i.ViewCount,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listImage = `-- name: ListImage :many
-SELECT id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename
+SELECT id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename, random_key, view_count, deleted_at
 FROM images
+WHERE deleted_at IS NULL
 ORDER BY id DESC
 LIMIT $1 OFFSET $2
 `
@@ -191,6 +412,9 @@ func (q *Queries) ListImage(ctx context.Context, arg ListImageParams) ([]Image,
 			&i.CreatedAt,
 			&i.OriginalFilename,
 			&i.SimpleFilename,
+			&i.RandomKey,
+			&i.ViewCount,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -205,23 +429,219 @@ func (q *Queries) ListImage(ctx context.Context, arg ListImageParams) ([]Image,
 	return items, nil
 }
 
-const searchImages = `-- name: SearchImages :many
-SELECT DISTINCT id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename
+const listImagesByCharacterOrderByPosition = `-- name: ListImagesByCharacterOrderByPosition :many
+SELECT images.id, images.title, images.original_src, images.simple_src, images.updated_at, images.created_at, images.original_filename, images.simple_filename, images.random_key, images.view_count, images.deleted_at,
+  COALESCE(icr.position, 2147483647)::integer AS position
 FROM images
-WHERE title LIKE '%' || COALESCE($3) || '%'
-  OR original_filename LIKE '%' || COALESCE($3) || '%'
-ORDER BY id DESC
-LIMIT $1 OFFSET $2
+  JOIN image_characters_relations icr ON icr.image_id = images.id
+WHERE images.deleted_at IS NULL
+  AND icr.character_id = $1
+  AND (
+    NOT $2::boolean
+    OR COALESCE(icr.position, 2147483647) > $3::integer
+    OR (
+      COALESCE(icr.position, 2147483647) = $3::integer
+      AND images.id < $4
+    )
+  )
+ORDER BY COALESCE(icr.position, 2147483647),
+  images.id DESC
+LIMIT $6 OFFSET $5
 `
 
-type SearchImagesParams struct {
-	Limit  int32          `json:"limit"`
-	Offset int32          `json:"offset"`
-	Query  sql.NullString `json:"query"`
+type ListImagesByCharacterOrderByPositionParams struct {
+	CharacterID    int64 `json:"character_id"`
+	UseCursor      bool  `json:"use_cursor"`
+	CursorPosition int32 `json:"cursor_position"`
+	CursorID       int64 `json:"cursor_id"`
+	Skip           int32 `json:"skip"`
+	MaxResults     int32 `json:"max_results"`
 }
 
-func (q *Queries) SearchImages(ctx context.Context, arg SearchImagesParams) ([]Image, error) {
-	rows, err := q.db.QueryContext(ctx, searchImages, arg.Limit, arg.Offset, arg.Query)
+type ListImagesByCharacterOrderByPositionRow struct {
+	Image    Image `json:"image"`
+	Position int32 `json:"position"`
+}
+
+func (q *Queries) ListImagesByCharacterOrderByPosition(ctx context.Context, arg ListImagesByCharacterOrderByPositionParams) ([]ListImagesByCharacterOrderByPositionRow, error) {
+	rows, err := q.db.QueryContext(ctx, listImagesByCharacterOrderByPosition,
+		arg.CharacterID,
+		arg.UseCursor,
+		arg.CursorPosition,
+		arg.CursorID,
+		arg.Skip,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListImagesByCharacterOrderByPositionRow{}
+	for rows.Next() {
+		var i ListImagesByCharacterOrderByPositionRow
+		if err := rows.Scan(
+			&i.Image.ID,
+			&i.Image.Title,
+			&i.Image.OriginalSrc,
+			&i.Image.SimpleSrc,
+			&i.Image.UpdatedAt,
+			&i.Image.CreatedAt,
+			&i.Image.OriginalFilename,
+			&i.Image.SimpleFilename,
+			&i.Image.RandomKey,
+			&i.Image.ViewCount,
+			&i.Image.DeletedAt,
+			&i.Position,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listImagesByChildCategoryOrderByPosition = `-- name: ListImagesByChildCategoryOrderByPosition :many
+SELECT images.id, images.title, images.original_src, images.simple_src, images.updated_at, images.created_at, images.original_filename, images.simple_filename, images.random_key, images.view_count, images.deleted_at,
+  COALESCE(iccr.position, 2147483647)::integer AS position
+FROM images
+  JOIN image_child_categories_relations iccr ON iccr.image_id = images.id
+WHERE images.deleted_at IS NULL
+  AND iccr.child_category_id = $1
+  AND (
+    NOT $2::boolean
+    OR COALESCE(iccr.position, 2147483647) > $3::integer
+    OR (
+      COALESCE(iccr.position, 2147483647) = $3::integer
+      AND images.id < $4
+    )
+  )
+ORDER BY COALESCE(iccr.position, 2147483647),
+  images.id DESC
+LIMIT $6 OFFSET $5
+`
+
+type ListImagesByChildCategoryOrderByPositionParams struct {
+	ChildCategoryID int64 `json:"child_category_id"`
+	UseCursor       bool  `json:"use_cursor"`
+	CursorPosition  int32 `json:"cursor_position"`
+	CursorID        int64 `json:"cursor_id"`
+	Skip            int32 `json:"skip"`
+	MaxResults      int32 `json:"max_results"`
+}
+
+type ListImagesByChildCategoryOrderByPositionRow struct {
+	Image    Image `json:"image"`
+	Position int32 `json:"position"`
+}
+
+func (q *Queries) ListImagesByChildCategoryOrderByPosition(ctx context.Context, arg ListImagesByChildCategoryOrderByPositionParams) ([]ListImagesByChildCategoryOrderByPositionRow, error) {
+	rows, err := q.db.QueryContext(ctx, listImagesByChildCategoryOrderByPosition,
+		arg.ChildCategoryID,
+		arg.UseCursor,
+		arg.CursorPosition,
+		arg.CursorID,
+		arg.Skip,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListImagesByChildCategoryOrderByPositionRow{}
+	for rows.Next() {
+		var i ListImagesByChildCategoryOrderByPositionRow
+		if err := rows.Scan(
+			&i.Image.ID,
+			&i.Image.Title,
+			&i.Image.OriginalSrc,
+			&i.Image.SimpleSrc,
+			&i.Image.UpdatedAt,
+			&i.Image.CreatedAt,
+			&i.Image.OriginalFilename,
+			&i.Image.SimpleFilename,
+			&i.Image.RandomKey,
+			&i.Image.ViewCount,
+			&i.Image.DeletedAt,
+			&i.Position,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listImagesOrderByNewest = `-- name: ListImagesOrderByNewest :many
+SELECT id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename, random_key, view_count, deleted_at
+FROM images
+WHERE images.deleted_at IS NULL
+  AND (
+    $1::text = ''
+    OR EXISTS (
+      SELECT 1
+      FROM image_search_documents d
+      WHERE d.image_id = images.id
+        AND d.search_vector @@ $1::text::tsquery
+    )
+  )
+  AND (
+    $2::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_characters_relations icr
+      WHERE icr.image_id = images.id
+        AND icr.character_id = $2::bigint
+    )
+  )
+  AND (
+    $3::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_child_categories_relations iccr
+      WHERE iccr.image_id = images.id
+        AND iccr.child_category_id = $3::bigint
+    )
+  )
+  AND (
+    NOT $4::boolean
+    OR images.id < $5
+  )
+ORDER BY images.id DESC
+LIMIT $7 OFFSET $6
+`
+
+type ListImagesOrderByNewestParams struct {
+	Query           string `json:"query"`
+	CharacterID     int64  `json:"character_id"`
+	ChildCategoryID int64  `json:"child_category_id"`
+	UseCursor       bool   `json:"use_cursor"`
+	CursorID        int64  `json:"cursor_id"`
+	Skip            int32  `json:"skip"`
+	MaxResults      int32  `json:"max_results"`
+}
+
+func (q *Queries) ListImagesOrderByNewest(ctx context.Context, arg ListImagesOrderByNewestParams) ([]Image, error) {
+	rows, err := q.db.QueryContext(ctx, listImagesOrderByNewest,
+		arg.Query,
+		arg.CharacterID,
+		arg.ChildCategoryID,
+		arg.UseCursor,
+		arg.CursorID,
+		arg.Skip,
+		arg.MaxResults,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -238,6 +658,9 @@ func (q *Queries) SearchImages(ctx context.Context, arg SearchImagesParams) ([]I
 			&i.CreatedAt,
 			&i.OriginalFilename,
 			&i.SimpleFilename,
+			&i.RandomKey,
+			&i.ViewCount,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -252,37 +675,1043 @@ func (q *Queries) SearchImages(ctx context.Context, arg SearchImagesParams) ([]I
 	return items, nil
 }
 
-const updateImage = `-- name: UpdateImage :one
-UPDATE images
-SET title = $2,
-  original_src = $3,
-  simple_src = $4,
-  original_filename = $5,
-  simple_filename = $6,
-  updated_at = $7
-WHERE id = $1
-RETURNING id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename
+const listImagesOrderByOldest = `-- name: ListImagesOrderByOldest :many
+SELECT id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename, random_key, view_count, deleted_at
+FROM images
+WHERE images.deleted_at IS NULL
+  AND (
+    $1::text = ''
+    OR EXISTS (
+      SELECT 1
+      FROM image_search_documents d
+      WHERE d.image_id = images.id
+        AND d.search_vector @@ $1::text::tsquery
+    )
+  )
+  AND (
+    $2::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_characters_relations icr
+      WHERE icr.image_id = images.id
+        AND icr.character_id = $2::bigint
+    )
+  )
+  AND (
+    $3::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_child_categories_relations iccr
+      WHERE iccr.image_id = images.id
+        AND iccr.child_category_id = $3::bigint
+    )
+  )
+  AND (
+    NOT $4::boolean
+    OR images.id > $5
+  )
+ORDER BY images.id
+LIMIT $7 OFFSET $6
 `
 
-type UpdateImageParams struct {
-	ID               int64          `json:"id"`
-	Title            string         `json:"title"`
-	OriginalSrc      string         `json:"original_src"`
-	SimpleSrc        sql.NullString `json:"simple_src"`
-	OriginalFilename string         `json:"original_filename"`
-	SimpleFilename   sql.NullString `json:"simple_filename"`
-	UpdatedAt        time.Time      `json:"updated_at"`
+type ListImagesOrderByOldestParams struct {
+	Query           string `json:"query"`
+	CharacterID     int64  `json:"character_id"`
+	ChildCategoryID int64  `json:"child_category_id"`
+	UseCursor       bool   `json:"use_cursor"`
+	CursorID        int64  `json:"cursor_id"`
+	Skip            int32  `json:"skip"`
+	MaxResults      int32  `json:"max_results"`
 }
 
-func (q *Queries) UpdateImage(ctx context.Context, arg UpdateImageParams) (Image, error) {
-	row := q.db.QueryRowContext(ctx, updateImage,
-		arg.ID,
-		arg.Title,
-		arg.OriginalSrc,
-		arg.SimpleSrc,
-		arg.OriginalFilename,
-		arg.SimpleFilename,
-		arg.UpdatedAt,
+func (q *Queries) ListImagesOrderByOldest(ctx context.Context, arg ListImagesOrderByOldestParams) ([]Image, error) {
+	rows, err := q.db.QueryContext(ctx, listImagesOrderByOldest,
+		arg.Query,
+		arg.CharacterID,
+		arg.ChildCategoryID,
+		arg.UseCursor,
+		arg.CursorID,
+		arg.Skip,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Image{}
+	for rows.Next() {
+		var i Image
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.OriginalSrc,
+			&i.SimpleSrc,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.OriginalFilename,
+			&i.SimpleFilename,
+			&i.RandomKey,
+			&i.ViewCount,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listImagesOrderByPopular = `-- name: ListImagesOrderByPopular :many
+SELECT id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename, random_key, view_count, deleted_at
+FROM images
+WHERE images.deleted_at IS NULL
+  AND (
+    $1::text = ''
+    OR EXISTS (
+      SELECT 1
+      FROM image_search_documents d
+      WHERE d.image_id = images.id
+        AND d.search_vector @@ $1::text::tsquery
+    )
+  )
+  AND (
+    $2::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_characters_relations icr
+      WHERE icr.image_id = images.id
+        AND icr.character_id = $2::bigint
+    )
+  )
+  AND (
+    $3::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_child_categories_relations iccr
+      WHERE iccr.image_id = images.id
+        AND iccr.child_category_id = $3::bigint
+    )
+  )
+  AND (
+    NOT $4::boolean
+    OR (images.view_count, images.id) < ($5::bigint, $6::bigint)
+  )
+ORDER BY images.view_count DESC,
+  images.id DESC
+LIMIT $8 OFFSET $7
+`
+
+type ListImagesOrderByPopularParams struct {
+	Query           string `json:"query"`
+	CharacterID     int64  `json:"character_id"`
+	ChildCategoryID int64  `json:"child_category_id"`
+	UseCursor       bool   `json:"use_cursor"`
+	CursorViewCount int64  `json:"cursor_view_count"`
+	CursorID        int64  `json:"cursor_id"`
+	Skip            int32  `json:"skip"`
+	MaxResults      int32  `json:"max_results"`
+}
+
+func (q *Queries) ListImagesOrderByPopular(ctx context.Context, arg ListImagesOrderByPopularParams) ([]Image, error) {
+	rows, err := q.db.QueryContext(ctx, listImagesOrderByPopular,
+		arg.Query,
+		arg.CharacterID,
+		arg.ChildCategoryID,
+		arg.UseCursor,
+		arg.CursorViewCount,
+		arg.CursorID,
+		arg.Skip,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Image{}
+	for rows.Next() {
+		var i Image
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.OriginalSrc,
+			&i.SimpleSrc,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.OriginalFilename,
+			&i.SimpleFilename,
+			&i.RandomKey,
+			&i.ViewCount,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listImagesOrderByTitle = `-- name: ListImagesOrderByTitle :many
+SELECT id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename, random_key, view_count, deleted_at
+FROM images
+WHERE images.deleted_at IS NULL
+  AND (
+    $1::text = ''
+    OR EXISTS (
+      SELECT 1
+      FROM image_search_documents d
+      WHERE d.image_id = images.id
+        AND d.search_vector @@ $1::text::tsquery
+    )
+  )
+  AND (
+    $2::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_characters_relations icr
+      WHERE icr.image_id = images.id
+        AND icr.character_id = $2::bigint
+    )
+  )
+  AND (
+    $3::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_child_categories_relations iccr
+      WHERE iccr.image_id = images.id
+        AND iccr.child_category_id = $3::bigint
+    )
+  )
+  AND (
+    NOT $4::boolean
+    OR (images.title, images.id) > ($5::varchar, $6::bigint)
+  )
+ORDER BY images.title,
+  images.id
+LIMIT $8 OFFSET $7
+`
+
+type ListImagesOrderByTitleParams struct {
+	Query           string `json:"query"`
+	CharacterID     int64  `json:"character_id"`
+	ChildCategoryID int64  `json:"child_category_id"`
+	UseCursor       bool   `json:"use_cursor"`
+	CursorTitle     string `json:"cursor_title"`
+	CursorID        int64  `json:"cursor_id"`
+	Skip            int32  `json:"skip"`
+	MaxResults      int32  `json:"max_results"`
+}
+
+func (q *Queries) ListImagesOrderByTitle(ctx context.Context, arg ListImagesOrderByTitleParams) ([]Image, error) {
+	rows, err := q.db.QueryContext(ctx, listImagesOrderByTitle,
+		arg.Query,
+		arg.CharacterID,
+		arg.ChildCategoryID,
+		arg.UseCursor,
+		arg.CursorTitle,
+		arg.CursorID,
+		arg.Skip,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Image{}
+	for rows.Next() {
+		var i Image
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.OriginalSrc,
+			&i.SimpleSrc,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.OriginalFilename,
+			&i.SimpleFilename,
+			&i.RandomKey,
+			&i.ViewCount,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listImagesOrderByUpdated = `-- name: ListImagesOrderByUpdated :many
+SELECT id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename, random_key, view_count, deleted_at
+FROM images
+WHERE images.deleted_at IS NULL
+  AND (
+    $1::text = ''
+    OR EXISTS (
+      SELECT 1
+      FROM image_search_documents d
+      WHERE d.image_id = images.id
+        AND d.search_vector @@ $1::text::tsquery
+    )
+  )
+  AND (
+    $2::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_characters_relations icr
+      WHERE icr.image_id = images.id
+        AND icr.character_id = $2::bigint
+    )
+  )
+  AND (
+    $3::bigint = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_child_categories_relations iccr
+      WHERE iccr.image_id = images.id
+        AND iccr.child_category_id = $3::bigint
+    )
+  )
+  AND (
+    NOT $4::boolean
+    OR (images.updated_at, images.id) < ($5::timestamptz, $6::bigint)
+  )
+ORDER BY images.updated_at DESC,
+  images.id DESC
+LIMIT $8 OFFSET $7
+`
+
+type ListImagesOrderByUpdatedParams struct {
+	Query           string    `json:"query"`
+	CharacterID     int64     `json:"character_id"`
+	ChildCategoryID int64     `json:"child_category_id"`
+	UseCursor       bool      `json:"use_cursor"`
+	CursorUpdatedAt time.Time `json:"cursor_updated_at"`
+	CursorID        int64     `json:"cursor_id"`
+	Skip            int32     `json:"skip"`
+	MaxResults      int32     `json:"max_results"`
+}
+
+func (q *Queries) ListImagesOrderByUpdated(ctx context.Context, arg ListImagesOrderByUpdatedParams) ([]Image, error) {
+	rows, err := q.db.QueryContext(ctx, listImagesOrderByUpdated,
+		arg.Query,
+		arg.CharacterID,
+		arg.ChildCategoryID,
+		arg.UseCursor,
+		arg.CursorUpdatedAt,
+		arg.CursorID,
+		arg.Skip,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Image{}
+	for rows.Next() {
+		var i Image
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.OriginalSrc,
+			&i.SimpleSrc,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.OriginalFilename,
+			&i.SimpleFilename,
+			&i.RandomKey,
+			&i.ViewCount,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPurgeableImages = `-- name: ListPurgeableImages :many
+SELECT id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename, random_key, view_count, deleted_at
+FROM images
+WHERE deleted_at < $1::timestamptz
+ORDER BY deleted_at
+LIMIT $2
+`
+
+type ListPurgeableImagesParams struct {
+	DeletedBefore time.Time `json:"deleted_before"`
+	MaxResults    int32     `json:"max_results"`
+}
+
+func (q *Queries) ListPurgeableImages(ctx context.Context, arg ListPurgeableImagesParams) ([]Image, error) {
+	rows, err := q.db.QueryContext(ctx, listPurgeableImages, arg.DeletedBefore, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Image{}
+	for rows.Next() {
+		var i Image
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.OriginalSrc,
+			&i.SimpleSrc,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.OriginalFilename,
+			&i.SimpleFilename,
+			&i.RandomKey,
+			&i.ViewCount,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRelatedImages = `-- name: ListRelatedImages :many
+WITH scores AS (
+  SELECT related.image_id,
+    sum(related.weight) AS score
+  FROM (
+      SELECT r.image_id,
+        $2::int AS weight
+      FROM image_characters_relations r
+        JOIN image_characters_relations base ON base.character_id = r.character_id
+      WHERE base.image_id = $3
+      UNION ALL
+      SELECT r.image_id,
+        $4::int AS weight
+      FROM image_child_categories_relations r
+        JOIN image_child_categories_relations base ON base.child_category_id = r.child_category_id
+      WHERE base.image_id = $3
+      UNION ALL
+      SELECT r.image_id,
+        $5::int AS weight
+      FROM image_parent_categories_relations r
+        JOIN image_parent_categories_relations base ON base.parent_category_id = r.parent_category_id
+      WHERE base.image_id = $3
+    ) related
+  WHERE related.image_id != $3
+  GROUP BY related.image_id
+)
+SELECT images.id, images.title, images.original_src, images.simple_src, images.updated_at, images.created_at, images.original_filename, images.simple_filename, images.random_key, images.view_count, images.deleted_at
+FROM scores
+  JOIN images ON images.id = scores.image_id
+WHERE images.deleted_at IS NULL
+ORDER BY scores.score DESC,
+  RANDOM()
+LIMIT $1
+`
+
+type ListRelatedImagesParams struct {
+	MaxResults           int32 `json:"max_results"`
+	CharacterWeight      int32 `json:"character_weight"`
+	ImageID              int64 `json:"image_id"`
+	ChildCategoryWeight  int32 `json:"child_category_weight"`
+	ParentCategoryWeight int32 `json:"parent_category_weight"`
+}
+
+func (q *Queries) ListRelatedImages(ctx context.Context, arg ListRelatedImagesParams) ([]Image, error) {
+	rows, err := q.db.QueryContext(ctx, listRelatedImages,
+		arg.MaxResults,
+		arg.CharacterWeight,
+		arg.ImageID,
+		arg.ChildCategoryWeight,
+		arg.ParentCategoryWeight,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Image{}
+	for rows.Next() {
+		var i Image
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.OriginalSrc,
+			&i.SimpleSrc,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.OriginalFilename,
+			&i.SimpleFilename,
+			&i.RandomKey,
+			&i.ViewCount,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreImage = `-- name: RestoreImage :one
+UPDATE images
+SET deleted_at = NULL
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+RETURNING id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename, random_key, view_count, deleted_at
+`
+
+func (q *Queries) RestoreImage(ctx context.Context, id int64) (Image, error) {
+	row := q.db.QueryRowContext(ctx, restoreImage, id)
+	var i Image
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.OriginalSrc,
+		&i.SimpleSrc,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.OriginalFilename,
+		&i.SimpleFilename,
+		&i.RandomKey,
+		&i.ViewCount,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const sampleImagesBefore = `-- name: SampleImagesBefore :many
+SELECT id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename, random_key, view_count, deleted_at
+FROM images
+WHERE random_key < $1::double precision
+  AND images.deleted_at IS NULL
+  AND images.id != ALL($2::bigint [])
+  AND (
+    cardinality($3::bigint []) = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_characters_relations icr
+      WHERE icr.image_id = images.id
+        AND icr.character_id = ANY($3::bigint [])
+    )
+  )
+  AND (
+    cardinality($4::bigint []) = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_child_categories_relations iccr
+      WHERE iccr.image_id = images.id
+        AND iccr.child_category_id = ANY($4::bigint [])
+    )
+  )
+ORDER BY random_key
+LIMIT $6 OFFSET $5
+`
+
+type SampleImagesBeforeParams struct {
+	Pivot            float64 `json:"pivot"`
+	ExclusionIds     []int64 `json:"exclusion_ids"`
+	CharacterIds     []int64 `json:"character_ids"`
+	ChildCategoryIds []int64 `json:"child_category_ids"`
+	Skip             int32   `json:"skip"`
+	MaxResults       int32   `json:"max_results"`
+}
+
+func (q *Queries) SampleImagesBefore(ctx context.Context, arg SampleImagesBeforeParams) ([]Image, error) {
+	rows, err := q.db.QueryContext(ctx, sampleImagesBefore,
+		arg.Pivot,
+		pq.Array(arg.ExclusionIds),
+		pq.Array(arg.CharacterIds),
+		pq.Array(arg.ChildCategoryIds),
+		arg.Skip,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Image{}
+	for rows.Next() {
+		var i Image
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.OriginalSrc,
+			&i.SimpleSrc,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.OriginalFilename,
+			&i.SimpleFilename,
+			&i.RandomKey,
+			&i.ViewCount,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sampleImagesFrom = `-- name: SampleImagesFrom :many
+SELECT id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename, random_key, view_count, deleted_at
+FROM images
+WHERE random_key >= $1::double precision
+  AND images.deleted_at IS NULL
+  AND images.id != ALL($2::bigint [])
+  AND (
+    cardinality($3::bigint []) = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_characters_relations icr
+      WHERE icr.image_id = images.id
+        AND icr.character_id = ANY($3::bigint [])
+    )
+  )
+  AND (
+    cardinality($4::bigint []) = 0
+    OR EXISTS (
+      SELECT 1
+      FROM image_child_categories_relations iccr
+      WHERE iccr.image_id = images.id
+        AND iccr.child_category_id = ANY($4::bigint [])
+    )
+  )
+ORDER BY random_key
+LIMIT $6 OFFSET $5
+`
+
+type SampleImagesFromParams struct {
+	Pivot            float64 `json:"pivot"`
+	ExclusionIds     []int64 `json:"exclusion_ids"`
+	CharacterIds     []int64 `json:"character_ids"`
+	ChildCategoryIds []int64 `json:"child_category_ids"`
+	Skip             int32   `json:"skip"`
+	MaxResults       int32   `json:"max_results"`
+}
+
+func (q *Queries) SampleImagesFrom(ctx context.Context, arg SampleImagesFromParams) ([]Image, error) {
+	rows, err := q.db.QueryContext(ctx, sampleImagesFrom,
+		arg.Pivot,
+		pq.Array(arg.ExclusionIds),
+		pq.Array(arg.CharacterIds),
+		pq.Array(arg.ChildCategoryIds),
+		arg.Skip,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Image{}
+	for rows.Next() {
+		var i Image
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.OriginalSrc,
+			&i.SimpleSrc,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.OriginalFilename,
+			&i.SimpleFilename,
+			&i.RandomKey,
+			&i.ViewCount,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sampleImagesWeighted = `-- name: SampleImagesWeighted :many
+WITH candidates AS (
+  (
+    SELECT images.id,
+      images.random_key - $3::double precision AS distance
+    FROM images
+    WHERE images.random_key >= $3::double precision
+      AND images.deleted_at IS NULL
+      AND images.id != ALL($4::bigint [])
+      AND (
+        cardinality($5::bigint []) = 0
+        OR EXISTS (
+          SELECT 1
+          FROM image_characters_relations icr
+          WHERE icr.image_id = images.id
+            AND icr.character_id = ANY($5::bigint [])
+        )
+      )
+      AND (
+        cardinality($6::bigint []) = 0
+        OR EXISTS (
+          SELECT 1
+          FROM image_child_categories_relations iccr
+          WHERE iccr.image_id = images.id
+            AND iccr.child_category_id = ANY($6::bigint [])
+        )
+      )
+    ORDER BY images.random_key
+    LIMIT $7::int
+  )
+  UNION ALL
+  (
+    -- 末尾まで辿った場合は、先頭から起点の手前までを続けて候補にする
+    SELECT images.id,
+      images.random_key - $3::double precision + 1 AS distance
+    FROM images
+    WHERE images.random_key < $3::double precision
+      AND images.deleted_at IS NULL
+      AND images.id != ALL($4::bigint [])
+      AND (
+        cardinality($5::bigint []) = 0
+        OR EXISTS (
+          SELECT 1
+          FROM image_characters_relations icr
+          WHERE icr.image_id = images.id
+            AND icr.character_id = ANY($5::bigint [])
+        )
+      )
+      AND (
+        cardinality($6::bigint []) = 0
+        OR EXISTS (
+          SELECT 1
+          FROM image_child_categories_relations iccr
+          WHERE iccr.image_id = images.id
+            AND iccr.child_category_id = ANY($6::bigint [])
+        )
+      )
+    ORDER BY images.random_key
+    LIMIT $7::int
+  )
+),
+pool AS (
+  SELECT candidates.id,
+    candidates.distance
+  FROM candidates
+  ORDER BY candidates.distance
+  LIMIT $7::int
+),
+weights AS (
+  SELECT pool.id,
+    GREATEST(
+      COALESCE(
+        (
+          SELECT max(c.priority_level)
+          FROM image_characters_relations icr
+            JOIN characters c ON c.id = icr.character_id
+          WHERE icr.image_id = pool.id
+        ),
+        0
+      ),
+      COALESCE(
+        (
+          SELECT max(cc.priority_level)
+          FROM image_child_categories_relations iccr
+            JOIN child_categories cc ON cc.id = iccr.child_category_id
+          WHERE iccr.image_id = pool.id
+        ),
+        0
+      ),
+      1
+    ) AS weight,
+    -- 起点からの順位を(0, 1)の一様乱数として扱う
+    row_number() OVER (
+      ORDER BY pool.distance
+    )::double precision / (count(*) OVER () + 1) AS u
+  FROM pool
+)
+SELECT images.id, images.title, images.original_src, images.simple_src, images.updated_at, images.created_at, images.original_filename, images.simple_filename, images.random_key, images.view_count, images.deleted_at
+FROM weights
+  JOIN images ON images.id = weights.id
+ORDER BY power(weights.u, 1.0 / weights.weight) DESC,
+  images.id
+LIMIT $2 OFFSET $1
+`
+
+type SampleImagesWeightedParams struct {
+	Skip             int32   `json:"skip"`
+	MaxResults       int32   `json:"max_results"`
+	Pivot            float64 `json:"pivot"`
+	ExclusionIds     []int64 `json:"exclusion_ids"`
+	CharacterIds     []int64 `json:"character_ids"`
+	ChildCategoryIds []int64 `json:"child_category_ids"`
+	CandidateCount   int32   `json:"candidate_count"`
+}
+
+// 起点からrandom_keyのインデックスで辿った候補のみを重み付けし、全件のソートを避ける
+func (q *Queries) SampleImagesWeighted(ctx context.Context, arg SampleImagesWeightedParams) ([]Image, error) {
+	rows, err := q.db.QueryContext(ctx, sampleImagesWeighted,
+		arg.Skip,
+		arg.MaxResults,
+		arg.Pivot,
+		pq.Array(arg.ExclusionIds),
+		pq.Array(arg.CharacterIds),
+		pq.Array(arg.ChildCategoryIds),
+		arg.CandidateCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Image{}
+	for rows.Next() {
+		var i Image
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.OriginalSrc,
+			&i.SimpleSrc,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.OriginalFilename,
+			&i.SimpleFilename,
+			&i.RandomKey,
+			&i.ViewCount,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchImages = `-- name: SearchImages :many
+SELECT images.id, images.title, images.original_src, images.simple_src, images.updated_at, images.created_at, images.original_filename, images.simple_filename, images.random_key, images.view_count, images.deleted_at,
+  ts_rank(d.search_vector, $3::text::tsquery)::real AS rank,
+  count(*) OVER ()::bigint AS total_count
+FROM images
+  JOIN image_search_documents d ON d.image_id = images.id
+WHERE d.search_vector @@ $3::text::tsquery
+  AND images.deleted_at IS NULL
+ORDER BY rank DESC,
+  images.id DESC
+LIMIT $1 OFFSET $2
+`
+
+type SearchImagesParams struct {
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+	Query  string `json:"query"`
+}
+
+type SearchImagesRow struct {
+	Image      Image   `json:"image"`
+	Rank       float32 `json:"rank"`
+	TotalCount int64   `json:"total_count"`
+}
+
+func (q *Queries) SearchImages(ctx context.Context, arg SearchImagesParams) ([]SearchImagesRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchImages, arg.Limit, arg.Offset, arg.Query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchImagesRow{}
+	for rows.Next() {
+		var i SearchImagesRow
+		if err := rows.Scan(
+			&i.Image.ID,
+			&i.Image.Title,
+			&i.Image.OriginalSrc,
+			&i.Image.SimpleSrc,
+			&i.Image.UpdatedAt,
+			&i.Image.CreatedAt,
+			&i.Image.OriginalFilename,
+			&i.Image.SimpleFilename,
+			&i.Image.RandomKey,
+			&i.Image.ViewCount,
+			&i.Image.DeletedAt,
+			&i.Rank,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchImagesByCursor = `-- name: SearchImagesByCursor :many
+SELECT images.id, images.title, images.original_src, images.simple_src, images.updated_at, images.created_at, images.original_filename, images.simple_filename, images.random_key, images.view_count, images.deleted_at,
+  ts_rank(d.search_vector, $2::text::tsquery)::real AS rank
+FROM images
+  JOIN image_search_documents d ON d.image_id = images.id
+WHERE d.search_vector @@ $2::text::tsquery
+  AND images.deleted_at IS NULL
+  AND (
+    ts_rank(d.search_vector, $2::text::tsquery)::real < $3::real
+    OR (
+      ts_rank(d.search_vector, $2::text::tsquery)::real = $3::real
+      AND images.id < $4
+    )
+  )
+ORDER BY rank DESC,
+  images.id DESC
+LIMIT $1
+`
+
+type SearchImagesByCursorParams struct {
+	Limit      int32   `json:"limit"`
+	Query      string  `json:"query"`
+	CursorRank float32 `json:"cursor_rank"`
+	CursorID   int64   `json:"cursor_id"`
+}
+
+type SearchImagesByCursorRow struct {
+	Image Image   `json:"image"`
+	Rank  float32 `json:"rank"`
+}
+
+func (q *Queries) SearchImagesByCursor(ctx context.Context, arg SearchImagesByCursorParams) ([]SearchImagesByCursorRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchImagesByCursor,
+		arg.Limit,
+		arg.Query,
+		arg.CursorRank,
+		arg.CursorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchImagesByCursorRow{}
+	for rows.Next() {
+		var i SearchImagesByCursorRow
+		if err := rows.Scan(
+			&i.Image.ID,
+			&i.Image.Title,
+			&i.Image.OriginalSrc,
+			&i.Image.SimpleSrc,
+			&i.Image.UpdatedAt,
+			&i.Image.CreatedAt,
+			&i.Image.OriginalFilename,
+			&i.Image.SimpleFilename,
+			&i.Image.RandomKey,
+			&i.Image.ViewCount,
+			&i.Image.DeletedAt,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const softDeleteImage = `-- name: SoftDeleteImage :execrows
+UPDATE images
+SET deleted_at = now()
+WHERE id = $1
+  AND deleted_at IS NULL
+`
+
+func (q *Queries) SoftDeleteImage(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, softDeleteImage, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateImage = `-- name: UpdateImage :one
+UPDATE images
+SET title = $1,
+  original_src = $2,
+  simple_src = $3,
+  original_filename = $4,
+  simple_filename = $5,
+  updated_at = $6
+WHERE id = $7
+  AND updated_at = $8
+RETURNING id, title, original_src, simple_src, updated_at, created_at, original_filename, simple_filename, random_key, view_count, deleted_at
+`
+
+type UpdateImageParams struct {
+	Title             string         `json:"title"`
+	OriginalSrc       string         `json:"original_src"`
+	SimpleSrc         sql.NullString `json:"simple_src"`
+	OriginalFilename  string         `json:"original_filename"`
+	SimpleFilename    sql.NullString `json:"simple_filename"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	ID                int64          `json:"id"`
+	ExpectedUpdatedAt time.Time      `json:"expected_updated_at"`
+}
+
+// updated_atが一致しない場合は他の操作で更新済みのため、更新せずに0件を返す
+func (q *Queries) UpdateImage(ctx context.Context, arg UpdateImageParams) (Image, error) {
+	row := q.db.QueryRowContext(ctx, updateImage,
+		arg.Title,
+		arg.OriginalSrc,
+		arg.SimpleSrc,
+		arg.OriginalFilename,
+		arg.SimpleFilename,
+		arg.UpdatedAt,
+		arg.ID,
+		arg.ExpectedUpdatedAt,
 	)
 	var i Image
 	err := row.Scan(
@@ -294,6 +1723,9 @@ func (q *Queries) UpdateImage(ctx context.Context, arg UpdateImageParams) (Image
 		&i.CreatedAt,
 		&i.OriginalFilename,
 		&i.SimpleFilename,
+		&i.RandomKey,
+		&i.ViewCount,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -0,0 +1,164 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: login_attempts.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const countLoginAttempts = `-- name: CountLoginAttempts :one
+SELECT count(*)
+FROM login_attempts
+WHERE (
+    $1::text = ''
+    OR operator_name = $1::text
+  )
+  AND (
+    $2::text = ''
+    OR email = $2::text
+  )
+  AND (
+    $3::boolean IS NULL
+    OR succeeded = $3::boolean
+  )
+  AND created_at >= $4
+  AND created_at < $5
+`
+
+type CountLoginAttemptsParams struct {
+	OperatorName string       `json:"operator_name"`
+	Email        string       `json:"email"`
+	Succeeded    sql.NullBool `json:"succeeded"`
+	FromTime     time.Time    `json:"from_time"`
+	ToTime       time.Time    `json:"to_time"`
+}
+
+func (q *Queries) CountLoginAttempts(ctx context.Context, arg CountLoginAttemptsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countLoginAttempts,
+		arg.OperatorName,
+		arg.Email,
+		arg.Succeeded,
+		arg.FromTime,
+		arg.ToTime,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createLoginAttempt = `-- name: CreateLoginAttempt :exec
+INSERT INTO login_attempts (
+    email,
+    operator_name,
+    succeeded,
+    failure_reason,
+    client_ip,
+    user_agent
+  )
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+  )
+`
+
+type CreateLoginAttemptParams struct {
+	Email         string `json:"email"`
+	OperatorName  string `json:"operator_name"`
+	Succeeded     bool   `json:"succeeded"`
+	FailureReason string `json:"failure_reason"`
+	ClientIp      string `json:"client_ip"`
+	UserAgent     string `json:"user_agent"`
+}
+
+func (q *Queries) CreateLoginAttempt(ctx context.Context, arg CreateLoginAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, createLoginAttempt,
+		arg.Email,
+		arg.OperatorName,
+		arg.Succeeded,
+		arg.FailureReason,
+		arg.ClientIp,
+		arg.UserAgent,
+	)
+	return err
+}
+
+const listLoginAttempts = `-- name: ListLoginAttempts :many
+SELECT id, email, operator_name, succeeded, failure_reason, client_ip, user_agent, created_at
+FROM login_attempts
+WHERE (
+    $1::text = ''
+    OR operator_name = $1::text
+  )
+  AND (
+    $2::text = ''
+    OR email = $2::text
+  )
+  AND (
+    $3::boolean IS NULL
+    OR succeeded = $3::boolean
+  )
+  AND created_at >= $4
+  AND created_at < $5
+ORDER BY created_at DESC,
+  id DESC
+LIMIT $7 OFFSET $6
+`
+
+type ListLoginAttemptsParams struct {
+	OperatorName string       `json:"operator_name"`
+	Email        string       `json:"email"`
+	Succeeded    sql.NullBool `json:"succeeded"`
+	FromTime     time.Time    `json:"from_time"`
+	ToTime       time.Time    `json:"to_time"`
+	Offset       int32        `json:"offset"`
+	Limit        int32        `json:"limit"`
+}
+
+func (q *Queries) ListLoginAttempts(ctx context.Context, arg ListLoginAttemptsParams) ([]LoginAttempt, error) {
+	rows, err := q.db.QueryContext(ctx, listLoginAttempts,
+		arg.OperatorName,
+		arg.Email,
+		arg.Succeeded,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LoginAttempt{}
+	for rows.Next() {
+		var i LoginAttempt
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.OperatorName,
+			&i.Succeeded,
+			&i.FailureReason,
+			&i.ClientIp,
+			&i.UserAgent,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
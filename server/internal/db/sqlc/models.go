@@ -6,11 +6,28 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// 管理画面での操作履歴.操作と同じトランザクションで記録する.
+type AuditLog struct {
+	ID int64 `json:"id"`
+	// 操作したオペレーター.オペレーターの変更・削除後も履歴を残すため外部キーにしない.
+	OperatorName string `json:"operator_name"`
+	Action       string `json:"action"`
+	EntityType   string `json:"entity_type"`
+	// 操作対象のID.日替わりイラストの場合は日付、一括の並び替えの場合は空文字.
+	EntityID string `json:"entity_id"`
+	// 変更された項目ごとの変更前(before)と変更後(after)の値.
+	Diff      json.RawMessage `json:"diff"`
+	ClientIp  string          `json:"client_ip"`
+	UserAgent string          `json:"user_agent"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
 type Character struct {
 	ID            int64          `json:"id"`
 	Name          string         `json:"name"`
@@ -19,6 +36,8 @@ type Character struct {
 	CreatedAt     time.Time      `json:"created_at"`
 	Filename      sql.NullString `json:"filename"`
 	PriorityLevel int16          `json:"priority_level"`
+	// ゴミ箱に移動した日時.NULLでない場合は公開しない.保持期間を過ぎると完全に削除する.
+	DeletedAt sql.NullTime `json:"-"`
 }
 
 type ChildCategory struct {
@@ -28,6 +47,18 @@ type ChildCategory struct {
 	UpdatedAt     time.Time `json:"updated_at"`
 	CreatedAt     time.Time `json:"created_at"`
 	PriorityLevel int16     `json:"priority_level"`
+	// 親カテゴリと合わせてゴミ箱に移動した日時.NULLでない場合は公開しない.
+	DeletedAt sql.NullTime `json:"-"`
+}
+
+type DailyIllustration struct {
+	// 日本時間の日付
+	Date    time.Time `json:"date"`
+	ImageID int64     `json:"image_id"`
+	// 管理者が指定したイラストの場合はtrue
+	IsOverride bool      `json:"is_override"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type Image struct {
@@ -41,18 +72,28 @@ type Image struct {
 	CreatedAt        time.Time      `json:"created_at"`
 	OriginalFilename string         `json:"original_filename"`
 	SimpleFilename   sql.NullString `json:"simple_filename"`
+	// ランダムに抽出する際の並び順.インデックスを使って全件のソートを避けるために使用する.
+	RandomKey float64 `json:"-"`
+	// 閲覧数.人気順の並び替えに使用する.
+	ViewCount int64 `json:"-"`
+	// ゴミ箱に移動した日時.NULLでない場合は公開しない.保持期間を過ぎると完全に削除する.
+	DeletedAt sql.NullTime `json:"-"`
 }
 
 type ImageCharactersRelation struct {
 	ID          int64 `json:"id"`
 	ImageID     int64 `json:"image_id"`
 	CharacterID int64 `json:"character_id"`
+	// キャラクターごとのイラスト一覧での表示順.NULLの場合は並び順が指定されたイラストの後に新しい順で並べる.
+	Position sql.NullInt32 `json:"position"`
 }
 
 type ImageChildCategoriesRelation struct {
 	ID              int64 `json:"id"`
 	ImageID         int64 `json:"image_id"`
 	ChildCategoryID int64 `json:"child_category_id"`
+	// 子カテゴリごとのイラスト一覧での表示順.NULLの場合は並び順が指定されたイラストの後に新しい順で並べる.
+	Position sql.NullInt32 `json:"position"`
 }
 
 type ImageParentCategoriesRelation struct {
@@ -61,12 +102,54 @@ type ImageParentCategoriesRelation struct {
 	ParentCategoryID int64 `json:"parent_category_id"`
 }
 
+// イラスト編集前のメタデータのスナップショット.
+type ImageRevision struct {
+	ID                int64          `json:"id"`
+	ImageID           int64          `json:"image_id"`
+	Title             string         `json:"title"`
+	OriginalSrc       string         `json:"original_src"`
+	SimpleSrc         sql.NullString `json:"simple_src"`
+	OriginalFilename  string         `json:"original_filename"`
+	SimpleFilename    sql.NullString `json:"simple_filename"`
+	CharacterIds      []int64        `json:"character_ids"`
+	ParentCategoryIds []int64        `json:"parent_category_ids"`
+	ChildCategoryIds  []int64        `json:"child_category_ids"`
+	// 編集したオペレーター.オペレーターの変更・削除後も履歴を残すため外部キーにしない.
+	OperatorName string    `json:"operator_name"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type ImageSearchDocument struct {
+	ImageID      int64       `json:"image_id"`
+	SearchVector interface{} `json:"search_vector"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// 管理画面へのログインの履歴.失敗したログインも記録する.
+type LoginAttempt struct {
+	ID int64 `json:"id"`
+	// ログインに使用されたメールアドレス.登録されていないメールアドレスも記録する.
+	Email string `json:"email"`
+	// メールアドレスに対応するオペレーター.登録されていないメールアドレスの場合は空文字.オペレーターの変更・削除後も履歴を残すため外部キーにしない.
+	OperatorName string `json:"operator_name"`
+	Succeeded    bool   `json:"succeeded"`
+	// 失敗した理由(unknown_email, wrong_password).成功した場合は空文字.
+	FailureReason string    `json:"failure_reason"`
+	ClientIp      string    `json:"client_ip"`
+	UserAgent     string    `json:"user_agent"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
 type Operator struct {
 	ID             int64     `json:"id"`
 	Name           string    `json:"name"`
-	HashedPassword string    `json:"hashed_password"`
+	HashedPassword string    `json:"-"`
 	Email          string    `json:"email"`
 	CreatedAt      time.Time `json:"created_at"`
+	// 管理画面での権限(owner: オペレーターの管理を含むすべての操作, editor: コンテンツの閲覧と編集, viewer: コンテンツの閲覧のみ).
+	Role string `json:"role"`
+	// 無効化した日時.無効化されたオペレーターはログインできない.
+	DisabledAt sql.NullTime `json:"disabled_at"`
 }
 
 type ParentCategory struct {
@@ -77,6 +160,15 @@ type ParentCategory struct {
 	CreatedAt     time.Time      `json:"created_at"`
 	Filename      sql.NullString `json:"filename"`
 	PriorityLevel int16          `json:"priority_level"`
+	// ゴミ箱に移動した日時.NULLでない場合は公開しない.保持期間を過ぎると完全に削除する.
+	DeletedAt sql.NullTime `json:"-"`
+}
+
+type SearchLog struct {
+	ID          int64     `json:"id"`
+	Query       string    `json:"query"`
+	ResultCount int64     `json:"result_count"`
+	SearchedAt  time.Time `json:"searched_at"`
 }
 
 type Session struct {
@@ -89,4 +181,18 @@ type Session struct {
 	ExpiresAt    time.Time      `json:"expires_at"`
 	CreatedAt    time.Time      `json:"created_at"`
 	Email        sql.NullString `json:"email"`
+	// ログイン時のセッションのID.リフレッシュトークンを更新して作成したセッションも同じ値を持つ.
+	FamilyID uuid.UUID `json:"family_id"`
+	// リフレッシュトークンを更新した日時.更新済みのリフレッシュトークンが再度使用された場合は漏洩とみなす.
+	RotatedAt sql.NullTime `json:"rotated_at"`
+	// セッションと同時に発行したアクセストークンのID.セッションが無効化された場合はアクセストークンも拒否する.
+	AccessTokenID uuid.NullUUID `json:"access_token_id"`
+}
+
+type Synonym struct {
+	ID        int64     `json:"id"`
+	Word      string    `json:"word"`
+	Synonym   string    `json:"synonym"`
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
 }
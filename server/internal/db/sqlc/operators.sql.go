@@ -10,19 +10,76 @@ import (
 )
 
 const createOperator = `-- name: CreateOperator :one
-INSERT INTO operators (name, hashed_password, email)
-VALUES ($1, $2, $3)
-RETURNING id, name, hashed_password, email, created_at
+INSERT INTO operators (name, hashed_password, email, role)
+VALUES ($1, $2, $3, $4)
+RETURNING id, name, hashed_password, email, created_at, role, disabled_at
 `
 
 type CreateOperatorParams struct {
 	Name           string `json:"name"`
-	HashedPassword string `json:"hashed_password"`
+	HashedPassword string `json:"-"`
 	Email          string `json:"email"`
+	Role           string `json:"role"`
 }
 
 func (q *Queries) CreateOperator(ctx context.Context, arg CreateOperatorParams) (Operator, error) {
-	row := q.db.QueryRowContext(ctx, createOperator, arg.Name, arg.HashedPassword, arg.Email)
+	row := q.db.QueryRowContext(ctx, createOperator,
+		arg.Name,
+		arg.HashedPassword,
+		arg.Email,
+		arg.Role,
+	)
+	var i Operator
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.HashedPassword,
+		&i.Email,
+		&i.CreatedAt,
+		&i.Role,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const disableOperator = `-- name: DisableOperator :one
+UPDATE operators
+SET disabled_at = now()
+WHERE id = $1
+RETURNING id, name, hashed_password, email, created_at, role, disabled_at
+`
+
+func (q *Queries) DisableOperator(ctx context.Context, id int64) (Operator, error) {
+	row := q.db.QueryRowContext(ctx, disableOperator, id)
+	var i Operator
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.HashedPassword,
+		&i.Email,
+		&i.CreatedAt,
+		&i.Role,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const editOperator = `-- name: EditOperator :one
+UPDATE operators
+SET email = $1,
+  role = $2
+WHERE id = $3
+RETURNING id, name, hashed_password, email, created_at, role, disabled_at
+`
+
+type EditOperatorParams struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+	ID    int64  `json:"id"`
+}
+
+func (q *Queries) EditOperator(ctx context.Context, arg EditOperatorParams) (Operator, error) {
+	row := q.db.QueryRowContext(ctx, editOperator, arg.Email, arg.Role, arg.ID)
 	var i Operator
 	err := row.Scan(
 		&i.ID,
@@ -30,12 +87,58 @@ func (q *Queries) CreateOperator(ctx context.Context, arg CreateOperatorParams)
 		&i.HashedPassword,
 		&i.Email,
 		&i.CreatedAt,
+		&i.Role,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const enableOperator = `-- name: EnableOperator :one
+UPDATE operators
+SET disabled_at = NULL
+WHERE id = $1
+RETURNING id, name, hashed_password, email, created_at, role, disabled_at
+`
+
+func (q *Queries) EnableOperator(ctx context.Context, id int64) (Operator, error) {
+	row := q.db.QueryRowContext(ctx, enableOperator, id)
+	var i Operator
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.HashedPassword,
+		&i.Email,
+		&i.CreatedAt,
+		&i.Role,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const getOperator = `-- name: GetOperator :one
+SELECT id, name, hashed_password, email, created_at, role, disabled_at
+FROM operators
+WHERE id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetOperator(ctx context.Context, id int64) (Operator, error) {
+	row := q.db.QueryRowContext(ctx, getOperator, id)
+	var i Operator
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.HashedPassword,
+		&i.Email,
+		&i.CreatedAt,
+		&i.Role,
+		&i.DisabledAt,
 	)
 	return i, err
 }
 
 const getOperatorByEmail = `-- name: GetOperatorByEmail :one
-SELECT id, name, hashed_password, email, created_at
+SELECT id, name, hashed_password, email, created_at, role, disabled_at
 FROM operators
 WHERE email = $1
 LIMIT 1
@@ -50,23 +153,116 @@ func (q *Queries) GetOperatorByEmail(ctx context.Context, email string) (Operato
 		&i.HashedPassword,
 		&i.Email,
 		&i.CreatedAt,
+		&i.Role,
+		&i.DisabledAt,
 	)
 	return i, err
 }
 
+const getOperatorByName = `-- name: GetOperatorByName :one
+SELECT id, name, hashed_password, email, created_at, role, disabled_at
+FROM operators
+WHERE name = $1
+LIMIT 1
+`
+
+func (q *Queries) GetOperatorByName(ctx context.Context, name string) (Operator, error) {
+	row := q.db.QueryRowContext(ctx, getOperatorByName, name)
+	var i Operator
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.HashedPassword,
+		&i.Email,
+		&i.CreatedAt,
+		&i.Role,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const listOperators = `-- name: ListOperators :many
+SELECT id, name, hashed_password, email, created_at, role, disabled_at
+FROM operators
+ORDER BY id
+`
+
+func (q *Queries) ListOperators(ctx context.Context) ([]Operator, error) {
+	rows, err := q.db.QueryContext(ctx, listOperators)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Operator{}
+	for rows.Next() {
+		var i Operator
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.HashedPassword,
+			&i.Email,
+			&i.CreatedAt,
+			&i.Role,
+			&i.DisabledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockActiveOwners = `-- name: LockActiveOwners :many
+SELECT id
+FROM operators
+WHERE role = 'owner'
+  AND disabled_at IS NULL
+ORDER BY id
+FOR UPDATE
+`
+
+func (q *Queries) LockActiveOwners(ctx context.Context) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, lockActiveOwners)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateOperator = `-- name: UpdateOperator :one
 UPDATE operators
 SET name = $2,
   hashed_password = $3,
   email = $4
 WHERE id = $1
-RETURNING id, name, hashed_password, email, created_at
+RETURNING id, name, hashed_password, email, created_at, role, disabled_at
 `
 
 type UpdateOperatorParams struct {
 	ID             int64  `json:"id"`
 	Name           string `json:"name"`
-	HashedPassword string `json:"hashed_password"`
+	HashedPassword string `json:"-"`
 	Email          string `json:"email"`
 }
 
@@ -84,6 +280,8 @@ func (q *Queries) UpdateOperator(ctx context.Context, arg UpdateOperatorParams)
 		&i.HashedPassword,
 		&i.Email,
 		&i.CreatedAt,
+		&i.Role,
+		&i.DisabledAt,
 	)
 	return i, err
 }
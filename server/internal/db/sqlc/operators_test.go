@@ -27,6 +27,7 @@ func TestCreateOperator(t *testing.T) {
 				Name:           "test_operator_name_10001",
 				HashedPassword: hashedPassword,
 				Email:          "test_10001@test.com",
+				Role:           "editor",
 			},
 			want: db.Operator{
 				Name:           "test_operator_name_10001",
@@ -59,6 +60,16 @@ func TestCreateOperator(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "異常系（roleが定義されていない権限の場合）",
+			arg: db.CreateOperatorParams{
+				Name:           "test_operator_name_10003",
+				HashedPassword: hashedPassword,
+				Email:          "test_10003@test.com",
+				Role:           "admin",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -73,6 +84,8 @@ func TestCreateOperator(t *testing.T) {
 				require.Equal(t, tt.arg.Name, operator.Name)
 				require.Equal(t, tt.arg.HashedPassword, operator.HashedPassword)
 				require.Equal(t, tt.arg.Email, operator.Email)
+				require.Equal(t, tt.arg.Role, operator.Role)
+				require.False(t, operator.DisabledAt.Valid)
 				require.NotZero(t, operator.ID)
 				require.NotZero(t, operator.CreatedAt)
 			}
@@ -104,6 +117,7 @@ func TestGetOperator(t *testing.T) {
 					Name:           "test_operator_name_00001",
 					HashedPassword: hashedPassword,
 					Email:          "test_00001@test.com",
+					Role:           "viewer",
 				},
 			},
 			want: db.Operator{
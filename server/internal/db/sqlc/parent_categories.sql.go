@@ -9,11 +9,27 @@ import (
 	"context"
 	"database/sql"
 	"time"
+
+	"github.com/lib/pq"
 )
 
+const countDeletedParentCategories = `-- name: CountDeletedParentCategories :one
+SELECT count(*)
+FROM parent_categories
+WHERE deleted_at IS NOT NULL
+`
+
+func (q *Queries) CountDeletedParentCategories(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countDeletedParentCategories)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countParentCategories = `-- name: CountParentCategories :one
 SELECT count(*)
 FROM parent_categories
+WHERE deleted_at IS NULL
 `
 
 func (q *Queries) CountParentCategories(ctx context.Context) (int64, error) {
@@ -26,12 +42,17 @@ func (q *Queries) CountParentCategories(ctx context.Context) (int64, error) {
 const countSearchParentCategories = `-- name: CountSearchParentCategories :one
 SELECT DISTINCT count(*)
 FROM parent_categories
-WHERE name LIKE '%' || COALESCE($1) || '%'
-  OR filename LIKE '%' || COALESCE($1) || '%'
+WHERE deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1
+    FROM unnest($1::text []) AS p(pattern)
+    WHERE normalize_search_text(name) LIKE p.pattern ESCAPE '\'
+      OR normalize_search_text(filename) LIKE p.pattern ESCAPE '\'
+  )
 `
 
-func (q *Queries) CountSearchParentCategories(ctx context.Context, query sql.NullString) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countSearchParentCategories, query)
+func (q *Queries) CountSearchParentCategories(ctx context.Context, patterns []string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSearchParentCategories, pq.Array(patterns))
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -40,7 +61,7 @@ func (q *Queries) CountSearchParentCategories(ctx context.Context, query sql.Nul
 const createParentCategory = `-- name: CreateParentCategory :one
 INSERT INTO parent_categories (name, src, filename, priority_level)
 VALUES ($1, $2, $3, $4)
-RETURNING id, name, src, updated_at, created_at, filename, priority_level
+RETURNING id, name, src, updated_at, created_at, filename, priority_level, deleted_at
 `
 
 type CreateParentCategoryParams struct {
@@ -66,6 +87,7 @@ func (q *Queries) CreateParentCategory(ctx context.Context, arg CreateParentCate
 		&i.CreatedAt,
 		&i.Filename,
 		&i.PriorityLevel,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -80,10 +102,35 @@ func (q *Queries) DeleteParentCategory(ctx context.Context, id int64) error {
 	return err
 }
 
+const getDeletedParentCategoryForUpdate = `-- name: GetDeletedParentCategoryForUpdate :one
+SELECT id, name, src, updated_at, created_at, filename, priority_level, deleted_at
+FROM parent_categories
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+LIMIT 1 FOR UPDATE
+`
+
+func (q *Queries) GetDeletedParentCategoryForUpdate(ctx context.Context, id int64) (ParentCategory, error) {
+	row := q.db.QueryRowContext(ctx, getDeletedParentCategoryForUpdate, id)
+	var i ParentCategory
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Src,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.Filename,
+		&i.PriorityLevel,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
 const getParentCategory = `-- name: GetParentCategory :one
-SELECT id, name, src, updated_at, created_at, filename, priority_level
+SELECT id, name, src, updated_at, created_at, filename, priority_level, deleted_at
 FROM parent_categories
 WHERE id = $1
+  AND deleted_at IS NULL
 LIMIT 1
 `
 
@@ -98,13 +145,39 @@ func (q *Queries) GetParentCategory(ctx context.Context, id int64) (ParentCatego
 		&i.CreatedAt,
 		&i.Filename,
 		&i.PriorityLevel,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getParentCategoryForUpdate = `-- name: GetParentCategoryForUpdate :one
+SELECT id, name, src, updated_at, created_at, filename, priority_level, deleted_at
+FROM parent_categories
+WHERE id = $1
+  AND deleted_at IS NULL
+LIMIT 1 FOR UPDATE
+`
+
+func (q *Queries) GetParentCategoryForUpdate(ctx context.Context, id int64) (ParentCategory, error) {
+	row := q.db.QueryRowContext(ctx, getParentCategoryForUpdate, id)
+	var i ParentCategory
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Src,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.Filename,
+		&i.PriorityLevel,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const listAllParentCategories = `-- name: ListAllParentCategories :many
-SELECT id, name, src, updated_at, created_at, filename, priority_level
+SELECT id, name, src, updated_at, created_at, filename, priority_level, deleted_at
 FROM parent_categories
+WHERE deleted_at IS NULL
 ORDER BY priority_level DESC,
   id DESC
 `
@@ -126,6 +199,53 @@ func (q *Queries) ListAllParentCategories(ctx context.Context) ([]ParentCategory
 			&i.CreatedAt,
 			&i.Filename,
 			&i.PriorityLevel,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeletedParentCategories = `-- name: ListDeletedParentCategories :many
+SELECT id, name, src, updated_at, created_at, filename, priority_level, deleted_at
+FROM parent_categories
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC,
+  id DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListDeletedParentCategoriesParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListDeletedParentCategories(ctx context.Context, arg ListDeletedParentCategoriesParams) ([]ParentCategory, error) {
+	rows, err := q.db.QueryContext(ctx, listDeletedParentCategories, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ParentCategory{}
+	for rows.Next() {
+		var i ParentCategory
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Src,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.Filename,
+			&i.PriorityLevel,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -140,9 +260,41 @@ func (q *Queries) ListAllParentCategories(ctx context.Context) ([]ParentCategory
 	return items, nil
 }
 
+const listExistingParentCategoryIDs = `-- name: ListExistingParentCategoryIDs :many
+SELECT id
+FROM parent_categories
+WHERE id = ANY($1::bigint [])
+  AND deleted_at IS NULL
+ORDER BY id
+`
+
+func (q *Queries) ListExistingParentCategoryIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listExistingParentCategoryIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listParentCategories = `-- name: ListParentCategories :many
-SELECT id, name, src, updated_at, created_at, filename, priority_level
+SELECT id, name, src, updated_at, created_at, filename, priority_level, deleted_at
 FROM parent_categories
+WHERE deleted_at IS NULL
 ORDER BY priority_level DESC,
   id DESC
 LIMIT $1 OFFSET $2
@@ -170,6 +322,7 @@ func (q *Queries) ListParentCategories(ctx context.Context, arg ListParentCatego
 			&i.CreatedAt,
 			&i.Filename,
 			&i.PriorityLevel,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -184,17 +337,162 @@ func (q *Queries) ListParentCategories(ctx context.Context, arg ListParentCatego
 	return items, nil
 }
 
+const listParentCategoriesByCursor = `-- name: ListParentCategoriesByCursor :many
+SELECT id, name, src, updated_at, created_at, filename, priority_level, deleted_at
+FROM parent_categories
+WHERE deleted_at IS NULL
+  AND (
+    priority_level < $2
+    OR (
+      priority_level = $2
+      AND id < $3
+    )
+  )
+ORDER BY priority_level DESC,
+  id DESC
+LIMIT $1
+`
+
+type ListParentCategoriesByCursorParams struct {
+	Limit               int32 `json:"limit"`
+	CursorPriorityLevel int16 `json:"cursor_priority_level"`
+	CursorID            int64 `json:"cursor_id"`
+}
+
+func (q *Queries) ListParentCategoriesByCursor(ctx context.Context, arg ListParentCategoriesByCursorParams) ([]ParentCategory, error) {
+	rows, err := q.db.QueryContext(ctx, listParentCategoriesByCursor, arg.Limit, arg.CursorPriorityLevel, arg.CursorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ParentCategory{}
+	for rows.Next() {
+		var i ParentCategory
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Src,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.Filename,
+			&i.PriorityLevel,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPurgeableParentCategories = `-- name: ListPurgeableParentCategories :many
+SELECT id, name, src, updated_at, created_at, filename, priority_level, deleted_at
+FROM parent_categories
+WHERE deleted_at < $1::timestamptz
+ORDER BY deleted_at
+LIMIT $2
+`
+
+type ListPurgeableParentCategoriesParams struct {
+	DeletedBefore time.Time `json:"deleted_before"`
+	MaxResults    int32     `json:"max_results"`
+}
+
+func (q *Queries) ListPurgeableParentCategories(ctx context.Context, arg ListPurgeableParentCategoriesParams) ([]ParentCategory, error) {
+	rows, err := q.db.QueryContext(ctx, listPurgeableParentCategories, arg.DeletedBefore, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ParentCategory{}
+	for rows.Next() {
+		var i ParentCategory
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Src,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.Filename,
+			&i.PriorityLevel,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const reorderParentCategories = `-- name: ReorderParentCategories :execrows
+UPDATE parent_categories t
+SET priority_level = (cardinality($1::bigint []) - ord.idx + 1)::smallint,
+  updated_at = now()
+FROM unnest($1::bigint []) WITH ORDINALITY AS ord(id, idx)
+WHERE t.id = ord.id
+  AND t.deleted_at IS NULL
+`
+
+func (q *Queries) ReorderParentCategories(ctx context.Context, ids []int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reorderParentCategories, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreParentCategory = `-- name: RestoreParentCategory :one
+UPDATE parent_categories
+SET deleted_at = NULL
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+RETURNING id, name, src, updated_at, created_at, filename, priority_level, deleted_at
+`
+
+func (q *Queries) RestoreParentCategory(ctx context.Context, id int64) (ParentCategory, error) {
+	row := q.db.QueryRowContext(ctx, restoreParentCategory, id)
+	var i ParentCategory
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Src,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.Filename,
+		&i.PriorityLevel,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
 const searchParentCategories = `-- name: SearchParentCategories :many
-SELECT DISTINCT id, name, src, updated_at, created_at, filename, priority_level
+SELECT DISTINCT id, name, src, updated_at, created_at, filename, priority_level, deleted_at
 FROM parent_categories
-WHERE name LIKE '%' || COALESCE($1) || '%'
-  OR filename LIKE '%' || COALESCE($1) || '%'
+WHERE deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1
+    FROM unnest($1::text []) AS p(pattern)
+    WHERE normalize_search_text(name) LIKE p.pattern ESCAPE '\'
+      OR normalize_search_text(filename) LIKE p.pattern ESCAPE '\'
+  )
 ORDER BY priority_level DESC,
   id DESC
 `
 
-func (q *Queries) SearchParentCategories(ctx context.Context, query sql.NullString) ([]ParentCategory, error) {
-	rows, err := q.db.QueryContext(ctx, searchParentCategories, query)
+func (q *Queries) SearchParentCategories(ctx context.Context, patterns []string) ([]ParentCategory, error) {
+	rows, err := q.db.QueryContext(ctx, searchParentCategories, pq.Array(patterns))
 	if err != nil {
 		return nil, err
 	}
@@ -210,6 +508,7 @@ func (q *Queries) SearchParentCategories(ctx context.Context, query sql.NullStri
 			&i.CreatedAt,
 			&i.Filename,
 			&i.PriorityLevel,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -224,34 +523,62 @@ func (q *Queries) SearchParentCategories(ctx context.Context, query sql.NullStri
 	return items, nil
 }
 
-const updateParentCategory = `-- name: UpdateParentCategory :one
+const softDeleteParentCategory = `-- name: SoftDeleteParentCategory :one
 UPDATE parent_categories
-SET name = $2,
-  src = $3,
-  filename = $4,
-  updated_at = $5,
-  priority_level = $6
+SET deleted_at = now()
 WHERE id = $1
-RETURNING id, name, src, updated_at, created_at, filename, priority_level
+  AND deleted_at IS NULL
+RETURNING id, name, src, updated_at, created_at, filename, priority_level, deleted_at
+`
+
+func (q *Queries) SoftDeleteParentCategory(ctx context.Context, id int64) (ParentCategory, error) {
+	row := q.db.QueryRowContext(ctx, softDeleteParentCategory, id)
+	var i ParentCategory
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Src,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.Filename,
+		&i.PriorityLevel,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const updateParentCategory = `-- name: UpdateParentCategory :one
+UPDATE parent_categories
+SET name = $1,
+  src = $2,
+  filename = $3,
+  updated_at = $4,
+  priority_level = $5
+WHERE id = $6
+  AND updated_at = $7
+RETURNING id, name, src, updated_at, created_at, filename, priority_level, deleted_at
 `
 
 type UpdateParentCategoryParams struct {
-	ID            int64          `json:"id"`
-	Name          string         `json:"name"`
-	Src           string         `json:"src"`
-	Filename      sql.NullString `json:"filename"`
-	UpdatedAt     time.Time      `json:"updated_at"`
-	PriorityLevel int16          `json:"priority_level"`
+	Name              string         `json:"name"`
+	Src               string         `json:"src"`
+	Filename          sql.NullString `json:"filename"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	PriorityLevel     int16          `json:"priority_level"`
+	ID                int64          `json:"id"`
+	ExpectedUpdatedAt time.Time      `json:"expected_updated_at"`
 }
 
+// updated_atが一致しない場合は他の操作で更新済みのため、更新せずに0件を返す
 func (q *Queries) UpdateParentCategory(ctx context.Context, arg UpdateParentCategoryParams) (ParentCategory, error) {
 	row := q.db.QueryRowContext(ctx, updateParentCategory,
-		arg.ID,
 		arg.Name,
 		arg.Src,
 		arg.Filename,
 		arg.UpdatedAt,
 		arg.PriorityLevel,
+		arg.ID,
+		arg.ExpectedUpdatedAt,
 	)
 	var i ParentCategory
 	err := row.Scan(
@@ -262,6 +589,7 @@ func (q *Queries) UpdateParentCategory(ctx context.Context, arg UpdateParentCate
 		&i.CreatedAt,
 		&i.Filename,
 		&i.PriorityLevel,
+		&i.DeletedAt,
 	)
 	return i, err
 }
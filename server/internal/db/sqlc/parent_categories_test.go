@@ -317,3 +317,60 @@ func TestUpdateParentCategory(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchParentCategories(t *testing.T) {
+	defer TearDown(t, testQueries)
+
+	// 名前・ファイル名はnormalize_search_textで正規化して比較する
+	_, err := testQueries.ExecQuery(context.Background(), `
+		INSERT INTO parent_categories (id, name, src, filename, priority_level)
+		VALUES
+		(10001, 'キセツ', 'test_parent_category_src_10001', 'season.png', 2),
+		(10002, 'どうぶつ', 'test_parent_category_src_10002', 'ANIMAL_Season.png', 1),
+		(10003, 'たべもの', 'test_parent_category_src_10003', NULL, 0);
+	`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		patterns []string
+		wantIDs  []int64
+	}{
+		{
+			name:     "正常系（カタカナの名前にひらがなで一致する場合）",
+			patterns: []string{"%きせつ%"},
+			wantIDs:  []int64{10001},
+		},
+		{
+			name:     "正常系（ファイル名に大文字・小文字を区別せず一致する場合）",
+			patterns: []string{"%season%"},
+			wantIDs:  []int64{10001, 10002},
+		},
+		{
+			name:     "正常系（いずれかのパターンに一致する場合）",
+			patterns: []string{"%たべもの%", "%animal%"},
+			wantIDs:  []int64{10002, 10003},
+		},
+		{
+			name:     "正常系（一致しない場合）",
+			patterns: []string{"%のりもの%"},
+			wantIDs:  []int64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pcates, err := testQueries.SearchParentCategories(context.Background(), tt.patterns)
+			require.NoError(t, err)
+			gotIDs := []int64{}
+			for _, p := range pcates {
+				gotIDs = append(gotIDs, p.ID)
+			}
+			require.ElementsMatch(t, tt.wantIDs, gotIDs)
+
+			count, err := testQueries.CountSearchParentCategories(context.Background(), tt.patterns)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(tt.wantIDs)), count)
+		})
+	}
+}
@@ -6,27 +6,50 @@ package db
 
 import (
 	"context"
-	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AddImageViewCounts(ctx context.Context, arg AddImageViewCountsParams) (int64, error)
+	BlockOperatorSessions(ctx context.Context, name string) error
+	BlockSessionFamily(ctx context.Context, familyID uuid.UUID) error
+	ClearImageCharacterPositions(ctx context.Context, characterID int64) error
+	ClearImageChildCategoryPositions(ctx context.Context, childCategoryID int64) error
+	CountAuditLogs(ctx context.Context, arg CountAuditLogsParams) (int64, error)
 	CountCharacters(ctx context.Context) (int64, error)
+	CountCharactersByImageIDs(ctx context.Context, imageIds []int64) ([]CountCharactersByImageIDsRow, error)
+	CountChildCategories(ctx context.Context) (int64, error)
+	CountChildCategoriesByImageIDs(ctx context.Context, imageIds []int64) ([]CountChildCategoriesByImageIDsRow, error)
+	CountDeletedCharacters(ctx context.Context) (int64, error)
+	CountDeletedImages(ctx context.Context) (int64, error)
+	CountDeletedParentCategories(ctx context.Context) (int64, error)
+	CountImageRevisions(ctx context.Context, imageID int64) (int64, error)
 	CountImages(ctx context.Context) (int64, error)
+	// ゴミ箱のイラストもunique制約の対象のため、deleted_atでは絞り込まない
+	CountImagesByOriginalFilename(ctx context.Context, arg CountImagesByOriginalFilenameParams) (int64, error)
+	CountLoginAttempts(ctx context.Context, arg CountLoginAttemptsParams) (int64, error)
 	CountParentCategories(ctx context.Context) (int64, error)
-	CountSearchCharacters(ctx context.Context, query sql.NullString) (int64, error)
-	CountSearchImages(ctx context.Context, query sql.NullString) (int64, error)
-	CountSearchParentCategories(ctx context.Context, query sql.NullString) (int64, error)
+	CountSampleImagesFrom(ctx context.Context, arg CountSampleImagesFromParams) (int64, error)
+	CountSearchCharacters(ctx context.Context, patterns []string) (int64, error)
+	CountSearchImages(ctx context.Context, query string) (int64, error)
+	CountSearchParentCategories(ctx context.Context, patterns []string) (int64, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) error
 	CreateCharacter(ctx context.Context, arg CreateCharacterParams) (Character, error)
 	CreateChildCategory(ctx context.Context, arg CreateChildCategoryParams) (ChildCategory, error)
+	CreateDailyIllustration(ctx context.Context, arg CreateDailyIllustrationParams) error
 	CreateImage(ctx context.Context, arg CreateImageParams) (Image, error)
 	CreateImageCharacterRelations(ctx context.Context, arg CreateImageCharacterRelationsParams) (ImageCharactersRelation, error)
 	CreateImageChildCategoryRelations(ctx context.Context, arg CreateImageChildCategoryRelationsParams) (ImageChildCategoriesRelation, error)
 	CreateImageParentCategoryRelations(ctx context.Context, arg CreateImageParentCategoryRelationsParams) (ImageParentCategoriesRelation, error)
+	CreateImageRevision(ctx context.Context, arg CreateImageRevisionParams) (ImageRevision, error)
+	CreateLoginAttempt(ctx context.Context, arg CreateLoginAttemptParams) error
 	CreateOperator(ctx context.Context, arg CreateOperatorParams) (Operator, error)
 	CreateParentCategory(ctx context.Context, arg CreateParentCategoryParams) (ParentCategory, error)
+	CreateSearchLog(ctx context.Context, arg CreateSearchLogParams) error
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateSynonym(ctx context.Context, arg CreateSynonymParams) (Synonym, error)
 	DeleteAllChildCategoriesByParentCategoryID(ctx context.Context, parentID int64) error
 	DeleteAllImageCharacterRelationsByCharacterID(ctx context.Context, characterID int64) error
 	DeleteAllImageCharacterRelationsByImageID(ctx context.Context, imageID int64) error
@@ -36,44 +59,122 @@ type Querier interface {
 	DeleteAllImageParentCategoryRelationsByParentCategoryID(ctx context.Context, parentCategoryID int64) error
 	DeleteCharacter(ctx context.Context, id int64) error
 	DeleteChildCategory(ctx context.Context, id int64) error
+	DeleteDailyIllustrationOverride(ctx context.Context, date time.Time) (int64, error)
 	DeleteImage(ctx context.Context, id int64) error
 	DeleteImageCharacterRelations(ctx context.Context, id int64) error
 	DeleteImageChildCategoryRelations(ctx context.Context, id int64) error
 	DeleteImageParentCategoryRelations(ctx context.Context, id int64) error
 	DeleteParentCategory(ctx context.Context, id int64) error
-	FetchRandomImage(ctx context.Context, arg FetchRandomImageParams) ([]Image, error)
+	DeleteSynonym(ctx context.Context, id int64) error
+	DisableOperator(ctx context.Context, id int64) (Operator, error)
+	EditOperator(ctx context.Context, arg EditOperatorParams) (Operator, error)
+	EnableOperator(ctx context.Context, id int64) (Operator, error)
+	FilterImageIDs(ctx context.Context, arg FilterImageIDsParams) ([]int64, error)
 	GetCharacter(ctx context.Context, id int64) (Character, error)
+	GetCharacterForUpdate(ctx context.Context, id int64) (Character, error)
 	GetChildCategoriesByParentID(ctx context.Context, parentID int64) ([]ChildCategory, error)
 	GetChildCategory(ctx context.Context, id int64) (ChildCategory, error)
+	GetDailyIllustration(ctx context.Context, date time.Time) (DailyIllustration, error)
+	GetDeletedParentCategoryForUpdate(ctx context.Context, id int64) (ParentCategory, error)
 	GetImage(ctx context.Context, id int64) (Image, error)
+	GetImageForUpdate(ctx context.Context, id int64) (Image, error)
+	GetImageRevision(ctx context.Context, arg GetImageRevisionParams) (ImageRevision, error)
+	GetOperator(ctx context.Context, id int64) (Operator, error)
 	GetOperatorByEmail(ctx context.Context, email string) (Operator, error)
+	GetOperatorByName(ctx context.Context, name string) (Operator, error)
 	GetParentCategory(ctx context.Context, id int64) (ParentCategory, error)
+	GetParentCategoryForUpdate(ctx context.Context, id int64) (ParentCategory, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSessionByAccessTokenID(ctx context.Context, accessTokenID uuid.NullUUID) (Session, error)
+	GetSynonym(ctx context.Context, id int64) (Synonym, error)
+	ListActiveSessions(ctx context.Context, name string) ([]Session, error)
 	ListAllCharacters(ctx context.Context) ([]Character, error)
 	ListAllParentCategories(ctx context.Context) ([]ParentCategory, error)
+	ListAllSynonyms(ctx context.Context) ([]Synonym, error)
+	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error)
 	ListCharacters(ctx context.Context, arg ListCharactersParams) ([]Character, error)
+	ListCharactersByCursor(ctx context.Context, arg ListCharactersByCursorParams) ([]Character, error)
 	ListChildCategories(ctx context.Context, arg ListChildCategoriesParams) ([]ChildCategory, error)
+	ListChildCategoriesByIDs(ctx context.Context, ids []int64) ([]ChildCategory, error)
+	ListChildCategoryIDsByParentIDIncludingDeleted(ctx context.Context, parentID int64) ([]int64, error)
+	ListDailyIllustrationOverrides(ctx context.Context, fromDate time.Time) ([]DailyIllustration, error)
+	ListDailyImageIDs(ctx context.Context, arg ListDailyImageIDsParams) ([]int64, error)
+	ListDeletedCharacters(ctx context.Context, arg ListDeletedCharactersParams) ([]Character, error)
+	ListDeletedImages(ctx context.Context, arg ListDeletedImagesParams) ([]Image, error)
+	ListDeletedParentCategories(ctx context.Context, arg ListDeletedParentCategoriesParams) ([]ParentCategory, error)
+	ListExistingCharacterIDs(ctx context.Context, ids []int64) ([]int64, error)
+	ListExistingChildCategoryIDs(ctx context.Context, ids []int64) ([]int64, error)
+	ListExistingParentCategoryIDs(ctx context.Context, ids []int64) ([]int64, error)
 	ListImage(ctx context.Context, arg ListImageParams) ([]Image, error)
+	ListImageCharacterRelationsByCharacterID(ctx context.Context, characterID int64) ([]ImageCharactersRelation, error)
 	ListImageCharacterRelationsByCharacterIDWIthPagination(ctx context.Context, arg ListImageCharacterRelationsByCharacterIDWIthPaginationParams) ([]ImageCharactersRelation, error)
 	ListImageCharacterRelationsByImageID(ctx context.Context, imageID int64) ([]ImageCharactersRelation, error)
 	ListImageChildCategoryRelationsByChildCategoryID(ctx context.Context, childCategoryID int64) ([]ImageChildCategoriesRelation, error)
 	ListImageChildCategoryRelationsByChildCategoryIDWithPagination(ctx context.Context, arg ListImageChildCategoryRelationsByChildCategoryIDWithPaginationParams) ([]ImageChildCategoriesRelation, error)
 	ListImageChildCategoryRelationsByImageID(ctx context.Context, imageID int64) ([]ImageChildCategoriesRelation, error)
+	ListImageIDsWithoutSearchDocument(ctx context.Context) ([]int64, error)
 	ListImageParentCategoryRelationsByImageID(ctx context.Context, imageID int64) ([]ImageParentCategoriesRelation, error)
 	ListImageParentCategoryRelationsByParentCategoryID(ctx context.Context, parentCategoryID int64) ([]ImageParentCategoriesRelation, error)
 	ListImageParentCategoryRelationsByParentCategoryIDWithPagination(ctx context.Context, arg ListImageParentCategoryRelationsByParentCategoryIDWithPaginationParams) ([]ImageParentCategoriesRelation, error)
+	ListImageRevisions(ctx context.Context, arg ListImageRevisionsParams) ([]ImageRevision, error)
+	ListImagesByCharacterOrderByPosition(ctx context.Context, arg ListImagesByCharacterOrderByPositionParams) ([]ListImagesByCharacterOrderByPositionRow, error)
+	ListImagesByChildCategoryOrderByPosition(ctx context.Context, arg ListImagesByChildCategoryOrderByPositionParams) ([]ListImagesByChildCategoryOrderByPositionRow, error)
+	ListImagesOrderByNewest(ctx context.Context, arg ListImagesOrderByNewestParams) ([]Image, error)
+	ListImagesOrderByOldest(ctx context.Context, arg ListImagesOrderByOldestParams) ([]Image, error)
+	ListImagesOrderByPopular(ctx context.Context, arg ListImagesOrderByPopularParams) ([]Image, error)
+	ListImagesOrderByTitle(ctx context.Context, arg ListImagesOrderByTitleParams) ([]Image, error)
+	ListImagesOrderByUpdated(ctx context.Context, arg ListImagesOrderByUpdatedParams) ([]Image, error)
+	ListLoginAttempts(ctx context.Context, arg ListLoginAttemptsParams) ([]LoginAttempt, error)
+	ListOperators(ctx context.Context) ([]Operator, error)
 	ListParentCategories(ctx context.Context, arg ListParentCategoriesParams) ([]ParentCategory, error)
+	ListParentCategoriesByCursor(ctx context.Context, arg ListParentCategoriesByCursorParams) ([]ParentCategory, error)
+	ListPurgeableCharacters(ctx context.Context, arg ListPurgeableCharactersParams) ([]Character, error)
+	ListPurgeableImages(ctx context.Context, arg ListPurgeableImagesParams) ([]Image, error)
+	ListPurgeableParentCategories(ctx context.Context, arg ListPurgeableParentCategoriesParams) ([]ParentCategory, error)
+	ListRelatedImages(ctx context.Context, arg ListRelatedImagesParams) ([]Image, error)
+	ListSearchTrends(ctx context.Context, arg ListSearchTrendsParams) ([]ListSearchTrendsRow, error)
+	ListTopSearchQueries(ctx context.Context, arg ListTopSearchQueriesParams) ([]ListTopSearchQueriesRow, error)
+	ListZeroResultSearchQueries(ctx context.Context, arg ListZeroResultSearchQueriesParams) ([]ListZeroResultSearchQueriesRow, error)
+	LockActiveOwners(ctx context.Context) ([]int64, error)
+	ReorderCharacters(ctx context.Context, ids []int64) (int64, error)
+	ReorderChildCategories(ctx context.Context, ids []int64) (int64, error)
+	ReorderParentCategories(ctx context.Context, ids []int64) (int64, error)
+	RestoreCharacter(ctx context.Context, id int64) (Character, error)
+	RestoreChildCategoriesByParentID(ctx context.Context, arg RestoreChildCategoriesByParentIDParams) error
+	RestoreImage(ctx context.Context, id int64) (Image, error)
+	RestoreParentCategory(ctx context.Context, id int64) (ParentCategory, error)
+	RotateSession(ctx context.Context, id uuid.UUID) (int64, error)
+	SampleImagesBefore(ctx context.Context, arg SampleImagesBeforeParams) ([]Image, error)
+	SampleImagesFrom(ctx context.Context, arg SampleImagesFromParams) ([]Image, error)
+	// 起点からrandom_keyのインデックスで辿った候補のみを重み付けし、全件のソートを避ける
+	SampleImagesWeighted(ctx context.Context, arg SampleImagesWeightedParams) ([]Image, error)
 	SearchCharacters(ctx context.Context, arg SearchCharactersParams) ([]Character, error)
-	SearchImages(ctx context.Context, arg SearchImagesParams) ([]Image, error)
-	SearchParentCategories(ctx context.Context, query sql.NullString) ([]ParentCategory, error)
+	SearchCharactersByCursor(ctx context.Context, arg SearchCharactersByCursorParams) ([]Character, error)
+	SearchImages(ctx context.Context, arg SearchImagesParams) ([]SearchImagesRow, error)
+	SearchImagesByCursor(ctx context.Context, arg SearchImagesByCursorParams) ([]SearchImagesByCursorRow, error)
+	SearchParentCategories(ctx context.Context, patterns []string) ([]ParentCategory, error)
+	SetImageCharacterPositions(ctx context.Context, arg SetImageCharacterPositionsParams) (int64, error)
+	SetImageChildCategoryPositions(ctx context.Context, arg SetImageChildCategoryPositionsParams) (int64, error)
+	SoftDeleteCharacter(ctx context.Context, id int64) (int64, error)
+	SoftDeleteChildCategoriesByParentID(ctx context.Context, arg SoftDeleteChildCategoriesByParentIDParams) error
+	SoftDeleteImage(ctx context.Context, id int64) (int64, error)
+	SoftDeleteParentCategory(ctx context.Context, id int64) (ParentCategory, error)
+	SuggestSearchTerms(ctx context.Context, arg SuggestSearchTermsParams) ([]SuggestSearchTermsRow, error)
+	// updated_atが一致しない場合は他の操作で更新済みのため、更新せずに0件を返す
 	UpdateCharacter(ctx context.Context, arg UpdateCharacterParams) (Character, error)
+	// updated_atが一致しない場合は他の操作で更新済みのため、更新せずに0件を返す
 	UpdateChildCategory(ctx context.Context, arg UpdateChildCategoryParams) (ChildCategory, error)
+	// updated_atが一致しない場合は他の操作で更新済みのため、更新せずに0件を返す
 	UpdateImage(ctx context.Context, arg UpdateImageParams) (Image, error)
 	UpdateImageCharacterRelations(ctx context.Context, arg UpdateImageCharacterRelationsParams) (ImageCharactersRelation, error)
 	UpdateImageChildCategoryRelations(ctx context.Context, arg UpdateImageChildCategoryRelationsParams) (ImageChildCategoriesRelation, error)
 	UpdateImageParentCategoryRelations(ctx context.Context, arg UpdateImageParentCategoryRelationsParams) (ImageParentCategoriesRelation, error)
 	UpdateOperator(ctx context.Context, arg UpdateOperatorParams) (Operator, error)
+	// updated_atが一致しない場合は他の操作で更新済みのため、更新せずに0件を返す
 	UpdateParentCategory(ctx context.Context, arg UpdateParentCategoryParams) (ParentCategory, error)
+	UpdateSynonym(ctx context.Context, arg UpdateSynonymParams) (Synonym, error)
+	UpsertDailyIllustrationOverride(ctx context.Context, arg UpsertDailyIllustrationOverrideParams) (DailyIllustration, error)
+	UpsertImageSearchDocument(ctx context.Context, arg UpsertImageSearchDocumentParams) error
 }
 
 var _ Querier = (*Queries)(nil)
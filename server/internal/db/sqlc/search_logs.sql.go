@@ -0,0 +1,176 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: search_logs.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createSearchLog = `-- name: CreateSearchLog :exec
+INSERT INTO search_logs (query, result_count, searched_at)
+VALUES ($1, $2, $3)
+`
+
+type CreateSearchLogParams struct {
+	Query       string    `json:"query"`
+	ResultCount int64     `json:"result_count"`
+	SearchedAt  time.Time `json:"searched_at"`
+}
+
+func (q *Queries) CreateSearchLog(ctx context.Context, arg CreateSearchLogParams) error {
+	_, err := q.db.ExecContext(ctx, createSearchLog, arg.Query, arg.ResultCount, arg.SearchedAt)
+	return err
+}
+
+const listSearchTrends = `-- name: ListSearchTrends :many
+SELECT (searched_at AT TIME ZONE 'Asia/Tokyo')::date AS date,
+  count(*) AS count,
+  count(*) FILTER (
+    WHERE result_count = 0
+  ) AS zero_result_count
+FROM search_logs
+WHERE searched_at >= $1
+  AND searched_at < $2
+  AND (
+    $3::text = ''
+    OR query = $3::text
+  )
+GROUP BY 1
+ORDER BY 1
+`
+
+type ListSearchTrendsParams struct {
+	FromTime time.Time `json:"from_time"`
+	ToTime   time.Time `json:"to_time"`
+	Query    string    `json:"query"`
+}
+
+type ListSearchTrendsRow struct {
+	Date            time.Time `json:"date"`
+	Count           int64     `json:"count"`
+	ZeroResultCount int64     `json:"zero_result_count"`
+}
+
+func (q *Queries) ListSearchTrends(ctx context.Context, arg ListSearchTrendsParams) ([]ListSearchTrendsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listSearchTrends, arg.FromTime, arg.ToTime, arg.Query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSearchTrendsRow{}
+	for rows.Next() {
+		var i ListSearchTrendsRow
+		if err := rows.Scan(&i.Date, &i.Count, &i.ZeroResultCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTopSearchQueries = `-- name: ListTopSearchQueries :many
+SELECT query,
+  count(*) AS count,
+  max(searched_at)::timestamptz AS last_searched_at
+FROM search_logs
+WHERE searched_at >= $1
+  AND searched_at < $2
+GROUP BY query
+ORDER BY count DESC,
+  query
+LIMIT $3
+`
+
+type ListTopSearchQueriesParams struct {
+	FromTime   time.Time `json:"from_time"`
+	ToTime     time.Time `json:"to_time"`
+	MaxResults int32     `json:"max_results"`
+}
+
+type ListTopSearchQueriesRow struct {
+	Query          string    `json:"query"`
+	Count          int64     `json:"count"`
+	LastSearchedAt time.Time `json:"last_searched_at"`
+}
+
+func (q *Queries) ListTopSearchQueries(ctx context.Context, arg ListTopSearchQueriesParams) ([]ListTopSearchQueriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTopSearchQueries, arg.FromTime, arg.ToTime, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTopSearchQueriesRow{}
+	for rows.Next() {
+		var i ListTopSearchQueriesRow
+		if err := rows.Scan(&i.Query, &i.Count, &i.LastSearchedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listZeroResultSearchQueries = `-- name: ListZeroResultSearchQueries :many
+SELECT query,
+  count(*) AS count,
+  max(searched_at)::timestamptz AS last_searched_at
+FROM search_logs
+WHERE searched_at >= $1
+  AND searched_at < $2
+  AND result_count = 0
+GROUP BY query
+ORDER BY count DESC,
+  query
+LIMIT $3
+`
+
+type ListZeroResultSearchQueriesParams struct {
+	FromTime   time.Time `json:"from_time"`
+	ToTime     time.Time `json:"to_time"`
+	MaxResults int32     `json:"max_results"`
+}
+
+type ListZeroResultSearchQueriesRow struct {
+	Query          string    `json:"query"`
+	Count          int64     `json:"count"`
+	LastSearchedAt time.Time `json:"last_searched_at"`
+}
+
+func (q *Queries) ListZeroResultSearchQueries(ctx context.Context, arg ListZeroResultSearchQueriesParams) ([]ListZeroResultSearchQueriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listZeroResultSearchQueries, arg.FromTime, arg.ToTime, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListZeroResultSearchQueriesRow{}
+	for rows.Next() {
+		var i ListZeroResultSearchQueriesRow
+		if err := rows.Scan(&i.Query, &i.Count, &i.LastSearchedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
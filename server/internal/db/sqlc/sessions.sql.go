@@ -13,9 +13,33 @@ import (
 	"github.com/google/uuid"
 )
 
+const blockOperatorSessions = `-- name: BlockOperatorSessions :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE name = $1
+`
+
+func (q *Queries) BlockOperatorSessions(ctx context.Context, name string) error {
+	_, err := q.db.ExecContext(ctx, blockOperatorSessions, name)
+	return err
+}
+
+const blockSessionFamily = `-- name: BlockSessionFamily :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE family_id = $1
+`
+
+func (q *Queries) BlockSessionFamily(ctx context.Context, familyID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, blockSessionFamily, familyID)
+	return err
+}
+
 const createSession = `-- name: CreateSession :one
 INSERT INTO sessions (
     id,
+    family_id,
+    access_token_id,
     name,
     email,
     refresh_token,
@@ -23,23 +47,27 @@ INSERT INTO sessions (
     user_agent,
     client_ip
   )
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, name, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, email
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, name, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, email, family_id, rotated_at, access_token_id
 `
 
 type CreateSessionParams struct {
-	ID           uuid.UUID      `json:"id"`
-	Name         string         `json:"name"`
-	Email        sql.NullString `json:"email"`
-	RefreshToken string         `json:"refresh_token"`
-	ExpiresAt    time.Time      `json:"expires_at"`
-	UserAgent    string         `json:"user_agent"`
-	ClientIp     string         `json:"client_ip"`
+	ID            uuid.UUID      `json:"id"`
+	FamilyID      uuid.UUID      `json:"family_id"`
+	AccessTokenID uuid.NullUUID  `json:"access_token_id"`
+	Name          string         `json:"name"`
+	Email         sql.NullString `json:"email"`
+	RefreshToken  string         `json:"refresh_token"`
+	ExpiresAt     time.Time      `json:"expires_at"`
+	UserAgent     string         `json:"user_agent"`
+	ClientIp      string         `json:"client_ip"`
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
 	row := q.db.QueryRowContext(ctx, createSession,
 		arg.ID,
+		arg.FamilyID,
+		arg.AccessTokenID,
 		arg.Name,
 		arg.Email,
 		arg.RefreshToken,
@@ -58,12 +86,15 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.ExpiresAt,
 		&i.CreatedAt,
 		&i.Email,
+		&i.FamilyID,
+		&i.RotatedAt,
+		&i.AccessTokenID,
 	)
 	return i, err
 }
 
 const getSession = `-- name: GetSession :one
-SELECT id, name, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, email
+SELECT id, name, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, email, family_id, rotated_at, access_token_id
 FROM sessions
 WHERE id = $1
 LIMIT 1
@@ -82,6 +113,97 @@ func (q *Queries) GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 		&i.ExpiresAt,
 		&i.CreatedAt,
 		&i.Email,
+		&i.FamilyID,
+		&i.RotatedAt,
+		&i.AccessTokenID,
 	)
 	return i, err
 }
+
+const getSessionByAccessTokenID = `-- name: GetSessionByAccessTokenID :one
+SELECT id, name, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, email, family_id, rotated_at, access_token_id
+FROM sessions
+WHERE access_token_id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetSessionByAccessTokenID(ctx context.Context, accessTokenID uuid.NullUUID) (Session, error) {
+	row := q.db.QueryRowContext(ctx, getSessionByAccessTokenID, accessTokenID)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.RefreshToken,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.IsBlocked,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.Email,
+		&i.FamilyID,
+		&i.RotatedAt,
+		&i.AccessTokenID,
+	)
+	return i, err
+}
+
+const listActiveSessions = `-- name: ListActiveSessions :many
+SELECT id, name, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, email, family_id, rotated_at, access_token_id
+FROM sessions
+WHERE name = $1
+  AND is_blocked = false
+  AND rotated_at IS NULL
+  AND expires_at > now()
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListActiveSessions(ctx context.Context, name string) ([]Session, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveSessions, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.RefreshToken,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.IsBlocked,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.Email,
+			&i.FamilyID,
+			&i.RotatedAt,
+			&i.AccessTokenID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rotateSession = `-- name: RotateSession :execrows
+UPDATE sessions
+SET rotated_at = now()
+WHERE id = $1
+  AND rotated_at IS NULL
+`
+
+func (q *Queries) RotateSession(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rotateSession, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
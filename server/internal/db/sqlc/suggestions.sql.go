@@ -0,0 +1,92 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: suggestions.sql
+
+package db
+
+import (
+	"context"
+)
+
+const suggestSearchTerms = `-- name: SuggestSearchTerms :many
+SELECT s.term::text AS term,
+  s.kind::text AS kind,
+  s.score::bigint AS score
+FROM (
+    SELECT characters.name AS term,
+      'character' AS kind,
+      count(icr.id) AS score
+    FROM characters
+      LEFT JOIN image_characters_relations icr ON icr.character_id = characters.id
+    WHERE characters.deleted_at IS NULL
+      AND normalize_search_text(characters.name) LIKE $1::text ESCAPE '\'
+    GROUP BY characters.id
+    UNION ALL
+    SELECT parent_categories.name,
+      'parent_category',
+      count(ipcr.id)
+    FROM parent_categories
+      LEFT JOIN image_parent_categories_relations ipcr ON ipcr.parent_category_id = parent_categories.id
+    WHERE parent_categories.deleted_at IS NULL
+      AND normalize_search_text(parent_categories.name) LIKE $1::text ESCAPE '\'
+    GROUP BY parent_categories.id
+    UNION ALL
+    SELECT child_categories.name,
+      'child_category',
+      count(iccr.id)
+    FROM child_categories
+      LEFT JOIN image_child_categories_relations iccr ON iccr.child_category_id = child_categories.id
+    WHERE child_categories.deleted_at IS NULL
+      AND normalize_search_text(child_categories.name) LIKE $1::text ESCAPE '\'
+    GROUP BY child_categories.id
+    UNION ALL
+    SELECT images.title,
+      'title',
+      count(*)
+    FROM images
+    WHERE images.deleted_at IS NULL
+      AND normalize_search_text(images.title) LIKE $1::text ESCAPE '\'
+    GROUP BY images.title
+  ) s
+ORDER BY normalize_search_text(s.term) = $2::text DESC,
+  s.score DESC,
+  length(s.term),
+  s.term
+LIMIT $3
+`
+
+type SuggestSearchTermsParams struct {
+	Pattern    string `json:"pattern"`
+	Normalized string `json:"normalized"`
+	MaxResults int32  `json:"max_results"`
+}
+
+type SuggestSearchTermsRow struct {
+	Term  string `json:"term"`
+	Kind  string `json:"kind"`
+	Score int64  `json:"score"`
+}
+
+func (q *Queries) SuggestSearchTerms(ctx context.Context, arg SuggestSearchTermsParams) ([]SuggestSearchTermsRow, error) {
+	rows, err := q.db.QueryContext(ctx, suggestSearchTerms, arg.Pattern, arg.Normalized, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SuggestSearchTermsRow{}
+	for rows.Next() {
+		var i SuggestSearchTermsRow
+		if err := rows.Scan(&i.Term, &i.Kind, &i.Score); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
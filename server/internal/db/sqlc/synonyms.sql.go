@@ -0,0 +1,135 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: synonyms.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createSynonym = `-- name: CreateSynonym :one
+INSERT INTO synonyms (word, synonym)
+VALUES ($1, $2)
+RETURNING id, word, synonym, updated_at, created_at
+`
+
+type CreateSynonymParams struct {
+	Word    string `json:"word"`
+	Synonym string `json:"synonym"`
+}
+
+func (q *Queries) CreateSynonym(ctx context.Context, arg CreateSynonymParams) (Synonym, error) {
+	row := q.db.QueryRowContext(ctx, createSynonym, arg.Word, arg.Synonym)
+	var i Synonym
+	err := row.Scan(
+		&i.ID,
+		&i.Word,
+		&i.Synonym,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteSynonym = `-- name: DeleteSynonym :exec
+DELETE FROM synonyms
+WHERE id = $1
+`
+
+func (q *Queries) DeleteSynonym(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteSynonym, id)
+	return err
+}
+
+const getSynonym = `-- name: GetSynonym :one
+SELECT id, word, synonym, updated_at, created_at
+FROM synonyms
+WHERE id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetSynonym(ctx context.Context, id int64) (Synonym, error) {
+	row := q.db.QueryRowContext(ctx, getSynonym, id)
+	var i Synonym
+	err := row.Scan(
+		&i.ID,
+		&i.Word,
+		&i.Synonym,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAllSynonyms = `-- name: ListAllSynonyms :many
+SELECT id, word, synonym, updated_at, created_at
+FROM synonyms
+ORDER BY word,
+  id
+`
+
+func (q *Queries) ListAllSynonyms(ctx context.Context) ([]Synonym, error) {
+	rows, err := q.db.QueryContext(ctx, listAllSynonyms)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Synonym{}
+	for rows.Next() {
+		var i Synonym
+		if err := rows.Scan(
+			&i.ID,
+			&i.Word,
+			&i.Synonym,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateSynonym = `-- name: UpdateSynonym :one
+UPDATE synonyms
+SET word = $2,
+  synonym = $3,
+  updated_at = $4
+WHERE id = $1
+RETURNING id, word, synonym, updated_at, created_at
+`
+
+type UpdateSynonymParams struct {
+	ID        int64     `json:"id"`
+	Word      string    `json:"word"`
+	Synonym   string    `json:"synonym"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (q *Queries) UpdateSynonym(ctx context.Context, arg UpdateSynonymParams) (Synonym, error) {
+	row := q.db.QueryRowContext(ctx, updateSynonym,
+		arg.ID,
+		arg.Word,
+		arg.Synonym,
+		arg.UpdatedAt,
+	)
+	var i Synonym
+	err := row.Scan(
+		&i.ID,
+		&i.Word,
+		&i.Synonym,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
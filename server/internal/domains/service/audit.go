@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+)
+
+const (
+	AUDIT_ACTION_CREATE   = "create"
+	AUDIT_ACTION_UPDATE   = "update"
+	AUDIT_ACTION_DELETE   = "delete"
+	AUDIT_ACTION_RESTORE  = "restore"
+	AUDIT_ACTION_REORDER  = "reorder"
+	AUDIT_ACTION_ROLLBACK = "rollback"
+	AUDIT_ACTION_DISABLE  = "disable"
+	AUDIT_ACTION_ENABLE   = "enable"
+
+	AUDIT_ENTITY_ILLUSTRATION       = "illustration"
+	AUDIT_ENTITY_DAILY_ILLUSTRATION = "daily_illustration"
+	AUDIT_ENTITY_CHARACTER          = "character"
+	AUDIT_ENTITY_PARENT_CATEGORY    = "parent_category"
+	AUDIT_ENTITY_CHILD_CATEGORY     = "child_category"
+	AUDIT_ENTITY_SYNONYM            = "synonym"
+	AUDIT_ENTITY_OPERATOR           = "operator"
+)
+
+// AuditActorKey は操作者の情報をコンテキストに保存するキー
+// gin.Contextでは、Setで保存した値を文字列のキーでValueから取得できる
+const AuditActorKey = "audit_actor"
+
+// AuditActor は監査ログに記録する操作者の情報
+type AuditActor struct {
+	OperatorName string
+	ClientIP     string
+	UserAgent    string
+}
+
+// auditChange は項目ごとの変更前後の値
+type auditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// illustrationAuditState は監査ログに記録するイラストの状態
+// 画像ファイルそのものではなく、ファイル名・パスと紐づくキャラクター・カテゴリのIDを記録する
+type illustrationAuditState struct {
+	Title             string  `json:"title"`
+	OriginalFilename  string  `json:"original_filename"`
+	OriginalSrc       string  `json:"original_src"`
+	SimpleSrc         string  `json:"simple_src"`
+	CharacterIDs      []int64 `json:"character_ids"`
+	ParentCategoryIDs []int64 `json:"parent_category_ids"`
+	ChildCategoryIDs  []int64 `json:"child_category_ids"`
+}
+
+type ListAuditLogsParams struct {
+	// 空文字の場合は絞り込まない
+	OperatorName string
+	Action       string
+	EntityType   string
+	EntityID     string
+	From         time.Time
+	To           time.Time
+	Limit        int32
+	Offset       int32
+}
+
+// AuditLogService は監査ログの参照に関するユースケースをまとめたサービス
+// 監査ログの記録は、各サービスの更新処理と同じトランザクションで行う
+type AuditLogService struct {
+	store *db.Store
+}
+
+func NewAuditLogService(store *db.Store) *AuditLogService {
+	return &AuditLogService{
+		store: store,
+	}
+}
+
+// List は条件に一致する監査ログを新しい順に取得する
+func (s *AuditLogService) List(ctx context.Context, arg ListAuditLogsParams) ([]db.AuditLog, int64, error) {
+	logs, err := s.store.ListAuditLogs(ctx, db.ListAuditLogsParams{
+		OperatorName: arg.OperatorName,
+		Action:       arg.Action,
+		EntityType:   arg.EntityType,
+		EntityID:     arg.EntityID,
+		FromTime:     arg.From,
+		ToTime:       arg.To,
+		Limit:        arg.Limit,
+		Offset:       arg.Offset,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to ListAuditLogs : %w", err)
+	}
+
+	total, err := s.store.CountAuditLogs(ctx, db.CountAuditLogsParams{
+		OperatorName: arg.OperatorName,
+		Action:       arg.Action,
+		EntityType:   arg.EntityType,
+		EntityID:     arg.EntityID,
+		FromTime:     arg.From,
+		ToTime:       arg.To,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to CountAuditLogs : %w", err)
+	}
+
+	return logs, total, nil
+}
+
+// recordAuditLog は操作内容を監査ログに記録する
+// beforeとafterはJSONのオブジェクトとして比較し、値が変わった項目のみを記録する
+// 作成時はbefore、削除時はafterにnilを指定する
+func recordAuditLog(ctx context.Context, q db.Querier, action, entityType string, entityID any, before, after any) error {
+	diff, err := auditDiff(before, after)
+	if err != nil {
+		return fmt.Errorf("failed to auditDiff : %w", err)
+	}
+
+	actor := auditActorFrom(ctx)
+	err = q.CreateAuditLog(ctx, db.CreateAuditLogParams{
+		OperatorName: actor.OperatorName,
+		Action:       action,
+		EntityType:   entityType,
+		EntityID:     fmt.Sprint(entityID),
+		Diff:         diff,
+		ClientIp:     actor.ClientIP,
+		UserAgent:    actor.UserAgent,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to CreateAuditLog : %w", err)
+	}
+
+	return nil
+}
+
+// auditActorFrom はコンテキストから操作者の情報を取得する
+// 認証を経由しない呼び出しの場合は空の値を返す
+func auditActorFrom(ctx context.Context) AuditActor {
+	actor, _ := ctx.Value(AuditActorKey).(AuditActor)
+	return actor
+}
+
+// auditDiff は変更前後の値を比較し、変更された項目ごとの変更前後の値をJSONにする
+func auditDiff(before, after any) (json.RawMessage, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]auditChange{}
+	for key, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[key]) {
+			changes[key] = auditChange{Before: value, After: afterFields[key]}
+		}
+	}
+	for key, value := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			changes[key] = auditChange{Before: nil, After: value}
+		}
+	}
+
+	return json.Marshal(changes)
+}
+
+// auditFields は値をJSONのオブジェクトとして項目ごとに分解する
+func auditFields(v any) (map[string]any, error) {
+	fields := map[string]any{}
+	if v == nil {
+		return fields, nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit value : %w", err)
+	}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal audit value : %w", err)
+	}
+	return fields, nil
+}
+
+// illustrationAuditStateOf は編集履歴と同じ形式のスナップショットを監査ログ用の状態に変換する
+func illustrationAuditStateOf(snapshot db.ImageRevision) illustrationAuditState {
+	return illustrationAuditState{
+		Title:             snapshot.Title,
+		OriginalFilename:  snapshot.OriginalFilename,
+		OriginalSrc:       snapshot.OriginalSrc,
+		SimpleSrc:         snapshot.SimpleSrc.String,
+		CharacterIDs:      snapshot.CharacterIds,
+		ParentCategoryIDs: snapshot.ParentCategoryIds,
+		ChildCategoryIDs:  snapshot.ChildCategoryIds,
+	}
+}
+
+// recordIllustrationAuditLog はイラストの操作後の状態を取得し、監査ログに記録する
+// 削除の場合はafterにnilを指定する
+func recordIllustrationAuditLog(ctx context.Context, q db.Querier, action string, imageID int64, before *illustrationAuditState, after *db.Image) error {
+	var beforeState, afterState any
+	if before != nil {
+		beforeState = *before
+	}
+	if after != nil {
+		snapshot, err := snapshotImage(ctx, q, *after)
+		if err != nil {
+			return fmt.Errorf("failed to snapshotImage : %w", err)
+		}
+		afterState = illustrationAuditStateOf(snapshot)
+	}
+
+	return recordAuditLog(ctx, q, action, AUDIT_ENTITY_ILLUSTRATION, imageID, beforeState, afterState)
+}
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/pkg/token"
+	"shin-monta-no-mori/pkg/util"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidRefreshToken はリフレッシュトークンの署名が不正な場合や、対応するセッションが存在しない場合のエラー
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid")
+	// ErrSessionBlocked はセッションが無効化されている場合のエラー
+	ErrSessionBlocked = errors.New("session is blocked")
+	// ErrSessionExpired はセッションの有効期限が切れている場合のエラー
+	ErrSessionExpired = errors.New("session has expired")
+	// ErrRefreshTokenReused は更新済みのリフレッシュトークンが再度使用された場合のエラー
+	ErrRefreshTokenReused = errors.New("refresh token has already been used")
+	// ErrOperatorDisabled はオペレーターが無効化されている場合のエラー
+	ErrOperatorDisabled = errors.New("operator is disabled")
+)
+
+// AuthService はオペレーターのログインセッションに関するユースケースをまとめたサービス
+type AuthService struct {
+	store                *db.Store
+	tokenMaker           token.Maker
+	accessTokenDuration  time.Duration
+	refreshTokenDuration time.Duration
+}
+
+func NewAuthService(store *db.Store, tokenMaker token.Maker, config util.Config) *AuthService {
+	return &AuthService{
+		store:                store,
+		tokenMaker:           tokenMaker,
+		accessTokenDuration:  config.AccessTokenDuration,
+		refreshTokenDuration: config.RefreshTokenDuration,
+	}
+}
+
+// AuthTokens は発行したアクセストークンとリフレッシュトークン
+type AuthTokens struct {
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
+
+type StartSessionParams struct {
+	OperatorName string
+	Role         token.Role
+	Email        string
+	ClientIP     string
+	UserAgent    string
+}
+
+// StartSession はログインしたオペレーターのトークンを発行し、新しいセッションを作成する
+func (s *AuthService) StartSession(ctx context.Context, arg StartSessionParams) (AuthTokens, error) {
+	tokens, err := s.issue(ctx, s.store.Queries, arg, uuid.Nil)
+	if err != nil {
+		return AuthTokens{}, err
+	}
+
+	return tokens, nil
+}
+
+type RefreshParams struct {
+	RefreshToken string
+	ClientIP     string
+	UserAgent    string
+}
+
+// Refresh はリフレッシュトークンを検証し、新しいアクセストークンとリフレッシュトークンを発行する
+// 使用されたリフレッシュトークンは更新済みとし、再度使用された場合は漏洩とみなして同じログインのセッションをすべて無効化する
+func (s *AuthService) Refresh(ctx context.Context, arg RefreshParams) (AuthTokens, error) {
+	payload, err := s.tokenMaker.VerifyToken(arg.RefreshToken)
+	if err != nil {
+		return AuthTokens{}, fmt.Errorf("%w : %v", ErrInvalidRefreshToken, err)
+	}
+	if err := payload.Expect(token.TokenTypeRefresh); err != nil {
+		return AuthTokens{}, fmt.Errorf("%w : %v", ErrInvalidRefreshToken, err)
+	}
+
+	session, err := s.store.GetSession(ctx, payload.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return AuthTokens{}, fmt.Errorf("%w : %v", ErrInvalidRefreshToken, err)
+		}
+		return AuthTokens{}, fmt.Errorf("failed to GetSession : %w", err)
+	}
+
+	if session.IsBlocked {
+		return AuthTokens{}, ErrSessionBlocked
+	}
+	if session.RefreshToken != arg.RefreshToken || session.Name != payload.Username {
+		return AuthTokens{}, ErrInvalidRefreshToken
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return AuthTokens{}, ErrSessionExpired
+	}
+	if session.RotatedAt.Valid {
+		return AuthTokens{}, s.blockFamily(ctx, session.FamilyID)
+	}
+
+	// 権限の変更や無効化を反映するため、オペレーターの現在の状態を取得する
+	operator, err := s.store.GetOperatorByName(ctx, session.Name)
+	if err != nil {
+		return AuthTokens{}, fmt.Errorf("failed to GetOperatorByName : %w", err)
+	}
+	if operator.DisabledAt.Valid {
+		return AuthTokens{}, ErrOperatorDisabled
+	}
+
+	var tokens AuthTokens
+	reused := false
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		// 同時に更新された場合も再使用とみなすため、更新済みでない場合のみ更新する
+		rows, err := q.RotateSession(ctx, session.ID)
+		if err != nil {
+			return fmt.Errorf("failed to RotateSession : %w", err)
+		}
+		if rows == 0 {
+			reused = true
+			return nil
+		}
+
+		tokens, err = s.issue(ctx, q, StartSessionParams{
+			OperatorName: session.Name,
+			Role:         token.Role(operator.Role),
+			Email:        session.Email.String,
+			ClientIP:     arg.ClientIP,
+			UserAgent:    arg.UserAgent,
+		}, session.FamilyID)
+		return err
+	})
+	if txErr != nil {
+		return AuthTokens{}, txErr
+	}
+	if reused {
+		return AuthTokens{}, s.blockFamily(ctx, session.FamilyID)
+	}
+
+	return tokens, nil
+}
+
+// ListActiveSessions はオペレーターのログイン中のセッションを新しい順に取得する
+// 更新済みのセッションは含めず、ログインごとに最新のセッションのみを返す
+func (s *AuthService) ListActiveSessions(ctx context.Context, operatorName string) ([]db.Session, error) {
+	sessions, err := s.store.ListActiveSessions(ctx, operatorName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListActiveSessions : %w", err)
+	}
+
+	return sessions, nil
+}
+
+// GetSessionByAccessToken はアクセストークンと同時に作成したセッションを取得する
+func (s *AuthService) GetSessionByAccessToken(ctx context.Context, accessTokenID uuid.UUID) (db.Session, error) {
+	session, err := s.store.GetSessionByAccessTokenID(ctx, uuid.NullUUID{UUID: accessTokenID, Valid: true})
+	if err != nil {
+		return db.Session{}, fmt.Errorf("failed to GetSessionByAccessTokenID : %w", err)
+	}
+
+	return session, nil
+}
+
+// IsAccessTokenBlocked はアクセストークンのセッションが無効化されているかを返す
+// セッションと紐づかないアクセストークンは無効化されていないものとする
+func (s *AuthService) IsAccessTokenBlocked(ctx context.Context, accessTokenID uuid.UUID) (bool, error) {
+	session, err := s.GetSessionByAccessToken(ctx, accessTokenID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return session.IsBlocked, nil
+}
+
+// Logout はアクセストークンのセッションと同じログインのセッションをすべて無効化する
+func (s *AuthService) Logout(ctx context.Context, accessTokenID uuid.UUID) error {
+	session, err := s.GetSessionByAccessToken(ctx, accessTokenID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	if err := s.store.BlockSessionFamily(ctx, session.FamilyID); err != nil {
+		return fmt.Errorf("failed to BlockSessionFamily : %w", err)
+	}
+
+	return nil
+}
+
+// RevokeSession はオペレーターのセッションと同じログインのセッションをすべて無効化する
+// 他のオペレーターのセッションは存在しないものとして扱う
+func (s *AuthService) RevokeSession(ctx context.Context, operatorName string, id uuid.UUID) error {
+	session, err := s.store.GetSession(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to GetSession : %w", err)
+	}
+	if session.Name != operatorName {
+		return fmt.Errorf("failed to GetSession : %w", sql.ErrNoRows)
+	}
+
+	if err := s.store.BlockSessionFamily(ctx, session.FamilyID); err != nil {
+		return fmt.Errorf("failed to BlockSessionFamily : %w", err)
+	}
+
+	return nil
+}
+
+// ログインに失敗した理由
+const (
+	LoginFailureUnknownEmail  = "unknown_email"
+	LoginFailureWrongPassword = "wrong_password"
+	LoginFailureDisabled      = "disabled"
+)
+
+type LoginAttemptParams struct {
+	Email string
+	// 登録されていないメールアドレスの場合は空文字
+	OperatorName string
+	Succeeded    bool
+	// 成功した場合は空文字
+	FailureReason string
+	ClientIP      string
+	UserAgent     string
+}
+
+// RecordLoginAttempt はログインの成否をログイン履歴に記録する
+func (s *AuthService) RecordLoginAttempt(ctx context.Context, arg LoginAttemptParams) error {
+	err := s.store.CreateLoginAttempt(ctx, db.CreateLoginAttemptParams{
+		Email:         arg.Email,
+		OperatorName:  arg.OperatorName,
+		Succeeded:     arg.Succeeded,
+		FailureReason: arg.FailureReason,
+		ClientIp:      arg.ClientIP,
+		UserAgent:     arg.UserAgent,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to CreateLoginAttempt : %w", err)
+	}
+
+	return nil
+}
+
+type ListLoginAttemptsParams struct {
+	// 空文字の場合は絞り込まない
+	OperatorName string
+	Email        string
+	// nilの場合は絞り込まない
+	Succeeded *bool
+	From      time.Time
+	To        time.Time
+	Limit     int32
+	Offset    int32
+}
+
+// ListLoginAttempts は条件に一致するログイン履歴を新しい順に取得する
+func (s *AuthService) ListLoginAttempts(ctx context.Context, arg ListLoginAttemptsParams) ([]db.LoginAttempt, int64, error) {
+	var succeeded sql.NullBool
+	if arg.Succeeded != nil {
+		succeeded = sql.NullBool{Bool: *arg.Succeeded, Valid: true}
+	}
+
+	attempts, err := s.store.ListLoginAttempts(ctx, db.ListLoginAttemptsParams{
+		OperatorName: arg.OperatorName,
+		Email:        arg.Email,
+		Succeeded:    succeeded,
+		FromTime:     arg.From,
+		ToTime:       arg.To,
+		Limit:        arg.Limit,
+		Offset:       arg.Offset,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to ListLoginAttempts : %w", err)
+	}
+
+	total, err := s.store.CountLoginAttempts(ctx, db.CountLoginAttemptsParams{
+		OperatorName: arg.OperatorName,
+		Email:        arg.Email,
+		Succeeded:    succeeded,
+		FromTime:     arg.From,
+		ToTime:       arg.To,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to CountLoginAttempts : %w", err)
+	}
+
+	return attempts, total, nil
+}
+
+// issue はアクセストークンとリフレッシュトークンを発行し、リフレッシュトークンのセッションを作成する
+// familyIDにuuid.Nilを指定した場合は、新しいログインとして作成したセッションのIDを使用する
+func (s *AuthService) issue(ctx context.Context, q *db.Queries, arg StartSessionParams, familyID uuid.UUID) (AuthTokens, error) {
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(arg.OperatorName, arg.Role, token.TokenTypeAccess, s.accessTokenDuration)
+	if err != nil {
+		return AuthTokens{}, fmt.Errorf("failed to CreateToken : %w", err)
+	}
+
+	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(arg.OperatorName, arg.Role, token.TokenTypeRefresh, s.refreshTokenDuration)
+	if err != nil {
+		return AuthTokens{}, fmt.Errorf("failed to CreateToken : %w", err)
+	}
+
+	if familyID == uuid.Nil {
+		familyID = refreshPayload.ID
+	}
+	_, err = q.CreateSession(ctx, db.CreateSessionParams{
+		ID:            refreshPayload.ID,
+		FamilyID:      familyID,
+		AccessTokenID: uuid.NullUUID{UUID: accessPayload.ID, Valid: true},
+		Name:          arg.OperatorName,
+		Email:         sql.NullString{String: arg.Email, Valid: arg.Email != ""},
+		RefreshToken:  refreshToken,
+		ExpiresAt:     refreshPayload.ExpiredAt,
+		UserAgent:     arg.UserAgent,
+		ClientIp:      arg.ClientIP,
+	})
+	if err != nil {
+		return AuthTokens{}, fmt.Errorf("failed to CreateSession : %w", err)
+	}
+
+	return AuthTokens{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
+	}, nil
+}
+
+// blockFamily は再使用されたリフレッシュトークンと同じログインのセッションをすべて無効化する
+func (s *AuthService) blockFamily(ctx context.Context, familyID uuid.UUID) error {
+	if err := s.store.BlockSessionFamily(ctx, familyID); err != nil {
+		return fmt.Errorf("failed to BlockSessionFamily : %w", err)
+	}
+
+	return ErrRefreshTokenReused
+}
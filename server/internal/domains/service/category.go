@@ -1 +1,552 @@
 package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+	model "shin-monta-no-mori/internal/domains/models"
+	"shin-monta-no-mori/pkg/lib/cursor"
+)
+
+// CategoryService は親カテゴリ・子カテゴリに関するユースケースをまとめたサービス
+type CategoryService struct {
+	store    *db.Store
+	storage  StorageService
+	synonyms *SynonymDictionary
+}
+
+func NewCategoryService(store *db.Store, storage StorageService, synonyms *SynonymDictionary) *CategoryService {
+	return &CategoryService{
+		store:    store,
+		storage:  storage,
+		synonyms: synonyms,
+	}
+}
+
+// Get は親カテゴリを子カテゴリと合わせて取得する
+func (s *CategoryService) Get(ctx context.Context, parentCategoryID int64) (*model.Category, error) {
+	pcate, err := s.store.GetParentCategory(ctx, parentCategoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetParentCategory : %w", err)
+	}
+
+	ccates, err := s.store.GetChildCategoriesByParentID(ctx, pcate.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetChildCategoriesByParentID : %w", err)
+	}
+
+	category := model.NewCategory()
+	category.ParentCategory = pcate
+	category.ChildCategory = ccates
+
+	return category, nil
+}
+
+// ListAll は全ての親カテゴリを子カテゴリと合わせて取得する
+func (s *CategoryService) ListAll(ctx context.Context) ([]model.Category, error) {
+	pcates, err := s.store.ListAllParentCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListAllParentCategories : %w", err)
+	}
+
+	return s.withChildCategories(ctx, pcates)
+}
+
+// List は親カテゴリを子カテゴリと合わせて取得する
+func (s *CategoryService) List(ctx context.Context, limit, offset int32) ([]model.Category, error) {
+	pcates, err := s.store.ListParentCategories(ctx, db.ListParentCategoriesParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListParentCategories : %w", err)
+	}
+
+	return s.withChildCategories(ctx, pcates)
+}
+
+// ListByCursor はカーソルより後ろの親カテゴリを子カテゴリと合わせて取得する
+func (s *CategoryService) ListByCursor(ctx context.Context, c cursor.Cursor, limit int32) ([]model.Category, error) {
+	pcates, err := s.store.ListParentCategoriesByCursor(ctx, db.ListParentCategoriesByCursorParams{
+		Limit:               limit,
+		CursorPriorityLevel: c.PriorityLevel,
+		CursorID:            c.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListParentCategoriesByCursor : %w", err)
+	}
+
+	return s.withChildCategories(ctx, pcates)
+}
+
+// Search は名前やファイル名に一致する親カテゴリを子カテゴリと合わせて取得する
+// 検索語は正規化し、同義語が登録されている場合は展開する
+func (s *CategoryService) Search(ctx context.Context, query string) ([]model.Category, error) {
+	patterns, err := likePatterns(ctx, s.synonyms, query)
+	if err != nil {
+		return nil, err
+	}
+
+	pcates, err := s.store.SearchParentCategories(ctx, patterns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to SearchParentCategories : %w", err)
+	}
+
+	return s.withChildCategories(ctx, pcates)
+}
+
+// CountSearch は名前やファイル名に一致する親カテゴリの件数を取得する
+func (s *CategoryService) CountSearch(ctx context.Context, query string) (int64, error) {
+	patterns, err := likePatterns(ctx, s.synonyms, query)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := s.store.CountSearchParentCategories(ctx, patterns)
+	if err != nil {
+		return 0, fmt.Errorf("failed to CountSearchParentCategories : %w", err)
+	}
+
+	return count, nil
+}
+
+func (s *CategoryService) withChildCategories(ctx context.Context, pcates []db.ParentCategory) ([]model.Category, error) {
+	categories := make([]model.Category, len(pcates))
+	for i, pcate := range pcates {
+		ccates, err := s.store.GetChildCategoriesByParentID(ctx, pcate.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to GetChildCategoriesByParentID (parent_category_id: %d) : %w", pcate.ID, err)
+		}
+
+		categories[i] = model.Category{
+			ParentCategory: pcate,
+			ChildCategory:  ccates,
+		}
+	}
+
+	return categories, nil
+}
+
+type CreateParentCategoryParams struct {
+	Name          string
+	Filename      string
+	PriorityLevel int16
+	Image         *ImageFile
+}
+
+// CreateParentCategory は画像をアップロードし、親カテゴリを作成する
+func (s *CategoryService) CreateParentCategory(ctx context.Context, arg CreateParentCategoryParams) (db.ParentCategory, error) {
+	arg.Filename = normalizeFilename(arg.Filename)
+
+	var pcate db.ParentCategory
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		src, err := uploadImage(ctx, s.storage, arg.Image, arg.Filename, IMAGE_TYPE_CATEGORY, false)
+		if err != nil {
+			return fmt.Errorf("failed to UploadImage : %w", err)
+		}
+
+		pcate, err = q.CreateParentCategory(ctx, db.CreateParentCategoryParams{
+			Name:          arg.Name,
+			Src:           src,
+			Filename:      sql.NullString{String: arg.Filename, Valid: true},
+			PriorityLevel: arg.PriorityLevel,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to CreateParentCategory : %w", err)
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_CREATE, AUDIT_ENTITY_PARENT_CATEGORY, pcate.ID, nil, pcate); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return db.ParentCategory{}, fmt.Errorf("CreateParentCategory transaction was failed : %w", txErr)
+	}
+
+	return pcate, nil
+}
+
+type EditParentCategoryParams struct {
+	Name          string
+	Filename      string
+	PriorityLevel int16
+	Image         *ImageFile
+	// クライアントが取得した時点の親カテゴリの更新日時
+	Version time.Time
+}
+
+// EditParentCategory は親カテゴリを更新する
+// ファイル名や画像が変更された場合は、画像をアップロードし直す
+// 取得した時点から他の操作で更新されている場合はErrVersionMismatchを返す
+func (s *CategoryService) EditParentCategory(ctx context.Context, id int64, arg EditParentCategoryParams) (db.ParentCategory, error) {
+	arg.Filename = normalizeFilename(arg.Filename)
+
+	pcate, err := s.store.GetParentCategory(ctx, id)
+	if err != nil {
+		return db.ParentCategory{}, fmt.Errorf("failed to GetParentCategory : %w", err)
+	}
+	if err := checkVersion(pcate.UpdatedAt, arg.Version); err != nil {
+		return db.ParentCategory{}, err
+	}
+
+	before := pcate
+	var files fileChanges
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		// 画像を差し替える前に行をロックし、取得した時点から更新されていないことを確認する
+		pcate, err = q.GetParentCategoryForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to GetParentCategoryForUpdate : %w", err)
+		}
+		if err := checkVersion(pcate.UpdatedAt, arg.Version); err != nil {
+			return err
+		}
+
+		src := pcate.Src
+		if pcate.Filename.String != arg.Filename || arg.Image != nil {
+			src, err = uploadImage(ctx, s.storage, arg.Image, arg.Filename, IMAGE_TYPE_CATEGORY, false)
+			if err != nil {
+				return fmt.Errorf("failed to UploadImage : %w", err)
+			}
+			files.replace(pcate.Src, src)
+		}
+
+		params := db.UpdateParentCategoryParams{
+			ID:                pcate.ID,
+			Name:              arg.Name,
+			Src:               src,
+			Filename:          sql.NullString{String: pcate.Filename.String, Valid: true},
+			PriorityLevel:     arg.PriorityLevel,
+			UpdatedAt:         time.Now(),
+			ExpectedUpdatedAt: before.UpdatedAt,
+		}
+		if pcate.Filename.String != arg.Filename {
+			params.Filename = sql.NullString{String: arg.Filename, Valid: true}
+		}
+
+		pcate, err = q.UpdateParentCategory(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to UpdateParentCategory : %w", versionError(err))
+		}
+
+		// カテゴリ名は関連するイラストの検索対象のため、検索用ドキュメントを作り直す
+		relations, err := q.ListImageParentCategoryRelationsByParentCategoryID(ctx, pcate.ID)
+		if err != nil {
+			return fmt.Errorf("failed to ListImageParentCategoryRelationsByParentCategoryID : %w", err)
+		}
+		imageIDs := make([]int64, len(relations))
+		for i, r := range relations {
+			imageIDs[i] = r.ImageID
+		}
+		if err := RefreshSearchDocuments(ctx, q, imageIDs); err != nil {
+			return fmt.Errorf("failed to RefreshSearchDocuments : %w", err)
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_UPDATE, AUDIT_ENTITY_PARENT_CATEGORY, pcate.ID, before, pcate); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		files.rollback(ctx, s.storage)
+		return db.ParentCategory{}, fmt.Errorf("EditParentCategory transaction was failed : %w", txErr)
+	}
+	files.commit(ctx, s.storage)
+
+	return pcate, nil
+}
+
+type PatchParentCategoryParams struct {
+	// nilの場合は変更しない
+	Name          *string
+	PriorityLevel *int16
+	// クライアントが取得した時点の親カテゴリの更新日時
+	Version time.Time
+}
+
+// PatchParentCategory は親カテゴリの指定された項目のみを更新する
+// 画像とファイル名は現在の値を引き継ぐ
+func (s *CategoryService) PatchParentCategory(ctx context.Context, id int64, arg PatchParentCategoryParams) (db.ParentCategory, error) {
+	pcate, err := s.store.GetParentCategory(ctx, id)
+	if err != nil {
+		return db.ParentCategory{}, fmt.Errorf("failed to GetParentCategory : %w", err)
+	}
+
+	params := EditParentCategoryParams{
+		Name:          pcate.Name,
+		Filename:      pcate.Filename.String,
+		PriorityLevel: pcate.PriorityLevel,
+		Version:       arg.Version,
+	}
+	if arg.Name != nil {
+		params.Name = *arg.Name
+	}
+	if arg.PriorityLevel != nil {
+		params.PriorityLevel = *arg.PriorityLevel
+	}
+
+	return s.EditParentCategory(ctx, id, params)
+}
+
+type ReplaceParentCategoryImageParams struct {
+	// 空文字の場合は現在のファイル名を引き継ぐ
+	Filename string
+	Image    *ImageFile
+	// クライアントが取得した時点の親カテゴリの更新日時
+	Version time.Time
+}
+
+// ReplaceParentCategoryImage は親カテゴリの画像とファイル名のみを更新する
+func (s *CategoryService) ReplaceParentCategoryImage(ctx context.Context, id int64, arg ReplaceParentCategoryImageParams) (db.ParentCategory, error) {
+	pcate, err := s.store.GetParentCategory(ctx, id)
+	if err != nil {
+		return db.ParentCategory{}, fmt.Errorf("failed to GetParentCategory : %w", err)
+	}
+
+	params := EditParentCategoryParams{
+		Name:          pcate.Name,
+		Filename:      pcate.Filename.String,
+		PriorityLevel: pcate.PriorityLevel,
+		Image:         arg.Image,
+		Version:       arg.Version,
+	}
+	if arg.Filename != "" {
+		params.Filename = arg.Filename
+	}
+
+	return s.EditParentCategory(ctx, id, params)
+}
+
+// DeleteParentCategory は親カテゴリと配下の子カテゴリをゴミ箱に移動する
+// イラストとの関連とアップロード済みの画像は復元できるよう残し、保持期間を過ぎた後にPurgeで削除する
+// 配下の子カテゴリには親カテゴリと同じ削除日時を設定し、復元時に合わせて戻す
+func (s *CategoryService) DeleteParentCategory(ctx context.Context, id int64) error {
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		pcate, err := q.SoftDeleteParentCategory(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to SoftDeleteParentCategory : %w", err)
+		}
+
+		// 検索用ドキュメントからカテゴリ名を除くため、関連するイラストを控えておく
+		imageIDs, err := parentCategoryImageIDs(ctx, q, pcate.ID)
+		if err != nil {
+			return err
+		}
+
+		err = q.SoftDeleteChildCategoriesByParentID(ctx, db.SoftDeleteChildCategoriesByParentIDParams{
+			ParentID:  pcate.ID,
+			DeletedAt: pcate.DeletedAt.Time,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to SoftDeleteChildCategoriesByParentID : %w", err)
+		}
+
+		if err := RefreshSearchDocuments(ctx, q, imageIDs); err != nil {
+			return fmt.Errorf("failed to RefreshSearchDocuments : %w", err)
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_DELETE, AUDIT_ENTITY_PARENT_CATEGORY, pcate.ID, pcate, nil); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return fmt.Errorf("DeleteParentCategory transaction was failed : %w", txErr)
+	}
+
+	return nil
+}
+
+// parentCategoryImageIDs は親カテゴリ、または配下の子カテゴリに関連するイラストのIDを取得する
+func parentCategoryImageIDs(ctx context.Context, q db.Querier, parentCategoryID int64) ([]int64, error) {
+	seen := map[int64]bool{}
+	imageIDs := []int64{}
+	add := func(id int64) {
+		if !seen[id] {
+			seen[id] = true
+			imageIDs = append(imageIDs, id)
+		}
+	}
+
+	relations, err := q.ListImageParentCategoryRelationsByParentCategoryID(ctx, parentCategoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListImageParentCategoryRelationsByParentCategoryID : %w", err)
+	}
+	for _, r := range relations {
+		add(r.ImageID)
+	}
+
+	ccates, err := q.GetChildCategoriesByParentID(ctx, parentCategoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetChildCategoriesByParentID : %w", err)
+	}
+	for _, ccate := range ccates {
+		crelations, err := q.ListImageChildCategoryRelationsByChildCategoryID(ctx, ccate.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to ListImageChildCategoryRelationsByChildCategoryID (child_category_id: %d) : %w", ccate.ID, err)
+		}
+		for _, r := range crelations {
+			add(r.ImageID)
+		}
+	}
+
+	return imageIDs, nil
+}
+
+type CreateChildCategoryParams struct {
+	Name          string
+	ParentID      int64
+	PriorityLevel int16
+}
+
+// CreateChildCategory は子カテゴリを作成する
+func (s *CategoryService) CreateChildCategory(ctx context.Context, arg CreateChildCategoryParams) (db.ChildCategory, error) {
+	var ccate db.ChildCategory
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		ccate, err = q.CreateChildCategory(ctx, db.CreateChildCategoryParams{
+			Name:          arg.Name,
+			ParentID:      arg.ParentID,
+			PriorityLevel: arg.PriorityLevel,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to CreateChildCategory : %w", err)
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_CREATE, AUDIT_ENTITY_CHILD_CATEGORY, ccate.ID, nil, ccate); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return db.ChildCategory{}, fmt.Errorf("CreateChildCategory transaction was failed : %w", txErr)
+	}
+
+	return ccate, nil
+}
+
+type EditChildCategoryParams struct {
+	Name          string
+	ParentID      int64
+	PriorityLevel int16
+	// クライアントが取得した時点の子カテゴリの更新日時
+	Version time.Time
+}
+
+// EditChildCategory は子カテゴリを更新する
+// 取得した時点から他の操作で更新されている場合はErrVersionMismatchを返す
+func (s *CategoryService) EditChildCategory(ctx context.Context, id int64, arg EditChildCategoryParams) (db.ChildCategory, error) {
+	ccate, err := s.store.GetChildCategory(ctx, id)
+	if err != nil {
+		return db.ChildCategory{}, fmt.Errorf("failed to GetChildCategory : %w", err)
+	}
+	if err := checkVersion(ccate.UpdatedAt, arg.Version); err != nil {
+		return db.ChildCategory{}, err
+	}
+
+	before := ccate
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		ccate, err = q.UpdateChildCategory(ctx, db.UpdateChildCategoryParams{
+			ID:                ccate.ID,
+			Name:              arg.Name,
+			ParentID:          arg.ParentID,
+			PriorityLevel:     arg.PriorityLevel,
+			UpdatedAt:         time.Now(),
+			ExpectedUpdatedAt: before.UpdatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to UpdateChildCategory : %w", versionError(err))
+		}
+
+		// カテゴリ名は関連するイラストの検索対象のため、検索用ドキュメントを作り直す
+		relations, err := q.ListImageChildCategoryRelationsByChildCategoryID(ctx, ccate.ID)
+		if err != nil {
+			return fmt.Errorf("failed to ListImageChildCategoryRelationsByChildCategoryID : %w", err)
+		}
+		imageIDs := make([]int64, len(relations))
+		for i, r := range relations {
+			imageIDs[i] = r.ImageID
+		}
+		if err := RefreshSearchDocuments(ctx, q, imageIDs); err != nil {
+			return fmt.Errorf("failed to RefreshSearchDocuments : %w", err)
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_UPDATE, AUDIT_ENTITY_CHILD_CATEGORY, ccate.ID, before, ccate); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return db.ChildCategory{}, fmt.Errorf("EditChildCategory transaction was failed : %w", txErr)
+	}
+
+	return ccate, nil
+}
+
+type PatchChildCategoryParams struct {
+	// nilの場合は変更しない
+	Name          *string
+	ParentID      *int64
+	PriorityLevel *int16
+	// クライアントが取得した時点の子カテゴリの更新日時
+	Version time.Time
+}
+
+// PatchChildCategory は子カテゴリの指定された項目のみを更新する
+func (s *CategoryService) PatchChildCategory(ctx context.Context, id int64, arg PatchChildCategoryParams) (db.ChildCategory, error) {
+	ccate, err := s.store.GetChildCategory(ctx, id)
+	if err != nil {
+		return db.ChildCategory{}, fmt.Errorf("failed to GetChildCategory : %w", err)
+	}
+
+	params := EditChildCategoryParams{
+		Name:          ccate.Name,
+		ParentID:      ccate.ParentID,
+		PriorityLevel: ccate.PriorityLevel,
+		Version:       arg.Version,
+	}
+	if arg.Name != nil {
+		params.Name = *arg.Name
+	}
+	if arg.ParentID != nil {
+		params.ParentID = *arg.ParentID
+	}
+	if arg.PriorityLevel != nil {
+		params.PriorityLevel = *arg.PriorityLevel
+	}
+
+	return s.EditChildCategory(ctx, id, params)
+}
+
+// DeleteChildCategory は子カテゴリを削除する
+func (s *CategoryService) DeleteChildCategory(ctx context.Context, id int64) error {
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		ccate, err := q.GetChildCategory(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to GetChildCategory : %w", err)
+		}
+
+		if err := q.DeleteChildCategory(ctx, ccate.ID); err != nil {
+			return fmt.Errorf("failed to DeleteChildCategory : %w", err)
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_DELETE, AUDIT_ENTITY_CHILD_CATEGORY, ccate.ID, ccate, nil); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return fmt.Errorf("DeleteChildCategory transaction was failed : %w", txErr)
+	}
+
+	return nil
+}
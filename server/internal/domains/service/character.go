@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/pkg/lib/cursor"
+)
+
+// CharacterService はキャラクターに関するユースケースをまとめたサービス
+type CharacterService struct {
+	store    *db.Store
+	storage  StorageService
+	synonyms *SynonymDictionary
+}
+
+func NewCharacterService(store *db.Store, storage StorageService, synonyms *SynonymDictionary) *CharacterService {
+	return &CharacterService{
+		store:    store,
+		storage:  storage,
+		synonyms: synonyms,
+	}
+}
+
+type SearchCharactersParams struct {
+	Query  string
+	Limit  int32
+	Offset int32
+	// 指定された場合はOffsetより優先し、キーセットページネーションで取得する
+	Cursor *cursor.Cursor
+}
+
+// Search は名前やファイル名に一致するキャラクターを取得する
+// 検索語は正規化し、同義語が登録されている場合は展開する
+func (s *CharacterService) Search(ctx context.Context, arg SearchCharactersParams) ([]db.Character, error) {
+	patterns, err := likePatterns(ctx, s.synonyms, arg.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	if arg.Cursor != nil {
+		characters, err := s.store.SearchCharactersByCursor(ctx, db.SearchCharactersByCursorParams{
+			Limit:               arg.Limit,
+			Patterns:            patterns,
+			CursorPriorityLevel: arg.Cursor.PriorityLevel,
+			CursorID:            arg.Cursor.ID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to SearchCharactersByCursor : %w", err)
+		}
+		return characters, nil
+	}
+
+	characters, err := s.store.SearchCharacters(ctx, db.SearchCharactersParams{
+		Limit:    arg.Limit,
+		Offset:   arg.Offset,
+		Patterns: patterns,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to SearchCharacters : %w", err)
+	}
+
+	return characters, nil
+}
+
+// CountSearch は名前やファイル名に一致するキャラクターの件数を取得する
+func (s *CharacterService) CountSearch(ctx context.Context, query string) (int64, error) {
+	patterns, err := likePatterns(ctx, s.synonyms, query)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := s.store.CountSearchCharacters(ctx, patterns)
+	if err != nil {
+		return 0, fmt.Errorf("failed to CountSearchCharacters : %w", err)
+	}
+
+	return count, nil
+}
+
+type CreateCharacterParams struct {
+	Name          string
+	Filename      string
+	PriorityLevel int16
+	Image         *ImageFile
+}
+
+// Create は画像をアップロードし、キャラクターを作成する
+func (s *CharacterService) Create(ctx context.Context, arg CreateCharacterParams) (db.Character, error) {
+	arg.Filename = normalizeFilename(arg.Filename)
+
+	var character db.Character
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		src, err := uploadImage(ctx, s.storage, arg.Image, arg.Filename, IMAGE_TYPE_CHARACTER, false)
+		if err != nil {
+			return fmt.Errorf("failed to UploadImage : %w", err)
+		}
+
+		character, err = q.CreateCharacter(ctx, db.CreateCharacterParams{
+			Name:          arg.Name,
+			Src:           src,
+			Filename:      sql.NullString{String: arg.Filename, Valid: true},
+			PriorityLevel: arg.PriorityLevel,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to CreateCharacter : %w", err)
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_CREATE, AUDIT_ENTITY_CHARACTER, character.ID, nil, character); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return db.Character{}, fmt.Errorf("CreateCharacter transaction was failed : %w", txErr)
+	}
+
+	return character, nil
+}
+
+type EditCharacterParams struct {
+	Name          string
+	Filename      string
+	PriorityLevel int16
+	Image         *ImageFile
+	// クライアントが取得した時点のキャラクターの更新日時
+	Version time.Time
+}
+
+// Edit はキャラクターを更新する
+// ファイル名や画像が変更された場合は、画像をアップロードし直す
+// 取得した時点から他の操作で更新されている場合はErrVersionMismatchを返す
+func (s *CharacterService) Edit(ctx context.Context, id int64, arg EditCharacterParams) (db.Character, error) {
+	arg.Filename = normalizeFilename(arg.Filename)
+
+	character, err := s.store.GetCharacter(ctx, id)
+	if err != nil {
+		return db.Character{}, fmt.Errorf("failed to GetCharacter : %w", err)
+	}
+	if err := checkVersion(character.UpdatedAt, arg.Version); err != nil {
+		return db.Character{}, err
+	}
+
+	before := character
+	var files fileChanges
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		// 画像を差し替える前に行をロックし、取得した時点から更新されていないことを確認する
+		character, err = q.GetCharacterForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to GetCharacterForUpdate : %w", err)
+		}
+		if err := checkVersion(character.UpdatedAt, arg.Version); err != nil {
+			return err
+		}
+
+		src := character.Src
+		if character.Filename.String != arg.Filename || arg.Image != nil {
+			src, err = uploadImage(ctx, s.storage, arg.Image, arg.Filename, IMAGE_TYPE_CHARACTER, false)
+			if err != nil {
+				return fmt.Errorf("failed to UploadImage : %w", err)
+			}
+			files.replace(character.Src, src)
+		}
+
+		params := db.UpdateCharacterParams{
+			ID:                character.ID,
+			Name:              arg.Name,
+			Src:               src,
+			Filename:          sql.NullString{String: character.Filename.String, Valid: true},
+			PriorityLevel:     arg.PriorityLevel,
+			UpdatedAt:         time.Now(),
+			ExpectedUpdatedAt: before.UpdatedAt,
+		}
+		if character.Filename.String != arg.Filename {
+			params.Filename = sql.NullString{String: arg.Filename, Valid: true}
+		}
+
+		character, err = q.UpdateCharacter(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to UpdateCharacter : %w", versionError(err))
+		}
+
+		// キャラクター名は関連するイラストの検索対象のため、検索用ドキュメントを作り直す
+		if err := refreshSearchDocumentsByCharacterID(ctx, q, character.ID); err != nil {
+			return err
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_UPDATE, AUDIT_ENTITY_CHARACTER, character.ID, before, character); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		files.rollback(ctx, s.storage)
+		return db.Character{}, fmt.Errorf("EditCharacter transaction was failed : %w", txErr)
+	}
+	files.commit(ctx, s.storage)
+
+	return character, nil
+}
+
+type PatchCharacterParams struct {
+	// nilの場合は変更しない
+	Name          *string
+	PriorityLevel *int16
+	// クライアントが取得した時点のキャラクターの更新日時
+	Version time.Time
+}
+
+// Patch はキャラクターの指定された項目のみを更新する
+// 画像とファイル名は現在の値を引き継ぐ
+func (s *CharacterService) Patch(ctx context.Context, id int64, arg PatchCharacterParams) (db.Character, error) {
+	character, err := s.store.GetCharacter(ctx, id)
+	if err != nil {
+		return db.Character{}, fmt.Errorf("failed to GetCharacter : %w", err)
+	}
+
+	params := EditCharacterParams{
+		Name:          character.Name,
+		Filename:      character.Filename.String,
+		PriorityLevel: character.PriorityLevel,
+		Version:       arg.Version,
+	}
+	if arg.Name != nil {
+		params.Name = *arg.Name
+	}
+	if arg.PriorityLevel != nil {
+		params.PriorityLevel = *arg.PriorityLevel
+	}
+
+	return s.Edit(ctx, id, params)
+}
+
+type ReplaceCharacterImageParams struct {
+	// 空文字の場合は現在のファイル名を引き継ぐ
+	Filename string
+	Image    *ImageFile
+	// クライアントが取得した時点のキャラクターの更新日時
+	Version time.Time
+}
+
+// ReplaceImage はキャラクターの画像とファイル名のみを更新する
+func (s *CharacterService) ReplaceImage(ctx context.Context, id int64, arg ReplaceCharacterImageParams) (db.Character, error) {
+	character, err := s.store.GetCharacter(ctx, id)
+	if err != nil {
+		return db.Character{}, fmt.Errorf("failed to GetCharacter : %w", err)
+	}
+
+	params := EditCharacterParams{
+		Name:          character.Name,
+		Filename:      character.Filename.String,
+		PriorityLevel: character.PriorityLevel,
+		Image:         arg.Image,
+		Version:       arg.Version,
+	}
+	if arg.Filename != "" {
+		params.Filename = arg.Filename
+	}
+
+	return s.Edit(ctx, id, params)
+}
+
+// Delete はキャラクターをゴミ箱に移動する
+// イラストとの関連とアップロード済みの画像は復元できるよう残し、保持期間を過ぎた後にPurgeで削除する
+func (s *CharacterService) Delete(ctx context.Context, id int64) error {
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		character, err := q.GetCharacter(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to GetCharacter : %w", err)
+		}
+
+		deleted, err := q.SoftDeleteCharacter(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to SoftDeleteCharacter : %w", err)
+		}
+		if deleted == 0 {
+			return fmt.Errorf("failed to SoftDeleteCharacter : %w", sql.ErrNoRows)
+		}
+
+		// 検索用ドキュメントからキャラクター名を除く
+		relations, err := q.ListImageCharacterRelationsByCharacterID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to ListImageCharacterRelationsByCharacterID : %w", err)
+		}
+		imageIDs := make([]int64, len(relations))
+		for i, r := range relations {
+			imageIDs[i] = r.ImageID
+		}
+		if err := RefreshSearchDocuments(ctx, q, imageIDs); err != nil {
+			return fmt.Errorf("failed to RefreshSearchDocuments : %w", err)
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_DELETE, AUDIT_ENTITY_CHARACTER, id, character, nil); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return fmt.Errorf("DeleteCharacter transaction was failed : %w", txErr)
+	}
+
+	return nil
+}
+
+func refreshSearchDocumentsByCharacterID(ctx context.Context, q db.Querier, characterID int64) error {
+	relations, err := q.ListImageCharacterRelationsByCharacterID(ctx, characterID)
+	if err != nil {
+		return fmt.Errorf("failed to ListImageCharacterRelationsByCharacterID : %w", err)
+	}
+
+	imageIDs := make([]int64, len(relations))
+	for i, r := range relations {
+		imageIDs[i] = r.ImageID
+	}
+	if err := RefreshSearchDocuments(ctx, q, imageIDs); err != nil {
+		return fmt.Errorf("failed to RefreshSearchDocuments : %w", err)
+	}
+
+	return nil
+}
@@ -1,21 +1,20 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"shin-monta-no-mori/pkg/util"
 	"strings"
 	"time"
 
 	"cloud.google.com/go/storage"
-	"github.com/gin-gonic/gin"
 	"google.golang.org/api/option"
 )
 
 type StorageService interface {
-	UploadFile(ctx *gin.Context, file multipart.File, filename string, fileType string, isSimple bool) (string, error)
-	DeleteFile(ctx *gin.Context, filePath string) error
+	UploadFile(ctx context.Context, file io.Reader, filename string, fileType string, isSimple bool) (string, error)
+	DeleteFile(ctx context.Context, filePath string) error
 }
 
 type GCSStorageService struct {
@@ -30,7 +29,7 @@ func NewGCSStorageService(config util.Config) StorageService {
 }
 
 // GCSアップロード
-func (g *GCSStorageService) UploadFile(ctx *gin.Context, file multipart.File, filename string, fileType string, isSimple bool) (string, error) {
+func (g *GCSStorageService) UploadFile(ctx context.Context, file io.Reader, filename string, fileType string, isSimple bool) (string, error) {
 	client, err := createClient(ctx, g.Config)
 	if err != nil {
 		return "", fmt.Errorf("cannot create client : %w", err)
@@ -67,7 +66,7 @@ func (g *GCSStorageService) UploadFile(ctx *gin.Context, file multipart.File, fi
 }
 
 // GCS上の画像を削除する
-func (g *GCSStorageService) DeleteFile(ctx *gin.Context, deleteSrcPath string) error {
+func (g *GCSStorageService) DeleteFile(ctx context.Context, deleteSrcPath string) error {
 	client, err := createClient(ctx, g.Config)
 	if err != nil {
 		return fmt.Errorf("failed to create : %w", err)
@@ -86,7 +85,7 @@ func (g *GCSStorageService) DeleteFile(ctx *gin.Context, deleteSrcPath string) e
 }
 
 // GCSクライアントとの接続
-func createClient(ctx *gin.Context, c util.Config) (*storage.Client, error) {
+func createClient(ctx context.Context, c util.Config) (*storage.Client, error) {
 	client, err := storage.NewClient(ctx, option.WithCredentialsFile(c.CredentialFilePath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create : %w", err)
@@ -97,7 +96,7 @@ func createClient(ctx *gin.Context, c util.Config) (*storage.Client, error) {
 	return client, err
 }
 
-func updateMetadata(ctx *gin.Context, object *storage.ObjectHandle) error {
+func updateMetadata(ctx context.Context, object *storage.ObjectHandle) error {
 	// メタデータの更新
 	attrsToUpdate := storage.ObjectAttrsToUpdate{
 		CacheControl: "no-cache",
@@ -107,3 +106,40 @@ func updateMetadata(ctx *gin.Context, object *storage.ObjectHandle) error {
 	}
 	return nil
 }
+
+// fileChanges はトランザクション中に行った画像の差し替えを記録する
+// 画像の削除は取り消せないため、差し替え前の画像はコミット後に削除し、
+// ロールバックした場合は新しくアップロードした画像を削除する
+type fileChanges struct {
+	uploaded []string
+	replaced []string
+}
+
+// replace は画像の差し替えを記録する
+// ファイル名が同じ場合は同じオブジェクトを上書きしているため、削除の対象にしない
+func (c *fileChanges) replace(oldSrc, newSrc string) {
+	if oldSrc == newSrc {
+		return
+	}
+	if newSrc != "" {
+		c.uploaded = append(c.uploaded, newSrc)
+	}
+	if oldSrc != "" {
+		c.replaced = append(c.replaced, oldSrc)
+	}
+}
+
+// commit は差し替え前の画像を削除する
+// DBの更新は完了しているため、削除に失敗した画像は残したまま処理を続ける
+func (c *fileChanges) commit(ctx context.Context, storage StorageService) {
+	for _, src := range c.replaced {
+		_ = storage.DeleteFile(ctx, src)
+	}
+}
+
+// rollback は新しくアップロードした画像を削除する
+func (c *fileChanges) rollback(ctx context.Context, storage StorageService) {
+	for _, src := range c.uploaded {
+		_ = storage.DeleteFile(ctx, src)
+	}
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+	model "shin-monta-no-mori/internal/domains/models"
+	"shin-monta-no-mori/pkg/util"
+)
+
+// DailyDate は日時を日本時間の日付に変換する
+// DBのdate型として扱うため、その日付のUTCの0時として返す
+func DailyDate(t time.Time) time.Time {
+	jst := t.In(util.JST)
+	return time.Date(jst.Year(), jst.Month(), jst.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Daily は日付に対応する今日のイラストを取得する
+// 管理者が指定したイラストがあればそれを、なければその日付から決まる順序でイラストを選び、以降も同じイラストを返すよう保存する
+// 前後noRepeatDays日の間に選ばれたイラストは、他に選べるイラストがない場合を除いて選ばない
+func (s *IllustrationService) Daily(ctx context.Context, date time.Time, noRepeatDays int) (*model.Illustration, error) {
+	date = DailyDate(date)
+
+	daily, err := s.store.GetDailyIllustration(ctx, date)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to GetDailyIllustration : %w", err)
+		}
+
+		imageID, err := s.pickDailyImageID(ctx, date, noRepeatDays)
+		if err != nil {
+			return nil, err
+		}
+
+		// 同時にリクエストされた場合も同じイラストになるよう、先に保存されたものを使う
+		err = s.store.CreateDailyIllustration(ctx, db.CreateDailyIllustrationParams{
+			Date:    date,
+			ImageID: imageID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to CreateDailyIllustration : %w", err)
+		}
+
+		daily, err = s.store.GetDailyIllustration(ctx, date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to GetDailyIllustration : %w", err)
+		}
+	}
+
+	return s.Get(ctx, daily.ImageID)
+}
+
+func (s *IllustrationService) pickDailyImageID(ctx context.Context, date time.Time, noRepeatDays int) (int64, error) {
+	recentIDs, err := s.store.ListDailyImageIDs(ctx, db.ListDailyImageIDsParams{
+		FromDate: date.AddDate(0, 0, -noRepeatDays),
+		ToDate:   date.AddDate(0, 0, noRepeatDays+1),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to ListDailyImageIDs : %w", err)
+	}
+
+	// 日付ごとに同じ順序となるよう、YYYYMMDDをseedとする
+	seed, _ := strconv.ParseInt(date.Format("20060102"), 10, 64)
+	for _, exclusionIDs := range [][]int64{recentIDs, nil} {
+		images, err := s.Sample(ctx, SampleParams{
+			Seed:         seed,
+			ExclusionIDs: exclusionIDs,
+			Limit:        1,
+		})
+		if err != nil {
+			return 0, err
+		}
+		if len(images) > 0 {
+			return images[0].ID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no illustration to pick as daily illustration : %w", sql.ErrNoRows)
+}
+
+// ListDailyOverrides は指定した日付以降に、管理者が指定した今日のイラストを日付順に取得する
+func (s *IllustrationService) ListDailyOverrides(ctx context.Context, from time.Time) ([]db.DailyIllustration, error) {
+	overrides, err := s.store.ListDailyIllustrationOverrides(ctx, DailyDate(from))
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListDailyIllustrationOverrides : %w", err)
+	}
+
+	return overrides, nil
+}
+
+// SetDailyOverride は日付に対応する今日のイラストを管理者が指定したイラストにする
+// 既にイラストが選ばれている日付の場合も上書きする
+func (s *IllustrationService) SetDailyOverride(ctx context.Context, date time.Time, imageID int64) (db.DailyIllustration, error) {
+	if _, err := s.store.GetImage(ctx, imageID); err != nil {
+		return db.DailyIllustration{}, fmt.Errorf("failed to GetImage : %w", err)
+	}
+
+	var daily db.DailyIllustration
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		before, err := getDailyIllustrationForAudit(ctx, q, date)
+		if err != nil {
+			return err
+		}
+
+		daily, err = q.UpsertDailyIllustrationOverride(ctx, db.UpsertDailyIllustrationOverrideParams{
+			Date:    DailyDate(date),
+			ImageID: imageID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to UpsertDailyIllustrationOverride : %w", err)
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_UPDATE, AUDIT_ENTITY_DAILY_ILLUSTRATION, DailyDate(date).Format(time.DateOnly), before, daily); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return db.DailyIllustration{}, fmt.Errorf("SetDailyOverride transaction was failed : %w", txErr)
+	}
+
+	return daily, nil
+}
+
+// DeleteDailyOverride は管理者が指定した今日のイラストを取り消す
+// 取り消した日付は、次に取得された際に改めてイラストが選ばれる
+func (s *IllustrationService) DeleteDailyOverride(ctx context.Context, date time.Time) error {
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		before, err := getDailyIllustrationForAudit(ctx, q, date)
+		if err != nil {
+			return err
+		}
+
+		rows, err := q.DeleteDailyIllustrationOverride(ctx, DailyDate(date))
+		if err != nil {
+			return fmt.Errorf("failed to DeleteDailyIllustrationOverride : %w", err)
+		}
+		if rows == 0 {
+			return fmt.Errorf("failed to DeleteDailyIllustrationOverride : %w", sql.ErrNoRows)
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_DELETE, AUDIT_ENTITY_DAILY_ILLUSTRATION, DailyDate(date).Format(time.DateOnly), before, nil); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return fmt.Errorf("DeleteDailyOverride transaction was failed : %w", txErr)
+	}
+
+	return nil
+}
+
+// getDailyIllustrationForAudit は監査ログに記録する変更前の今日のイラストを取得する
+// まだイラストが選ばれていない日付の場合はnilを返す
+func getDailyIllustrationForAudit(ctx context.Context, q db.Querier, date time.Time) (any, error) {
+	daily, err := q.GetDailyIllustration(ctx, DailyDate(date))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to GetDailyIllustration : %w", err)
+	}
+	return daily, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+)
+
+// FilterParams はキャラクター・子カテゴリ・検索語によるイラストの絞り込み条件
+type FilterParams struct {
+	Query            string
+	CharacterIDs     []int64
+	ChildCategoryIDs []int64
+	// trueの場合は指定した全てのキャラクター・子カテゴリと関連するイラスト、
+	// falseの場合はいずれかと関連するイラストに絞り込む
+	MatchAll bool
+	Limit    int32
+	Offset   int32
+}
+
+// CharacterFacet は絞り込み結果のうち、キャラクターと関連するイラストの件数
+type CharacterFacet struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// ChildCategoryFacet は絞り込み結果のうち、子カテゴリと関連するイラストの件数
+type ChildCategoryFacet struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	ParentID int64  `json:"parent_id"`
+	Count    int64  `json:"count"`
+}
+
+// FilterResult はイラストの絞り込み結果
+type FilterResult struct {
+	Images          []db.Image
+	TotalCount      int64
+	Characters      []CharacterFacet
+	ChildCategories []ChildCategoryFacet
+}
+
+// Filter はキャラクター・子カテゴリ・検索語でイラストを絞り込み、新しい順に取得する
+// 絞り込み結果全体に対する、キャラクター・子カテゴリごとの件数も合わせて返す
+func (s *IllustrationService) Filter(ctx context.Context, arg FilterParams) (*FilterResult, error) {
+	query, err := tsQuery(ctx, s.synonyms, arg.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	// nilのスライスはNULLとして渡されるため、空のスライスに揃える
+	characterIDs := append([]int64{}, arg.CharacterIDs...)
+	childCategoryIDs := append([]int64{}, arg.ChildCategoryIDs...)
+
+	imageIDs, err := s.store.FilterImageIDs(ctx, db.FilterImageIDsParams{
+		Query:            query,
+		MatchAll:         arg.MatchAll,
+		CharacterIds:     characterIDs,
+		ChildCategoryIds: childCategoryIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to FilterImageIDs : %w", err)
+	}
+
+	result := &FilterResult{
+		Images:          []db.Image{},
+		TotalCount:      int64(len(imageIDs)),
+		Characters:      []CharacterFacet{},
+		ChildCategories: []ChildCategoryFacet{},
+	}
+	if len(imageIDs) == 0 {
+		return result, nil
+	}
+
+	characters, err := s.store.CountCharactersByImageIDs(ctx, imageIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to CountCharactersByImageIDs : %w", err)
+	}
+	for _, c := range characters {
+		result.Characters = append(result.Characters, CharacterFacet{
+			ID:    c.ID,
+			Name:  c.Name,
+			Count: c.Count,
+		})
+	}
+
+	ccates, err := s.store.CountChildCategoriesByImageIDs(ctx, imageIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to CountChildCategoriesByImageIDs : %w", err)
+	}
+	for _, c := range ccates {
+		result.ChildCategories = append(result.ChildCategories, ChildCategoryFacet{
+			ID:       c.ID,
+			Name:     c.Name,
+			ParentID: c.ParentID,
+			Count:    c.Count,
+		})
+	}
+
+	result.Images, err = s.getImages(ctx, page(imageIDs, arg.Limit, arg.Offset))
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// page はIDの一覧からlimit・offsetで指定した範囲を切り出す
+func page(ids []int64, limit, offset int32) []int64 {
+	start := int(offset)
+	if start < 0 || start >= len(ids) {
+		return []int64{}
+	}
+
+	end := start + int(limit)
+	if end > len(ids) {
+		end = len(ids)
+	}
+
+	return ids[start:end]
+}
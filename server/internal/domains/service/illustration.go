@@ -1,76 +1,567 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"net/http"
+	"io"
 	"path/filepath"
 	"strings"
+	"time"
 
-	"shin-monta-no-mori/internal/app"
 	db "shin-monta-no-mori/internal/db/sqlc"
 	model "shin-monta-no-mori/internal/domains/models"
-	"shin-monta-no-mori/pkg/util"
-
-	"github.com/gin-gonic/gin"
 )
 
 const (
 	pngExtension = ".png"
+
+	IMAGE_TYPE_IMAGE     = "image"
+	IMAGE_TYPE_CHARACTER = "character"
+	IMAGE_TYPE_CATEGORY  = "category"
 )
 
-// FetchRelationInfoForIllustrations はimageと関連するcharacterやcategoryを取得する処理
-func FetchRelationInfoForIllustrations(c *gin.Context, store *db.Store, i db.Image) *model.Illustration {
-	// キャラクターの取得
-	icrs, err := store.ListImageCharacterRelationsByImageID(c, i.ID)
+var ErrInvalidImageExtension = errors.New("please upload only png extension image")
+
+// ErrImageRequiredForRename はファイル名のみを変更しようとした場合のエラー
+// ファイル名を変更すると既存の画像は削除されるため、新しい画像と合わせて送信する必要がある
+var ErrImageRequiredForRename = errors.New("image file is required to change the filename")
+
+// ImageFile はアップロードする画像ファイルを表す
+// HTTPのmultipartに依存しないように、ファイル名と中身のみを保持する
+type ImageFile struct {
+	// 拡張子のチェックに使用する元のファイル名
+	Name    string
+	Content io.Reader
+}
+
+// IllustrationService はイラストに関するユースケースをまとめたサービス
+type IllustrationService struct {
+	store    *db.Store
+	storage  StorageService
+	synonyms *SynonymDictionary
+}
+
+func NewIllustrationService(store *db.Store, storage StorageService, synonyms *SynonymDictionary) *IllustrationService {
+	return &IllustrationService{
+		store:    store,
+		storage:  storage,
+		synonyms: synonyms,
+	}
+}
+
+// Get はIDに紐づくイラストを関連するcharacterやcategoryと合わせて取得する
+func (s *IllustrationService) Get(ctx context.Context, id int64) (*model.Illustration, error) {
+	image, err := s.store.GetImage(ctx, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, app.ErrorResponse(fmt.Errorf("failed to ListImageCharacterRelationsByImageID : %w", err)))
+		return nil, fmt.Errorf("failed to GetImage : %w", err)
+	}
+
+	return FetchRelationInfoForIllustrations(ctx, s.store, image)
+}
+
+func (s *IllustrationService) getImages(ctx context.Context, imageIDs []int64) ([]db.Image, error) {
+	images := []db.Image{}
+	for _, id := range imageIDs {
+		image, err := s.store.GetImage(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to GetImage (image_id: %d) : %w", id, err)
 		}
+		images = append(images, image)
+	}
 
-		c.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to ListImageCharacterRelationsByImageID : %w", err)))
+	return images, nil
+}
+
+type CreateIllustrationParams struct {
+	Title            string
+	Filename         string
+	Characters       []int64
+	ParentCategories []int64
+	ChildCategories  []int64
+	OriginalImage    *ImageFile
+	SimpleImage      *ImageFile
+}
+
+// Create は画像をアップロードし、イラストと関連情報を1つのトランザクションで保存する
+// 紐づけるキャラクター・カテゴリやファイル名に問題がある場合は、アップロード前にValidationErrorを返す
+func (s *IllustrationService) Create(ctx context.Context, arg CreateIllustrationParams) (*model.Illustration, error) {
+	arg.Filename = normalizeFilename(arg.Filename)
+
+	relations, err := validateIllustration(ctx, s.store, 0, arg.Filename, illustrationRelations{
+		Characters:       arg.Characters,
+		ParentCategories: arg.ParentCategories,
+		ChildCategories:  arg.ChildCategories,
+	})
+	if err != nil {
+		return nil, err
 	}
+	arg.Characters = relations.Characters
+	arg.ParentCategories = relations.ParentCategories
+	arg.ChildCategories = relations.ChildCategories
+
+	var image db.Image
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		var originalSrc string
+		if arg.Filename != "" {
+			originalSrc, err = s.uploadImage(ctx, arg.OriginalImage, arg.Filename, IMAGE_TYPE_IMAGE, false)
+			if err != nil {
+				return fmt.Errorf("failed to UploadImage : %w", err)
+			}
+		}
 
-	characters := []*model.Character{}
-	for _, icr := range icrs {
-		char, err := store.GetCharacter(c, icr.CharacterID)
+		var simpleSrc string
+		if arg.Filename != "" && arg.SimpleImage != nil {
+			simpleSrc, err = s.uploadImage(ctx, arg.SimpleImage, arg.Filename, IMAGE_TYPE_IMAGE, true)
+			if err != nil {
+				return fmt.Errorf("failed to UploadImage for simple image : %w", err)
+			}
+		}
+
+		params := db.CreateImageParams{
+			Title:            arg.Title,
+			OriginalSrc:      originalSrc,
+			OriginalFilename: arg.Filename,
+			SimpleSrc:        sql.NullString{String: "", Valid: false},
+			SimpleFilename:   sql.NullString{String: "", Valid: false},
+		}
+		if simpleSrc != "" {
+			params.SimpleSrc = sql.NullString{String: simpleSrc, Valid: true}
+			params.SimpleFilename = sql.NullString{String: arg.Filename + "_s", Valid: true}
+		}
+
+		image, err = q.CreateImage(ctx, params)
 		if err != nil {
-			if err == sql.ErrNoRows {
-				c.JSON(http.StatusNotFound, app.ErrorResponse(fmt.Errorf("failed to GetImage: %w", err)))
+			return fmt.Errorf("failed to CreateImage : %w", err)
+		}
+
+		// ImageCharacterRelationsの保存
+		for _, cID := range arg.Characters {
+			_, err := q.CreateImageCharacterRelations(ctx, db.CreateImageCharacterRelationsParams{
+				ImageID:     image.ID,
+				CharacterID: cID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to CreateImageCharacterRelations (character_id: %d) : %w", cID, err)
+			}
+		}
+
+		// ImageParentCategoryRelationsの保存
+		// mapを使うことで、重複する値を取り除く
+		parentCategorySet := make(map[int64]struct{})
+		for _, pcID := range arg.ParentCategories {
+			parentCategorySet[pcID] = struct{}{}
+		}
+		for pcID := range parentCategorySet {
+			_, err := q.CreateImageParentCategoryRelations(ctx, db.CreateImageParentCategoryRelationsParams{
+				ImageID:          image.ID,
+				ParentCategoryID: pcID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to CreateImageParentCategoryRelations (parent_category_id: %d) : %w", pcID, err)
 			}
+		}
+
+		// ImageChildCategoryRelationsの保存
+		for _, ccID := range arg.ChildCategories {
+			_, err := q.CreateImageChildCategoryRelations(ctx, db.CreateImageChildCategoryRelationsParams{
+				ImageID:         image.ID,
+				ChildCategoryID: ccID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to CreateImageChildCategoryRelations (child_category_id: %d) : %w", ccID, err)
+			}
+		}
+
+		if err := RefreshSearchDocument(ctx, q, image.ID); err != nil {
+			return fmt.Errorf("failed to RefreshSearchDocument : %w", err)
+		}
+
+		if err := recordIllustrationAuditLog(ctx, q, AUDIT_ACTION_CREATE, image.ID, nil, &image); err != nil {
+			return fmt.Errorf("failed to recordIllustrationAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return nil, fmt.Errorf("CreateImage transaction was failed : %w", txErr)
+	}
+
+	return s.Get(ctx, image.ID)
+}
+
+type EditIllustrationParams struct {
+	Title               string
+	Filename            string
+	Characters          []int64
+	ParentCategories    []int64
+	ChildCategories     []int64
+	OriginalImage       *ImageFile
+	SimpleImage         *ImageFile
+	IsDeleteSimpleImage bool
+	// 編集履歴に記録するオペレーター名
+	OperatorName string
+	// クライアントが取得した時点のイラストの更新日時
+	Version time.Time
+}
+
+// Edit はイラストと関連情報を1つのトランザクションで更新する
+// 更新前の状態は編集履歴としてimage_revisionsに保存する
+// 取得した時点から他の操作で更新されている場合はErrVersionMismatchを返す
+// 紐づけるキャラクター・カテゴリやファイル名に問題がある場合は、画像の差し替え前にValidationErrorを返す
+func (s *IllustrationService) Edit(ctx context.Context, id int64, arg EditIllustrationParams) (*model.Illustration, error) {
+	arg.Filename = normalizeFilename(arg.Filename)
+
+	image, err := s.store.GetImage(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetImage : %w", err)
+	}
+	if err := checkVersion(image.UpdatedAt, arg.Version); err != nil {
+		return nil, err
+	}
+
+	relations, err := validateIllustration(ctx, s.store, image.ID, arg.Filename, illustrationRelations{
+		Characters:       arg.Characters,
+		ParentCategories: arg.ParentCategories,
+		ChildCategories:  arg.ChildCategories,
+	})
+	if err != nil {
+		return nil, err
+	}
+	arg.Characters = relations.Characters
+	arg.ParentCategories = relations.ParentCategories
+	arg.ChildCategories = relations.ChildCategories
+
+	var files fileChanges
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		// 画像を差し替える前に行をロックし、取得した時点から更新されていないことを確認する
+		image, err = q.GetImageForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to GetImageForUpdate : %w", err)
+		}
+		if err := checkVersion(image.UpdatedAt, arg.Version); err != nil {
+			return err
+		}
+
+		revision, err := createImageRevision(ctx, q, image, arg.OperatorName)
+		if err != nil {
+			return fmt.Errorf("failed to createImageRevision : %w", err)
+		}
+
+		// Conditions for updating originalSrc:
+		// 1. ファイル名のみ変更
+		// 2. イメージのみ変更
+		// 3. ファイル名＆イメージが変更
+		originalSrc := image.OriginalSrc
+		if image.OriginalFilename != arg.Filename || arg.OriginalImage != nil {
+			originalSrc, err = s.uploadImage(ctx, arg.OriginalImage, arg.Filename, IMAGE_TYPE_IMAGE, false)
+			if err != nil {
+				return fmt.Errorf("failed to UploadImage : %w", err)
+			}
+			files.replace(image.OriginalSrc, originalSrc)
+		}
+
+		// Conditions for updating simpleSrc:
+		// 1. ファイル名のみ変更
+		// 2. イメージのみ変更
+		// 3. ファイル名＆イメージが変更
+		// 4. イメージの削除
+		shouldUpdateSimpleSrc := image.OriginalFilename != arg.Filename || arg.SimpleImage != nil
+		simpleSrc := image.SimpleSrc.String
+		if shouldUpdateSimpleSrc {
+			newSimpleSrc, err := s.uploadImage(ctx, arg.SimpleImage, arg.Filename, IMAGE_TYPE_IMAGE, true)
+			if err != nil {
+				return fmt.Errorf("failed to UploadImage for simple image : %w", err)
+			}
+			files.replace(simpleSrc, newSimpleSrc)
+			simpleSrc = newSimpleSrc
+		}
+
+		if arg.IsDeleteSimpleImage {
+			files.replace(simpleSrc, "")
+			simpleSrc = ""
+		}
+
+		// imageのUpdate処理
+		params := db.UpdateImageParams{
+			ID:               image.ID,
+			Title:            arg.Title,
+			OriginalSrc:      originalSrc,
+			SimpleSrc:        sql.NullString{String: "", Valid: false},
+			OriginalFilename: arg.Filename,
+			SimpleFilename:   sql.NullString{String: "", Valid: false},
+			// TODO: timezoneがUTCになっている。厳密な時系列を扱う必要がある課題が出た時に修正する必要あり。
+			UpdatedAt:         time.Now(),
+			ExpectedUpdatedAt: image.UpdatedAt,
+		}
+		if simpleSrc != "" {
+			params.SimpleSrc = sql.NullString{String: simpleSrc, Valid: true}
+			params.SimpleFilename = sql.NullString{String: arg.Filename + "_s", Valid: true}
+		}
+		image, err = q.UpdateImage(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to UpdateImage : %w", versionError(err))
+		}
+
+		if err := UpdateImageCharacterRelationsIDs(ctx, q, image.ID, arg.Characters); err != nil {
+			return fmt.Errorf("failed to UpdateImageCharacterRelationsIDs : %w", err)
+		}
+		if err := UpdateImageParentCategoryRelationsIDs(ctx, q, image.ID, arg.ParentCategories); err != nil {
+			return fmt.Errorf("failed to UpdateImageParentCategoryRelationsIDs : %w", err)
+		}
+		if err := UpdateImageChildCategoryRelationsIDs(ctx, q, image.ID, arg.ChildCategories); err != nil {
+			return fmt.Errorf("failed to UpdateImageChildCategoryRelationsIDs : %w", err)
+		}
+
+		if err := RefreshSearchDocument(ctx, q, image.ID); err != nil {
+			return fmt.Errorf("failed to RefreshSearchDocument : %w", err)
+		}
+
+		before := illustrationAuditStateOf(revision)
+		if err := recordIllustrationAuditLog(ctx, q, AUDIT_ACTION_UPDATE, image.ID, &before, &image); err != nil {
+			return fmt.Errorf("failed to recordIllustrationAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		files.rollback(ctx, s.storage)
+		return nil, fmt.Errorf("EditImage transaction was failed : %w", txErr)
+	}
+	files.commit(ctx, s.storage)
+
+	return FetchRelationInfoForIllustrations(ctx, s.store, image)
+}
+
+// RelationPatch はキャラクター・カテゴリとの紐づけに対する追加・削除
+type RelationPatch struct {
+	Add    []int64
+	Remove []int64
+}
+
+// apply は現在紐づいているIDに追加・削除を反映したIDを返す
+// 同じIDを追加と削除の両方に指定した場合は削除を優先する
+func (p RelationPatch) apply(current []int64) []int64 {
+	remove := make(map[int64]bool, len(p.Remove))
+	for _, id := range p.Remove {
+		remove[id] = true
+	}
+
+	seen := map[int64]bool{}
+	ids := []int64{}
+	for _, id := range append(append([]int64{}, current...), p.Add...) {
+		if remove[id] || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+type PatchIllustrationParams struct {
+	// nilの場合は変更しない
+	Title            *string
+	Characters       RelationPatch
+	ParentCategories RelationPatch
+	ChildCategories  RelationPatch
+	// 編集履歴に記録するオペレーター名
+	OperatorName string
+	// クライアントが取得した時点のイラストの更新日時
+	Version time.Time
+}
+
+// Patch はイラストの指定された項目のみを更新する
+// 指定されていない項目と画像は現在の値を引き継ぎ、Editと同じく編集履歴と監査ログを記録する
+func (s *IllustrationService) Patch(ctx context.Context, id int64, arg PatchIllustrationParams) (*model.Illustration, error) {
+	params, err := s.currentEditParams(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if arg.Title != nil {
+		params.Title = *arg.Title
+	}
+	params.Characters = arg.Characters.apply(params.Characters)
+	params.ParentCategories = arg.ParentCategories.apply(params.ParentCategories)
+	params.ChildCategories = arg.ChildCategories.apply(params.ChildCategories)
+	params.OperatorName = arg.OperatorName
+	params.Version = arg.Version
+
+	return s.Edit(ctx, id, params)
+}
+
+type ReplaceIllustrationImageParams struct {
+	// 空文字の場合は現在のファイル名を引き継ぐ
+	Filename            string
+	OriginalImage       *ImageFile
+	SimpleImage         *ImageFile
+	IsDeleteSimpleImage bool
+	// 編集履歴に記録するオペレーター名
+	OperatorName string
+	// クライアントが取得した時点のイラストの更新日時
+	Version time.Time
+}
+
+// ReplaceImage はイラストの画像とファイル名のみを更新する
+// タイトルと紐づくキャラクター・カテゴリは現在の値を引き継ぐ
+func (s *IllustrationService) ReplaceImage(ctx context.Context, id int64, arg ReplaceIllustrationImageParams) (*model.Illustration, error) {
+	params, err := s.currentEditParams(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
-			c.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to GetImage : %w", err)))
+	// ファイル名を変更すると既存の画像は削除されるため、新しい画像が必要になる
+	if arg.Filename != "" && normalizeFilename(arg.Filename) != params.Filename {
+		if arg.OriginalImage == nil {
+			return nil, ErrImageRequiredForRename
 		}
-		character := &model.Character{Character: char}
+		params.Filename = arg.Filename
+	}
+	params.OriginalImage = arg.OriginalImage
+	params.SimpleImage = arg.SimpleImage
+	params.IsDeleteSimpleImage = arg.IsDeleteSimpleImage
+	params.OperatorName = arg.OperatorName
+	params.Version = arg.Version
 
-		characters = append(characters, character)
+	return s.Edit(ctx, id, params)
+}
+
+// currentEditParams はイラストの現在の状態をEditの引数に変換する
+func (s *IllustrationService) currentEditParams(ctx context.Context, id int64) (EditIllustrationParams, error) {
+	image, err := s.store.GetImage(ctx, id)
+	if err != nil {
+		return EditIllustrationParams{}, fmt.Errorf("failed to GetImage : %w", err)
+	}
+	snapshot, err := snapshotImage(ctx, s.store, image)
+	if err != nil {
+		return EditIllustrationParams{}, fmt.Errorf("failed to snapshotImage : %w", err)
+	}
+
+	return EditIllustrationParams{
+		Title:            snapshot.Title,
+		Filename:         snapshot.OriginalFilename,
+		Characters:       snapshot.CharacterIds,
+		ParentCategories: snapshot.ParentCategoryIds,
+		ChildCategories:  snapshot.ChildCategoryIds,
+	}, nil
+}
+
+// Delete はイラストをゴミ箱に移動する
+// 関連情報とアップロード済みの画像は復元できるよう残し、保持期間を過ぎた後にPurgeで削除する
+func (s *IllustrationService) Delete(ctx context.Context, id int64) error {
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		image, err := q.GetImage(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to GetImage : %w", err)
+		}
+		snapshot, err := snapshotImage(ctx, q, image)
+		if err != nil {
+			return fmt.Errorf("failed to snapshotImage : %w", err)
+		}
+
+		deleted, err := q.SoftDeleteImage(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to SoftDeleteImage : %w", err)
+		}
+		if deleted == 0 {
+			return fmt.Errorf("failed to SoftDeleteImage : %w", sql.ErrNoRows)
+		}
+
+		before := illustrationAuditStateOf(snapshot)
+		if err := recordIllustrationAuditLog(ctx, q, AUDIT_ACTION_DELETE, id, &before, nil); err != nil {
+			return fmt.Errorf("failed to recordIllustrationAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return fmt.Errorf("DeleteImage transaction was failed : %w", txErr)
+	}
+
+	return nil
+}
+
+func (s *IllustrationService) fetchRelations(ctx context.Context, images []db.Image) ([]*model.Illustration, error) {
+	illustrations := []*model.Illustration{}
+	for _, i := range images {
+		il, err := FetchRelationInfoForIllustrations(ctx, s.store, i)
+		if err != nil {
+			return nil, err
+		}
+		illustrations = append(illustrations, il)
+	}
+
+	return illustrations, nil
+}
+
+// uploadImage は画像をストレージにアップロードする
+// isSimpleはGCSにアップロードする時に画像に'_s'をつけるために使用する
+// ファイルが指定されていない場合は空文字を返す
+func (s *IllustrationService) uploadImage(ctx context.Context, file *ImageFile, filename string, fileType string, isSimple bool) (string, error) {
+	return uploadImage(ctx, s.storage, file, filename, fileType, isSimple)
+}
+
+func uploadImage(ctx context.Context, storage StorageService, file *ImageFile, filename string, fileType string, isSimple bool) (string, error) {
+	if file == nil {
+		return "", nil
+	}
+
+	ext := strings.ToLower(filepath.Ext(file.Name))
+	if ext != pngExtension {
+		return "", ErrInvalidImageExtension
+	}
+
+	return storage.UploadFile(ctx, file.Content, filename, fileType, isSimple)
+}
+
+func normalizeFilename(filename string) string {
+	return strings.ReplaceAll(filename, " ", "-")
+}
+
+// FetchRelationInfoForIllustrations はimageと関連するcharacterやcategoryを取得する処理
+func FetchRelationInfoForIllustrations(ctx context.Context, q db.Querier, i db.Image) (*model.Illustration, error) {
+	// キャラクターの取得
+	icrs, err := q.ListImageCharacterRelationsByImageID(ctx, i.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListImageCharacterRelationsByImageID : %w", err)
+	}
+
+	characters := []*model.Character{}
+	for _, icr := range icrs {
+		char, err := q.GetCharacter(ctx, icr.CharacterID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to GetCharacter : %w", err)
+		}
+		characters = append(characters, &model.Character{Character: char})
 	}
 
 	// image.IDに関連するparent_categoryの取得
-	ipcrs, err := store.ListImageParentCategoryRelationsByImageID(c, i.ID)
+	ipcrs, err := q.ListImageParentCategoryRelationsByImageID(ctx, i.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to ListImageCharacterRelationsByImageID : %w", err)))
+		return nil, fmt.Errorf("failed to ListImageParentCategoryRelationsByImageID : %w", err)
 	}
 	pCates := []db.ParentCategory{}
 	for _, ipcr := range ipcrs {
-		pCate, err := store.GetParentCategory(c, ipcr.ParentCategoryID)
+		pCate, err := q.GetParentCategory(ctx, ipcr.ParentCategoryID)
 		if err != nil {
-			c.JSON(http.StatusNotFound, app.ErrorResponse(fmt.Errorf("failed to GetParentCategory : %w", err)))
+			return nil, fmt.Errorf("failed to GetParentCategory : %w", err)
 		}
 		pCates = append(pCates, pCate)
 	}
 
 	// image.IDに関連するchild_categoryの取得
-	iccrs, err := store.ListImageChildCategoryRelationsByImageID(c, i.ID)
+	iccrs, err := q.ListImageChildCategoryRelationsByImageID(ctx, i.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, app.ErrorResponse(fmt.Errorf("failed to ListImageCharacterRelationsByImageID : %w", err)))
+		return nil, fmt.Errorf("failed to ListImageChildCategoryRelationsByImageID : %w", err)
 	}
 	cCates := []db.ChildCategory{}
 	for _, iccr := range iccrs {
-		cCate, err := store.GetChildCategory(c, iccr.ChildCategoryID)
+		cCate, err := q.GetChildCategory(ctx, iccr.ChildCategoryID)
 		if err != nil {
-			c.JSON(http.StatusNotFound, app.ErrorResponse(fmt.Errorf("failed to GetChildCategory : %w", err)))
+			return nil, fmt.Errorf("failed to GetChildCategory : %w", err)
 		}
 		cCates = append(cCates, cCate)
 	}
@@ -93,46 +584,12 @@ func FetchRelationInfoForIllustrations(c *gin.Context, store *db.Store, i db.Ima
 	il.Characters = characters
 	il.Categories = categories
 
-	return il
-}
-
-// isSimpleはGCSにアップロードする時に画像に'_s'をつけるために使用する
-func UploadImageSrc(c *gin.Context, config *util.Config, formKey string, filename string, fileType string, isSimple bool) (string, error) {
-	f, err := c.FormFile(formKey)
-	if err != nil {
-		if err == http.ErrMissingFile {
-			return "", nil
-		}
-		return "", fmt.Errorf("failed to get file: %w", err)
-	}
-
-	file, err := f.Open()
-	if err != nil {
-		return "", fmt.Errorf("failed to open file: %w", err)
-	}
-	defer file.Close()
-
-	ext := strings.ToLower(filepath.Ext(f.Filename))
-	if ext != pngExtension {
-		return "", errors.New("please upload only png extension image")
-	}
-
-	storageService := &GCSStorageService{
-		Config: *config,
-	}
-	return storageService.UploadFile(c, file, filename, fileType, isSimple)
-}
-
-func DeleteImageSrc(c *gin.Context, config *util.Config, src string) error {
-	storageService := &GCSStorageService{
-		Config: *config,
-	}
-	return storageService.DeleteFile(c, src)
+	return il, nil
 }
 
 // UpdateImageCharacterRelationsIDs updates the character relations for an image.
-func UpdateImageCharacterRelationsIDs(c *gin.Context, store *db.Store, imageID int64, requestCharacterIDs []int64) error {
-	existingRelations, err := store.ListImageCharacterRelationsByImageID(c, imageID)
+func UpdateImageCharacterRelationsIDs(ctx context.Context, q db.Querier, imageID int64, requestCharacterIDs []int64) error {
+	existingRelations, err := q.ListImageCharacterRelationsByImageID(ctx, imageID)
 	if err != nil {
 		return fmt.Errorf("failed to ListImageCharacterRelationsByImageID: %w", err)
 	}
@@ -152,7 +609,7 @@ func UpdateImageCharacterRelationsIDs(c *gin.Context, store *db.Store, imageID i
 	// Remove relations that are not needed anymore.
 	for _, existRel := range existingRelations {
 		if !requestIDs[existRel.CharacterID] {
-			if err := store.DeleteImageCharacterRelations(c, existRel.ID); err != nil {
+			if err := q.DeleteImageCharacterRelations(ctx, existRel.ID); err != nil {
 				return fmt.Errorf("failed to DeleteImageCharacterRelations: %w", err)
 			}
 		}
@@ -161,7 +618,7 @@ func UpdateImageCharacterRelationsIDs(c *gin.Context, store *db.Store, imageID i
 	// Add new relations that do not exist yet.
 	for requestID := range requestIDs {
 		if !existingIDs[requestID] {
-			_, err := store.CreateImageCharacterRelations(c, db.CreateImageCharacterRelationsParams{
+			_, err := q.CreateImageCharacterRelations(ctx, db.CreateImageCharacterRelationsParams{
 				ImageID:     imageID,
 				CharacterID: requestID,
 			})
@@ -175,8 +632,8 @@ func UpdateImageCharacterRelationsIDs(c *gin.Context, store *db.Store, imageID i
 }
 
 // UpdateImageParentCategoryRelationsIDs updates the parent_category relations for an image.
-func UpdateImageParentCategoryRelationsIDs(c *gin.Context, store *db.Store, imageID int64, requestParentCategoryIDs []int64) error {
-	existingRelations, err := store.ListImageParentCategoryRelationsByImageID(c, imageID)
+func UpdateImageParentCategoryRelationsIDs(ctx context.Context, q db.Querier, imageID int64, requestParentCategoryIDs []int64) error {
+	existingRelations, err := q.ListImageParentCategoryRelationsByImageID(ctx, imageID)
 	if err != nil {
 		return fmt.Errorf("failed to ListImageParentCategoryRelationsByImageID: %w", err)
 	}
@@ -196,16 +653,16 @@ func UpdateImageParentCategoryRelationsIDs(c *gin.Context, store *db.Store, imag
 	// Remove relations that are not needed anymore.
 	for _, existRel := range existingRelations {
 		if !requestIDs[existRel.ParentCategoryID] {
-			if err := store.DeleteImageParentCategoryRelations(c, existRel.ID); err != nil {
+			if err := q.DeleteImageParentCategoryRelations(ctx, existRel.ID); err != nil {
 				return fmt.Errorf("failed to DeleteImageParentCategoryRelations: %w", err)
 			}
 		}
 	}
 
-	// Add new relations that do not exist 	yet.
+	// Add new relations that do not exist yet.
 	for requestID := range requestIDs {
 		if !existingIDs[requestID] {
-			_, err := store.CreateImageParentCategoryRelations(c, db.CreateImageParentCategoryRelationsParams{
+			_, err := q.CreateImageParentCategoryRelations(ctx, db.CreateImageParentCategoryRelationsParams{
 				ImageID:          imageID,
 				ParentCategoryID: requestID,
 			})
@@ -219,8 +676,8 @@ func UpdateImageParentCategoryRelationsIDs(c *gin.Context, store *db.Store, imag
 }
 
 // UpdateImageChildCategoryRelationsIDs updates the child_category relations for an image.
-func UpdateImageChildCategoryRelationsIDs(c *gin.Context, store *db.Store, imageID int64, requestChildCategoryIDs []int64) error {
-	existingRelations, err := store.ListImageChildCategoryRelationsByImageID(c, imageID)
+func UpdateImageChildCategoryRelationsIDs(ctx context.Context, q db.Querier, imageID int64, requestChildCategoryIDs []int64) error {
+	existingRelations, err := q.ListImageChildCategoryRelationsByImageID(ctx, imageID)
 	if err != nil {
 		return fmt.Errorf("failed to ListImageChildCategoryRelationsByImageID: %w", err)
 	}
@@ -240,16 +697,16 @@ func UpdateImageChildCategoryRelationsIDs(c *gin.Context, store *db.Store, image
 	// Remove relations that are not needed anymore.
 	for _, existRel := range existingRelations {
 		if !requestIDs[existRel.ChildCategoryID] {
-			if err := store.DeleteImageChildCategoryRelations(c, existRel.ID); err != nil {
+			if err := q.DeleteImageChildCategoryRelations(ctx, existRel.ID); err != nil {
 				return fmt.Errorf("failed to DeleteImageChildCategoryRelations: %w", err)
 			}
 		}
 	}
 
-	// Add new relations that do not exist 	yet.
+	// Add new relations that do not exist yet.
 	for requestID := range requestIDs {
 		if !existingIDs[requestID] {
-			_, err := store.CreateImageChildCategoryRelations(c, db.CreateImageChildCategoryRelationsParams{
+			_, err := q.CreateImageChildCategoryRelations(ctx, db.CreateImageChildCategoryRelationsParams{
 				ImageID:         imageID,
 				ChildCategoryID: requestID,
 			})
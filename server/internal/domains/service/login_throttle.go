@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"shin-monta-no-mori/internal/cache"
+	"shin-monta-no-mori/pkg/lib/logger"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// ログインの試行回数を数える期間
+	loginRateWindow = time.Minute
+	// 期間内に同じIPから試行できる回数
+	loginRateLimitPerIP = 20
+	// 期間内に同じメールアドレスで試行できる回数
+	loginRateLimitPerEmail = 10
+
+	// 連続した失敗を数える期間
+	loginFailureWindow = 24 * time.Hour
+	// ロックするまでに連続して失敗できる回数
+	loginMaxFailures = 5
+	// 初回のロックの期間。ロックされるたびに倍にする
+	loginBaseLockout = time.Minute
+	// ロックの期間の上限
+	loginMaxLockout = time.Hour
+)
+
+// LoginThrottledError はログインの試行回数の上限を超えた場合や、メールアドレスがロックされている場合のエラー
+type LoginThrottledError struct {
+	// 再度ログインを試行できるまでの時間
+	RetryAfter time.Duration
+	Reason     string
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("login throttled : %s, retry after %s", e.Reason, e.RetryAfter)
+}
+
+// LoginThrottleService はRedisで管理するログインの試行回数・ロックに関するユースケースをまとめたサービス
+// Redisに接続できない場合もログインできるよう、Redisのエラーはログに出力して制限しない
+type LoginThrottleService struct {
+	redis  cache.RedisClient
+	logger logger.Logger
+}
+
+func NewLoginThrottleService(redis cache.RedisClient, logger logger.Logger) *LoginThrottleService {
+	return &LoginThrottleService{
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+// Allow はIP・メールアドレスごとの試行回数と、メールアドレスのロックを確認する
+// 制限を超えている場合はLoginThrottledErrorを返す
+func (s *LoginThrottleService) Allow(ctx context.Context, clientIP, email string) error {
+	email = normalizeEmail(email)
+
+	var lockedUntil time.Time
+	err := s.redis.Get(ctx, cache.GetLoginLockedUntilKey(email), &lockedUntil)
+	if err == nil && time.Now().Before(lockedUntil) {
+		return &LoginThrottledError{RetryAfter: time.Until(lockedUntil), Reason: "email is locked"}
+	}
+	if err != nil && !errors.Is(err, redis.Nil) {
+		s.logger.Warn("failed redis data get", zap.String("email", email), zap.Error(err))
+	}
+
+	if err := s.checkRate(ctx, cache.GetLoginRateByIPKey(clientIP), loginRateLimitPerIP); err != nil {
+		s.logger.Warn("login rate limit exceeded", zap.String("client_ip", clientIP))
+		return err
+	}
+	if err := s.checkRate(ctx, cache.GetLoginRateByEmailKey(email), loginRateLimitPerEmail); err != nil {
+		s.logger.Warn("login rate limit exceeded", zap.String("email", email))
+		return err
+	}
+
+	return nil
+}
+
+// RecordFailure はメールアドレスの連続した失敗を数え、上限に達した場合はメールアドレスをロックする
+// ロックの期間は、ロックされるたびに倍にする
+func (s *LoginThrottleService) RecordFailure(ctx context.Context, clientIP, email string) {
+	email = normalizeEmail(email)
+
+	failures, err := s.redis.Incr(ctx, cache.GetLoginFailuresKey(email), loginFailureWindow)
+	if err != nil {
+		s.logger.Warn("failed redis data incr", zap.String("email", email), zap.Error(err))
+		return
+	}
+	if failures < loginMaxFailures {
+		return
+	}
+
+	lockouts, err := s.redis.Incr(ctx, cache.GetLoginLockoutCountKey(email), loginFailureWindow)
+	if err != nil {
+		s.logger.Warn("failed redis data incr", zap.String("email", email), zap.Error(err))
+		return
+	}
+	lockout := lockoutDuration(lockouts)
+
+	err = s.redis.Set(ctx, cache.GetLoginLockedUntilKey(email), time.Now().Add(lockout), lockout)
+	if err != nil {
+		s.logger.Warn("failed redis data set", zap.String("email", email), zap.Error(err))
+		return
+	}
+	// ロックが解除された後は、再度上限まで失敗できるようにする
+	err = s.redis.DelKeys(ctx, []string{cache.GetLoginFailuresKey(email)})
+	if err != nil {
+		s.logger.Warn("failed redis data delete", zap.String("email", email), zap.Error(err))
+	}
+
+	s.logger.Warn("login locked",
+		zap.String("email", email),
+		zap.String("client_ip", clientIP),
+		zap.Int64("failures", failures),
+		zap.Int64("lockouts", lockouts),
+		zap.Duration("lockout", lockout),
+	)
+}
+
+// Reset はログインに成功したメールアドレスの連続した失敗とロックの回数を消去する
+func (s *LoginThrottleService) Reset(ctx context.Context, email string) {
+	email = normalizeEmail(email)
+
+	err := s.redis.DelKeys(ctx, []string{
+		cache.GetLoginFailuresKey(email),
+		cache.GetLoginLockoutCountKey(email),
+	})
+	if err != nil {
+		s.logger.Warn("failed redis data delete", zap.String("email", email), zap.Error(err))
+	}
+}
+
+// checkRate はキーの試行回数を数え、上限を超えた場合はLoginThrottledErrorを返す
+func (s *LoginThrottleService) checkRate(ctx context.Context, key string, limit int64) error {
+	count, err := s.redis.Incr(ctx, key, loginRateWindow)
+	if err != nil {
+		s.logger.Warn("failed redis data incr", zap.String("redis_key", key), zap.Error(err))
+		return nil
+	}
+	if count > limit {
+		return &LoginThrottledError{RetryAfter: loginRateWindow, Reason: "too many attempts"}
+	}
+
+	return nil
+}
+
+// lockoutDuration はn回目のロックの期間を返す
+func lockoutDuration(n int64) time.Duration {
+	lockout := loginBaseLockout
+	for i := int64(1); i < n; i++ {
+		lockout *= 2
+		if lockout >= loginMaxLockout {
+			return loginMaxLockout
+		}
+	}
+
+	return lockout
+}
+
+// normalizeEmail は大文字・小文字や前後の空白の違いで制限を回避できないよう、メールアドレスを正規化する
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
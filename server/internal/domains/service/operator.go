@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/pkg/lib/password"
+	"shin-monta-no-mori/pkg/token"
+)
+
+var (
+	// ErrOperatorAlreadyExists は同じ名前またはメールアドレスのオペレーターが既に登録されている場合のエラー
+	ErrOperatorAlreadyExists = errors.New("operator with the same name or email already exists")
+	// ErrLastOwner は有効なownerがいなくなる操作をした場合のエラー
+	ErrLastOwner = errors.New("at least one active owner is required")
+)
+
+// OperatorService は管理画面のオペレーターの管理に関するユースケースをまとめたサービス
+type OperatorService struct {
+	store *db.Store
+}
+
+func NewOperatorService(store *db.Store) *OperatorService {
+	return &OperatorService{
+		store: store,
+	}
+}
+
+// List は全てのオペレーターを登録順に取得する
+func (s *OperatorService) List(ctx context.Context) ([]db.Operator, error) {
+	operators, err := s.store.ListOperators(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListOperators : %w", err)
+	}
+
+	return operators, nil
+}
+
+// Get はオペレーターを取得する
+func (s *OperatorService) Get(ctx context.Context, id int64) (db.Operator, error) {
+	operator, err := s.store.GetOperator(ctx, id)
+	if err != nil {
+		return db.Operator{}, fmt.Errorf("failed to GetOperator : %w", err)
+	}
+
+	return operator, nil
+}
+
+type InviteOperatorParams struct {
+	Name  string
+	Email string
+	Role  token.Role
+}
+
+// Invite はオペレーターを登録し、初期パスワードを返す
+// 初期パスワードは保存しないため、招待したオペレーターに直接伝える
+func (s *OperatorService) Invite(ctx context.Context, arg InviteOperatorParams) (db.Operator, string, error) {
+	initialPassword, err := password.Generate()
+	if err != nil {
+		return db.Operator{}, "", err
+	}
+	hashedPassword, err := password.HashPassword(initialPassword)
+	if err != nil {
+		return db.Operator{}, "", err
+	}
+
+	var operator db.Operator
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		operator, err = q.CreateOperator(ctx, db.CreateOperatorParams{
+			Name:           arg.Name,
+			HashedPassword: hashedPassword,
+			Email:          arg.Email,
+			Role:           string(arg.Role),
+		})
+		if err != nil {
+			if isUniqueViolation(err) {
+				return ErrOperatorAlreadyExists
+			}
+			return fmt.Errorf("failed to CreateOperator : %w", err)
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_CREATE, AUDIT_ENTITY_OPERATOR, operator.ID, nil, operator); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return db.Operator{}, "", fmt.Errorf("InviteOperator transaction was failed : %w", txErr)
+	}
+
+	return operator, initialPassword, nil
+}
+
+type EditOperatorParams struct {
+	Email string
+	Role  token.Role
+}
+
+// Edit はオペレーターのメールアドレスと権限を更新する
+// 権限を変更した場合は、変更前の権限のトークンを使用できないよう、オペレーターのセッションをすべて無効化する
+func (s *OperatorService) Edit(ctx context.Context, id int64, arg EditOperatorParams) (db.Operator, error) {
+	var operator db.Operator
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		owners, err := lockActiveOwners(ctx, q)
+		if err != nil {
+			return err
+		}
+
+		before, err := q.GetOperator(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to GetOperator : %w", err)
+		}
+
+		if isActiveOwner(before) && arg.Role != token.RoleOwner && len(owners) <= 1 {
+			return ErrLastOwner
+		}
+
+		operator, err = q.EditOperator(ctx, db.EditOperatorParams{
+			ID:    before.ID,
+			Email: arg.Email,
+			Role:  string(arg.Role),
+		})
+		if err != nil {
+			if isUniqueViolation(err) {
+				return ErrOperatorAlreadyExists
+			}
+			return fmt.Errorf("failed to EditOperator : %w", err)
+		}
+
+		if operator.Role != before.Role {
+			if err := q.BlockOperatorSessions(ctx, operator.Name); err != nil {
+				return fmt.Errorf("failed to BlockOperatorSessions : %w", err)
+			}
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_UPDATE, AUDIT_ENTITY_OPERATOR, operator.ID, before, operator); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return db.Operator{}, fmt.Errorf("EditOperator transaction was failed : %w", txErr)
+	}
+
+	return operator, nil
+}
+
+// Disable はオペレーターを無効化し、セッションをすべて無効化する
+// 無効化されたオペレーターはログインできない
+func (s *OperatorService) Disable(ctx context.Context, id int64) (db.Operator, error) {
+	var operator db.Operator
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		owners, err := lockActiveOwners(ctx, q)
+		if err != nil {
+			return err
+		}
+
+		before, err := q.GetOperator(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to GetOperator : %w", err)
+		}
+		if before.DisabledAt.Valid {
+			operator = before
+			return nil
+		}
+
+		if isActiveOwner(before) && len(owners) <= 1 {
+			return ErrLastOwner
+		}
+
+		operator, err = q.DisableOperator(ctx, before.ID)
+		if err != nil {
+			return fmt.Errorf("failed to DisableOperator : %w", err)
+		}
+
+		if err := q.BlockOperatorSessions(ctx, operator.Name); err != nil {
+			return fmt.Errorf("failed to BlockOperatorSessions : %w", err)
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_DISABLE, AUDIT_ENTITY_OPERATOR, operator.ID, before, operator); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return db.Operator{}, fmt.Errorf("DisableOperator transaction was failed : %w", txErr)
+	}
+
+	return operator, nil
+}
+
+// Enable は無効化したオペレーターを有効に戻す
+func (s *OperatorService) Enable(ctx context.Context, id int64) (db.Operator, error) {
+	var operator db.Operator
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		before, err := q.GetOperator(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to GetOperator : %w", err)
+		}
+		if !before.DisabledAt.Valid {
+			operator = before
+			return nil
+		}
+
+		operator, err = q.EnableOperator(ctx, before.ID)
+		if err != nil {
+			return fmt.Errorf("failed to EnableOperator : %w", err)
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_ENABLE, AUDIT_ENTITY_OPERATOR, operator.ID, before, operator); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return db.Operator{}, fmt.Errorf("EnableOperator transaction was failed : %w", txErr)
+	}
+
+	return operator, nil
+}
+
+// isActiveOwner は無効化されていないownerかを返す
+func isActiveOwner(operator db.Operator) bool {
+	return token.Role(operator.Role) == token.RoleOwner && !operator.DisabledAt.Valid
+}
+
+// lockActiveOwners は有効なownerの行をロックし、IDを返す
+// 権限の変更や無効化が同時に行われても最後のownerが残るよう、対象のオペレーターを取得する前に呼び出す
+func lockActiveOwners(ctx context.Context, q *db.Queries) ([]int64, error) {
+	owners, err := q.LockActiveOwners(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to LockActiveOwners : %w", err)
+	}
+
+	return owners, nil
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+)
+
+var ErrInvalidIllustrationOrder = errors.New("illustration order contains duplicated or unrelated illustrations")
+
+// ReorderIllustrations はキャラクターに紐づくイラストの表示順を、指定されたIDの順に更新する
+// 指定されなかったイラストは表示順を解除し、指定されたイラストの後に新しい順で並べる
+func (s *CharacterService) ReorderIllustrations(ctx context.Context, characterID int64, imageIDs []int64) error {
+	if _, err := s.store.GetCharacter(ctx, characterID); err != nil {
+		return fmt.Errorf("failed to GetCharacter : %w", err)
+	}
+	if hasDuplicateIDs(imageIDs) {
+		return ErrInvalidIllustrationOrder
+	}
+
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		relations, err := q.ListImageCharacterRelationsByCharacterID(ctx, characterID)
+		if err != nil {
+			return fmt.Errorf("failed to ListImageCharacterRelationsByCharacterID : %w", err)
+		}
+		positions := make([]positionedRelation, len(relations))
+		for i, r := range relations {
+			positions[i] = positionedRelation{ImageID: r.ImageID, Position: r.Position}
+		}
+
+		if err := q.ClearImageCharacterPositions(ctx, characterID); err != nil {
+			return fmt.Errorf("failed to ClearImageCharacterPositions : %w", err)
+		}
+
+		updated, err := q.SetImageCharacterPositions(ctx, db.SetImageCharacterPositionsParams{
+			CharacterID: characterID,
+			ImageIds:    append([]int64{}, imageIDs...),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to SetImageCharacterPositions : %w", err)
+		}
+		// キャラクターに紐づいていないイラストが含まれる場合は更新件数が一致しない
+		if updated != int64(len(imageIDs)) {
+			return ErrInvalidIllustrationOrder
+		}
+
+		before, after := illustrationOrderAuditState(positions, imageIDs)
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_REORDER, AUDIT_ENTITY_CHARACTER, characterID, before, after); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return fmt.Errorf("ReorderIllustrations transaction was failed : %w", txErr)
+	}
+
+	return nil
+}
+
+// ReorderIllustrations は子カテゴリに紐づくイラストの表示順を、指定されたIDの順に更新する
+// 指定されなかったイラストは表示順を解除し、指定されたイラストの後に新しい順で並べる
+func (s *CategoryService) ReorderIllustrations(ctx context.Context, childCategoryID int64, imageIDs []int64) error {
+	if _, err := s.store.GetChildCategory(ctx, childCategoryID); err != nil {
+		return fmt.Errorf("failed to GetChildCategory : %w", err)
+	}
+	if hasDuplicateIDs(imageIDs) {
+		return ErrInvalidIllustrationOrder
+	}
+
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		relations, err := q.ListImageChildCategoryRelationsByChildCategoryID(ctx, childCategoryID)
+		if err != nil {
+			return fmt.Errorf("failed to ListImageChildCategoryRelationsByChildCategoryID : %w", err)
+		}
+		positions := make([]positionedRelation, len(relations))
+		for i, r := range relations {
+			positions[i] = positionedRelation{ImageID: r.ImageID, Position: r.Position}
+		}
+
+		if err := q.ClearImageChildCategoryPositions(ctx, childCategoryID); err != nil {
+			return fmt.Errorf("failed to ClearImageChildCategoryPositions : %w", err)
+		}
+
+		updated, err := q.SetImageChildCategoryPositions(ctx, db.SetImageChildCategoryPositionsParams{
+			ChildCategoryID: childCategoryID,
+			ImageIds:        append([]int64{}, imageIDs...),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to SetImageChildCategoryPositions : %w", err)
+		}
+		// 子カテゴリに紐づいていないイラストが含まれる場合は更新件数が一致しない
+		if updated != int64(len(imageIDs)) {
+			return ErrInvalidIllustrationOrder
+		}
+
+		before, after := illustrationOrderAuditState(positions, imageIDs)
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_REORDER, AUDIT_ENTITY_CHILD_CATEGORY, childCategoryID, before, after); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return fmt.Errorf("ReorderIllustrations transaction was failed : %w", txErr)
+	}
+
+	return nil
+}
+
+// positionedRelation はイラストとの関連ごとの表示順
+type positionedRelation struct {
+	ImageID  int64
+	Position sql.NullInt32
+}
+
+// illustrationOrderAuditState は監査ログに記録する並び替え前後のイラストの表示順を返す
+// 並び替え前は表示順が指定されていたイラストのみを表示順に並べる
+func illustrationOrderAuditState(before []positionedRelation, after []int64) (map[string][]int64, map[string][]int64) {
+	positioned := []positionedRelation{}
+	for _, r := range before {
+		if r.Position.Valid {
+			positioned = append(positioned, r)
+		}
+	}
+	slices.SortStableFunc(positioned, func(a, b positionedRelation) int {
+		return cmp.Compare(a.Position.Int32, b.Position.Int32)
+	})
+
+	beforeIDs := make([]int64, len(positioned))
+	for i, r := range positioned {
+		beforeIDs[i] = r.ImageID
+	}
+
+	return map[string][]int64{"illustration_order": beforeIDs},
+		map[string][]int64{"illustration_order": append([]int64{}, after...)}
+}
+
+func hasDuplicateIDs(ids []int64) bool {
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return true
+		}
+		seen[id] = true
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+)
+
+var ErrInvalidPriorityOrder = errors.New("order must contain every id exactly once")
+
+// Reorder は指定されたIDの順にキャラクターのpriority_levelを振り直す
+// 先頭のキャラクターほどpriority_levelが大きくなり、一覧の上位に表示される
+// 並び順の一部だけが更新されないよう、全てのキャラクターのIDを指定する必要がある
+func (s *CharacterService) Reorder(ctx context.Context, ids []int64) error {
+	if err := validatePriorityOrder(ids); err != nil {
+		return err
+	}
+
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		total, err := q.CountCharacters(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to CountCharacters : %w", err)
+		}
+		if total != int64(len(ids)) {
+			return ErrInvalidPriorityOrder
+		}
+
+		characters, err := q.ListAllCharacters(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to ListAllCharacters : %w", err)
+		}
+		beforeIDs := make([]int64, len(characters))
+		for i, c := range characters {
+			beforeIDs[i] = c.ID
+		}
+
+		updated, err := q.ReorderCharacters(ctx, append([]int64{}, ids...))
+		if err != nil {
+			return fmt.Errorf("failed to ReorderCharacters : %w", err)
+		}
+		// 存在しないIDが含まれる場合は更新件数が一致しない
+		if updated != total {
+			return ErrInvalidPriorityOrder
+		}
+
+		if err := recordPriorityOrderAuditLog(ctx, q, AUDIT_ENTITY_CHARACTER, beforeIDs, ids); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return fmt.Errorf("Reorder transaction was failed : %w", txErr)
+	}
+
+	return nil
+}
+
+// ReorderParams はカテゴリの並び替え条件
+// 指定されなかった方の並び順は変更しない
+type ReorderParams struct {
+	ParentCategoryIDs []int64
+	ChildCategoryIDs  []int64
+}
+
+// Reorder は指定されたIDの順に親カテゴリ・子カテゴリのpriority_levelを振り直す
+// 子カテゴリは親カテゴリごとに表示されるため、異なる親カテゴリの子カテゴリ間の順序は表示に影響しない
+func (s *CategoryService) Reorder(ctx context.Context, arg ReorderParams) error {
+	if len(arg.ParentCategoryIDs) == 0 && len(arg.ChildCategoryIDs) == 0 {
+		return ErrInvalidPriorityOrder
+	}
+	if err := validatePriorityOrder(arg.ParentCategoryIDs); err != nil {
+		return err
+	}
+	if err := validatePriorityOrder(arg.ChildCategoryIDs); err != nil {
+		return err
+	}
+
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		if len(arg.ParentCategoryIDs) > 0 {
+			total, err := q.CountParentCategories(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to CountParentCategories : %w", err)
+			}
+			if total != int64(len(arg.ParentCategoryIDs)) {
+				return ErrInvalidPriorityOrder
+			}
+
+			pcates, err := q.ListAllParentCategories(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to ListAllParentCategories : %w", err)
+			}
+			beforeIDs := make([]int64, len(pcates))
+			for i, c := range pcates {
+				beforeIDs[i] = c.ID
+			}
+
+			updated, err := q.ReorderParentCategories(ctx, append([]int64{}, arg.ParentCategoryIDs...))
+			if err != nil {
+				return fmt.Errorf("failed to ReorderParentCategories : %w", err)
+			}
+			if updated != total {
+				return ErrInvalidPriorityOrder
+			}
+
+			if err := recordPriorityOrderAuditLog(ctx, q, AUDIT_ENTITY_PARENT_CATEGORY, beforeIDs, arg.ParentCategoryIDs); err != nil {
+				return err
+			}
+		}
+
+		if len(arg.ChildCategoryIDs) > 0 {
+			total, err := q.CountChildCategories(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to CountChildCategories : %w", err)
+			}
+			if total != int64(len(arg.ChildCategoryIDs)) {
+				return ErrInvalidPriorityOrder
+			}
+
+			ccates, err := q.ListChildCategories(ctx, db.ListChildCategoriesParams{
+				Limit:  int32(total),
+				Offset: 0,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to ListChildCategories : %w", err)
+			}
+			beforeIDs := make([]int64, len(ccates))
+			for i, c := range ccates {
+				beforeIDs[i] = c.ID
+			}
+
+			updated, err := q.ReorderChildCategories(ctx, append([]int64{}, arg.ChildCategoryIDs...))
+			if err != nil {
+				return fmt.Errorf("failed to ReorderChildCategories : %w", err)
+			}
+			if updated != total {
+				return ErrInvalidPriorityOrder
+			}
+
+			if err := recordPriorityOrderAuditLog(ctx, q, AUDIT_ENTITY_CHILD_CATEGORY, beforeIDs, arg.ChildCategoryIDs); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return fmt.Errorf("Reorder transaction was failed : %w", txErr)
+	}
+
+	return nil
+}
+
+// validatePriorityOrder はIDが重複しておらず、priority_level(smallint)で表現できる件数であることを確認する
+func validatePriorityOrder(ids []int64) error {
+	if len(ids) > math.MaxInt16 || hasDuplicateIDs(ids) {
+		return ErrInvalidPriorityOrder
+	}
+	return nil
+}
+
+// recordPriorityOrderAuditLog は一括の並び替え前後の表示順を監査ログに記録する
+// 特定の1件に対する操作ではないため、操作対象のIDは空にする
+func recordPriorityOrderAuditLog(ctx context.Context, q db.Querier, entityType string, before, after []int64) error {
+	err := recordAuditLog(ctx, q, AUDIT_ACTION_REORDER, entityType, "",
+		map[string][]int64{"order": before},
+		map[string][]int64{"order": append([]int64{}, after...)},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to recordAuditLog : %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+)
+
+// SampleParams はイラストをランダムに抽出する条件
+type SampleParams struct {
+	// 同じseedであれば同じ並び順となるため、offsetを変えてページングできる
+	Seed             int64
+	ExclusionIDs     []int64
+	CharacterIDs     []int64
+	ChildCategoryIDs []int64
+	// trueの場合は関連するキャラクター・子カテゴリのpriority_levelが高いイラストほど選ばれやすくする
+	Weighted bool
+	Limit    int32
+	Offset   int32
+}
+
+// weightedSampleCandidates は重み付きで抽出する際に、起点から辿って候補とするイラストの件数
+// 同じseedのページングで並び順が変わらないよう、limit・offsetによらず一定とする
+const weightedSampleCandidates = 500
+
+// NewSampleSeed はSampleに指定するseedを生成する
+func NewSampleSeed() int64 {
+	return rand.Int63()
+}
+
+// Sample はイラストをランダムに抽出する
+// 各イラストに予め割り振ったrandom_keyを、seedから決めた起点から順に辿ることで、全件のソートを避けて抽出する
+// Weightedの場合は起点から辿った最大weightedSampleCandidates件の候補の中から重み付きで抽出する
+func (s *IllustrationService) Sample(ctx context.Context, arg SampleParams) ([]db.Image, error) {
+	pivot := rand.New(rand.NewSource(arg.Seed)).Float64()
+
+	// nilのスライスはNULLとして渡されるため、空のスライスに揃える
+	exclusionIDs := append([]int64{}, arg.ExclusionIDs...)
+	characterIDs := append([]int64{}, arg.CharacterIDs...)
+	childCategoryIDs := append([]int64{}, arg.ChildCategoryIDs...)
+
+	if arg.Weighted {
+		images, err := s.store.SampleImagesWeighted(ctx, db.SampleImagesWeightedParams{
+			Pivot:            pivot,
+			ExclusionIds:     exclusionIDs,
+			CharacterIds:     characterIDs,
+			ChildCategoryIds: childCategoryIDs,
+			CandidateCount:   weightedSampleCandidates,
+			MaxResults:       arg.Limit,
+			Skip:             arg.Offset,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to SampleImagesWeighted : %w", err)
+		}
+
+		return images, nil
+	}
+
+	images, err := s.store.SampleImagesFrom(ctx, db.SampleImagesFromParams{
+		Pivot:            pivot,
+		ExclusionIds:     exclusionIDs,
+		CharacterIds:     characterIDs,
+		ChildCategoryIds: childCategoryIDs,
+		MaxResults:       arg.Limit,
+		Skip:             arg.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to SampleImagesFrom : %w", err)
+	}
+	if len(images) >= int(arg.Limit) {
+		return images, nil
+	}
+
+	// 末尾まで辿った場合は、先頭から起点の手前までを続けて取得する
+	offset := int32(0)
+	if len(images) == 0 {
+		count, err := s.store.CountSampleImagesFrom(ctx, db.CountSampleImagesFromParams{
+			Pivot:            pivot,
+			ExclusionIds:     exclusionIDs,
+			CharacterIds:     characterIDs,
+			ChildCategoryIds: childCategoryIDs,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to CountSampleImagesFrom : %w", err)
+		}
+		offset = max(arg.Offset-int32(count), 0)
+	}
+
+	rest, err := s.store.SampleImagesBefore(ctx, db.SampleImagesBeforeParams{
+		Pivot:            pivot,
+		ExclusionIds:     exclusionIDs,
+		CharacterIds:     characterIDs,
+		ChildCategoryIds: childCategoryIDs,
+		MaxResults:       arg.Limit - int32(len(images)),
+		Skip:             offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to SampleImagesBefore : %w", err)
+	}
+
+	return append(images, rest...), nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+)
+
+// 関連イラストのスコアに加算する、共通するキャラクター・カテゴリ1件あたりの重み
+const (
+	relatedCharacterWeight      = 3
+	relatedChildCategoryWeight  = 2
+	relatedParentCategoryWeight = 1
+)
+
+// Related はIDに紐づくイラストと関連するイラストを取得する
+// 共通するキャラクター・子カテゴリ・親カテゴリが多いものほど上位とし、同じスコアのものはランダムに並べる
+// 関連するイラストが上限に満たない場合は、ランダムに選んだイラストで補う
+func (s *IllustrationService) Related(ctx context.Context, id int64, limit int32) ([]db.Image, error) {
+	if _, err := s.store.GetImage(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to GetImage : %w", err)
+	}
+
+	images, err := s.store.ListRelatedImages(ctx, db.ListRelatedImagesParams{
+		ImageID:              id,
+		CharacterWeight:      relatedCharacterWeight,
+		ChildCategoryWeight:  relatedChildCategoryWeight,
+		ParentCategoryWeight: relatedParentCategoryWeight,
+		MaxResults:           limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListRelatedImages : %w", err)
+	}
+	if len(images) >= int(limit) {
+		return images, nil
+	}
+
+	// 関連イラストと重複する可能性があるため、不足分より多めに取得する
+	randoms, err := s.Sample(ctx, SampleParams{
+		Seed:         NewSampleSeed(),
+		ExclusionIDs: []int64{id},
+		Limit:        limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]bool, len(images))
+	for _, image := range images {
+		seen[image.ID] = true
+	}
+	for _, image := range randoms {
+		if len(images) >= int(limit) {
+			break
+		}
+		if seen[image.ID] {
+			continue
+		}
+		seen[image.ID] = true
+		images = append(images, image)
+	}
+
+	return images, nil
+}
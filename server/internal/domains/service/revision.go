@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+	model "shin-monta-no-mori/internal/domains/models"
+)
+
+const (
+	REVISION_RELATION_CHARACTERS        = "characters"
+	REVISION_RELATION_PARENT_CATEGORIES = "parent_categories"
+	REVISION_RELATION_CHILD_CATEGORIES  = "child_categories"
+)
+
+// RevisionDiff は2つの編集履歴の間で変更された項目
+type RevisionDiff struct {
+	FromRevisionID int64 `json:"from_revision_id"`
+	// 0の場合は現在のイラストとの差分
+	ToRevisionID int64            `json:"to_revision_id"`
+	Fields       []FieldChange    `json:"fields"`
+	Relations    []RelationChange `json:"relations"`
+}
+
+// FieldChange はイラストの項目ごとの変更前後の値
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// RelationChange はキャラクター・カテゴリとの紐づけの追加・削除
+type RelationChange struct {
+	Relation string  `json:"relation"`
+	Added    []int64 `json:"added"`
+	Removed  []int64 `json:"removed"`
+}
+
+// ListRevisions はイラストの編集履歴を新しい順に取得する
+func (s *IllustrationService) ListRevisions(ctx context.Context, imageID int64, limit, offset int32) ([]db.ImageRevision, int64, error) {
+	if _, err := s.store.GetImage(ctx, imageID); err != nil {
+		return nil, 0, fmt.Errorf("failed to GetImage : %w", err)
+	}
+
+	revisions, err := s.store.ListImageRevisions(ctx, db.ListImageRevisionsParams{
+		ImageID: imageID,
+		Limit:   limit,
+		Offset:  offset,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to ListImageRevisions : %w", err)
+	}
+
+	total, err := s.store.CountImageRevisions(ctx, imageID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to CountImageRevisions : %w", err)
+	}
+
+	return revisions, total, nil
+}
+
+// DiffRevisions はfromの編集履歴からtoの編集履歴までの変更点を返す
+// toが0の場合は現在のイラストとの差分を返す
+func (s *IllustrationService) DiffRevisions(ctx context.Context, imageID, fromID, toID int64) (*RevisionDiff, error) {
+	from, err := s.store.GetImageRevision(ctx, db.GetImageRevisionParams{ID: fromID, ImageID: imageID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetImageRevision : %w", err)
+	}
+
+	var to db.ImageRevision
+	if toID == 0 {
+		image, err := s.store.GetImage(ctx, imageID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to GetImage : %w", err)
+		}
+		to, err = snapshotImage(ctx, s.store, image)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshotImage : %w", err)
+		}
+	} else {
+		to, err = s.store.GetImageRevision(ctx, db.GetImageRevisionParams{ID: toID, ImageID: imageID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to GetImageRevision : %w", err)
+		}
+	}
+
+	return diffRevisions(from, to), nil
+}
+
+type RollbackIllustrationParams struct {
+	RevisionID int64
+	// 編集履歴に記録するオペレーター名
+	OperatorName string
+	// クライアントが取得した時点のイラストの更新日時
+	Version time.Time
+}
+
+// Rollback はイラストのタイトルと関連情報を指定された編集履歴の状態に戻す
+// 画像ファイルは差し替え時に削除されているため、ファイル名と画像は現在のものを維持する
+// 編集履歴の作成後にゴミ箱へ移動・削除されたキャラクター・カテゴリは紐づけない
+// ロールバック前の状態も編集履歴として保存するため、ロールバック自体を取り消すこともできる
+// 取得した時点から他の操作で更新されている場合はErrVersionMismatchを返す
+func (s *IllustrationService) Rollback(ctx context.Context, imageID int64, arg RollbackIllustrationParams) (*model.Illustration, error) {
+	var image db.Image
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		revision, err := q.GetImageRevision(ctx, db.GetImageRevisionParams{ID: arg.RevisionID, ImageID: imageID})
+		if err != nil {
+			return fmt.Errorf("failed to GetImageRevision : %w", err)
+		}
+
+		image, err = q.GetImageForUpdate(ctx, imageID)
+		if err != nil {
+			return fmt.Errorf("failed to GetImageForUpdate : %w", err)
+		}
+		if err := checkVersion(image.UpdatedAt, arg.Version); err != nil {
+			return err
+		}
+
+		current, err := createImageRevision(ctx, q, image, arg.OperatorName)
+		if err != nil {
+			return fmt.Errorf("failed to createImageRevision : %w", err)
+		}
+
+		image, err = q.UpdateImage(ctx, db.UpdateImageParams{
+			ID:                image.ID,
+			Title:             revision.Title,
+			OriginalSrc:       image.OriginalSrc,
+			SimpleSrc:         image.SimpleSrc,
+			OriginalFilename:  image.OriginalFilename,
+			SimpleFilename:    image.SimpleFilename,
+			UpdatedAt:         time.Now(),
+			ExpectedUpdatedAt: image.UpdatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to UpdateImage : %w", versionError(err))
+		}
+
+		characterIDs, err := q.ListExistingCharacterIDs(ctx, append([]int64{}, revision.CharacterIds...))
+		if err != nil {
+			return fmt.Errorf("failed to ListExistingCharacterIDs : %w", err)
+		}
+		if err := UpdateImageCharacterRelationsIDs(ctx, q, image.ID, characterIDs); err != nil {
+			return fmt.Errorf("failed to UpdateImageCharacterRelationsIDs : %w", err)
+		}
+
+		parentCategoryIDs, err := q.ListExistingParentCategoryIDs(ctx, append([]int64{}, revision.ParentCategoryIds...))
+		if err != nil {
+			return fmt.Errorf("failed to ListExistingParentCategoryIDs : %w", err)
+		}
+		if err := UpdateImageParentCategoryRelationsIDs(ctx, q, image.ID, parentCategoryIDs); err != nil {
+			return fmt.Errorf("failed to UpdateImageParentCategoryRelationsIDs : %w", err)
+		}
+
+		childCategoryIDs, err := q.ListExistingChildCategoryIDs(ctx, append([]int64{}, revision.ChildCategoryIds...))
+		if err != nil {
+			return fmt.Errorf("failed to ListExistingChildCategoryIDs : %w", err)
+		}
+		if err := UpdateImageChildCategoryRelationsIDs(ctx, q, image.ID, childCategoryIDs); err != nil {
+			return fmt.Errorf("failed to UpdateImageChildCategoryRelationsIDs : %w", err)
+		}
+
+		if err := RefreshSearchDocument(ctx, q, image.ID); err != nil {
+			return fmt.Errorf("failed to RefreshSearchDocument : %w", err)
+		}
+
+		before := illustrationAuditStateOf(current)
+		if err := recordIllustrationAuditLog(ctx, q, AUDIT_ACTION_ROLLBACK, image.ID, &before, &image); err != nil {
+			return fmt.Errorf("failed to recordIllustrationAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return nil, fmt.Errorf("Rollback transaction was failed : %w", txErr)
+	}
+
+	return FetchRelationInfoForIllustrations(ctx, s.store, image)
+}
+
+// createImageRevision はイラストの現在の状態を編集履歴として保存する
+func createImageRevision(ctx context.Context, q db.Querier, image db.Image, operatorName string) (db.ImageRevision, error) {
+	snapshot, err := snapshotImage(ctx, q, image)
+	if err != nil {
+		return db.ImageRevision{}, err
+	}
+
+	return q.CreateImageRevision(ctx, db.CreateImageRevisionParams{
+		ImageID:           snapshot.ImageID,
+		Title:             snapshot.Title,
+		OriginalSrc:       snapshot.OriginalSrc,
+		SimpleSrc:         snapshot.SimpleSrc,
+		OriginalFilename:  snapshot.OriginalFilename,
+		SimpleFilename:    snapshot.SimpleFilename,
+		CharacterIds:      snapshot.CharacterIds,
+		ParentCategoryIds: snapshot.ParentCategoryIds,
+		ChildCategoryIds:  snapshot.ChildCategoryIds,
+		OperatorName:      operatorName,
+	})
+}
+
+// snapshotImage はイラストと紐づくキャラクター・カテゴリのIDを編集履歴と同じ形式にまとめる
+func snapshotImage(ctx context.Context, q db.Querier, image db.Image) (db.ImageRevision, error) {
+	characterRelations, err := q.ListImageCharacterRelationsByImageID(ctx, image.ID)
+	if err != nil {
+		return db.ImageRevision{}, fmt.Errorf("failed to ListImageCharacterRelationsByImageID : %w", err)
+	}
+	characterIDs := make([]int64, 0, len(characterRelations))
+	for _, rel := range characterRelations {
+		characterIDs = append(characterIDs, rel.CharacterID)
+	}
+
+	parentCategoryRelations, err := q.ListImageParentCategoryRelationsByImageID(ctx, image.ID)
+	if err != nil {
+		return db.ImageRevision{}, fmt.Errorf("failed to ListImageParentCategoryRelationsByImageID : %w", err)
+	}
+	parentCategoryIDs := make([]int64, 0, len(parentCategoryRelations))
+	for _, rel := range parentCategoryRelations {
+		parentCategoryIDs = append(parentCategoryIDs, rel.ParentCategoryID)
+	}
+
+	childCategoryRelations, err := q.ListImageChildCategoryRelationsByImageID(ctx, image.ID)
+	if err != nil {
+		return db.ImageRevision{}, fmt.Errorf("failed to ListImageChildCategoryRelationsByImageID : %w", err)
+	}
+	childCategoryIDs := make([]int64, 0, len(childCategoryRelations))
+	for _, rel := range childCategoryRelations {
+		childCategoryIDs = append(childCategoryIDs, rel.ChildCategoryID)
+	}
+
+	slices.Sort(characterIDs)
+	slices.Sort(parentCategoryIDs)
+	slices.Sort(childCategoryIDs)
+
+	return db.ImageRevision{
+		ImageID:           image.ID,
+		Title:             image.Title,
+		OriginalSrc:       image.OriginalSrc,
+		SimpleSrc:         image.SimpleSrc,
+		OriginalFilename:  image.OriginalFilename,
+		SimpleFilename:    image.SimpleFilename,
+		CharacterIds:      characterIDs,
+		ParentCategoryIds: parentCategoryIDs,
+		ChildCategoryIds:  childCategoryIDs,
+	}, nil
+}
+
+// diffRevisions は2つの編集履歴の差分を計算する
+func diffRevisions(from, to db.ImageRevision) *RevisionDiff {
+	diff := &RevisionDiff{
+		FromRevisionID: from.ID,
+		ToRevisionID:   to.ID,
+		Fields:         []FieldChange{},
+		Relations:      []RelationChange{},
+	}
+
+	fields := []FieldChange{
+		{Field: "title", From: from.Title, To: to.Title},
+		{Field: "original_filename", From: from.OriginalFilename, To: to.OriginalFilename},
+		{Field: "original_src", From: from.OriginalSrc, To: to.OriginalSrc},
+		{Field: "simple_filename", From: from.SimpleFilename.String, To: to.SimpleFilename.String},
+		{Field: "simple_src", From: from.SimpleSrc.String, To: to.SimpleSrc.String},
+	}
+	for _, field := range fields {
+		if field.From != field.To {
+			diff.Fields = append(diff.Fields, field)
+		}
+	}
+
+	relations := []struct {
+		name     string
+		from, to []int64
+	}{
+		{REVISION_RELATION_CHARACTERS, from.CharacterIds, to.CharacterIds},
+		{REVISION_RELATION_PARENT_CATEGORIES, from.ParentCategoryIds, to.ParentCategoryIds},
+		{REVISION_RELATION_CHILD_CATEGORIES, from.ChildCategoryIds, to.ChildCategoryIds},
+	}
+	for _, relation := range relations {
+		added := subtractIDs(relation.to, relation.from)
+		removed := subtractIDs(relation.from, relation.to)
+		if len(added) > 0 || len(removed) > 0 {
+			diff.Relations = append(diff.Relations, RelationChange{
+				Relation: relation.name,
+				Added:    added,
+				Removed:  removed,
+			})
+		}
+	}
+
+	return diff
+}
+
+// subtractIDs はaに含まれ、bに含まれないIDを昇順で返す
+func subtractIDs(a, b []int64) []int64 {
+	exclude := make(map[int64]bool, len(b))
+	for _, id := range b {
+		exclude[id] = true
+	}
+
+	result := []int64{}
+	for _, id := range a {
+		if !exclude[id] {
+			result = append(result, id)
+		}
+	}
+	slices.Sort(result)
+	return result
+}
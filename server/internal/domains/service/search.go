@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"shin-monta-no-mori/internal/cache"
+	db "shin-monta-no-mori/internal/db/sqlc"
+	model "shin-monta-no-mori/internal/domains/models"
+	"shin-monta-no-mori/pkg/lib/cursor"
+	"shin-monta-no-mori/pkg/lib/logger"
+	"shin-monta-no-mori/pkg/lib/textsearch"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// SearchParams はイラストの全文検索の条件
+type SearchParams struct {
+	Query string
+	// 指定された場合は関連度ではなく、指定された並び順で取得する
+	Sort   string
+	Limit  int32
+	Offset int32
+	// 指定された場合はOffsetより優先し、キーセットページネーションで取得する
+	Cursor *cursor.Cursor
+}
+
+// SearchImagesResult はイラストの全文検索の結果
+type SearchImagesResult struct {
+	Images     []db.Image
+	NextCursor string
+	// 検索に一致したイラストの件数。1ページ目（OffsetとCursorの指定なし）の取得時のみ数え、それ以外は0の場合がある
+	TotalCount int64
+}
+
+type rankedImage struct {
+	image db.Image
+	rank  float32
+}
+
+// SearchImages はタイトル、ファイル名、キャラクター名、カテゴリ名を対象に全文検索し、関連度の高い順にイラストを取得する
+// 検索語は正規化し、同義語が登録されている場合は展開する
+// 並び順が指定された場合は、検索に一致するイラストをその順に取得する
+// 検索できる語が含まれない場合は、全てのイラストを新しい順（並び順が指定された場合はその順）に取得する
+func (s *IllustrationService) SearchImages(ctx context.Context, arg SearchParams) (*SearchImagesResult, error) {
+	if arg.Sort != "" {
+		return s.listSearchImages(ctx, arg)
+	}
+
+	query, err := tsQuery(ctx, s.synonyms, arg.Query)
+	if err != nil {
+		return nil, err
+	}
+	if query == "" {
+		return s.listSearchImages(ctx, arg)
+	}
+
+	// 並び順を指定した一覧のカーソルは、関連度順では使用できない
+	if arg.Cursor != nil && arg.Cursor.Sort != "" {
+		return nil, fmt.Errorf("%w : cursor is not for relevance", cursor.ErrInvalidCursor)
+	}
+
+	ranked := []rankedImage{}
+	var total int64
+	if arg.Cursor != nil {
+		rows, err := s.store.SearchImagesByCursor(ctx, db.SearchImagesByCursorParams{
+			Limit:      arg.Limit,
+			Query:      query,
+			CursorRank: arg.Cursor.Rank,
+			CursorID:   arg.Cursor.ID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to SearchImagesByCursor : %w", err)
+		}
+		for _, row := range rows {
+			ranked = append(ranked, rankedImage{image: row.Image, rank: row.Rank})
+		}
+	} else {
+		rows, err := s.store.SearchImages(ctx, db.SearchImagesParams{
+			Limit:  arg.Limit,
+			Offset: arg.Offset,
+			Query:  query,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to SearchImages : %w", err)
+		}
+		for _, row := range rows {
+			ranked = append(ranked, rankedImage{image: row.Image, rank: row.Rank})
+			total = row.TotalCount
+		}
+	}
+
+	images := make([]db.Image, len(ranked))
+	for i, r := range ranked {
+		images[i] = r.image
+	}
+
+	return &SearchImagesResult{
+		Images: images,
+		NextCursor: cursor.Next(ranked, int(arg.Limit), func(r rankedImage) cursor.Cursor {
+			return cursor.Cursor{ID: r.image.ID, Rank: r.rank}
+		}),
+		TotalCount: total,
+	}, nil
+}
+
+func (s *IllustrationService) listSearchImages(ctx context.Context, arg SearchParams) (*SearchImagesResult, error) {
+	result, err := s.ListImages(ctx, ListImagesParams{
+		Sort:   arg.Sort,
+		Query:  arg.Query,
+		Limit:  arg.Limit,
+		Offset: arg.Offset,
+		Cursor: arg.Cursor,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 並び順ごとのクエリはインデックスを使用して必要な件数のみ取得するため、1ページ目のみ件数を別に数える
+	// 1ページ目に全件が収まる場合は、取得した件数をそのまま使う
+	var total int64
+	if arg.Cursor == nil && arg.Offset == 0 {
+		total = int64(len(result.Images))
+		if result.NextCursor != "" {
+			total, err = countSearchImages(ctx, s.store, s.synonyms, arg.Query)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &SearchImagesResult{
+		Images:     result.Images,
+		NextCursor: result.NextCursor,
+		TotalCount: total,
+	}, nil
+}
+
+// Search は全文検索に一致するイラストを関連情報と合わせて取得する
+// 次ページ取得用のカーソルも合わせて返す
+func (s *IllustrationService) Search(ctx context.Context, arg SearchParams) ([]*model.Illustration, string, error) {
+	result, err := s.SearchImages(ctx, arg)
+	if err != nil {
+		return nil, "", err
+	}
+
+	illustrations, err := s.fetchRelations(ctx, result.Images)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return illustrations, result.NextCursor, nil
+}
+
+// CountSearch は全文検索に一致するイラストの件数を取得する
+func (s *IllustrationService) CountSearch(ctx context.Context, q string) (int64, error) {
+	return countSearchImages(ctx, s.store, s.synonyms, q)
+}
+
+func countSearchImages(ctx context.Context, q db.Querier, dict *SynonymDictionary, query string) (int64, error) {
+	tsq, err := tsQuery(ctx, dict, query)
+	if err != nil {
+		return 0, err
+	}
+
+	if tsq == "" {
+		count, err := q.CountImages(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to CountImages : %w", err)
+		}
+		return count, nil
+	}
+
+	count, err := q.CountSearchImages(ctx, tsq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to CountSearchImages : %w", err)
+	}
+
+	return count, nil
+}
+
+// IndexMissingSearchDocuments は検索用ドキュメントが作成されていないイラストをインデックスし、その件数を返す
+func (s *IllustrationService) IndexMissingSearchDocuments(ctx context.Context) (int, error) {
+	imageIDs, err := s.store.ListImageIDsWithoutSearchDocument(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to ListImageIDsWithoutSearchDocument : %w", err)
+	}
+
+	if err := RefreshSearchDocuments(ctx, s.store, imageIDs); err != nil {
+		return 0, err
+	}
+
+	return len(imageIDs), nil
+}
+
+// RefreshSearchDocument はイラストのタイトル、ファイル名、関連するキャラクター名・カテゴリ名から検索用ドキュメントを作り直す
+func RefreshSearchDocument(ctx context.Context, q db.Querier, imageID int64) error {
+	image, err := q.GetImage(ctx, imageID)
+	if err != nil {
+		return fmt.Errorf("failed to GetImage (image_id: %d) : %w", imageID, err)
+	}
+
+	il, err := FetchRelationInfoForIllustrations(ctx, q, image)
+	if err != nil {
+		return err
+	}
+
+	names := []string{}
+	for _, c := range il.Characters {
+		names = append(names, c.Character.Name)
+	}
+	for _, cate := range il.Categories {
+		names = append(names, cate.ParentCategory.Name)
+		for _, ccate := range cate.ChildCategory {
+			names = append(names, ccate.Name)
+		}
+	}
+
+	err = q.UpsertImageSearchDocument(ctx, db.UpsertImageSearchDocumentParams{
+		ImageID: image.ID,
+		SearchVector: textsearch.Vector(
+			textsearch.Section{Text: image.Title, Weight: textsearch.WeightA},
+			textsearch.Section{Text: strings.Join(names, " "), Weight: textsearch.WeightB},
+			textsearch.Section{Text: image.OriginalFilename, Weight: textsearch.WeightC},
+		),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to UpsertImageSearchDocument (image_id: %d) : %w", image.ID, err)
+	}
+
+	return nil
+}
+
+// RefreshSearchDocuments は複数のイラストの検索用ドキュメントを作り直す
+func RefreshSearchDocuments(ctx context.Context, q db.Querier, imageIDs []int64) error {
+	for _, id := range imageIDs {
+		if err := RefreshSearchDocument(ctx, q, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SynonymDictionary は検索語の展開に使用する同義語辞書を取得する
+// 検索のたびに同義語を全件取得しないよう、辞書はRedisにキャッシュする
+// 同義語を更新した場合は、cache.SynonymsKeyのキャッシュを削除する
+type SynonymDictionary struct {
+	store  db.Querier
+	redis  cache.RedisClient
+	logger logger.Logger
+}
+
+func NewSynonymDictionary(store db.Querier, redis cache.RedisClient, logger logger.Logger) *SynonymDictionary {
+	return &SynonymDictionary{
+		store:  store,
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+// Load は同義語辞書をキャッシュから取得し、キャッシュがない場合はDBから作成してキャッシュする
+// Redisに接続できない場合も検索できるよう、Redisのエラーはログに出力してDBから取得する
+func (d *SynonymDictionary) Load(ctx context.Context) (textsearch.Synonyms, error) {
+	synonyms := textsearch.Synonyms{}
+	err := d.redis.Get(ctx, cache.SynonymsKey, &synonyms)
+	if err == nil {
+		return synonyms, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		d.logger.Warn("failed redis data get", zap.String("redis_key", cache.SynonymsKey), zap.Error(err))
+	}
+
+	rows, err := d.store.ListAllSynonyms(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListAllSynonyms : %w", err)
+	}
+
+	synonyms = textsearch.Synonyms{}
+	for _, row := range rows {
+		synonyms.Add(row.Word, row.Synonym)
+	}
+
+	err = d.redis.Set(ctx, cache.SynonymsKey, synonyms, cache.CacheDurationDay)
+	if err != nil {
+		d.logger.Warn("failed redis data set", zap.String("redis_key", cache.SynonymsKey), zap.Error(err))
+	}
+
+	return synonyms, nil
+}
+
+// tsQuery は検索文字列を同義語で展開し、tsqueryの形式に変換する
+func tsQuery(ctx context.Context, dict *SynonymDictionary, query string) (string, error) {
+	synonyms, err := dict.Load(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return textsearch.Query(query, synonyms), nil
+}
+
+// likePatterns は検索文字列を正規化・同義語で展開し、normalize_search_textと比較する部分一致のパターンに変換する
+// 検索文字列に含まれる%や_は、ワイルドカードではなく文字として扱う
+func likePatterns(ctx context.Context, dict *SynonymDictionary, query string) ([]string, error) {
+	synonyms, err := dict.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	words := synonyms.Expand(strings.TrimSpace(query))
+	patterns := make([]string, len(words))
+	for i, w := range words {
+		patterns[i] = "%" + escapeLike(w) + "%"
+	}
+
+	return patterns, nil
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/pkg/lib/logger"
+	"shin-monta-no-mori/pkg/lib/textsearch"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// 書き込みを待つ検索ログの最大数。超えた分は破棄する
+	searchLogBufferSize = 1024
+	// 1件の検索ログの書き込みにかける時間の上限
+	searchLogWriteTimeout = 5 * time.Second
+)
+
+type searchLogEntry struct {
+	query       string
+	resultCount int64
+	searchedAt  time.Time
+}
+
+// SearchLogService は公開APIでの検索語を記録・集計するサービス
+// 検索のレスポンスを遅らせないよう、記録はバックグラウンドで行う
+// 個人を特定できる情報は記録しない
+type SearchLogService struct {
+	store   *db.Store
+	logger  logger.Logger
+	entries chan searchLogEntry
+}
+
+// NewSearchLogService は検索ログを書き込むgoroutineを起動し、サービスを作成する
+func NewSearchLogService(store *db.Store, logger logger.Logger) *SearchLogService {
+	s := &SearchLogService{
+		store:   store,
+		logger:  logger,
+		entries: make(chan searchLogEntry, searchLogBufferSize),
+	}
+	go s.run()
+
+	return s
+}
+
+// Record は検索語と検索時の結果の件数を記録する
+// 書き込みはバックグラウンドで行うため、呼び出し元をブロックしない
+func (s *SearchLogService) Record(query string, resultCount int64) {
+	if normalizeSearchLogQuery(query) == "" {
+		return
+	}
+
+	select {
+	case s.entries <- searchLogEntry{query: query, resultCount: resultCount, searchedAt: time.Now()}:
+	default:
+		s.logger.Warn("search log buffer is full, dropped search log", zap.String("query", query))
+	}
+}
+
+func (s *SearchLogService) run() {
+	for entry := range s.entries {
+		if err := s.write(entry); err != nil {
+			s.logger.Warn("failed to write search log", zap.String("query", entry.query), zap.Error(err))
+		}
+	}
+}
+
+func (s *SearchLogService) write(entry searchLogEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), searchLogWriteTimeout)
+	defer cancel()
+
+	err := s.store.CreateSearchLog(ctx, db.CreateSearchLogParams{
+		Query:       normalizeSearchLogQuery(entry.query),
+		ResultCount: entry.resultCount,
+		SearchedAt:  entry.searchedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to CreateSearchLog : %w", err)
+	}
+
+	return nil
+}
+
+// normalizeSearchLogQuery は表記揺れや空白の違いを同じ検索語として集計できるよう正規化する
+func normalizeSearchLogQuery(query string) string {
+	return strings.Join(strings.Fields(textsearch.Normalize(query)), " ")
+}
+
+// SearchQueryStat は検索語ごとの集計
+type SearchQueryStat struct {
+	Query          string    `json:"query"`
+	Count          int64     `json:"count"`
+	LastSearchedAt time.Time `json:"last_searched_at"`
+}
+
+// SearchTrend は日ごとの検索数
+type SearchTrend struct {
+	// 日本時間の日付（YYYY-MM-DD）
+	Date            string `json:"date"`
+	Count           int64  `json:"count"`
+	ZeroResultCount int64  `json:"zero_result_count"`
+}
+
+// ListTopQueries は期間内に検索された回数が多い検索語を取得する
+func (s *SearchLogService) ListTopQueries(ctx context.Context, from, to time.Time, limit int32) ([]SearchQueryStat, error) {
+	rows, err := s.store.ListTopSearchQueries(ctx, db.ListTopSearchQueriesParams{
+		FromTime:   from,
+		ToTime:     to,
+		MaxResults: limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListTopSearchQueries : %w", err)
+	}
+
+	stats := make([]SearchQueryStat, len(rows))
+	for i, row := range rows {
+		stats[i] = SearchQueryStat{Query: row.Query, Count: row.Count, LastSearchedAt: row.LastSearchedAt}
+	}
+
+	return stats, nil
+}
+
+// ListZeroResultQueries は期間内に検索結果が0件だった検索語を、検索された回数が多い順に取得する
+func (s *SearchLogService) ListZeroResultQueries(ctx context.Context, from, to time.Time, limit int32) ([]SearchQueryStat, error) {
+	rows, err := s.store.ListZeroResultSearchQueries(ctx, db.ListZeroResultSearchQueriesParams{
+		FromTime:   from,
+		ToTime:     to,
+		MaxResults: limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListZeroResultSearchQueries : %w", err)
+	}
+
+	stats := make([]SearchQueryStat, len(rows))
+	for i, row := range rows {
+		stats[i] = SearchQueryStat{Query: row.Query, Count: row.Count, LastSearchedAt: row.LastSearchedAt}
+	}
+
+	return stats, nil
+}
+
+// ListTrends は期間内の日ごとの検索数を取得する
+// 検索語を指定した場合は、その検索語の検索数のみを集計する
+func (s *SearchLogService) ListTrends(ctx context.Context, from, to time.Time, query string) ([]SearchTrend, error) {
+	rows, err := s.store.ListSearchTrends(ctx, db.ListSearchTrendsParams{
+		FromTime: from,
+		ToTime:   to,
+		Query:    normalizeSearchLogQuery(query),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListSearchTrends : %w", err)
+	}
+
+	trends := make([]SearchTrend, len(rows))
+	for i, row := range rows {
+		trends[i] = SearchTrend{Date: row.Date.Format(time.DateOnly), Count: row.Count, ZeroResultCount: row.ZeroResultCount}
+	}
+
+	return trends, nil
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+	model "shin-monta-no-mori/internal/domains/models"
+	"shin-monta-no-mori/pkg/lib/cursor"
+)
+
+// イラスト一覧の並び順
+const (
+	ILLUSTRATION_SORT_NEWEST  = "newest"
+	ILLUSTRATION_SORT_OLDEST  = "oldest"
+	ILLUSTRATION_SORT_TITLE   = "title"
+	ILLUSTRATION_SORT_UPDATED = "updated"
+	ILLUSTRATION_SORT_POPULAR = "popular"
+	// キャラクター・子カテゴリごとに管理者が指定した表示順
+	// 表示順が指定されていないイラストは、その後に新しい順で並べる
+	ILLUSTRATION_SORT_MANUAL = "manual"
+)
+
+// ListImagesParams はイラスト一覧の取得条件
+type ListImagesParams struct {
+	// 空の場合は新しい順に並べる
+	Sort string
+	// 指定された場合は全文検索に一致するイラストに絞り込む
+	Query string
+	// 0以外が指定された場合は、キャラクター・子カテゴリに紐づくイラストに絞り込む
+	CharacterID     int64
+	ChildCategoryID int64
+	Limit           int32
+	Offset          int32
+	// 指定された場合はOffsetより優先し、キーセットページネーションで取得する
+	Cursor *cursor.Cursor
+}
+
+// ListImagesResult はイラスト一覧の取得結果
+type ListImagesResult struct {
+	Images     []db.Image
+	NextCursor string
+}
+
+// ListImages は指定された並び順でイラストを取得する
+// 並び順ごとにインデックスを使用できるよう、それぞれ別のクエリで取得する
+func (s *IllustrationService) ListImages(ctx context.Context, arg ListImagesParams) (*ListImagesResult, error) {
+	sort := arg.Sort
+	if sort == "" {
+		sort = ILLUSTRATION_SORT_NEWEST
+	}
+
+	// 別の並び順で発行されたカーソルは、起点の値の意味が異なるため使用できない
+	var c cursor.Cursor
+	useCursor := arg.Cursor != nil
+	if useCursor {
+		c = *arg.Cursor
+		cursorSort := c.Sort
+		if cursorSort == "" && c.Rank == 0 {
+			cursorSort = ILLUSTRATION_SORT_NEWEST
+		}
+		if cursorSort != sort {
+			return nil, fmt.Errorf("%w : cursor is not for sort '%s'", cursor.ErrInvalidCursor, sort)
+		}
+		// カーソルの次から取得するため、ページ番号から求めたOffsetは使用しない
+		arg.Offset = 0
+	}
+
+	if sort == ILLUSTRATION_SORT_MANUAL {
+		return s.listImagesByPosition(ctx, arg, useCursor, c)
+	}
+
+	query, err := tsQuery(ctx, s.synonyms, arg.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []db.Image
+	switch sort {
+	case ILLUSTRATION_SORT_NEWEST:
+		images, err = s.store.ListImagesOrderByNewest(ctx, db.ListImagesOrderByNewestParams{
+			Query:           query,
+			CharacterID:     arg.CharacterID,
+			ChildCategoryID: arg.ChildCategoryID,
+			UseCursor:       useCursor,
+			CursorID:        c.ID,
+			MaxResults:      arg.Limit,
+			Skip:            arg.Offset,
+		})
+	case ILLUSTRATION_SORT_OLDEST:
+		images, err = s.store.ListImagesOrderByOldest(ctx, db.ListImagesOrderByOldestParams{
+			Query:           query,
+			CharacterID:     arg.CharacterID,
+			ChildCategoryID: arg.ChildCategoryID,
+			UseCursor:       useCursor,
+			CursorID:        c.ID,
+			MaxResults:      arg.Limit,
+			Skip:            arg.Offset,
+		})
+	case ILLUSTRATION_SORT_TITLE:
+		images, err = s.store.ListImagesOrderByTitle(ctx, db.ListImagesOrderByTitleParams{
+			Query:           query,
+			CharacterID:     arg.CharacterID,
+			ChildCategoryID: arg.ChildCategoryID,
+			UseCursor:       useCursor,
+			CursorTitle:     c.Title,
+			CursorID:        c.ID,
+			MaxResults:      arg.Limit,
+			Skip:            arg.Offset,
+		})
+	case ILLUSTRATION_SORT_UPDATED:
+		images, err = s.store.ListImagesOrderByUpdated(ctx, db.ListImagesOrderByUpdatedParams{
+			Query:           query,
+			CharacterID:     arg.CharacterID,
+			ChildCategoryID: arg.ChildCategoryID,
+			UseCursor:       useCursor,
+			CursorUpdatedAt: time.UnixMicro(c.UpdatedAt),
+			CursorID:        c.ID,
+			MaxResults:      arg.Limit,
+			Skip:            arg.Offset,
+		})
+	case ILLUSTRATION_SORT_POPULAR:
+		images, err = s.store.ListImagesOrderByPopular(ctx, db.ListImagesOrderByPopularParams{
+			Query:           query,
+			CharacterID:     arg.CharacterID,
+			ChildCategoryID: arg.ChildCategoryID,
+			UseCursor:       useCursor,
+			CursorViewCount: c.ViewCount,
+			CursorID:        c.ID,
+			MaxResults:      arg.Limit,
+			Skip:            arg.Offset,
+		})
+	default:
+		return nil, fmt.Errorf("unknown sort '%s'", sort)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListImages (sort: %s) : %w", sort, err)
+	}
+
+	return &ListImagesResult{
+		Images: images,
+		NextCursor: cursor.Next(images, int(arg.Limit), func(image db.Image) cursor.Cursor {
+			return sortCursor(sort, image)
+		}),
+	}, nil
+}
+
+// listImagesByPosition はキャラクター・子カテゴリごとの表示順でイラストを取得する
+// 表示順は関連テーブルに保持しているため、キャラクター・子カテゴリのどちらか一方の指定が必要となる
+func (s *IllustrationService) listImagesByPosition(ctx context.Context, arg ListImagesParams, useCursor bool, c cursor.Cursor) (*ListImagesResult, error) {
+	if arg.Query != "" {
+		return nil, fmt.Errorf("sort '%s' cannot be used with query", ILLUSTRATION_SORT_MANUAL)
+	}
+
+	var items []positionedImage
+	switch {
+	case arg.CharacterID != 0 && arg.ChildCategoryID == 0:
+		rows, err := s.store.ListImagesByCharacterOrderByPosition(ctx, db.ListImagesByCharacterOrderByPositionParams{
+			CharacterID:    arg.CharacterID,
+			UseCursor:      useCursor,
+			CursorPosition: c.Position,
+			CursorID:       c.ID,
+			MaxResults:     arg.Limit,
+			Skip:           arg.Offset,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to ListImagesByCharacterOrderByPosition : %w", err)
+		}
+		for _, row := range rows {
+			items = append(items, positionedImage{image: row.Image, position: row.Position})
+		}
+	case arg.ChildCategoryID != 0 && arg.CharacterID == 0:
+		rows, err := s.store.ListImagesByChildCategoryOrderByPosition(ctx, db.ListImagesByChildCategoryOrderByPositionParams{
+			ChildCategoryID: arg.ChildCategoryID,
+			UseCursor:       useCursor,
+			CursorPosition:  c.Position,
+			CursorID:        c.ID,
+			MaxResults:      arg.Limit,
+			Skip:            arg.Offset,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to ListImagesByChildCategoryOrderByPosition : %w", err)
+		}
+		for _, row := range rows {
+			items = append(items, positionedImage{image: row.Image, position: row.Position})
+		}
+	default:
+		return nil, fmt.Errorf("sort '%s' requires either character_id or child_category_id", ILLUSTRATION_SORT_MANUAL)
+	}
+
+	images := make([]db.Image, 0, len(items))
+	for _, item := range items {
+		images = append(images, item.image)
+	}
+
+	return &ListImagesResult{
+		Images: images,
+		NextCursor: cursor.Next(items, int(arg.Limit), func(item positionedImage) cursor.Cursor {
+			return cursor.Cursor{ID: item.image.ID, Sort: ILLUSTRATION_SORT_MANUAL, Position: item.position}
+		}),
+	}, nil
+}
+
+// positionedImage は表示順と合わせて取得したイラスト
+type positionedImage struct {
+	image    db.Image
+	position int32
+}
+
+// List は指定された並び順でイラストを関連情報と合わせて取得する
+// 次ページ取得用のカーソルも合わせて返す
+func (s *IllustrationService) List(ctx context.Context, arg ListImagesParams) ([]*model.Illustration, string, error) {
+	result, err := s.ListImages(ctx, arg)
+	if err != nil {
+		return nil, "", err
+	}
+
+	illustrations, err := s.fetchRelations(ctx, result.Images)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return illustrations, result.NextCursor, nil
+}
+
+// sortCursor は並び順に応じて、イラストを起点とするカーソルを生成する
+// 新しい順の場合は、並び順を指定しない一覧と同じくIDのみとする
+func sortCursor(sort string, image db.Image) cursor.Cursor {
+	switch sort {
+	case ILLUSTRATION_SORT_OLDEST:
+		return cursor.Cursor{ID: image.ID, Sort: sort}
+	case ILLUSTRATION_SORT_TITLE:
+		return cursor.Cursor{ID: image.ID, Sort: sort, Title: image.Title}
+	case ILLUSTRATION_SORT_UPDATED:
+		return cursor.Cursor{ID: image.ID, Sort: sort, UpdatedAt: image.UpdatedAt.UnixMicro()}
+	case ILLUSTRATION_SORT_POPULAR:
+		return cursor.Cursor{ID: image.ID, Sort: sort, ViewCount: image.ViewCount}
+	default:
+		return cursor.Cursor{ID: image.ID}
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/pkg/lib/textsearch"
+)
+
+// 検索候補の種類
+const (
+	SUGGESTION_KIND_CHARACTER       = "character"
+	SUGGESTION_KIND_PARENT_CATEGORY = "parent_category"
+	SUGGESTION_KIND_CHILD_CATEGORY  = "child_category"
+	SUGGESTION_KIND_TITLE           = "title"
+)
+
+// Suggestion は検索ボックスに表示する検索候補
+type Suggestion struct {
+	Text string `json:"text"`
+	Kind string `json:"kind"`
+}
+
+// Suggest はイラストのタイトル、キャラクター名、カテゴリ名から、正規化した入力に前方一致する検索候補を取得する
+// 完全一致するもの、関連するイラストが多いもの、短いものの順に並べ、同じ表記の候補は1つにまとめる
+func (s *IllustrationService) Suggest(ctx context.Context, prefix string, limit int32) ([]Suggestion, error) {
+	suggestions := []Suggestion{}
+
+	normalized := textsearch.Normalize(strings.TrimSpace(prefix))
+	if normalized == "" {
+		return suggestions, nil
+	}
+
+	// 種類の異なる同じ表記の候補を除いても件数が足りるよう、多めに取得する
+	rows, err := s.store.SuggestSearchTerms(ctx, db.SuggestSearchTermsParams{
+		Pattern:    escapeLike(normalized) + "%",
+		Normalized: normalized,
+		MaxResults: limit * 2,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to SuggestSearchTerms : %w", err)
+	}
+
+	seen := map[string]bool{}
+	for _, row := range rows {
+		if seen[row.Term] {
+			continue
+		}
+		seen[row.Term] = true
+
+		suggestions = append(suggestions, Suggestion{Text: row.Term, Kind: row.Kind})
+		if len(suggestions) == int(limit) {
+			break
+		}
+	}
+
+	return suggestions, nil
+}
+
+// escapeLike はLIKEのパターンで特別な意味を持つ文字をエスケープする
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
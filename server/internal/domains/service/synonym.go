@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+
+	"github.com/lib/pq"
+)
+
+// ErrSynonymAlreadyExists は同じ語と同義語の組み合わせが既に登録されている場合のエラー
+var ErrSynonymAlreadyExists = errors.New("synonym already exists")
+
+// SynonymService は検索語の展開に使用する同義語に関するユースケースをまとめたサービス
+type SynonymService struct {
+	store *db.Store
+}
+
+func NewSynonymService(store *db.Store) *SynonymService {
+	return &SynonymService{
+		store: store,
+	}
+}
+
+// Get は同義語を取得する
+func (s *SynonymService) Get(ctx context.Context, id int64) (db.Synonym, error) {
+	synonym, err := s.store.GetSynonym(ctx, id)
+	if err != nil {
+		return db.Synonym{}, fmt.Errorf("failed to GetSynonym : %w", err)
+	}
+
+	return synonym, nil
+}
+
+// ListAll は全ての同義語を語の順に取得する
+func (s *SynonymService) ListAll(ctx context.Context) ([]db.Synonym, error) {
+	synonyms, err := s.store.ListAllSynonyms(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListAllSynonyms : %w", err)
+	}
+
+	return synonyms, nil
+}
+
+type SynonymParams struct {
+	Word    string
+	Synonym string
+}
+
+// Create は同義語を作成する
+func (s *SynonymService) Create(ctx context.Context, arg SynonymParams) (db.Synonym, error) {
+	var synonym db.Synonym
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		synonym, err = q.CreateSynonym(ctx, db.CreateSynonymParams{
+			Word:    arg.Word,
+			Synonym: arg.Synonym,
+		})
+		if err != nil {
+			if isUniqueViolation(err) {
+				return ErrSynonymAlreadyExists
+			}
+			return fmt.Errorf("failed to CreateSynonym : %w", err)
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_CREATE, AUDIT_ENTITY_SYNONYM, synonym.ID, nil, synonym); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return db.Synonym{}, fmt.Errorf("CreateSynonym transaction was failed : %w", txErr)
+	}
+
+	return synonym, nil
+}
+
+// Edit は同義語を更新する
+func (s *SynonymService) Edit(ctx context.Context, id int64, arg SynonymParams) (db.Synonym, error) {
+	var synonym db.Synonym
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		before, err := q.GetSynonym(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to GetSynonym : %w", err)
+		}
+
+		synonym, err = q.UpdateSynonym(ctx, db.UpdateSynonymParams{
+			ID:        before.ID,
+			Word:      arg.Word,
+			Synonym:   arg.Synonym,
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			if isUniqueViolation(err) {
+				return ErrSynonymAlreadyExists
+			}
+			return fmt.Errorf("failed to UpdateSynonym : %w", err)
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_UPDATE, AUDIT_ENTITY_SYNONYM, synonym.ID, before, synonym); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return db.Synonym{}, fmt.Errorf("EditSynonym transaction was failed : %w", txErr)
+	}
+
+	return synonym, nil
+}
+
+// Delete は同義語を削除する
+func (s *SynonymService) Delete(ctx context.Context, id int64) error {
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		synonym, err := q.GetSynonym(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to GetSynonym : %w", err)
+		}
+
+		if err := q.DeleteSynonym(ctx, synonym.ID); err != nil {
+			return fmt.Errorf("failed to DeleteSynonym : %w", err)
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_DELETE, AUDIT_ENTITY_SYNONYM, synonym.ID, synonym, nil); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return fmt.Errorf("DeleteSynonym transaction was failed : %w", txErr)
+	}
+
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -0,0 +1,404 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/pkg/lib/logger"
+
+	"go.uber.org/zap"
+)
+
+// ゴミ箱の種類
+const (
+	TRASH_TYPE_ILLUSTRATIONS = "illustrations"
+	TRASH_TYPE_CHARACTERS    = "characters"
+	TRASH_TYPE_CATEGORIES    = "categories"
+)
+
+const (
+	// 1回のPurgeで種類ごとに完全に削除する最大数
+	trashPurgeBatchSize = 100
+	// 1回のPurgeにかける時間の上限
+	trashPurgeTimeout = 5 * time.Minute
+)
+
+var ErrUnknownTrashType = errors.New("unknown trash type")
+
+// TrashRetention はゴミ箱の保持日数を期間に変換する
+func TrashRetention(days int) time.Duration {
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// TrashItem はゴミ箱に移動されたイラスト・キャラクター・親カテゴリ
+type TrashItem struct {
+	ID int64 `json:"id"`
+	// イラストの場合はタイトル
+	Name string `json:"name"`
+	// イラストの場合はオリジナル画像
+	Src       string    `json:"src"`
+	DeletedAt time.Time `json:"deleted_at"`
+	// 保持期間を過ぎ、完全に削除される日時
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+// TrashService はゴミ箱からの復元と、保持期間を過ぎたデータの完全な削除をまとめたサービス
+type TrashService struct {
+	store   *db.Store
+	storage StorageService
+	logger  logger.Logger
+}
+
+func NewTrashService(store *db.Store, storage StorageService, logger logger.Logger) *TrashService {
+	return &TrashService{
+		store:   store,
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// List はゴミ箱に移動された日時が新しい順に取得する
+// 合わせて種類ごとの総数も返す
+func (s *TrashService) List(ctx context.Context, trashType string, limit, offset int32, retention time.Duration) ([]TrashItem, int64, error) {
+	items := []TrashItem{}
+	var total int64
+
+	switch trashType {
+	case TRASH_TYPE_ILLUSTRATIONS:
+		images, err := s.store.ListDeletedImages(ctx, db.ListDeletedImagesParams{Limit: limit, Offset: offset})
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to ListDeletedImages : %w", err)
+		}
+		for _, image := range images {
+			items = append(items, newTrashItem(image.ID, image.Title, image.OriginalSrc, image.DeletedAt.Time, retention))
+		}
+		total, err = s.store.CountDeletedImages(ctx)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to CountDeletedImages : %w", err)
+		}
+	case TRASH_TYPE_CHARACTERS:
+		characters, err := s.store.ListDeletedCharacters(ctx, db.ListDeletedCharactersParams{Limit: limit, Offset: offset})
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to ListDeletedCharacters : %w", err)
+		}
+		for _, character := range characters {
+			items = append(items, newTrashItem(character.ID, character.Name, character.Src, character.DeletedAt.Time, retention))
+		}
+		total, err = s.store.CountDeletedCharacters(ctx)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to CountDeletedCharacters : %w", err)
+		}
+	case TRASH_TYPE_CATEGORIES:
+		pcates, err := s.store.ListDeletedParentCategories(ctx, db.ListDeletedParentCategoriesParams{Limit: limit, Offset: offset})
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to ListDeletedParentCategories : %w", err)
+		}
+		for _, pcate := range pcates {
+			items = append(items, newTrashItem(pcate.ID, pcate.Name, pcate.Src, pcate.DeletedAt.Time, retention))
+		}
+		total, err = s.store.CountDeletedParentCategories(ctx)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to CountDeletedParentCategories : %w", err)
+		}
+	default:
+		return nil, 0, fmt.Errorf("%w : %s", ErrUnknownTrashType, trashType)
+	}
+
+	return items, total, nil
+}
+
+func newTrashItem(id int64, name, src string, deletedAt time.Time, retention time.Duration) TrashItem {
+	return TrashItem{
+		ID:        id,
+		Name:      name,
+		Src:       src,
+		DeletedAt: deletedAt,
+		PurgeAt:   deletedAt.Add(retention),
+	}
+}
+
+// Restore はゴミ箱から元に戻す
+// イラストとの関連は削除時のまま残しているため、検索用ドキュメントを作り直すことで元の状態に戻る
+// ゴミ箱にない場合はsql.ErrNoRowsを返す
+func (s *TrashService) Restore(ctx context.Context, trashType string, id int64) error {
+	var restore func(ctx context.Context, q *db.Queries, id int64) (any, []int64, error)
+	var entityType string
+	switch trashType {
+	case TRASH_TYPE_ILLUSTRATIONS:
+		restore = restoreImage
+		entityType = AUDIT_ENTITY_ILLUSTRATION
+	case TRASH_TYPE_CHARACTERS:
+		restore = restoreCharacter
+		entityType = AUDIT_ENTITY_CHARACTER
+	case TRASH_TYPE_CATEGORIES:
+		restore = restoreParentCategory
+		entityType = AUDIT_ENTITY_PARENT_CATEGORY
+	default:
+		return fmt.Errorf("%w : %s", ErrUnknownTrashType, trashType)
+	}
+
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		restored, imageIDs, err := restore(ctx, q, id)
+		if err != nil {
+			return err
+		}
+
+		if err := RefreshSearchDocuments(ctx, q, imageIDs); err != nil {
+			return fmt.Errorf("failed to RefreshSearchDocuments : %w", err)
+		}
+
+		if err := recordAuditLog(ctx, q, AUDIT_ACTION_RESTORE, entityType, id, nil, restored); err != nil {
+			return fmt.Errorf("failed to recordAuditLog : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return fmt.Errorf("Restore transaction was failed : %w", txErr)
+	}
+
+	return nil
+}
+
+// restoreImage はイラストを復元し、監査ログに記録する復元後の状態と、検索用ドキュメントを作り直すイラストのIDを返す
+func restoreImage(ctx context.Context, q *db.Queries, id int64) (any, []int64, error) {
+	image, err := q.RestoreImage(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to RestoreImage : %w", err)
+	}
+
+	snapshot, err := snapshotImage(ctx, q, image)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to snapshotImage : %w", err)
+	}
+
+	return illustrationAuditStateOf(snapshot), []int64{image.ID}, nil
+}
+
+// restoreCharacter はキャラクターを復元し、監査ログに記録する復元後の状態と、検索用ドキュメントを作り直すイラストのIDを返す
+func restoreCharacter(ctx context.Context, q *db.Queries, id int64) (any, []int64, error) {
+	character, err := q.RestoreCharacter(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to RestoreCharacter : %w", err)
+	}
+
+	relations, err := q.ListImageCharacterRelationsByCharacterID(ctx, character.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to ListImageCharacterRelationsByCharacterID : %w", err)
+	}
+	imageIDs := make([]int64, len(relations))
+	for i, r := range relations {
+		imageIDs[i] = r.ImageID
+	}
+
+	return character, imageIDs, nil
+}
+
+// restoreParentCategory は親カテゴリと、合わせてゴミ箱に移動した子カテゴリを復元し、監査ログに記録する復元後の状態と、検索用ドキュメントを作り直すイラストのIDを返す
+func restoreParentCategory(ctx context.Context, q *db.Queries, id int64) (any, []int64, error) {
+	pcate, err := q.GetDeletedParentCategoryForUpdate(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to GetDeletedParentCategoryForUpdate : %w", err)
+	}
+
+	err = q.RestoreChildCategoriesByParentID(ctx, db.RestoreChildCategoriesByParentIDParams{
+		ParentID:  pcate.ID,
+		DeletedAt: pcate.DeletedAt.Time,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to RestoreChildCategoriesByParentID : %w", err)
+	}
+
+	restored, err := q.RestoreParentCategory(ctx, pcate.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to RestoreParentCategory : %w", err)
+	}
+
+	imageIDs, err := parentCategoryImageIDs(ctx, q, pcate.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return restored, imageIDs, nil
+}
+
+// StartPurgeJob は保持期間を過ぎたデータを定期的に完全に削除するgoroutineを起動する
+// ctxがキャンセルされると停止する
+func (s *TrashService) StartPurgeJob(ctx context.Context, retention, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			s.runPurge(ctx, retention)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *TrashService) runPurge(ctx context.Context, retention time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, trashPurgeTimeout)
+	defer cancel()
+
+	purged, err := s.Purge(ctx, time.Now().Add(-retention))
+	if err != nil {
+		s.logger.Warn("failed to purge trash", zap.Int("purged", purged), zap.Error(err))
+		return
+	}
+	if purged > 0 {
+		s.logger.Info("purged trash", zap.Int("purged", purged))
+	}
+}
+
+// Purge はbeforeより前にゴミ箱に移動されたデータを、関連とアップロード済みの画像も含めて完全に削除する
+// 完全に削除した件数を返す
+func (s *TrashService) Purge(ctx context.Context, before time.Time) (int, error) {
+	purged := 0
+
+	images, err := s.store.ListPurgeableImages(ctx, db.ListPurgeableImagesParams{
+		DeletedBefore: before,
+		MaxResults:    trashPurgeBatchSize,
+	})
+	if err != nil {
+		return purged, fmt.Errorf("failed to ListPurgeableImages : %w", err)
+	}
+	for _, image := range images {
+		if err := s.purgeImage(ctx, image); err != nil {
+			return purged, fmt.Errorf("failed to purge image (image_id: %d) : %w", image.ID, err)
+		}
+		purged++
+	}
+
+	characters, err := s.store.ListPurgeableCharacters(ctx, db.ListPurgeableCharactersParams{
+		DeletedBefore: before,
+		MaxResults:    trashPurgeBatchSize,
+	})
+	if err != nil {
+		return purged, fmt.Errorf("failed to ListPurgeableCharacters : %w", err)
+	}
+	for _, character := range characters {
+		if err := s.purgeCharacter(ctx, character); err != nil {
+			return purged, fmt.Errorf("failed to purge character (character_id: %d) : %w", character.ID, err)
+		}
+		purged++
+	}
+
+	pcates, err := s.store.ListPurgeableParentCategories(ctx, db.ListPurgeableParentCategoriesParams{
+		DeletedBefore: before,
+		MaxResults:    trashPurgeBatchSize,
+	})
+	if err != nil {
+		return purged, fmt.Errorf("failed to ListPurgeableParentCategories : %w", err)
+	}
+	for _, pcate := range pcates {
+		if err := s.purgeParentCategory(ctx, pcate); err != nil {
+			return purged, fmt.Errorf("failed to purge parent category (parent_category_id: %d) : %w", pcate.ID, err)
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// purgeImage はイラストと関連情報を削除した後、アップロード済みの画像を削除する
+// 画像の削除に失敗してもデータは元に戻せないため、画像の削除はトランザクションの完了後に行う
+func (s *TrashService) purgeImage(ctx context.Context, image db.Image) error {
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		if err := q.DeleteAllImageChildCategoryRelationsByImageID(ctx, image.ID); err != nil {
+			return fmt.Errorf("failed to DeleteAllImageChildCategoryRelationsByImageID : %w", err)
+		}
+		if err := q.DeleteAllImageParentCategoryRelationsByImageID(ctx, image.ID); err != nil {
+			return fmt.Errorf("failed to DeleteAllImageParentCategoryRelationsByImageID : %w", err)
+		}
+		if err := q.DeleteAllImageCharacterRelationsByImageID(ctx, image.ID); err != nil {
+			return fmt.Errorf("failed to DeleteAllImageCharacterRelationsByImageID : %w", err)
+		}
+		if err := q.DeleteImage(ctx, image.ID); err != nil {
+			return fmt.Errorf("failed to DeleteImage : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return fmt.Errorf("PurgeImage transaction was failed : %w", txErr)
+	}
+
+	if err := s.storage.DeleteFile(ctx, image.OriginalSrc); err != nil {
+		return fmt.Errorf("failed to DeleteImageSrc : %w", err)
+	}
+	if image.SimpleSrc.String != "" {
+		if err := s.storage.DeleteFile(ctx, image.SimpleSrc.String); err != nil {
+			return fmt.Errorf("failed to DeleteImageSrc for simple image : %w", err)
+		}
+	}
+
+	return nil
+}
+
+// purgeCharacter はキャラクターとイラストとの関連を削除した後、アップロード済みの画像を削除する
+func (s *TrashService) purgeCharacter(ctx context.Context, character db.Character) error {
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		if err := q.DeleteAllImageCharacterRelationsByCharacterID(ctx, character.ID); err != nil {
+			return fmt.Errorf("failed to DeleteAllImageCharacterRelationsByCharacterID : %w", err)
+		}
+		if err := q.DeleteCharacter(ctx, character.ID); err != nil {
+			return fmt.Errorf("failed to DeleteCharacter : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return fmt.Errorf("PurgeCharacter transaction was failed : %w", txErr)
+	}
+
+	if err := s.storage.DeleteFile(ctx, character.Src); err != nil {
+		return fmt.Errorf("failed to DeleteImageSrc : %w", err)
+	}
+
+	return nil
+}
+
+// purgeParentCategory は親カテゴリと配下の子カテゴリ、イラストとの関連を削除した後、アップロード済みの画像を削除する
+func (s *TrashService) purgeParentCategory(ctx context.Context, pcate db.ParentCategory) error {
+	txErr := s.store.ExecTx(ctx, func(q *db.Queries) error {
+		if err := q.DeleteAllImageParentCategoryRelationsByParentCategoryID(ctx, pcate.ID); err != nil {
+			return fmt.Errorf("failed to DeleteAllImageParentCategoryRelationsByParentCategoryID : %w", err)
+		}
+
+		ccateIDs, err := q.ListChildCategoryIDsByParentIDIncludingDeleted(ctx, pcate.ID)
+		if err != nil {
+			return fmt.Errorf("failed to ListChildCategoryIDsByParentIDIncludingDeleted : %w", err)
+		}
+		for _, ccateID := range ccateIDs {
+			if err := q.DeleteAllImageChildCategoryRelationsByChildCategoryID(ctx, ccateID); err != nil {
+				return fmt.Errorf("failed to DeleteAllImageChildCategoryRelationsByChildCategoryID (child_category_id: %d) : %w", ccateID, err)
+			}
+		}
+		if err := q.DeleteAllChildCategoriesByParentCategoryID(ctx, pcate.ID); err != nil {
+			return fmt.Errorf("failed to DeleteAllChildCategoriesByParentCategoryID : %w", err)
+		}
+
+		if err := q.DeleteParentCategory(ctx, pcate.ID); err != nil {
+			return fmt.Errorf("failed to DeleteParentCategory : %w", err)
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return fmt.Errorf("PurgeParentCategory transaction was failed : %w", txErr)
+	}
+
+	if err := s.storage.DeleteFile(ctx, pcate.Src); err != nil {
+		return fmt.Errorf("failed to DeleteImageSrc : %w", err)
+	}
+
+	return nil
+}
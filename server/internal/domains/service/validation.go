@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/pkg/lib/apperror"
+)
+
+// ValidationError は入力の形式は正しいが、登録済みのデータと矛盾している場合のエラー
+// 項目ごとのエラーをまとめて返し、クライアントがすべての問題を一度に修正できるようにする
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// FieldError は項目ごとのエラー
+type FieldError struct {
+	Field  string          `json:"field"`
+	Reason apperror.Reason `json:"reason"`
+	// ログに出力する内容。レスポンスのメッセージはReasonから生成する
+	Message string `json:"message"`
+	// 問題のあるIDがある場合は昇順で含める
+	IDs []int64 `json:"ids,omitempty"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		messages = append(messages, fe.Field+": "+fe.Message)
+	}
+	return "validation failed : " + strings.Join(messages, ", ")
+}
+
+// illustrationRelations はイラストに紐づけるキャラクター・カテゴリのID
+type illustrationRelations struct {
+	Characters       []int64
+	ParentCategories []int64
+	ChildCategories  []int64
+}
+
+// validateIllustration はイラストの登録・更新前に、紐づけるキャラクター・カテゴリとファイル名を検証する
+// 選択された子カテゴリの親カテゴリが選択されていない場合は、親カテゴリを追加した紐づけを返す
+// imageIDには更新対象のイラストのIDを指定する。作成時は0を指定する
+func validateIllustration(ctx context.Context, q db.Querier, imageID int64, filename string, relations illustrationRelations) (illustrationRelations, error) {
+	var fieldErrors []FieldError
+
+	characters := uniqueIDs(relations.Characters)
+	existingCharacters, err := q.ListExistingCharacterIDs(ctx, characters)
+	if err != nil {
+		return relations, fmt.Errorf("failed to ListExistingCharacterIDs : %w", err)
+	}
+	if missing := subtractIDs(characters, existingCharacters); len(missing) > 0 {
+		fieldErrors = append(fieldErrors, FieldError{Field: "characters", Reason: apperror.ReasonNotExist, Message: "characters do not exist", IDs: missing})
+	}
+
+	parentCategories := uniqueIDs(relations.ParentCategories)
+	existingParentCategories, err := q.ListExistingParentCategoryIDs(ctx, parentCategories)
+	if err != nil {
+		return relations, fmt.Errorf("failed to ListExistingParentCategoryIDs : %w", err)
+	}
+	if missing := subtractIDs(parentCategories, existingParentCategories); len(missing) > 0 {
+		fieldErrors = append(fieldErrors, FieldError{Field: "parent_categories", Reason: apperror.ReasonNotExist, Message: "parent categories do not exist", IDs: missing})
+	}
+
+	childCategories := uniqueIDs(relations.ChildCategories)
+	existingChildCategories, err := q.ListChildCategoriesByIDs(ctx, childCategories)
+	if err != nil {
+		return relations, fmt.Errorf("failed to ListChildCategoriesByIDs : %w", err)
+	}
+	existingChildCategoryIDs := make([]int64, 0, len(existingChildCategories))
+	for _, cc := range existingChildCategories {
+		existingChildCategoryIDs = append(existingChildCategoryIDs, cc.ID)
+		// 子カテゴリは親カテゴリごとにまとめて表示するため、親カテゴリも紐づける
+		if !slices.Contains(parentCategories, cc.ParentID) {
+			parentCategories = append(parentCategories, cc.ParentID)
+		}
+	}
+	if missing := subtractIDs(childCategories, existingChildCategoryIDs); len(missing) > 0 {
+		fieldErrors = append(fieldErrors, FieldError{Field: "child_categories", Reason: apperror.ReasonNotExist, Message: "child categories do not exist", IDs: missing})
+	}
+
+	if filename != "" {
+		count, err := q.CountImagesByOriginalFilename(ctx, db.CountImagesByOriginalFilenameParams{
+			OriginalFilename: filename,
+			ExcludeID:        imageID,
+		})
+		if err != nil {
+			return relations, fmt.Errorf("failed to CountImagesByOriginalFilename : %w", err)
+		}
+		if count > 0 {
+			fieldErrors = append(fieldErrors, FieldError{Field: "filename", Reason: apperror.ReasonAlreadyUsed, Message: "filename is already used by another illustration"})
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return relations, &ValidationError{Errors: fieldErrors}
+	}
+
+	return illustrationRelations{
+		Characters:       characters,
+		ParentCategories: parentCategories,
+		ChildCategories:  childCategories,
+	}, nil
+}
+
+// uniqueIDs は重複を取り除いたIDを指定された順序のまま返す
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	result := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
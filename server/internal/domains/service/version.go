@@ -0,0 +1,28 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrVersionMismatch は編集対象が取得した時点から他の操作で更新されている場合のエラー
+var ErrVersionMismatch = errors.New("resource has been modified by another operation")
+
+// checkVersion は編集対象の現在の更新日時と、クライアントが取得した時点の更新日時を比較する
+// 画像の差し替えなど取り消せない処理の前に呼び出し、古い内容での上書きを防ぐ
+func checkVersion(current, expected time.Time) error {
+	if !current.Equal(expected) {
+		return ErrVersionMismatch
+	}
+	return nil
+}
+
+// versionError は更新日時を条件にしたUPDATEのエラーを変換する
+// 対象の行が存在することは確認済みのため、更新された行がない場合は他の操作で更新されたものとみなす
+func versionError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrVersionMismatch
+	}
+	return err
+}
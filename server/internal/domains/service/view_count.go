@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	db "shin-monta-no-mori/internal/db/sqlc"
+	"shin-monta-no-mori/pkg/lib/logger"
+
+	"go.uber.org/zap"
+)
+
+// 1回の閲覧数の書き込みにかける時間の上限
+const viewCountFlushTimeout = 30 * time.Second
+
+// ViewCountService は人気順の並び替えに使用するイラストの閲覧数を数えるサービス
+// 閲覧のたびにDBを更新しないよう、閲覧数はメモリに溜めて定期的にまとめて書き込む
+type ViewCountService struct {
+	store  *db.Store
+	logger logger.Logger
+
+	mu     sync.Mutex
+	counts map[int64]int64
+}
+
+func NewViewCountService(store *db.Store, logger logger.Logger) *ViewCountService {
+	return &ViewCountService{
+		store:  store,
+		logger: logger,
+		counts: map[int64]int64{},
+	}
+}
+
+// Record はイラストの閲覧を1回数える
+// 書き込みはFlushで行うため、呼び出し元をブロックしない
+func (s *ViewCountService) Record(imageID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.counts[imageID]++
+}
+
+// Flush は溜めた閲覧数をDBに書き込む
+// ゴミ箱に移動されたイラストの閲覧数は書き込まない
+// 書き込みに失敗した場合は、次回のFlushで再度書き込めるよう閲覧数を戻す
+func (s *ViewCountService) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	counts := s.counts
+	s.counts = map[int64]int64{}
+	s.mu.Unlock()
+
+	if len(counts) == 0 {
+		return nil
+	}
+
+	arg := db.AddImageViewCountsParams{
+		Ids:    make([]int64, 0, len(counts)),
+		Counts: make([]int64, 0, len(counts)),
+	}
+	for id, count := range counts {
+		arg.Ids = append(arg.Ids, id)
+		arg.Counts = append(arg.Counts, count)
+	}
+
+	if _, err := s.store.AddImageViewCounts(ctx, arg); err != nil {
+		s.mu.Lock()
+		for id, count := range counts {
+			s.counts[id] += count
+		}
+		s.mu.Unlock()
+		return fmt.Errorf("failed to AddImageViewCounts : %w", err)
+	}
+
+	return nil
+}
+
+// StartFlushJob は溜めた閲覧数を定期的にDBに書き込むgoroutineを起動する
+// ctxがキャンセルされると、残りの閲覧数を書き込んで停止する
+func (s *ViewCountService) StartFlushJob(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				s.runFlush(context.Background())
+				return
+			case <-ticker.C:
+				s.runFlush(ctx)
+			}
+		}
+	}()
+}
+
+func (s *ViewCountService) runFlush(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, viewCountFlushTimeout)
+	defer cancel()
+
+	if err := s.Flush(ctx); err != nil {
+		s.logger.Warn("failed to flush view counts", zap.Error(err))
+	}
+}
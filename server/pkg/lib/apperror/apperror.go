@@ -0,0 +1,138 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Code はクライアントが処理を分岐するためのエラーコード
+// エラーコードごとにHTTPステータスと利用者向けのメッセージが決まる
+type Code string
+
+const (
+	BadRequest           Code = "BAD_REQUEST"
+	ValidationFailed     Code = "VALIDATION_FAILED"
+	Unauthorized         Code = "UNAUTHORIZED"
+	InvalidCredentials   Code = "INVALID_CREDENTIALS"
+	Forbidden            Code = "FORBIDDEN"
+	NotFound             Code = "NOT_FOUND"
+	Conflict             Code = "CONFLICT"
+	PreconditionFailed   Code = "PRECONDITION_FAILED"
+	PreconditionRequired Code = "PRECONDITION_REQUIRED"
+	TooManyRequests      Code = "TOO_MANY_REQUESTS"
+	Internal             Code = "INTERNAL"
+)
+
+// Status はエラーコードに対応するHTTPステータスを返す
+func (c Code) Status() int {
+	switch c {
+	case BadRequest:
+		return http.StatusBadRequest
+	case ValidationFailed:
+		return http.StatusUnprocessableEntity
+	case Unauthorized, InvalidCredentials:
+		return http.StatusUnauthorized
+	case Forbidden:
+		return http.StatusForbidden
+	case NotFound:
+		return http.StatusNotFound
+	case Conflict:
+		return http.StatusConflict
+	case PreconditionFailed:
+		return http.StatusPreconditionFailed
+	case PreconditionRequired:
+		return http.StatusPreconditionRequired
+	case TooManyRequests:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Detail は項目ごとのエラー
+type Detail struct {
+	Field  string `json:"field"`
+	Reason Reason `json:"reason"`
+	// レスポンスを生成する際にReasonから設定する
+	Message string `json:"message"`
+	// 問題のあるIDがある場合に含める
+	IDs []int64 `json:"ids,omitempty"`
+}
+
+// Error はエラーコードと内部のエラーをまとめたエラー
+// 内部のエラーはログにのみ出力し、レスポンスには含めない
+type Error struct {
+	Code    Code
+	Details []Detail
+	Err     error
+}
+
+func New(code Code, err error, details ...Detail) *Error {
+	return &Error{
+		Code:    code,
+		Details: details,
+		Err:     err,
+	}
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return string(e.Code)
+	}
+	return fmt.Sprintf("%s : %v", e.Code, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// As はerrに含まれるErrorを返す
+// Errorを含まない場合は、内部のエラーとして扱う
+func As(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return New(Internal, err)
+}
+
+// Response はエラーレスポンスの形式
+type Response struct {
+	Error Body `json:"error"`
+}
+
+type Body struct {
+	Code    Code     `json:"code"`
+	Message string   `json:"message"`
+	Details []Detail `json:"details,omitempty"`
+}
+
+// Response は利用者向けのメッセージを指定された言語で設定したレスポンスを返す
+func (e *Error) Response(lang Lang) Response {
+	var details []Detail
+	for _, d := range e.Details {
+		d.Message = lang.reasonMessage(d.Reason)
+		details = append(details, d)
+	}
+
+	return Response{
+		Error: Body{
+			Code:    e.Code,
+			Message: lang.codeMessage(e.Code),
+			Details: details,
+		},
+	}
+}
+
+// LangFromAcceptLanguage はAccept-Languageヘッダーからメッセージの言語を決める
+// 英語が最も優先されている場合のみ英語とし、それ以外は日本語とする
+func LangFromAcceptLanguage(header string) Lang {
+	first, _, _ := strings.Cut(header, ",")
+	first, _, _ = strings.Cut(first, ";")
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(first)), "en") {
+		return English
+	}
+	return Japanese
+}
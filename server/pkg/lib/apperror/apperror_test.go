@@ -0,0 +1,136 @@
+package apperror_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"shin-monta-no-mori/pkg/lib/apperror"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLangFromAcceptLanguage(t *testing.T) {
+	testCases := []struct {
+		name   string
+		header string
+		want   apperror.Lang
+	}{
+		{
+			name:   "正常系 (英語)",
+			header: "en-US,en;q=0.9,ja;q=0.8",
+			want:   apperror.English,
+		},
+		{
+			name:   "正常系 (日本語)",
+			header: "ja,en-US;q=0.9",
+			want:   apperror.Japanese,
+		},
+		{
+			name:   "正常系 (未指定の場合は日本語)",
+			header: "",
+			want:   apperror.Japanese,
+		},
+		{
+			name:   "正常系 (対応していない言語の場合は日本語)",
+			header: "fr-FR",
+			want:   apperror.Japanese,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, apperror.LangFromAcceptLanguage(tc.header))
+		})
+	}
+}
+
+func TestResponse(t *testing.T) {
+	cause := errors.New("pq: relation \"images\" does not exist")
+
+	testCases := []struct {
+		name       string
+		err        *apperror.Error
+		lang       apperror.Lang
+		wantStatus int
+		wantBody   apperror.Body
+	}{
+		{
+			name:       "正常系 (内部エラーの詳細を含めない)",
+			err:        apperror.New(apperror.Internal, cause),
+			lang:       apperror.Japanese,
+			wantStatus: http.StatusInternalServerError,
+			wantBody: apperror.Body{
+				Code:    apperror.Internal,
+				Message: "サーバーでエラーが発生しました",
+			},
+		},
+		{
+			name: "正常系 (項目ごとのエラーを含める)",
+			err: apperror.New(apperror.ValidationFailed, cause, apperror.Detail{
+				Field:  "characters",
+				Reason: apperror.ReasonNotExist,
+				IDs:    []int64{3},
+			}),
+			lang:       apperror.English,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody: apperror.Body{
+				Code:    apperror.ValidationFailed,
+				Message: "The input has errors",
+				Details: []apperror.Detail{
+					{
+						Field:   "characters",
+						Reason:  apperror.ReasonNotExist,
+						Message: "Some of the specified items do not exist",
+						IDs:     []int64{3},
+					},
+				},
+			},
+		},
+		{
+			name:       "正常系 (存在しない場合)",
+			err:        apperror.New(apperror.NotFound, cause),
+			lang:       apperror.English,
+			wantStatus: http.StatusNotFound,
+			wantBody: apperror.Body{
+				Code:    apperror.NotFound,
+				Message: "The requested resource was not found",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.wantStatus, tc.err.Code.Status())
+			require.Equal(t, tc.wantBody, tc.err.Response(tc.lang).Error)
+			require.ErrorIs(t, tc.err, cause)
+		})
+	}
+}
+
+func TestAs(t *testing.T) {
+	appErr := apperror.New(apperror.Conflict, errors.New("duplicated"))
+
+	testCases := []struct {
+		name string
+		err  error
+		want apperror.Code
+	}{
+		{
+			name: "正常系 (ラップされたエラー)",
+			err:  fmt.Errorf("failed to create : %w", appErr),
+			want: apperror.Conflict,
+		},
+		{
+			name: "正常系 (エラーコードを持たない場合は内部エラー)",
+			err:  errors.New("unexpected"),
+			want: apperror.Internal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, apperror.As(tc.err).Code)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package apperror
+
+// Lang はメッセージの言語
+type Lang string
+
+const (
+	Japanese Lang = "ja"
+	English  Lang = "en"
+)
+
+// Reason は項目ごとのエラーの理由
+type Reason string
+
+const (
+	ReasonNotExist    Reason = "not_exist"
+	ReasonAlreadyUsed Reason = "already_used"
+)
+
+var codeMessages = map[Code]map[Lang]string{
+	BadRequest: {
+		Japanese: "リクエストの内容が正しくありません",
+		English:  "The request is invalid",
+	},
+	ValidationFailed: {
+		Japanese: "入力内容に誤りがあります",
+		English:  "The input has errors",
+	},
+	Unauthorized: {
+		Japanese: "認証に失敗しました",
+		English:  "Authentication failed",
+	},
+	InvalidCredentials: {
+		Japanese: "メールアドレスまたはパスワードが正しくありません",
+		English:  "The email or password is incorrect",
+	},
+	Forbidden: {
+		Japanese: "この操作を行う権限がありません",
+		English:  "You do not have permission to perform this operation",
+	},
+	NotFound: {
+		Japanese: "指定されたデータが見つかりません",
+		English:  "The requested resource was not found",
+	},
+	Conflict: {
+		Japanese: "既に登録されているデータと重複しています",
+		English:  "The request conflicts with existing data",
+	},
+	PreconditionFailed: {
+		Japanese: "他の操作で更新されています。最新の内容を確認してください",
+		English:  "The resource was modified by another operation. Please check the latest content",
+	},
+	PreconditionRequired: {
+		Japanese: "If-Matchヘッダーが必要です",
+		English:  "The If-Match header is required",
+	},
+	TooManyRequests: {
+		Japanese: "リクエストが多すぎます。しばらく待ってから再度お試しください",
+		English:  "Too many requests. Please try again later",
+	},
+	Internal: {
+		Japanese: "サーバーでエラーが発生しました",
+		English:  "An internal server error occurred",
+	},
+}
+
+var reasonMessages = map[Reason]map[Lang]string{
+	ReasonNotExist: {
+		Japanese: "存在しないデータが指定されています",
+		English:  "Some of the specified items do not exist",
+	},
+	ReasonAlreadyUsed: {
+		Japanese: "既に使用されています",
+		English:  "Already in use",
+	},
+}
+
+func (l Lang) codeMessage(code Code) string {
+	if m, ok := codeMessages[code]; ok {
+		return m[l]
+	}
+	return codeMessages[Internal][l]
+}
+
+func (l Lang) reasonMessage(reason Reason) string {
+	return reasonMessages[reason][l]
+}
@@ -2,8 +2,11 @@ package binder
 
 import (
 	"fmt"
-	"net/http"
 	"shin-monta-no-mori/internal/app"
+	"shin-monta-no-mori/pkg/lib/apperror"
+	"shin-monta-no-mori/pkg/lib/cursor"
+	"shin-monta-no-mori/pkg/lib/etag"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,8 +14,36 @@ import (
 // BindQuery クエリパラメータと構造体をバインドする
 func BindQuery(ctx *gin.Context, req interface{}) error {
 	if err := ctx.ShouldBindQuery(req); err != nil {
-		ctx.JSON(http.StatusBadRequest, app.ErrorResponse(fmt.Errorf("failed to c.ShouldBindQuery : %w", err)))
+		app.AbortWithError(ctx, apperror.BadRequest, fmt.Errorf("failed to c.ShouldBindQuery : %w", err))
 		return err
 	}
 	return nil
 }
+
+// BindCursor クエリパラメータで受け取ったカーソルをデコードする
+func BindCursor(ctx *gin.Context, s string) (cursor.Cursor, error) {
+	c, err := cursor.Decode(s)
+	if err != nil {
+		app.AbortWithError(ctx, apperror.BadRequest, fmt.Errorf("failed to decode cursor : %w", err))
+		return cursor.Cursor{}, err
+	}
+	return c, nil
+}
+
+// BindIfMatch If-Matchヘッダーで受け取ったETagを更新日時に変換する
+// ヘッダーがない場合は428、形式が不正な場合は400を返す
+func BindIfMatch(ctx *gin.Context) (time.Time, error) {
+	s := ctx.GetHeader("If-Match")
+	if s == "" {
+		err := fmt.Errorf("If-Match header is required")
+		app.AbortWithError(ctx, apperror.PreconditionRequired, err)
+		return time.Time{}, err
+	}
+
+	t, err := etag.Parse(s)
+	if err != nil {
+		app.AbortWithError(ctx, apperror.BadRequest, fmt.Errorf("failed to parse If-Match header : %w", err))
+		return time.Time{}, err
+	}
+	return t, nil
+}
@@ -0,0 +1,60 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor はキーセットページネーションで次ページの起点となる値を保持する
+// id順の一覧ではIDのみ、priority_level順の一覧ではPriorityLevel、検索結果の関連度順ではRankも使用する
+// 並び順を指定した一覧では、並び順のSortと、その並び替えに使用する値も使用する
+// 手動で並び替えた一覧では、表示順のPositionも使用する
+type Cursor struct {
+	ID            int64   `json:"id"`
+	PriorityLevel int16   `json:"pl,omitempty"`
+	Rank          float32 `json:"r,omitempty"`
+	Sort          string  `json:"s,omitempty"`
+	Title         string  `json:"t,omitempty"`
+	// マイクロ秒単位のUNIX時間
+	UpdatedAt int64 `json:"u,omitempty"`
+	ViewCount int64 `json:"v,omitempty"`
+	Position  int32 `json:"p,omitempty"`
+}
+
+// Encode はCursorをクライアントに返す不透明な文字列に変換する
+func Encode(c Cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode はクライアントから受け取った文字列をCursorに変換する
+func Decode(s string) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w : %v", ErrInvalidCursor, err)
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return Cursor{}, fmt.Errorf("%w : %v", ErrInvalidCursor, err)
+	}
+	if c.ID <= 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// Next は取得件数がlimitに達している場合に、最後の要素から次ページのカーソルを生成する
+// limitに満たない場合は次ページが存在しないため空文字を返す
+func Next[T any](items []T, limit int, cursorOf func(T) Cursor) string {
+	if limit <= 0 || len(items) < limit {
+		return ""
+	}
+
+	return Encode(cursorOf(items[len(items)-1]))
+}
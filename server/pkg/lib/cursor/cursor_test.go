@@ -0,0 +1,95 @@
+package cursor_test
+
+import (
+	"shin-monta-no-mori/pkg/lib/cursor"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	testCases := []struct {
+		name          string
+		setup         func() string
+		checkResponse func(t *testing.T, c cursor.Cursor, err error)
+	}{
+		{
+			name: "正常系 (idのみ)",
+			setup: func() string {
+				return cursor.Encode(cursor.Cursor{ID: 100})
+			},
+			checkResponse: func(t *testing.T, c cursor.Cursor, err error) {
+				require.NoError(t, err)
+				require.Equal(t, cursor.Cursor{ID: 100}, c)
+			},
+		},
+		{
+			name: "正常系 (priority_levelあり)",
+			setup: func() string {
+				return cursor.Encode(cursor.Cursor{ID: 5, PriorityLevel: 3})
+			},
+			checkResponse: func(t *testing.T, c cursor.Cursor, err error) {
+				require.NoError(t, err)
+				require.Equal(t, cursor.Cursor{ID: 5, PriorityLevel: 3}, c)
+			},
+		},
+		{
+			name: "正常系 (並び順あり)",
+			setup: func() string {
+				return cursor.Encode(cursor.Cursor{ID: 7, Sort: "title", Title: "タイトル"})
+			},
+			checkResponse: func(t *testing.T, c cursor.Cursor, err error) {
+				require.NoError(t, err)
+				require.Equal(t, cursor.Cursor{ID: 7, Sort: "title", Title: "タイトル"}, c)
+			},
+		},
+		{
+			name: "正常系 (表示順あり)",
+			setup: func() string {
+				return cursor.Encode(cursor.Cursor{ID: 9, Sort: "manual", Position: 2})
+			},
+			checkResponse: func(t *testing.T, c cursor.Cursor, err error) {
+				require.NoError(t, err)
+				require.Equal(t, cursor.Cursor{ID: 9, Sort: "manual", Position: 2}, c)
+			},
+		},
+		{
+			name: "異常系 (base64ではない文字列)",
+			setup: func() string {
+				return "!!!"
+			},
+			checkResponse: func(t *testing.T, c cursor.Cursor, err error) {
+				require.ErrorIs(t, err, cursor.ErrInvalidCursor)
+			},
+		},
+		{
+			name: "異常系 (idが不正)",
+			setup: func() string {
+				return cursor.Encode(cursor.Cursor{ID: 0})
+			},
+			checkResponse: func(t *testing.T, c cursor.Cursor, err error) {
+				require.ErrorIs(t, err, cursor.ErrInvalidCursor)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := cursor.Decode(tc.setup())
+			tc.checkResponse(t, c, err)
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	cursorOf := func(id int64) cursor.Cursor { return cursor.Cursor{ID: id} }
+
+	// limitに達していない場合は次ページなし
+	require.Empty(t, cursor.Next([]int64{3, 2}, 3, cursorOf))
+
+	// limitに達している場合は最後の要素がカーソルになる
+	next := cursor.Next([]int64{3, 2, 1}, 3, cursorOf)
+	c, err := cursor.Decode(next)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), c.ID)
+}
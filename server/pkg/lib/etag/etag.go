@@ -0,0 +1,33 @@
+package etag
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidETag = errors.New("invalid etag")
+
+// Format は更新日時からETagを生成する
+// DBの更新日時はマイクロ秒単位のため、マイクロ秒単位のUNIX時間を使用する
+func Format(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(updatedAt.UnixMicro(), 10) + `"`
+}
+
+// Parse はIf-Matchヘッダーで受け取ったETagを更新日時に変換する
+// 弱いETag(W/"...")も受け付けるが、複数のETagや"*"は受け付けない
+func Parse(s string) (time.Time, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "W/")
+	if len(s) < 2 || !strings.HasPrefix(s, `"`) || !strings.HasSuffix(s, `"`) {
+		return time.Time{}, fmt.Errorf("%w : %q", ErrInvalidETag, s)
+	}
+
+	micro, err := strconv.ParseInt(s[1:len(s)-1], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w : %v", ErrInvalidETag, err)
+	}
+
+	return time.UnixMicro(micro), nil
+}
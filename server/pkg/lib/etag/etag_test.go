@@ -0,0 +1,63 @@
+package etag_test
+
+import (
+	"shin-monta-no-mori/pkg/lib/etag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatParse(t *testing.T) {
+	updatedAt := time.Date(2024, 5, 1, 12, 30, 0, 123456000, time.UTC)
+
+	testCases := []struct {
+		name    string
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name:  "正常系",
+			input: etag.Format(updatedAt),
+			want:  updatedAt,
+		},
+		{
+			name:  "正常系 (弱いETag)",
+			input: "W/" + etag.Format(updatedAt),
+			want:  updatedAt,
+		},
+		{
+			name:    "異常系 (引用符で囲まれていない場合)",
+			input:   "1714566600123456",
+			wantErr: true,
+		},
+		{
+			name:    "異常系 (*の場合)",
+			input:   "*",
+			wantErr: true,
+		},
+		{
+			name:    "異常系 (複数のETagの場合)",
+			input:   `"1", "2"`,
+			wantErr: true,
+		},
+		{
+			name:    "異常系 (空の場合)",
+			input:   "",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := etag.Parse(tc.input)
+			if tc.wantErr {
+				require.ErrorIs(t, err, etag.ErrInvalidETag)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, tc.want.Equal(got))
+		})
+	}
+}
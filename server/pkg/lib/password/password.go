@@ -1,6 +1,8 @@
 package password
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 
 	"golang.org/x/crypto/bcrypt"
@@ -21,6 +23,35 @@ func CheckPassword(password string, hashedPassword string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
 
+// Generate returns a random password, used as the initial password of invited users
+func Generate() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// dummyHash is the hash compared when the user does not exist.
+// It is computed at package init so that the first lookup is not slower than the others
+var dummyHash = mustHashPassword("dummy password for unknown users")
+
+func mustHashPassword(password string) string {
+	hashed, err := HashPassword(password)
+	if err != nil {
+		panic(err)
+	}
+
+	return hashed
+}
+
+// CheckDummyPassword takes as long as CheckPassword, so that the response time
+// does not reveal whether the user exists
+func CheckDummyPassword(password string) {
+	_ = CheckPassword(password, dummyHash)
+}
+
 // CheckPassword checks if the provider email is correct or not
 func CheckEmail(gotEmail string, email string) error {
 	if gotEmail == email {
@@ -0,0 +1,26 @@
+package textsearch
+
+// Synonyms は正規化した語ごとに、検索時に展開する同義語を保持する
+type Synonyms map[string][]string
+
+// Add は語に同義語を追加する
+// 語・同義語はいずれも正規化して保持する
+func (s Synonyms) Add(word, synonym string) {
+	word, synonym = Normalize(word), Normalize(synonym)
+	if word == "" || synonym == "" || word == synonym {
+		return
+	}
+
+	for _, v := range s[word] {
+		if v == synonym {
+			return
+		}
+	}
+	s[word] = append(s[word], synonym)
+}
+
+// Expand は正規化した語と、その語に登録されている同義語を返す
+func (s Synonyms) Expand(word string) []string {
+	word = Normalize(word)
+	return append([]string{word}, s[word]...)
+}
@@ -0,0 +1,44 @@
+package textsearch_test
+
+import (
+	"shin-monta-no-mori/pkg/lib/textsearch"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSynonymsExpand(t *testing.T) {
+	synonyms := textsearch.Synonyms{}
+	synonyms.Add("ねこ", "猫")
+	synonyms.Add("ネコ", "ｷｬｯﾄ")
+	synonyms.Add("ねこ", "猫")
+	synonyms.Add("いぬ", "イヌ")
+
+	testCases := []struct {
+		name string
+		word string
+		want []string
+	}{
+		{
+			name: "正規化した語で同義語を展開",
+			word: "ネコ",
+			want: []string{"ねこ", "猫", "きゃっと"},
+		},
+		{
+			name: "正規化して同じになる同義語は登録しない",
+			word: "いぬ",
+			want: []string{"いぬ"},
+		},
+		{
+			name: "同義語が登録されていない語",
+			word: "森",
+			want: []string{"森"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, synonyms.Expand(tc.word))
+		})
+	}
+}
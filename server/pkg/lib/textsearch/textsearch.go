@@ -0,0 +1,166 @@
+package textsearch
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// tsvectorで扱える位置の最大値
+const maxPosition = 16383
+
+// 検索結果のランキングに使用する重み
+const (
+	WeightA = 'A'
+	WeightB = 'B'
+	WeightC = 'C'
+	WeightD = 'D'
+)
+
+// Section は同じ重みで検索対象とするテキストを表す
+type Section struct {
+	Text   string
+	Weight rune
+}
+
+// Normalize は表記揺れを吸収するため、テキストを検索用の表記に揃える
+// 全角・半角はNFKCで統一し、カタカナはひらがなに、英字は小文字に変換する
+// DBのnormalize_search_text関数と同じ変換を行う
+func Normalize(text string) string {
+	return strings.ToLower(strings.Map(katakanaToHiragana, norm.NFKC.String(text)))
+}
+
+func katakanaToHiragana(r rune) rune {
+	// ァ(U+30A1)〜ヶ(U+30F6)、ヽ(U+30FD)〜ヾ(U+30FE)はひらがなと同じ並びで配置されている
+	if ('ァ' <= r && r <= 'ヶ') || ('ヽ' <= r && r <= 'ヾ') {
+		return r - ('ァ' - 'ぁ')
+	}
+
+	return r
+}
+
+// Tokenize はテキストを正規化し、検索用のトークンに分割する
+// 英数字の連続はそのまま1トークンとし、日本語などそれ以外の文字の連続はbigramに分割する
+// 1文字での検索にも一致するように、bigramの末尾には最後の1文字をトークンとして追加する
+func Tokenize(text string) []string {
+	tokens := []string{}
+	for _, word := range splitWords(Normalize(text)) {
+		if isASCII(word) {
+			tokens = append(tokens, word)
+			continue
+		}
+		tokens = append(tokens, bigrams(word)...)
+	}
+
+	return tokens
+}
+
+// Vector はテキストをトークンに分割し、位置と重みを付けたtsvectorの形式に変換する
+func Vector(sections ...Section) string {
+	lexemes := []string{}
+	pos := 0
+	for _, section := range sections {
+		for _, token := range Tokenize(section.Text) {
+			if pos < maxPosition {
+				pos++
+			}
+			lexemes = append(lexemes, fmt.Sprintf("%s:%d%c", quote(token), pos, section.Weight))
+		}
+	}
+
+	return strings.Join(lexemes, " ")
+}
+
+// Query は検索文字列をtsqueryの形式に変換する
+// 空白区切りの各語、および語を分割したトークンは全てAND条件で結合する
+// 語に同義語が登録されている場合は、元の語と同義語のいずれかに一致すればよい
+// 英数字の語と1文字の語は前方一致で検索する
+// 検索できるトークンが含まれない場合は空文字を返す
+func Query(q string, synonyms Synonyms) string {
+	terms := []string{}
+	for _, word := range splitWords(Normalize(q)) {
+		alternatives := [][]string{}
+		for _, w := range synonyms.Expand(word) {
+			if t := wordTerms(w); len(t) > 0 {
+				alternatives = append(alternatives, t)
+			}
+		}
+
+		switch len(alternatives) {
+		case 0:
+		case 1:
+			terms = append(terms, alternatives[0]...)
+		default:
+			ors := make([]string, len(alternatives))
+			for i, t := range alternatives {
+				ors[i] = group(t, " & ")
+			}
+			terms = append(terms, group(ors, " | "))
+		}
+	}
+
+	return strings.Join(terms, " & ")
+}
+
+// wordTerms は語をAND条件で結合するtsqueryの項に変換する
+// 同義語が複数の語からなる場合は、全ての語を含むものに一致させる
+func wordTerms(text string) []string {
+	terms := []string{}
+	for _, word := range splitWords(text) {
+		if isASCII(word) || len([]rune(word)) == 1 {
+			terms = append(terms, quote(word)+":*")
+			continue
+		}
+
+		// 語の末尾の1文字は前のbigramに含まれるため、bigramのみで検索する
+		grams := bigrams(word)
+		for _, g := range grams[:len(grams)-1] {
+			terms = append(terms, quote(g))
+		}
+	}
+
+	return terms
+}
+
+func group(terms []string, op string) string {
+	if len(terms) == 1 {
+		return terms[0]
+	}
+
+	return "( " + strings.Join(terms, op) + " )"
+}
+
+// splitWords は文字・数字以外を区切りとしてテキストを語に分割する
+func splitWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func bigrams(word string) []string {
+	runes := []rune(word)
+	grams := make([]string, 0, len(runes))
+	for i := 0; i < len(runes)-1; i++ {
+		grams = append(grams, string(runes[i:i+2]))
+	}
+
+	return append(grams, string(runes[len(runes)-1]))
+}
+
+func isASCII(word string) bool {
+	for _, r := range word {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+
+	return true
+}
+
+// quote はトークンをtsvector・tsqueryのlexemeとしてクォートする
+func quote(token string) string {
+	token = strings.ReplaceAll(token, `\`, `\\`)
+	return "'" + strings.ReplaceAll(token, "'", "''") + "'"
+}
@@ -0,0 +1,139 @@
+package textsearch_test
+
+import (
+	"shin-monta-no-mori/pkg/lib/textsearch"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	testCases := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "英数字は1トークン",
+			text: "Monta Forest_2024",
+			want: []string{"monta", "forest", "2024"},
+		},
+		{
+			name: "日本語はbigramと末尾の1文字",
+			text: "もんたの森",
+			want: []string{"もん", "んた", "たの", "の森", "森"},
+		},
+		{
+			name: "記号や空白は区切りとして扱う",
+			text: "うさぎ・ねこ",
+			want: []string{"うさ", "さぎ", "ぎ", "ねこ", "こ"},
+		},
+		{
+			name: "カタカナ・半角カナはひらがなとして扱う",
+			text: "モンタ ﾓﾝﾀﾞ",
+			want: []string{"もん", "んた", "た", "もん", "んだ", "だ"},
+		},
+		{
+			name: "空文字",
+			text: "",
+			want: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, textsearch.Tokenize(tc.text))
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "ひらがなはそのまま",
+			text: "もんた",
+			want: "もんた",
+		},
+		{
+			name: "カタカナはひらがなに変換",
+			text: "モンタ",
+			want: "もんた",
+		},
+		{
+			name: "半角カナは濁点を含めてひらがなに変換",
+			text: "ﾓﾝﾀ ｶﾞｰﾃﾞﾝ",
+			want: "もんた がーでん",
+		},
+		{
+			name: "全角英数字は半角の小文字に変換",
+			text: "ＭＯＮＴＡ１２３　Forest",
+			want: "monta123 forest",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, textsearch.Normalize(tc.text))
+		})
+	}
+}
+
+func TestVector(t *testing.T) {
+	got := textsearch.Vector(
+		textsearch.Section{Text: "もんた", Weight: textsearch.WeightA},
+		textsearch.Section{Text: "monta", Weight: textsearch.WeightC},
+	)
+	require.Equal(t, "'もん':1A 'んた':2A 'た':3A 'monta':4C", got)
+
+	require.Empty(t, textsearch.Vector(textsearch.Section{Text: "", Weight: textsearch.WeightA}))
+}
+
+func TestQuery(t *testing.T) {
+	synonyms := textsearch.Synonyms{}
+	synonyms.Add("ねこ", "猫")
+	synonyms.Add("ねこ", "キャット")
+
+	testCases := []struct {
+		name     string
+		q        string
+		synonyms textsearch.Synonyms
+		want     string
+	}{
+		{
+			name: "複数語はAND条件",
+			q:    "もんた forest",
+			want: "'もん' & 'んた' & 'forest':*",
+		},
+		{
+			name: "1文字は前方一致",
+			q:    "森",
+			want: "'森':*",
+		},
+		{
+			name: "全角・カタカナは正規化して検索",
+			q:    "ＭＯＮＴＡ モンタ",
+			want: "'monta':* & 'もん' & 'んた'",
+		},
+		{
+			name:     "同義語はOR条件で展開",
+			q:        "ネコ 森",
+			synonyms: synonyms,
+			want:     "( 'ねこ' | '猫':* | ( 'きゃ' & 'ゃっ' & 'っと' ) ) & '森':*",
+		},
+		{
+			name: "検索できるトークンがない場合",
+			q:    " ・ ",
+			want: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, textsearch.Query(tc.q, tc.synonyms))
+		})
+	}
+}
@@ -33,13 +33,15 @@ func TestPasetoMaker(t *testing.T) {
 		{
 			name: "正常系",
 			setupToken: func() (string, *token.Payload, error) {
-				return maker.CreateToken("testuser", time.Minute)
+				return maker.CreateToken("testuser", token.RoleEditor, token.TokenTypeAccess, time.Minute)
 			},
 			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
 				require.NoError(t, err)
 				require.NotNil(t, payload)
 				require.NotZero(t, payload.ID)
 				require.Equal(t, "testuser", payload.Username)
+				require.Equal(t, token.RoleEditor, payload.Role)
+				require.Equal(t, token.TokenTypeAccess, payload.Type)
 				require.WithinDuration(t, time.Now().Add(time.Minute), payload.ExpiredAt, time.Second)
 			},
 		},
@@ -57,7 +59,7 @@ func TestPasetoMaker(t *testing.T) {
 		{
 			name: "異常系（ExpiredToken）",
 			setupToken: func() (string, *token.Payload, error) {
-				return maker.CreateToken("testuser", -time.Minute)
+				return maker.CreateToken("testuser", token.RoleEditor, token.TokenTypeAccess, -time.Minute)
 			},
 			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
 				require.Error(t, err)
@@ -10,18 +10,53 @@ import (
 var (
 	ErrExpiredToken = errors.New("token has expired")
 	ErrInvalidToken = errors.New("toke is invalid")
+	// ErrUnexpectedTokenType is returned when a token is used for a purpose other than its type
+	ErrUnexpectedTokenType = errors.New("token type is not allowed")
 )
 
+// TokenType distinguishes access tokens from refresh tokens issued for the same session
+type TokenType string
+
+const (
+	// TokenTypeAccess is used to call the admin APIs
+	TokenTypeAccess TokenType = "access"
+	// TokenTypeRefresh is only used to issue new tokens
+	TokenTypeRefresh TokenType = "refresh"
+)
+
+// Role is the role of the operator, which decides the operations allowed in the admin
+type Role string
+
+const (
+	// RoleOwner can do all operations, including managing operators
+	RoleOwner Role = "owner"
+	// RoleEditor can view and edit the contents
+	RoleEditor Role = "editor"
+	// RoleViewer can only view the contents
+	RoleViewer Role = "viewer"
+)
+
+// Valid reports whether the role is one of the defined roles
+func (r Role) Valid() bool {
+	switch r {
+	case RoleOwner, RoleEditor, RoleViewer:
+		return true
+	}
+	return false
+}
+
 // payload contains the payload data of the token
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
 	Username  string    `json:"username"`
+	Role      Role      `json:"role"`
+	Type      TokenType `json:"type"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
-// NewPayload creates a new token payload with a specific username and duration
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+// NewPayload creates a new token payload with a specific username, role, token type and duration
+func NewPayload(username string, role Role, tokenType TokenType, duration time.Duration) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -30,6 +65,8 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 	payload := &Payload{
 		ID:        tokenID,
 		Username:  username,
+		Role:      role,
+		Type:      tokenType,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
@@ -43,3 +80,13 @@ func (payload *Payload) Valid() error {
 
 	return nil
 }
+
+// Expect checks that the token is of the given type.
+// Tokens issued without a type are rejected as well.
+func (payload *Payload) Expect(tokenType TokenType) error {
+	if payload.Type != tokenType {
+		return ErrUnexpectedTokenType
+	}
+
+	return nil
+}
@@ -11,9 +11,22 @@ type Config struct {
 	Environment   string `mapstructure:"ENVIRONMENT"`
 	ServerAddress string `mapstructure:"SERVER_ADDRESS"`
 	Origin        string `mapstructure:"ORIGIN"`
+	// X-Forwarded-ForなどからクライアントのIPを取得する際に信頼するプロキシ(IPまたはCIDR、カンマ区切り)
+	// 指定しない場合は接続元のIPをクライアントのIPとする
+	TrustedProxies []string `mapstructure:"TRUSTED_PROXIES"`
 
 	// Image
 	ImageFetchLimit int `mapstructure:"IMAGE_FETCH_LIMIT"`
+	// 今日のイラストとして同じイラストを選ばない日数
+	DailyIllustrationNoRepeatDays int `mapstructure:"DAILY_ILLUSTRATION_NO_REPEAT_DAYS"`
+	// 溜めたイラストの閲覧数をDBに書き込む間隔
+	ViewCountFlushInterval time.Duration `mapstructure:"VIEW_COUNT_FLUSH_INTERVAL"`
+
+	// Trash
+	// ゴミ箱に移動したデータを完全に削除するまでの日数
+	TrashRetentionDays int `mapstructure:"TRASH_RETENTION_DAYS"`
+	// 保持期間を過ぎたデータを削除する間隔
+	TrashPurgeInterval time.Duration `mapstructure:"TRASH_PURGE_INTERVAL"`
 
 	// Character
 	CharacterFetchLimit int `mapstructure:"CHARACTER_FETCH_LIMIT"`
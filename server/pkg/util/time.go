@@ -0,0 +1,22 @@
+package util
+
+import "time"
+
+// JST は日本標準時
+// コンテナにタイムゾーンのデータがなくても使用できるよう、固定のオフセットで定義する
+var JST = time.FixedZone("Asia/Tokyo", 9*60*60)
+
+const dateLayout = "2006-01-02"
+
+// ParseDate はYYYY-MM-DD形式の日付を、日本時間のその日の0時として解釈する
+func ParseDate(s string) (time.Time, error) {
+	return time.ParseInLocation(dateLayout, s, JST)
+}
+
+// UntilNextDay は日本時間で翌日の0時になるまでの時間を返す
+func UntilNextDay(now time.Time) time.Duration {
+	t := now.In(JST)
+	next := time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, JST)
+
+	return next.Sub(now)
+}